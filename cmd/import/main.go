@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"os"
+	"path/filepath"
+	"rider-service/config"
+	"rider-service/internal/core/interfaces"
+	"rider-service/internal/core/services"
+	"rider-service/internal/repositories"
+	"rider-service/pkg/azure"
+	"rider-service/pkg/dto"
+	"rider-service/pkg/rabbitmq"
+	"strings"
+)
+
+const defaultConfig = "./config/local.config"
+
+// The import command creates riders in bulk from a CSV or NDJSON file like POST /api/riders/import does, but straight
+// against the database and message bus of the service. Without -commit the rows are only validated.
+//
+//	go run cmd/import/main.go -file riders.csv -commit
+func main() {
+	file := flag.String("file", "", "CSV or NDJSON file with the riders to import")
+	format := flag.String("format", "", "Format of the file, csv or ndjson; taken from the file extension when empty")
+	commit := flag.Bool("commit", false, "Create the riders instead of only validating them")
+	broker := flag.String("broker", "rabbitmq", "Message bus rider.create is published on, rabbitmq or azure")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+	}
+
+	cfg, err := config.UseConfig(GetEnvOrDefault("config", defaultConfig))
+
+	if err != nil {
+		exit(err)
+	}
+
+	input, err := os.Open(*file)
+
+	if err != nil {
+		exit(err)
+	}
+
+	defer input.Close()
+
+	rows, err := dto.ParseRiderImport(input, *format)
+
+	if err != nil {
+		exit(err)
+	}
+
+	dsn := fmt.Sprintf("host=%s port=%d user=%s "+
+		"password=%s dbname=%s sslmode=%s",
+		cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password, cfg.Database.Database, cfg.Database.SSLMode)
+	db, err := gorm.Open(postgres.Open(dsn))
+
+	if err != nil {
+		exit(err)
+	}
+
+	riderRepository, err := repositories.NewRiderRepository(db)
+
+	if err != nil {
+		exit(err)
+	}
+
+	publisher, err := newPublisher(*broker, cfg)
+
+	if err != nil {
+		exit(err)
+	}
+
+	riderService := services.NewRiderService(riderRepository, publisher, cfg)
+
+	results, err := riderService.ImportRiders(context.Background(), rows, *commit)
+
+	if err != nil {
+		exit(err)
+	}
+
+	report := dto.CreateRiderImportResponse(results, !*commit)
+
+	for _, row := range report.Rows {
+		fmt.Printf("line %d\t%s\t%s", row.Line, row.ID, row.Status)
+
+		if row.Detail != "" {
+			fmt.Printf("\t%s", row.Detail)
+		}
+
+		for _, field := range row.Errors {
+			fmt.Printf("\t%s %s", field.Field, field.Message)
+		}
+
+		fmt.Println()
+	}
+
+	fmt.Printf("%d created, %d valid, %d failed\n", report.Created, report.Valid, report.Failed)
+
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func newPublisher(broker string, cfg *config.Config) (interfaces.MessageBusPublisher, error) {
+	switch broker {
+	case "rabbitmq":
+		rmqServer, err := rabbitmq.NewRabbitMQ(cfg)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return services.NewRabbitMQPublisher(rmqServer, trace.NewNoopTracerProvider(), cfg), nil
+	case "azure":
+		azServer, err := azure.NewAzureServiceBus(cfg)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return services.NewAzurePublisher(azServer, cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown broker %q, use rabbitmq or azure", broker)
+	}
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, "import failed:", err)
+	os.Exit(1)
+}
+
+func GetEnvOrDefault(environmentKey, defaultValue string) string {
+	returnValue := os.Getenv(environmentKey)
+	if returnValue == "" {
+		returnValue = defaultValue
+	}
+	return returnValue
+}
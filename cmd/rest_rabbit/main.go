@@ -2,19 +2,26 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/uptrace/opentelemetry-go-extra/otelgorm"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"net/http"
 	"os"
+	"os/signal"
 	"rider-service/config"
+	"rider-service/internal/app"
 	"rider-service/internal/core/services"
 	"rider-service/internal/handlers"
 	"rider-service/internal/repositories"
 	"rider-service/pkg/logging"
 	"rider-service/pkg/rabbitmq"
 	"rider-service/pkg/tracing"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 
@@ -64,7 +71,14 @@ func main() {
 		db.Debug()
 	}
 
-	if err = db.Use(otelgorm.NewPlugin(otelgorm.WithTracerProvider(tracer))); err != nil {
+	gormTracing := []otelgorm.Option{otelgorm.WithTracerProvider(tracer)}
+
+	// Queries that write locations would otherwise record the coordinates in their spans.
+	if cfg.Privacy.RedactLocations {
+		gormTracing = append(gormTracing, otelgorm.WithoutQueryVariables())
+	}
+
+	if err = db.Use(otelgorm.NewPlugin(gormTracing...)); err != nil {
 		panic(err)
 	}
 
@@ -84,6 +98,18 @@ func main() {
 		logger.Panic(context.Background(), err)
 	}
 
+	idempotencyRepository, err := repositories.NewIdempotencyRepository(db)
+
+	if err != nil {
+		logger.Panic(context.Background(), err)
+	}
+
+	inboxRepository, err := repositories.NewInboxRepository(db)
+
+	if err != nil {
+		logger.Panic(context.Background(), err)
+	}
+
 	//--------------------------------------------------------------------------------------
 	// Setup RabbitMQ
 	//--------------------------------------------------------------------------------------
@@ -100,10 +126,19 @@ func main() {
 	// Setup Services
 	//--------------------------------------------------------------------------------------
 
-	serviceAreaService := services.NewServiceAreaService(serviceAreaRepository)
-	riderService := services.NewRiderService(riderRepository, rmqPublisher)
+	riderService := services.NewRiderService(riderRepository, rmqPublisher, cfg)
+	serviceAreaService := services.NewServiceAreaService(serviceAreaRepository, riderService)
+	statsService := services.NewStatsService(riderRepository, serviceAreaRepository, rmqPublisher, cfg)
+
+	routeEstimator, err := services.NewRouteEstimator(tracer, cfg)
+
+	if err != nil {
+		logger.Panic(context.Background(), err)
+	}
+
+	etaService := services.NewETAService(riderService, routeEstimator, cfg)
 
-	rmqSubscriber := handlers.NewRabbitMQ(rmqServer, riderService, serviceAreaService, cfg)
+	rmqSubscriber := handlers.NewRabbitMQ(rmqServer, riderService, serviceAreaService, inboxRepository, cfg)
 
 	//--------------------------------------------------------------------------------------
 	// Setup HTTP server
@@ -112,12 +147,61 @@ func main() {
 	router := gin.New()
 	router.Use(otelgin.Middleware(cfg.Server.Service, otelgin.WithTracerProvider(tracer)))
 
-	riderHandler := handlers.NewHTTPHandler(riderService, router, logger, cfg)
+	riderHandler := handlers.NewHTTPHandler(riderService, serviceAreaService, statsService, etaService, idempotencyRepository, router, logger, cfg)
 	riderHandler.SetupEndpoints()
 	riderHandler.SetupSwagger()
 
-	go rmqSubscriber.Listen()
-	logger.Fatal(context.Background(), router.Run(cfg.Server.Port))
+	if err := riderHandler.SetupAsyncAPI(rmqPublisher, rmqSubscriber); err != nil {
+		logger.Panic(context.Background(), err)
+	}
+
+	//--------------------------------------------------------------------------------------
+	// Setup gRPC server
+	//--------------------------------------------------------------------------------------
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			otelgrpc.UnaryServerInterceptor(otelgrpc.WithTracerProvider(tracer)),
+			handlers.UnaryLoggingInterceptor(logger),
+			handlers.UnaryAuthInterceptor,
+		),
+		grpc.ChainStreamInterceptor(
+			otelgrpc.StreamServerInterceptor(otelgrpc.WithTracerProvider(tracer)),
+			handlers.StreamLoggingInterceptor(logger),
+			handlers.StreamAuthInterceptor,
+		),
+	)
+
+	grpcHandler := handlers.NewGrpcHandler(riderService, grpcServer, logger, cfg)
+	grpcHandler.SetupEndpoints()
+	grpcHandler.SetupHealthprobe()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.Grpc.Port != "" {
+		go app.ServeGrpc(grpcServer, cfg.Grpc.Port, logger)
+	}
+
+	rmqSubscriber.Listen()
+
+	background := app.NewBackground(ctx)
+	background.Every(cfg.Rider.LocationFlushInterval, app.FlushLocations(riderService, logger))
+	background.Every(cfg.Presence.CheckInterval, app.MonitorPresence(riderService, logger))
+	background.Every(cfg.Stats.PublishInterval, app.PublishStats(statsService, logger))
+
+	server := &http.Server{Addr: cfg.Server.Port, Handler: router}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatal(context.Background(), err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+
+	app.Shutdown(server, grpcServer, rmqSubscriber, background, riderService, logger)
 }
 
 func GetEnvOrDefault(environmentKey, defaultValue string) string {
@@ -7,14 +7,26 @@ import (
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 	"strings"
+	"time"
 )
 
 type Config struct {
 	Server          Server
+	Grpc            Grpc
 	RabbitMQ        RabbitMQ
 	AzureServiceBus AzureServiceBus
 	Database        Database
 	Tracing         Tracing
+	Rider           Rider
+	Idempotency     Idempotency
+	Inbox           Inbox
+	Presence        Presence
+	Plausibility    Plausibility
+	Privacy         Privacy
+	Import          Import
+	Stats           Stats
+	Heatmap         Heatmap
+	ETA             ETA
 }
 
 type Server struct {
@@ -23,6 +35,13 @@ type Server struct {
 	Description string
 }
 
+type Grpc struct {
+	// Port is the address the gRPC server listens on. The server is not started without one.
+	Port string
+	// WatchInterval is how often the riders of a WatchRiders call are checked for changes.
+	WatchInterval time.Duration
+}
+
 type RabbitMQ struct {
 	Host     string
 	Port     int
@@ -51,12 +70,110 @@ type Tracing struct {
 	Port int
 }
 
+type Rider struct {
+	MaxCapacityDimension int
+	// MaxLocationAccuracy is the accuracy radius in meters above which fixes are rejected. Zero accepts every fix.
+	MaxLocationAccuracy float64
+	// LocationFlushInterval is how often buffered rider locations are written to the database.
+	// With an interval of zero every location is written right away.
+	LocationFlushInterval time.Duration
+}
+
+type Idempotency struct {
+	TTL time.Duration
+}
+
+type Inbox struct {
+	Retention time.Duration
+}
+
+type Plausibility struct {
+	// MaxSpeed is the highest speed in meters per second a rider can plausibly travel between two fixes. Zero disables the check.
+	MaxSpeed float64
+	// JitterRadius is the distance in meters within which a fix does not move the rider. Zero disables smoothing.
+	JitterRadius float64
+	// Bounds is the area riders work in, fixes outside of it are rejected. The zero box disables the check.
+	Bounds Bounds
+}
+
+type Bounds struct {
+	MinLatitude  float64
+	MaxLatitude  float64
+	MinLongitude float64
+	MaxLongitude float64
+}
+
+type Privacy struct {
+	// CoarseGridSize is the size in degrees of the grid locations are snapped to for viewers that may not see
+	// the exact location. Zero shares exact locations with everyone.
+	CoarseGridSize float64
+	// RedactLocations leaves locations out of log lines and trace spans.
+	RedactLocations bool
+}
+
+type Import struct {
+	// PublishRate is how many riders a bulk import creates, and publishes, per second. Zero does not limit the rate.
+	PublishRate float64
+	// MaxRows is the largest number of rows a single import can have.
+	MaxRows int
+}
+
+type Presence struct {
+	// Timeout is how long a rider can go without sending a location or heartbeat before it is set offline.
+	Timeout time.Duration
+	// AreaTimeouts overrides the timeout for service areas, keyed by the lower case identifier of the area.
+	AreaTimeouts map[string]time.Duration
+	// CheckInterval is how often silent riders are looked for. With an interval of zero riders are never set offline.
+	CheckInterval time.Duration
+}
+
+type Stats struct {
+	// StaleAfter is how long an online rider can go without sending a location or heartbeat before its location
+	// counts as stale in the stats.
+	StaleAfter time.Duration
+	// PublishInterval is how often the stats of every service area are published. Zero does not publish them.
+	PublishInterval time.Duration
+}
+
+type Heatmap struct {
+	// Precision is the geohash length of the cells of a heatmap that does not ask for one.
+	Precision int
+	// MaxPrecision is the longest geohash a heatmap can ask for. Cells much smaller than the coarse privacy grid
+	// would give away where available riders are.
+	MaxPrecision int
+	// HistoryWindow is how far back a history heatmap that does not ask for a start counts locations.
+	HistoryWindow time.Duration
+}
+
+type ETA struct {
+	// Estimator is haversine, which estimates routes from the distance as the crow flies, or osrm.
+	Estimator string
+	// Speeds are the average speeds in km/h of the haversine estimator, keyed by vehicle type.
+	Speeds map[string]float64
+	// DetourFactor is how much longer the haversine estimator takes a route to be than the distance as the crow flies.
+	DetourFactor float64
+	// MaxRiders is the largest number of riders an ETA can be asked for at once.
+	MaxRiders int
+	OSRM      OSRM
+}
+
+type OSRM struct {
+	// URL is the base URL of an OSRM compatible routing service.
+	URL string
+	// Profiles are the routing profiles, keyed by vehicle type. Vehicle types without a profile use the bicycle one.
+	Profiles map[string]string
+	Timeout  time.Duration
+}
+
 func initDefaultValues() *Config {
 	defaultConfig := &Config{}
 	defaultConfig.Server.Service = "rider-service"
 	defaultConfig.Server.Port = "1234"
 	defaultConfig.Server.Description = "Bikepack Rider Service"
 
+	defaultConfig.Grpc.Port = ":50051"
+	defaultConfig.Grpc.WatchInterval = 2 * time.Second
+
 	defaultConfig.RabbitMQ.Host = "localhost"
 	defaultConfig.RabbitMQ.Port = 5672
 	defaultConfig.RabbitMQ.User = "user"
@@ -76,6 +193,41 @@ func initDefaultValues() *Config {
 	defaultConfig.Tracing.Host = ""
 	defaultConfig.Tracing.Port = 0
 
+	defaultConfig.Rider.MaxCapacityDimension = 200
+	defaultConfig.Rider.LocationFlushInterval = time.Second
+	defaultConfig.Rider.MaxLocationAccuracy = 100
+
+	defaultConfig.Idempotency.TTL = 24 * time.Hour
+
+	defaultConfig.Inbox.Retention = 7 * 24 * time.Hour
+
+	defaultConfig.Plausibility.MaxSpeed = 40
+	defaultConfig.Plausibility.JitterRadius = 10
+
+	defaultConfig.Privacy.CoarseGridSize = 0.01
+	defaultConfig.Privacy.RedactLocations = true
+
+	defaultConfig.Import.PublishRate = 20
+	defaultConfig.Import.MaxRows = 5000
+
+	defaultConfig.Presence.Timeout = 5 * time.Minute
+	defaultConfig.Presence.CheckInterval = 30 * time.Second
+
+	defaultConfig.Stats.StaleAfter = time.Minute
+	defaultConfig.Stats.PublishInterval = time.Minute
+
+	defaultConfig.Heatmap.Precision = 6
+	defaultConfig.Heatmap.MaxPrecision = 6
+	defaultConfig.Heatmap.HistoryWindow = 24 * time.Hour
+
+	defaultConfig.ETA.Estimator = "haversine"
+	defaultConfig.ETA.Speeds = map[string]float64{"bicycle": 15, "e-bike": 20, "cargo-bike": 12}
+	defaultConfig.ETA.DetourFactor = 1.3
+	defaultConfig.ETA.MaxRiders = 100
+	defaultConfig.ETA.OSRM.URL = "http://localhost:5000"
+	defaultConfig.ETA.OSRM.Profiles = map[string]string{"bicycle": "bike", "e-bike": "bike", "cargo-bike": "bike"}
+	defaultConfig.ETA.OSRM.Timeout = 2 * time.Second
+
 	return defaultConfig
 }
 
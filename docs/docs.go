@@ -16,6 +16,64 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/location-anomalies": {
+            "get": {
+                "description": "reports suspicious rider locations, like impossible jumps and mocked locations, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get location anomalies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rider id",
+                        "name": "rider",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "impossible_jump",
+                            "mock_location"
+                        ],
+                        "type": "string",
+                        "description": "Kind of anomaly",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only anomalies detected at or after this RFC 3339 time",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Maximum number of anomalies, 100 by default",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.LocationAnomalyResponse"
+                            }
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/api/riders": {
             "get": {
                 "description": "gets all riders in the system",
@@ -26,6 +84,43 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "summary": "get all riders",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Only riders with this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Only riders in this service area",
+                        "name": "serviceArea",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Only riders with room left for a parcel this wide, with fitsHeight and fitsDepth",
+                        "name": "fitsWidth",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Only riders with room left for a parcel this high, with fitsWidth and fitsDepth",
+                        "name": "fitsHeight",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Only riders with room left for a parcel this deep, with fitsWidth and fitsHeight",
+                        "name": "fitsDepth",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -35,6 +130,12 @@ const docTemplate = `{
                                 "$ref": "#/definitions/dto.ridersResponse"
                             }
                         }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
                     }
                 }
             },
@@ -56,6 +157,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.BodyCreateRider"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -64,13 +171,246 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.RiderResponse"
                         }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/riders/eta": {
+            "get": {
+                "description": "estimates the routes of a list of candidate riders to a destination, fastest first. Riders without a route are listed last with an error instead of failing the list. At most eta.maxRiders riders can be asked for at once",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get ETAs of riders",
+                "parameters": [
+                    {
+                        "maximum": 90,
+                        "minimum": -90,
+                        "type": "number",
+                        "description": "Latitude of the destination",
+                        "name": "lat",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 180,
+                        "minimum": -180,
+                        "type": "number",
+                        "description": "Longitude of the destination",
+                        "name": "lon",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Rider id, repeated for every rider",
+                        "name": "rider",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.RiderETAResponse"
+                            }
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/riders/export": {
+            "get": {
+                "description": "streams the riders as a GeoJSON FeatureCollection of points, CSV or newline delimited rider responses, with the same filters as the rider list. Locations are as precise as admins may see them",
+                "produces": [
+                    "application/geo+json",
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "summary": "export riders",
+                "parameters": [
+                    {
+                        "enum": [
+                            "geojson",
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Export format, geojson by default",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Only riders with this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Only riders in this service area",
+                        "name": "serviceArea",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Only riders with room left for a parcel this wide, with fitsHeight and fitsDepth",
+                        "name": "fitsWidth",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Only riders with room left for a parcel this high, with fitsWidth and fitsDepth",
+                        "name": "fitsHeight",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Only riders with room left for a parcel this deep, with fitsWidth and fitsHeight",
+                        "name": "fitsDepth",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "One feature of the collection",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RiderFeature"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/riders/import": {
+            "post": {
+                "description": "creates riders in bulk from CSV, with an id, serviceArea, width, height and depth column, or NDJSON with one create body per line. Without commit the rows are only validated. Every row is reported, a failing row does not stop the import",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "import riders",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Format of the body, csv by default",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Create the riders instead of only validating them",
+                        "name": "commit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RiderImportResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/riders/locations:batch": {
+            "post": {
+                "description": "stores buffered fixes of several riders in one transaction, meant for gateways that forward fixes of many devices",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "update locations of several riders from buffered fixes",
+                "parameters": [
+                    {
+                        "description": "Buffered fixes per rider",
+                        "name": "riders",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BodyLocationBatches"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.RiderResponse"
+                            }
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
                     }
                 }
             }
         },
         "/api/riders/{id}": {
             "get": {
-                "description": "gets a rider from the system by its ID",
+                "description": "gets a rider from the system by its ID. The location is only exact for admins and dispatchers while the rider is on a delivery, coarse otherwise and left out while the rider is offline",
                 "produces": [
                     "application/json"
                 ],
@@ -90,6 +430,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.RiderResponse"
                         }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
                     }
                 }
             },
@@ -109,7 +455,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.BodyCreateRider"
+                            "$ref": "#/definitions/dto.BodyUpdateRider"
                         }
                     },
                     {
@@ -118,6 +464,12 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the rider the update is based on",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -126,28 +478,36 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.RiderResponse"
                         }
+                    },
+                    "412": {
+                        "description": "Rider was modified since the given ETag"
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
                     }
                 }
-            }
-        },
-        "/api/riders/{id}/location": {
-            "put": {
-                "description": "updates a rider's location",
+            },
+            "patch": {
+                "description": "updates only the given fields of a rider using a JSON Merge Patch (RFC 7396)",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/merge-patch+json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "update rider location",
+                "summary": "partially update rider",
                 "parameters": [
                     {
-                        "description": "Update rider",
+                        "description": "Rider fields to change",
                         "name": "rider",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.BodyLocation"
+                            "$ref": "#/definitions/dto.BodyPatchRider"
                         }
                     },
                     {
@@ -156,6 +516,12 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the rider the update is based on",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -164,41 +530,785 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.RiderResponse"
                         }
+                    },
+                    "412": {
+                        "description": "Rider was modified since the given ETag"
+                    },
+                    "415": {
+                        "description": "Body is not a merge patch"
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.BodyCreateRider": {
-            "type": "object",
-            "properties": {
-                "capacity": {
-                    "$ref": "#/definitions/dto.CreateDimensions"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "serviceArea": {
-                    "type": "integer"
-                },
-                "status": {
-                    "type": "integer"
+        },
+        "/api/riders/{id}/eta": {
+            "get": {
+                "description": "estimates how far, and how long, a rider is from a destination, starting from its last location. Riders that never sent a location or can not reach the destination are a conflict",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get rider ETA",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rider id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 90,
+                        "minimum": -90,
+                        "type": "number",
+                        "description": "Latitude of the destination",
+                        "name": "lat",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 180,
+                        "minimum": -180,
+                        "type": "number",
+                        "description": "Longitude of the destination",
+                        "name": "lon",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RiderETAResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
+                    }
                 }
             }
         },
-        "dto.BodyLocation": {
-            "type": "object",
-            "properties": {
-                "latitude": {
-                    "type": "number"
+        "/api/riders/{id}/heartbeat": {
+            "post": {
+                "description": "records that a rider is still there without sending a location, riders that send neither are set offline after a while",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "send rider heartbeat",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rider id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RiderResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/riders/{id}/location": {
+            "put": {
+                "description": "updates a rider's location",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "update rider location",
+                "parameters": [
+                    {
+                        "description": "Update rider",
+                        "name": "rider",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BodyLocation"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rider id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RiderResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/riders/{id}/locations:batch": {
+            "post": {
+                "description": "stores the fixes a rider's device buffered while offline and moves the rider to the newest one, fixes older than the rider's current location are skipped",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "update rider location from buffered fixes",
+                "parameters": [
+                    {
+                        "description": "Buffered fixes",
+                        "name": "fixes",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BodyLocationBatch"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rider id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RiderResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/riders/{id}/parcels/{parcel}": {
+            "put": {
+                "description": "puts a parcel in the rider's box, or replaces the parcel with the same id. A parcel that does not fit in the remaining capacity is a conflict, as is a parcel of a delivery",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "load parcel",
+                "parameters": [
+                    {
+                        "description": "Size and weight of the parcel",
+                        "name": "size",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BodyParcel"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rider id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Parcel id",
+                        "name": "parcel",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RiderResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "takes a parcel out of the rider's box. Parcels of a delivery are unloaded when the delivery is completed or cancelled, unloading them is a conflict",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "unload parcel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rider id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Parcel id",
+                        "name": "parcel",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RiderResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/service-areas": {
+            "get": {
+                "description": "gets the service areas riders can be assigned to, deleted service areas are left out",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get service areas",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.ServiceAreaResponse"
+                            }
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/service-areas/stats": {
+            "get": {
+                "description": "gets the overview of the riders of every service area that is not deleted, and the total of all areas",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get stats of all service areas",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StatsSummaryResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/service-areas/{id}/heatmap": {
+            "get": {
+                "description": "counts the riders of a service area per geohash cell, as a GeoJSON FeatureCollection of the cells. The riders source counts the current locations of the riders that are online, the history source the locations riders sent since a moment. Precision can not be higher than heatmap.maxPrecision",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get service area heatmap",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Service area id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "riders",
+                            "history"
+                        ],
+                        "type": "string",
+                        "description": "What to count, riders by default",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 12,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Geohash length of the cells, heatmap.precision by default",
+                        "name": "precision",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only history sent at or after this RFC 3339 time, heatmap.historyWindow ago by default",
+                        "name": "since",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.HeatmapResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/service-areas/{id}/riders": {
+            "get": {
+                "description": "gets the riders of a service area, also of a deleted one to find the riders that still have to be moved",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get riders of service area",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Service area id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.RiderResponse"
+                            }
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/service-areas/{id}/stats": {
+            "get": {
+                "description": "gets an overview of the riders of a service area: riders by status, the average capacity of the riders that are online and the riders whose location is stale",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get service area stats",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Service area id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ServiceAreaStatsResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.BodyCreateRider": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "capacity": {
+                    "$ref": "#/definitions/dto.CreateDimensions"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "serviceArea": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "dto.BodyLocation": {
+            "type": "object",
+            "properties": {
+                "accuracy": {
+                    "description": "Accuracy is the radius in meters the real position is likely within.",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "altitude": {
+                    "description": "Altitude is in meters above the WGS84 ellipsoid.",
+                    "type": "number"
+                },
+                "battery": {
+                    "description": "Battery is the charge of the device in percent.",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0
+                },
+                "heading": {
+                    "description": "Heading is in degrees clockwise from true north.",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "latitude": {
+                    "type": "number",
+                    "maximum": 90,
+                    "minimum": -90
+                },
+                "longitude": {
+                    "type": "number",
+                    "maximum": 180,
+                    "minimum": -180
+                },
+                "mocked": {
+                    "description": "Mocked is set when the device reports that the position comes from a mock location provider.",
+                    "type": "boolean"
+                },
+                "sequence": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "speed": {
+                    "description": "Speed is in meters per second.",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BodyLocationBatch": {
+            "type": "object",
+            "required": [
+                "fixes"
+            ],
+            "properties": {
+                "fixes": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/dto.BodyLocation"
+                    }
+                }
+            }
+        },
+        "dto.BodyLocationBatches": {
+            "type": "object",
+            "required": [
+                "riders"
+            ],
+            "properties": {
+                "riders": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/dto.BodyRiderLocationBatch"
+                    }
+                }
+            }
+        },
+        "dto.BodyParcel": {
+            "type": "object",
+            "properties": {
+                "size": {
+                    "$ref": "#/definitions/dto.CreateDimensions"
+                },
+                "weight": {
+                    "description": "Weight is in kilograms.",
+                    "type": "number",
+                    "minimum": 0
+                }
+            }
+        },
+        "dto.BodyPatchRider": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "$ref": "#/definitions/dto.PatchDimensions"
+                },
+                "serviceArea": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "vehicleType": {
+                    "type": "string",
+                    "enum": [
+                        "bicycle",
+                        "e-bike",
+                        "cargo-bike"
+                    ]
+                }
+            }
+        },
+        "dto.BodyRiderLocationBatch": {
+            "type": "object",
+            "required": [
+                "fixes",
+                "id"
+            ],
+            "properties": {
+                "fixes": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/dto.BodyLocation"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BodyUpdateRider": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "$ref": "#/definitions/dto.UpdateDimensions"
+                },
+                "serviceArea": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "status": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "dto.CreateDimensions": {
+            "type": "object",
+            "properties": {
+                "depth": {
+                    "type": "integer"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.GeoJSONPoint": {
+            "type": "object",
+            "properties": {
+                "coordinates": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.GeoJSONPolygon": {
+            "type": "object",
+            "properties": {
+                "coordinates": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "array",
+                            "items": {
+                                "type": "number"
+                            }
+                        }
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.HeatmapCellFeature": {
+            "type": "object",
+            "properties": {
+                "geometry": {
+                    "$ref": "#/definitions/dto.GeoJSONPolygon"
+                },
+                "properties": {
+                    "$ref": "#/definitions/dto.heatmapCellProperties"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.HeatmapResponse": {
+            "type": "object",
+            "properties": {
+                "computedAt": {
+                    "type": "string"
                 },
-                "longitude": {
+                "features": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.HeatmapCellFeature"
+                    }
+                },
+                "precision": {
+                    "type": "integer"
+                },
+                "serviceArea": {
+                    "$ref": "#/definitions/dto.ServiceAreaResponse"
+                },
+                "since": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.LocationAnomalyResponse": {
+            "type": "object",
+            "properties": {
+                "detectedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "impliedSpeed": {
+                    "description": "ImpliedSpeed is in meters per second.",
+                    "type": "number"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "location": {
+                    "$ref": "#/definitions/dto.riderResponseLocation"
+                },
+                "previousLocation": {
+                    "$ref": "#/definitions/dto.riderResponseLocation"
+                },
+                "rejected": {
+                    "type": "boolean"
+                },
+                "riderId": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ParcelResponse": {
+            "type": "object",
+            "properties": {
+                "deliveryId": {
+                    "description": "DeliveryID is the delivery the parcel belongs to, it is left out for parcels loaded through the API.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "pickedUp": {
+                    "description": "PickedUp is not set for the parcels of a delivery that was only assigned, their room is kept free.",
+                    "type": "boolean"
+                },
+                "size": {
+                    "$ref": "#/definitions/dto.riderResponseCapacity"
+                },
+                "weight": {
                     "type": "number"
                 }
             }
         },
-        "dto.CreateDimensions": {
+        "dto.PatchDimensions": {
             "type": "object",
             "properties": {
                 "depth": {
@@ -212,32 +1322,210 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.ProblemFieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ProblemResponse": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ProblemFieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "traceId": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RiderETAResponse": {
+            "type": "object",
+            "properties": {
+                "distance": {
+                    "description": "Distance is in meters and Duration in seconds, both are 0 when the rider has an error.",
+                    "type": "number"
+                },
+                "duration": {
+                    "type": "number"
+                },
+                "error": {
+                    "description": "Error is why there is no route for the rider, it is only set in a list of ETAs.",
+                    "type": "string"
+                },
+                "estimatedAt": {
+                    "type": "string"
+                },
+                "lastSeenAt": {
+                    "description": "LastSeenAt is when the rider was last at the location the route starts from.",
+                    "type": "string"
+                },
+                "riderId": {
+                    "type": "string"
+                },
+                "vehicleType": {
+                    "type": "string",
+                    "enum": [
+                        "bicycle",
+                        "e-bike",
+                        "cargo-bike"
+                    ]
+                }
+            }
+        },
+        "dto.RiderFeature": {
+            "type": "object",
+            "properties": {
+                "geometry": {
+                    "$ref": "#/definitions/dto.GeoJSONPoint"
+                },
+                "properties": {
+                    "$ref": "#/definitions/dto.riderFeatureProperties"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RiderImportResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "dryRun": {
+                    "type": "boolean"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.riderImportRowResponse"
+                    }
+                },
+                "valid": {
+                    "type": "integer"
+                }
+            }
+        },
         "dto.RiderResponse": {
             "type": "object",
             "properties": {
                 "capacity": {
                     "$ref": "#/definitions/dto.riderResponseCapacity"
                 },
+                "deliveryId": {
+                    "description": "DeliveryID is the delivery the rider is carrying, or assigned to when it carries none. It is left out while the\nrider has no delivery.",
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
+                "lastSeenAt": {
+                    "type": "string"
+                },
                 "location": {
+                    "description": "Location is left out when the rider does not share it, LocationPrecision tells whether it is exact or coarse.",
                     "$ref": "#/definitions/dto.riderResponseLocation"
                 },
+                "locationPrecision": {
+                    "type": "string",
+                    "enum": [
+                        "exact",
+                        "coarse",
+                        "hidden"
+                    ]
+                },
+                "lowBattery": {
+                    "description": "LowBattery is set when the rider's device last reported a battery level at or below domain.LowBatteryLevel.",
+                    "type": "boolean"
+                },
+                "parcels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ParcelResponse"
+                    }
+                },
+                "remainingCapacity": {
+                    "description": "RemainingCapacity is the room left in the box with the parcels in it, each parcel is stacked as a layer of its own.",
+                    "$ref": "#/definitions/dto.riderResponseCapacity"
+                },
                 "serviceArea": {
-                    "$ref": "#/definitions/dto.riderResponseArea"
+                    "$ref": "#/definitions/dto.ServiceAreaResponse"
                 },
                 "status": {
                     "type": "integer"
                 },
+                "telemetry": {
+                    "$ref": "#/definitions/dto.riderResponseTelemetry"
+                },
                 "user": {
                     "$ref": "#/definitions/dto.riderResponseUser"
+                },
+                "vehicleType": {
+                    "type": "string",
+                    "enum": [
+                        "bicycle",
+                        "e-bike",
+                        "cargo-bike"
+                    ]
+                }
+            }
+        },
+        "dto.RiderStatsResponse": {
+            "type": "object",
+            "properties": {
+                "averageCapacity": {
+                    "description": "AverageCapacity is taken over the riders that are online.",
+                    "$ref": "#/definitions/dto.averageCapacity"
+                },
+                "byStatus": {
+                    "$ref": "#/definitions/dto.ridersByStatus"
+                },
+                "online": {
+                    "type": "integer"
+                },
+                "riders": {
+                    "type": "integer"
+                },
+                "staleLocations": {
+                    "description": "StaleLocations counts the riders that are online but were not heard from for stats.staleAfter.",
+                    "type": "integer"
                 }
             }
         },
-        "dto.riderResponseArea": {
+        "dto.ServiceAreaResponse": {
             "type": "object",
             "properties": {
+                "deleted": {
+                    "description": "Deleted is set on the service area of a rider that still has to be moved out of a deleted service area.",
+                    "type": "boolean"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -246,6 +1534,136 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.ServiceAreaStatsResponse": {
+            "type": "object",
+            "properties": {
+                "computedAt": {
+                    "type": "string"
+                },
+                "serviceArea": {
+                    "$ref": "#/definitions/dto.ServiceAreaResponse"
+                },
+                "stats": {
+                    "$ref": "#/definitions/dto.RiderStatsResponse"
+                }
+            }
+        },
+        "dto.StatsSummaryResponse": {
+            "type": "object",
+            "properties": {
+                "computedAt": {
+                    "type": "string"
+                },
+                "serviceAreas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ServiceAreaStatsResponse"
+                    }
+                },
+                "total": {
+                    "$ref": "#/definitions/dto.RiderStatsResponse"
+                }
+            }
+        },
+        "dto.UpdateDimensions": {
+            "type": "object",
+            "properties": {
+                "depth": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "height": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "width": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "dto.averageCapacity": {
+            "type": "object",
+            "properties": {
+                "depth": {
+                    "type": "number"
+                },
+                "height": {
+                    "type": "number"
+                },
+                "width": {
+                    "type": "number"
+                }
+            }
+        },
+        "dto.heatmapCellProperties": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "geohash": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.riderFeatureProperties": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "lastName": {
+                    "type": "string"
+                },
+                "lastSeenAt": {
+                    "type": "string"
+                },
+                "locationPrecision": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "serviceArea": {
+                    "type": "string"
+                },
+                "serviceAreaId": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.riderImportRowResponse": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ProblemFieldError"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "line": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "valid",
+                        "failed"
+                    ]
+                }
+            }
+        },
         "dto.riderResponseCapacity": {
             "type": "object",
             "properties": {
@@ -271,6 +1689,26 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.riderResponseTelemetry": {
+            "type": "object",
+            "properties": {
+                "accuracy": {
+                    "type": "number"
+                },
+                "altitude": {
+                    "type": "number"
+                },
+                "battery": {
+                    "type": "integer"
+                },
+                "heading": {
+                    "type": "number"
+                },
+                "speed": {
+                    "type": "number"
+                }
+            }
+        },
         "dto.riderResponseUser": {
             "type": "object",
             "properties": {
@@ -285,6 +1723,23 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.ridersByStatus": {
+            "type": "object",
+            "properties": {
+                "assigned": {
+                    "type": "integer"
+                },
+                "available": {
+                    "type": "integer"
+                },
+                "delivering": {
+                    "type": "integer"
+                },
+                "offline": {
+                    "type": "integer"
+                }
+            }
+        },
         "dto.ridersResponse": {
             "type": "object",
             "properties": {
@@ -294,6 +1749,10 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
+                "remainingCapacity": {
+                    "description": "RemainingCapacity is the room left in the rider's box.",
+                    "$ref": "#/definitions/dto.riderResponseCapacity"
+                },
                 "serviceArea": {
                     "type": "integer"
                 },
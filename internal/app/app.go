@@ -0,0 +1,72 @@
+// Package app holds what the entrypoints of the service share: serving gRPC, the jobs that run in the background and
+// the order in which everything stops.
+package app
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"net"
+	"net/http"
+	"rider-service/internal/core/interfaces"
+	"rider-service/pkg/logging"
+	"time"
+)
+
+// ShutdownTimeout is how long running calls and the last flush of rider locations get when the service stops.
+const ShutdownTimeout = 10 * time.Second
+
+// Subscriber handles messages from the message bus until it quits.
+type Subscriber interface {
+	Quit()
+}
+
+// ServeGrpc serves gRPC calls on the port, the service stops when it can not.
+func ServeGrpc(server *grpc.Server, port string, logger logging.Logger) {
+	listener, err := net.Listen("tcp", port)
+
+	if err != nil {
+		logger.Fatal(context.Background(), err)
+	}
+
+	if err = server.Serve(listener); err != nil {
+		logger.Fatal(context.Background(), err)
+	}
+}
+
+// StopGrpc waits for running calls to finish until ctx is done, then cancels the calls that are left,
+// like watches that would otherwise keep the server open.
+func StopGrpc(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		server.Stop()
+	}
+}
+
+// Shutdown stops the service without losing rider locations. The servers and the subscriber stop first, so no location
+// arrives after the last flush, then the background jobs finish and the buffered locations are written.
+func Shutdown(server *http.Server, grpcServer *grpc.Server, subscriber Subscriber, background *Background, riderService interfaces.LocationFlusher, logger logging.Logger) {
+	logger.Info(context.Background(), "shutting down")
+
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Error(context.Background(), "could not shut down the HTTP server", "error", err)
+	}
+
+	StopGrpc(ctx, grpcServer)
+	subscriber.Quit()
+	background.Wait()
+
+	if err := riderService.FlushLocations(ctx); err != nil {
+		logger.Error(context.Background(), "could not flush rider locations", "error", err)
+	}
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"net/http"
+	"rider-service/pkg/logging"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type AppTestSuite struct {
+	suite.Suite
+}
+
+// recorder records the order in which the parts of the service are stopped.
+type recorder struct {
+	lock  sync.Mutex
+	steps []string
+}
+
+func (recorder *recorder) record(step string) {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	recorder.steps = append(recorder.steps, step)
+}
+
+func (recorder *recorder) Quit() {
+	recorder.record("quit")
+}
+
+func (recorder *recorder) FlushLocations(ctx context.Context) error {
+	recorder.record("flush")
+	return nil
+}
+
+func (suite *AppTestSuite) TestBackground_Every() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var calls int32
+	background := NewBackground(ctx)
+	background.Every(time.Millisecond, func() { atomic.AddInt32(&calls, 1) })
+
+	suite.Eventually(func() bool { return atomic.LoadInt32(&calls) >= 2 }, time.Second, time.Millisecond)
+
+	cancel()
+	background.Wait()
+
+	stopped := atomic.LoadInt32(&calls)
+	time.Sleep(5 * time.Millisecond)
+
+	suite.Equal(stopped, atomic.LoadInt32(&calls), "no calls are made after the context is done")
+}
+
+func (suite *AppTestSuite) TestBackground_Disabled() {
+	background := NewBackground(context.Background())
+	background.Every(0, func() { suite.Fail("a job without an interval is not run") })
+
+	background.Wait()
+}
+
+func (suite *AppTestSuite) TestShutdown_FlushesLast() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	steps := &recorder{}
+	started := make(chan struct{})
+	var once sync.Once
+
+	background := NewBackground(ctx)
+	background.Every(time.Millisecond, func() {
+		once.Do(func() { close(started) })
+		time.Sleep(10 * time.Millisecond)
+		steps.record("job")
+	})
+
+	<-started
+	cancel()
+
+	Shutdown(&http.Server{}, grpc.NewServer(), steps, background, steps, logging.MockLogger{})
+
+	suite.Require().GreaterOrEqual(len(steps.steps), 3)
+	suite.Equal("quit", steps.steps[0], "the subscriber stops first")
+	suite.Equal("job", steps.steps[len(steps.steps)-2], "the running job is finished")
+	suite.Equal("flush", steps.steps[len(steps.steps)-1], "the locations are flushed last")
+}
+
+func TestUnit_AppTestSuite(t *testing.T) {
+	suite.Run(t, new(AppTestSuite))
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+	"rider-service/internal/core/interfaces"
+	"rider-service/pkg/logging"
+	"sync"
+	"time"
+)
+
+// Background runs jobs at an interval until its context is done.
+type Background struct {
+	ctx     context.Context
+	running sync.WaitGroup
+}
+
+func NewBackground(ctx context.Context) *Background {
+	return &Background{ctx: ctx}
+}
+
+// Every calls fn at every interval until the context is done. An interval of zero disables the job.
+func (background *Background) Every(interval time.Duration, fn func()) {
+	if interval <= 0 {
+		return
+	}
+
+	background.running.Add(1)
+
+	go func() {
+		defer background.running.Done()
+		every(background.ctx, interval, fn)
+	}()
+}
+
+// Wait blocks until the jobs have stopped. A call that is running when the context is done is finished first.
+func (background *Background) Wait() {
+	background.running.Wait()
+}
+
+// FlushLocations returns a job that writes buffered rider locations to the database.
+func FlushLocations(riderService interfaces.LocationFlusher, logger logging.Logger) func() {
+	return func() {
+		if err := riderService.FlushLocations(context.Background()); err != nil {
+			logger.Error(context.Background(), "could not flush rider locations", "error", err)
+		}
+	}
+}
+
+// MonitorPresence returns a job that sets riders offline that stopped sending locations and heartbeats.
+func MonitorPresence(riderService interfaces.PresenceMonitor, logger logging.Logger) func() {
+	return func() {
+		riders, err := riderService.SetSilentRidersOffline(context.Background())
+
+		for _, rider := range riders {
+			logger.Info(context.Background(), "rider went offline", "rider", rider.UserID, "lastSeenAt", rider.LastSeenAt)
+		}
+
+		if err != nil {
+			logger.Error(context.Background(), "could not set silent riders offline", "error", err)
+		}
+	}
+}
+
+// PublishStats returns a job that publishes the stats of every service area.
+func PublishStats(statsService interfaces.StatsPublisher, logger logging.Logger) func() {
+	return func() {
+		if err := statsService.PublishStats(context.Background()); err != nil {
+			logger.Error(context.Background(), "could not publish service area stats", "error", err)
+		}
+	}
+}
+
+func every(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn()
+		}
+	}
+}
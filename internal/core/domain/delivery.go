@@ -0,0 +1,130 @@
+package domain
+
+import "fmt"
+
+// DeliveryEventKind is what happened to a delivery of a rider.
+type DeliveryEventKind string
+
+const (
+	DeliveryAssigned  DeliveryEventKind = "assigned"
+	DeliveryPickedUp  DeliveryEventKind = "picked_up"
+	DeliveryCompleted DeliveryEventKind = "completed"
+	DeliveryCancelled DeliveryEventKind = "cancelled"
+)
+
+// DeliveryEvent is a change of a delivery as the delivery service reports it.
+type DeliveryEvent struct {
+	Kind       DeliveryEventKind
+	DeliveryID string
+	RiderID    string
+	// Parcel is the size of what is delivered. It is optional, a delivery without a parcel takes no space.
+	Parcel Dimensions
+	// Weight is the weight of the parcel in kilograms.
+	Weight float64
+}
+
+func (event DeliveryEvent) Validate() error {
+	var fields []FieldError
+
+	switch event.Kind {
+	case DeliveryAssigned, DeliveryPickedUp, DeliveryCompleted, DeliveryCancelled:
+	default:
+		fields = append(fields, FieldError{Field: "kind", Message: fmt.Sprintf("%q is not a delivery event", event.Kind)})
+	}
+
+	if event.DeliveryID == "" {
+		fields = append(fields, FieldError{Field: "id", Message: "is required"})
+	}
+
+	if event.RiderID == "" {
+		fields = append(fields, FieldError{Field: "riderId", Message: "is required"})
+	}
+
+	if event.Parcel != (Dimensions{}) {
+		fields = append(fields, event.Parcel.Validate("parcel", 0)...)
+	}
+
+	if event.Weight < 0 {
+		fields = append(fields, FieldError{Field: "parcel.weight", Message: "can not be negative"})
+	}
+
+	if len(fields) > 0 {
+		return NewValidationError(fields...)
+	}
+
+	return nil
+}
+
+// Apply moves the rider along with its deliveries. Every delivery is a parcel of the rider, from when it is assigned
+// until it is completed or cancelled. A rider can have several deliveries at once: it is delivering while it carries
+// one, assigned while it only has deliveries that were not picked up yet and available again once it has none.
+// Events of a delivery the rider does not have leave it as it is, except for a pick up that arrives before the
+// assignment, which takes the delivery on right away. Riders that went offline stay offline until a delivery is
+// assigned or picked up.
+//
+// Deliveries are taken on even when their parcel does not fit, the rider then has no remaining capacity. Whoever
+// assigns deliveries is expected to check the remaining capacity first.
+func (event DeliveryEvent) Apply(rider Rider) (Rider, error) {
+	i := rider.Parcels.Find(event.DeliveryID)
+
+	if i >= 0 && rider.Parcels[i].DeliveryID != event.DeliveryID {
+		return Rider{}, NewValidationError(FieldError{Field: "id", Message: fmt.Sprintf("is a parcel of rider %s that is not part of a delivery", rider.UserID)})
+	}
+
+	switch event.Kind {
+	case DeliveryAssigned, DeliveryPickedUp:
+		parcels := append(Parcels(nil), rider.Parcels...)
+
+		if i < 0 {
+			i = len(parcels)
+			parcels = append(parcels, Parcel{ID: event.DeliveryID, DeliveryID: event.DeliveryID, Size: event.Parcel, Weight: event.Weight})
+		}
+
+		if event.Kind == DeliveryPickedUp {
+			parcels[i].PickedUp = true
+		}
+
+		rider.Parcels = parcels
+		rider = rider.followDeliveries()
+	case DeliveryCompleted, DeliveryCancelled:
+		if i < 0 {
+			return rider, nil
+		}
+
+		rider.Parcels = rider.Parcels.Without(i)
+
+		// Riders that went offline during the delivery stay offline.
+		if rider.OnDelivery() {
+			rider = rider.followDeliveries()
+		} else {
+			rider.DeliveryID = rider.followDeliveries().DeliveryID
+		}
+	}
+
+	return rider, nil
+}
+
+// followDeliveries sets the delivery and the status of the rider from the deliveries among its parcels.
+func (rider Rider) followDeliveries() Rider {
+	rider.DeliveryID = ""
+	rider.Status = StatusAvailable
+
+	for _, parcel := range rider.Parcels {
+		if parcel.DeliveryID == "" {
+			continue
+		}
+
+		if parcel.PickedUp {
+			rider.DeliveryID = parcel.DeliveryID
+			rider.Status = StatusDelivering
+			break
+		}
+
+		if rider.DeliveryID == "" {
+			rider.DeliveryID = parcel.DeliveryID
+			rider.Status = StatusAssigned
+		}
+	}
+
+	return rider
+}
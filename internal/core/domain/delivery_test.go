@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUnit_DeliveryEvent_Apply(t *testing.T) {
+	parcel := Dimensions{Width: 10, Height: 20, Depth: 30}
+	rider := Rider{Status: StatusAvailable, Capacity: Dimensions{Width: 40, Height: 50, Depth: 60}}
+
+	rider, err := DeliveryEvent{Kind: DeliveryAssigned, DeliveryID: "d-1", Parcel: parcel, Weight: 2}.Apply(rider)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusAssigned, rider.Status)
+	assert.Equal(t, "d-1", rider.DeliveryID)
+	assert.Equal(t, Dimensions{Width: 40, Height: 40, Depth: 60}, rider.RemainingCapacity(), "the parcel lies on its side")
+
+	rider, err = DeliveryEvent{Kind: DeliveryPickedUp, DeliveryID: "d-1"}.Apply(rider)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusDelivering, rider.Status)
+
+	rider, err = DeliveryEvent{Kind: DeliveryAssigned, DeliveryID: "d-2", Parcel: parcel, Weight: 3}.Apply(rider)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusDelivering, rider.Status, "a rider can be assigned a delivery while it carries another")
+	assert.Equal(t, "d-1", rider.DeliveryID)
+	assert.Equal(t, 5.0, rider.Parcels.Weight())
+	assert.Equal(t, Dimensions{Width: 40, Height: 30, Depth: 60}, rider.RemainingCapacity())
+
+	redelivered, err := DeliveryEvent{Kind: DeliveryAssigned, DeliveryID: "d-1"}.Apply(rider)
+	assert.NoError(t, err)
+	assert.Equal(t, rider, redelivered, "an assignment that arrives late does not undo the pick up")
+
+	stale, err := DeliveryEvent{Kind: DeliveryCancelled, DeliveryID: "d-3"}.Apply(rider)
+	assert.NoError(t, err)
+	assert.Equal(t, rider, stale)
+
+	rider, err = DeliveryEvent{Kind: DeliveryCompleted, DeliveryID: "d-1"}.Apply(rider)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusAssigned, rider.Status)
+	assert.Equal(t, "d-2", rider.DeliveryID)
+
+	rider, err = DeliveryEvent{Kind: DeliveryCancelled, DeliveryID: "d-2"}.Apply(rider)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusAvailable, rider.Status)
+	assert.Empty(t, rider.DeliveryID)
+	assert.Nil(t, rider.Parcels)
+	assert.Equal(t, rider.Capacity, rider.RemainingCapacity())
+}
+
+func TestUnit_DeliveryEvent_Apply_LoadedParcel(t *testing.T) {
+	rider := Rider{Status: StatusAvailable, Parcels: Parcels{{ID: "p-1", PickedUp: true}}}
+
+	_, err := DeliveryEvent{Kind: DeliveryAssigned, DeliveryID: "p-1"}.Apply(rider)
+	assert.ErrorIs(t, err, ErrValidation, "a parcel loaded through the API is not a delivery")
+
+	rider, err = DeliveryEvent{Kind: DeliveryAssigned, DeliveryID: "d-1"}.Apply(rider)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusAssigned, rider.Status, "loaded parcels do not make a rider busy")
+}
+
+func TestUnit_DeliveryEvent_Apply_Offline(t *testing.T) {
+	rider := Rider{Status: StatusOffline}
+
+	rider, _ = DeliveryEvent{Kind: DeliveryPickedUp, DeliveryID: "d-1"}.Apply(rider)
+	assert.Equal(t, StatusDelivering, rider.Status, "a pick up before the assignment takes the delivery on")
+
+	rider.Status = StatusOffline
+	rider, _ = DeliveryEvent{Kind: DeliveryCancelled, DeliveryID: "d-1"}.Apply(rider)
+	assert.Equal(t, StatusOffline, rider.Status, "a rider that went offline stays offline")
+	assert.Empty(t, rider.DeliveryID)
+}
+
+func TestUnit_DeliveryEvent_Validate(t *testing.T) {
+	assert.NoError(t, DeliveryEvent{Kind: DeliveryAssigned, DeliveryID: "d-1", RiderID: "r-1"}.Validate())
+
+	err := DeliveryEvent{Kind: "lost", Parcel: Dimensions{Width: 1}}.Validate()
+
+	var domainErr *Error
+	assert.ErrorAs(t, err, &domainErr)
+	assert.Len(t, domainErr.Fields, 5)
+}
@@ -1,7 +1,63 @@
 package domain
 
+import "fmt"
+
 type Dimensions struct {
 	Width  int
 	Height int
 	Depth  int
 }
+
+// Validate checks that every dimension is positive and, when max is positive, does not exceed max.
+func (d Dimensions) Validate(field string, max int) []FieldError {
+	var fields []FieldError
+
+	for _, dimension := range []struct {
+		name  string
+		value int
+	}{{"width", d.Width}, {"height", d.Height}, {"depth", d.Depth}} {
+		if dimension.value <= 0 {
+			fields = append(fields, FieldError{Field: field + "." + dimension.name, Message: "must be positive"})
+		} else if max > 0 && dimension.value > max {
+			fields = append(fields, FieldError{Field: field + "." + dimension.name, Message: fmt.Sprintf("can not be larger than %d", max)})
+		}
+	}
+
+	return fields
+}
+
+// orientations returns the dimensions of the box turned every way it can be turned.
+func (d Dimensions) orientations() []Dimensions {
+	return []Dimensions{
+		{Width: d.Width, Height: d.Height, Depth: d.Depth},
+		{Width: d.Width, Height: d.Depth, Depth: d.Height},
+		{Width: d.Height, Height: d.Width, Depth: d.Depth},
+		{Width: d.Height, Height: d.Depth, Depth: d.Width},
+		{Width: d.Depth, Height: d.Width, Depth: d.Height},
+		{Width: d.Depth, Height: d.Height, Depth: d.Width},
+	}
+}
+
+// FitsIn reports whether the box fits in the other box, turned in any way.
+func (d Dimensions) FitsIn(box Dimensions) bool {
+	for _, turned := range d.orientations() {
+		if turned.Width <= box.Width && turned.Height <= box.Height && turned.Depth <= box.Depth {
+			return true
+		}
+	}
+
+	return false
+}
+
+// layerHeight returns the lowest height the box can lie at on the floor of the other box.
+func (d Dimensions) layerHeight(box Dimensions) (int, bool) {
+	height, fits := 0, false
+
+	for _, turned := range d.orientations() {
+		if turned.Width <= box.Width && turned.Depth <= box.Depth && (!fits || turned.Height < height) {
+			height, fits = turned.Height, true
+		}
+	}
+
+	return height, fits
+}
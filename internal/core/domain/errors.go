@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ErrorKind string
+
+const (
+	ErrorKindNotFound    ErrorKind = "not-found"
+	ErrorKindValidation  ErrorKind = "validation"
+	ErrorKindConflict    ErrorKind = "conflict"
+	ErrorKindForbidden   ErrorKind = "forbidden"
+	ErrorKindUnavailable ErrorKind = "unavailable"
+)
+
+// FieldError describes why a single field failed validation.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Error is the error type returned by the core and its adapters.
+// Use errors.Is with one of the kind sentinels below to find out what went wrong.
+type Error struct {
+	Kind    ErrorKind
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+var (
+	ErrNotFound    = &Error{Kind: ErrorKindNotFound}
+	ErrValidation  = &Error{Kind: ErrorKindValidation}
+	ErrConflict    = &Error{Kind: ErrorKindConflict}
+	ErrForbidden   = &Error{Kind: ErrorKindForbidden}
+	ErrUnavailable = &Error{Kind: ErrorKindUnavailable}
+
+	// ErrVersionConflict is returned when a rider was modified after the version the caller based its change on.
+	ErrVersionConflict = &Error{Kind: ErrorKindConflict, Message: "rider was modified concurrently"}
+)
+
+func (e *Error) Error() string {
+	message := e.Message
+
+	if message == "" {
+		message = string(e.Kind)
+	}
+
+	if len(e.Fields) > 0 {
+		fields := make([]string, 0, len(e.Fields))
+
+		for _, field := range e.Fields {
+			fields = append(fields, field.Field+" "+field.Message)
+		}
+
+		message += ": " + strings.Join(fields, ", ")
+	}
+
+	if e.Err != nil {
+		message += ": " + e.Err.Error()
+	}
+
+	return message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors of the same kind. A target with a message only matches errors with that exact message.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+
+	if !ok {
+		return false
+	}
+
+	return t.Kind == e.Kind && (t.Message == "" || t.Message == e.Message)
+}
+
+func NewNotFoundError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrorKindNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewValidationError(fields ...FieldError) error {
+	return &Error{Kind: ErrorKindValidation, Message: "validation failed", Fields: fields}
+}
+
+func NewConflictError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrorKindConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+func NewForbiddenError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrorKindForbidden, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewUnavailableError wraps a failure of infrastructure the service depends on, like the database.
+func NewUnavailableError(err error, format string, args ...interface{}) error {
+	return &Error{Kind: ErrorKindUnavailable, Message: fmt.Sprintf(format, args...), Err: err}
+}
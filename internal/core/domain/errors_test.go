@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUnit_Error_IsKind(t *testing.T) {
+	err := fmt.Errorf("saving rider: %w", NewNotFoundError("rider %s not found", "test-id"))
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrConflict)
+}
+
+func TestUnit_Error_IsVersionConflict(t *testing.T) {
+	assert.ErrorIs(t, ErrVersionConflict, ErrConflict)
+	assert.ErrorIs(t, &Error{Kind: ErrorKindConflict, Message: ErrVersionConflict.Message}, ErrVersionConflict)
+	assert.NotErrorIs(t, NewConflictError("rider already exists"), ErrVersionConflict)
+}
+
+func TestUnit_Error_Unwrap(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := NewUnavailableError(cause, "could not access riders")
+
+	assert.ErrorIs(t, err, cause)
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Equal(t, "could not access riders: connection refused", err.Error())
+}
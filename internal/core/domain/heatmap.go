@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// MaxGeohashPrecision is the longest geohash, its cells are a few centimeters wide.
+const MaxGeohashPrecision = 12
+
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// Geohash returns the geohash of the cell of the location, with precision characters.
+func Geohash(location Location, precision int) string {
+	latitude := [2]float64{-90, 90}
+	longitude := [2]float64{-180, 180}
+
+	var hash strings.Builder
+	var cell, bits int
+	even := true
+
+	for hash.Len() < precision {
+		// Bits alternate between longitude and latitude, starting with longitude.
+		interval, value := &latitude, location.Latitude
+
+		if even {
+			interval, value = &longitude, location.Longitude
+		}
+
+		mid := (interval[0] + interval[1]) / 2
+		cell <<= 1
+
+		if value >= mid {
+			cell |= 1
+			interval[0] = mid
+		} else {
+			interval[1] = mid
+		}
+
+		even = !even
+
+		if bits++; bits == 5 {
+			hash.WriteByte(geohashAlphabet[cell])
+			cell, bits = 0, 0
+		}
+	}
+
+	return hash.String()
+}
+
+// GeohashBounds returns the box of the cell of the geohash. Characters that are not in the geohash alphabet are skipped.
+func GeohashBounds(hash string) Bounds {
+	bounds := Bounds{MinLatitude: -90, MaxLatitude: 90, MinLongitude: -180, MaxLongitude: 180}
+	even := true
+
+	for _, character := range hash {
+		cell := strings.IndexRune(geohashAlphabet, character)
+
+		if cell < 0 {
+			continue
+		}
+
+		for bit := 4; bit >= 0; bit-- {
+			min, max := &bounds.MinLatitude, &bounds.MaxLatitude
+
+			if even {
+				min, max = &bounds.MinLongitude, &bounds.MaxLongitude
+			}
+
+			mid := (*min + *max) / 2
+
+			if cell&(1<<bit) != 0 {
+				*min = mid
+			} else {
+				*max = mid
+			}
+
+			even = !even
+		}
+	}
+
+	return bounds
+}
+
+// HeatmapSource is what a heatmap counts.
+type HeatmapSource string
+
+const (
+	// HeatmapRiders counts the current locations of the riders that are online.
+	HeatmapRiders HeatmapSource = "riders"
+	// HeatmapHistory counts the fixes in the location history of the riders, at the time of the fix.
+	HeatmapHistory HeatmapSource = "history"
+)
+
+// HeatmapFilter selects what a heatmap of a service area counts.
+type HeatmapFilter struct {
+	ServiceAreaID int
+	Source        HeatmapSource
+	// Precision is the length of the geohashes of the cells.
+	Precision int
+	// Since only counts history fixes with a timestamp at or after it.
+	Since time.Time
+}
+
+// HeatmapCell is a geohash cell with the number of locations in it.
+type HeatmapCell struct {
+	Geohash string
+	Count   int
+	Bounds  Bounds
+}
+
+// Heatmap is the heatmap of a service area, with the filter it was computed for.
+type Heatmap struct {
+	ServiceArea ServiceArea
+	Filter      HeatmapFilter
+	Cells       []HeatmapCell
+	ComputedAt  time.Time
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUnit_Geohash(t *testing.T) {
+	eindhoven := Location{Latitude: 51.4416, Longitude: 5.4697}
+
+	assert.Equal(t, "u15u", Geohash(eindhoven, 4))
+	assert.Equal(t, "u15us", Geohash(eindhoven, 5))
+	assert.Equal(t, "ezs42", Geohash(Location{Latitude: 42.6, Longitude: -5.6}, 5))
+	assert.Equal(t, "", Geohash(eindhoven, 0))
+}
+
+func TestUnit_GeohashBounds(t *testing.T) {
+	bounds := GeohashBounds("ezs42")
+
+	assert.InDelta(t, 42.583, bounds.MinLatitude, 0.001)
+	assert.InDelta(t, 42.627, bounds.MaxLatitude, 0.001)
+	assert.InDelta(t, -5.625, bounds.MinLongitude, 0.001)
+	assert.InDelta(t, -5.581, bounds.MaxLongitude, 0.001)
+
+	eindhoven := Location{Latitude: 51.4416, Longitude: 5.4697}
+	assert.True(t, GeohashBounds(Geohash(eindhoven, 7)).Contains(eindhoven))
+	assert.Equal(t, Bounds{MinLatitude: -90, MaxLatitude: 90, MinLongitude: -180, MaxLongitude: 180}, GeohashBounds(""))
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord is the stored response to a request that was sent with an Idempotency-Key header.
+type IdempotencyRecord struct {
+	Key         string `gorm:"primaryKey"`
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	ExpiresAt   time.Time `gorm:"index"`
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/twpayne/go-geom/encoding/wkt"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
+	"math"
 )
 
 type Location struct {
@@ -15,6 +16,53 @@ type Location struct {
 	Longitude float64
 }
 
+// Validate checks that the coordinates are within the WGS84 range.
+func (l Location) Validate(field string) []FieldError {
+	var fields []FieldError
+
+	if l.Latitude < -90 || l.Latitude > 90 {
+		fields = append(fields, FieldError{Field: field + ".latitude", Message: "must be between -90 and 90"})
+	}
+
+	if l.Longitude < -180 || l.Longitude > 180 {
+		fields = append(fields, FieldError{Field: field + ".longitude", Message: "must be between -180 and 180"})
+	}
+
+	return fields
+}
+
+// earthRadius is the mean radius of the earth in meters.
+const earthRadius = 6371000
+
+// DistanceTo returns the great-circle distance in meters to the other location.
+func (l Location) DistanceTo(other Location) float64 {
+	lat1 := l.Latitude * math.Pi / 180
+	lat2 := other.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (other.Longitude - l.Longitude) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
+
+// Snap returns the centre of the cell of a grid of gridSize degrees the location lies in.
+// A grid size of zero returns the location as it is.
+func (l Location) Snap(gridSize float64) Location {
+	if gridSize <= 0 {
+		return l
+	}
+
+	snap := func(coordinate float64) float64 {
+		centre := (math.Floor(coordinate/gridSize) + 0.5) * gridSize
+
+		// Rounded to a micro degree so the centre does not show floating point noise.
+		return math.Round(centre*1e6) / 1e6
+	}
+
+	return Location{Latitude: snap(l.Latitude), Longitude: snap(l.Longitude)}
+}
+
 func (l Location) Value() (driver.Value, error) {
 
 	g := geom.NewPointFlat(geom.XY, geom.Coord{l.Longitude, l.Latitude})
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// LocationBatch is a list of fixes that a rider's device buffered, for example while it was offline.
+type LocationBatch struct {
+	RiderID string
+	Fixes   []LocationFix
+}
+
+// FreshFixes returns the fixes of the batch that are newer than the last fix stored for the rider, oldest first.
+func (batch LocationBatch) FreshFixes(rider Rider) []LocationFix {
+	fixes := make([]LocationFix, 0, len(batch.Fixes))
+
+	for _, fix := range batch.Fixes {
+		if !fix.IsStaleFor(rider) {
+			fixes = append(fixes, fix)
+		}
+	}
+
+	sort.SliceStable(fixes, func(i, j int) bool {
+		return fixes[j].IsNewerThan(fixes[i])
+	})
+
+	return fixes
+}
+
+// RiderLocation is a fix in the location history of a rider.
+type RiderLocation struct {
+	ID        uint   `gorm:"primaryKey"`
+	RiderID   string `gorm:"index"`
+	Location  Location
+	Timestamp *time.Time
+	Sequence  int64
+	Telemetry Telemetry `gorm:"embedded"`
+}
+
+func NewRiderLocation(riderId string, fix LocationFix) RiderLocation {
+	location := RiderLocation{
+		RiderID:   riderId,
+		Location:  fix.Location,
+		Sequence:  fix.Sequence,
+		Telemetry: fix.Telemetry,
+	}
+
+	if !fix.Timestamp.IsZero() {
+		timestamp := fix.Timestamp.UTC()
+		location.Timestamp = &timestamp
+	}
+
+	return location
+}
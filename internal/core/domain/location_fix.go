@@ -0,0 +1,61 @@
+package domain
+
+import "time"
+
+// LocationFix is a position reported by a rider's device.
+// Timestamp and Sequence are set by the client and are zero when it does not send them.
+type LocationFix struct {
+	Location  Location
+	Timestamp time.Time
+	Sequence  int64
+	Telemetry Telemetry
+	// Mocked is set when the device reports that the position comes from a mock location provider.
+	Mocked bool
+}
+
+// IsStaleFor reports whether the fix is older than, or a duplicate of, the last fix stored for the rider.
+func (fix LocationFix) IsStaleFor(rider Rider) bool {
+	if fix.Sequence != 0 && rider.LocationSequence != 0 && fix.Sequence <= rider.LocationSequence {
+		return true
+	}
+
+	if !fix.Timestamp.IsZero() && rider.LocationTimestamp != nil && !fix.Timestamp.After(*rider.LocationTimestamp) {
+		return true
+	}
+
+	return false
+}
+
+// IsNewerThan reports whether the fix was taken after the other one.
+// Sequences are compared when both fixes have one, timestamps otherwise.
+func (fix LocationFix) IsNewerThan(other LocationFix) bool {
+	if fix.Sequence != 0 && other.Sequence != 0 {
+		return fix.Sequence > other.Sequence
+	}
+
+	return fix.Timestamp.After(other.Timestamp)
+}
+
+// Apply moves the rider to the fix and remembers its client timestamp, sequence and telemetry.
+// The battery level belongs to the device rather than the fix, so it is kept when the fix does not report one.
+func (fix LocationFix) Apply(rider Rider) Rider {
+	battery := rider.Telemetry.Battery
+
+	rider.Location = fix.Location
+	rider.Telemetry = fix.Telemetry
+
+	if rider.Telemetry.Battery == nil {
+		rider.Telemetry.Battery = battery
+	}
+
+	if !fix.Timestamp.IsZero() {
+		timestamp := fix.Timestamp.UTC()
+		rider.LocationTimestamp = &timestamp
+	}
+
+	if fix.Sequence != 0 {
+		rider.LocationSequence = fix.Sequence
+	}
+
+	return rider
+}
@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Parcel is something a rider carries in its box.
+type Parcel struct {
+	ID string `json:"id"`
+	// DeliveryID is the delivery the parcel belongs to, it is empty for parcels loaded through the API.
+	DeliveryID string     `json:"deliveryId,omitempty"`
+	Size       Dimensions `json:"size"`
+	// Weight is in kilograms.
+	Weight float64 `json:"weight,omitempty"`
+	// PickedUp is set once the parcel is in the box. Parcels of a delivery that is only assigned are not picked up
+	// yet, but their room is kept free for them.
+	PickedUp bool `json:"pickedUp,omitempty"`
+}
+
+// Validate checks the parcel as it is loaded through the API.
+func (parcel Parcel) Validate(maxDimension int) error {
+	var fields []FieldError
+
+	if parcel.ID == "" {
+		fields = append(fields, FieldError{Field: "id", Message: "is required"})
+	}
+
+	fields = append(fields, parcel.Size.Validate("size", maxDimension)...)
+
+	if parcel.Weight < 0 {
+		fields = append(fields, FieldError{Field: "weight", Message: "can not be negative"})
+	}
+
+	if len(fields) > 0 {
+		return NewValidationError(fields...)
+	}
+
+	return nil
+}
+
+// Parcels are stored as a JSON array in the row of their rider, so they change together with the rider.
+type Parcels []Parcel
+
+// Find returns the index of the parcel with the id, or -1 when the rider does not carry it.
+func (parcels Parcels) Find(id string) int {
+	for i, parcel := range parcels {
+		if parcel.ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Without returns a copy of the parcels without the parcel at index i, or nil when no parcels are left.
+func (parcels Parcels) Without(i int) Parcels {
+	if len(parcels) == 1 {
+		return nil
+	}
+
+	without := make(Parcels, 0, len(parcels)-1)
+	without = append(without, parcels[:i]...)
+
+	return append(without, parcels[i+1:]...)
+}
+
+// Weight returns the total weight of the parcels in kilograms.
+func (parcels Parcels) Weight() float64 {
+	var weight float64
+
+	for _, parcel := range parcels {
+		weight += parcel.Weight
+	}
+
+	return weight
+}
+
+// RemainingIn returns the room left in a box of the capacity once the parcels are in it. Every parcel is a layer of
+// its own: it is turned so it lies on the floor of the box as low as possible, and the layers are stacked. This leaves
+// the room next to small parcels unused, but never puts more in a box than fits. Parcels that fill the box up to the
+// top leave its floor without height, parcels that do not fit at all leave no room.
+func (parcels Parcels) RemainingIn(capacity Dimensions) Dimensions {
+	remaining := capacity
+
+	for _, parcel := range parcels {
+		if parcel.Size == (Dimensions{}) {
+			continue
+		}
+
+		height, fits := parcel.Size.layerHeight(capacity)
+
+		if !fits || height > remaining.Height {
+			return Dimensions{}
+		}
+
+		remaining.Height -= height
+	}
+
+	return remaining
+}
+
+// Equal reports whether both have the same parcels in the same order.
+func (parcels Parcels) Equal(other Parcels) bool {
+	if len(parcels) != len(other) {
+		return false
+	}
+
+	for i := range parcels {
+		if parcels[i] != other[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (parcels Parcels) Value() (driver.Value, error) {
+	if parcels == nil {
+		return "[]", nil
+	}
+
+	js, err := json.Marshal(parcels)
+
+	return string(js), err
+}
+
+// Scan reads a rider without parcels as nil, like a rider that was never stored.
+func (parcels *Parcels) Scan(value interface{}) error {
+	var data []byte
+
+	switch value := value.(type) {
+	case nil:
+	case []byte:
+		data = value
+	case string:
+		data = []byte(value)
+	default:
+		return fmt.Errorf("can not scan %T into parcels", value)
+	}
+
+	*parcels = nil
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, parcels); err != nil {
+		return err
+	}
+
+	if len(*parcels) == 0 {
+		*parcels = nil
+	}
+
+	return nil
+}
+
+func (Parcels) GormDataType() string {
+	return "jsonb"
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUnit_Dimensions_FitsIn(t *testing.T) {
+	box := Dimensions{Width: 40, Height: 30, Depth: 20}
+
+	assert.True(t, Dimensions{Width: 20, Height: 40, Depth: 30}.FitsIn(box), "a parcel can be turned")
+	assert.True(t, box.FitsIn(box))
+	assert.False(t, Dimensions{Width: 41, Height: 1, Depth: 1}.FitsIn(box))
+	assert.False(t, Dimensions{Width: 30, Height: 30, Depth: 30}.FitsIn(box))
+}
+
+func TestUnit_Parcels_RemainingIn(t *testing.T) {
+	capacity := Dimensions{Width: 40, Height: 50, Depth: 60}
+
+	assert.Equal(t, capacity, Parcels(nil).RemainingIn(capacity))
+	assert.Equal(t, capacity, Parcels{{ID: "empty"}}.RemainingIn(capacity), "a parcel without a size takes no room")
+
+	parcels := Parcels{
+		{ID: "flat", Size: Dimensions{Width: 5, Height: 40, Depth: 60}},
+		{ID: "cube", Size: Dimensions{Width: 20, Height: 20, Depth: 20}},
+	}
+
+	assert.Equal(t, Dimensions{Width: 40, Height: 25, Depth: 60}, parcels.RemainingIn(capacity), "every parcel is a layer at its lowest height")
+
+	full := append(parcels, Parcel{ID: "tall", Size: Dimensions{Width: 25, Height: 25, Depth: 25}})
+	assert.Equal(t, Dimensions{Width: 40, Height: 0, Depth: 60}, full.RemainingIn(capacity), "a parcel that fills the box up to the top fits")
+	assert.True(t, Dimensions{Width: 25, Height: 25, Depth: 25}.FitsIn(parcels.RemainingIn(capacity)))
+
+	overfull := append(parcels, Parcel{ID: "taller", Size: Dimensions{Width: 26, Height: 26, Depth: 26}})
+	assert.Equal(t, Dimensions{}, overfull.RemainingIn(capacity))
+
+	wide := Parcels{{ID: "wide", Size: Dimensions{Width: 70, Height: 1, Depth: 1}}}
+	assert.Equal(t, Dimensions{}, wide.RemainingIn(capacity), "a parcel that does not fit leaves no room")
+}
+
+func TestUnit_Parcels_ValueScan(t *testing.T) {
+	parcels := Parcels{{ID: "p-1", DeliveryID: "d-1", Size: Dimensions{Width: 1, Height: 2, Depth: 3}, Weight: 1.5, PickedUp: true}}
+
+	value, err := parcels.Value()
+	assert.NoError(t, err)
+
+	var scanned Parcels
+	assert.NoError(t, scanned.Scan([]byte(value.(string))))
+	assert.Equal(t, parcels, scanned)
+
+	assert.NoError(t, scanned.Scan("[]"))
+	assert.Nil(t, scanned)
+
+	assert.NoError(t, scanned.Scan(nil))
+	assert.Nil(t, scanned)
+}
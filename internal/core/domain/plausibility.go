@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// nullIslandRadius is how close to 0,0 in degrees a fix is taken for a device that reported no position at all.
+const nullIslandRadius = 0.0001
+
+type AnomalyKind string
+
+const (
+	// AnomalyImpossibleJump is a fix that is further from the last one than the rider could have travelled.
+	AnomalyImpossibleJump AnomalyKind = "impossible_jump"
+	// AnomalyMockLocation is a fix that the device marked as mocked, or that claims a perfect accuracy no GPS gives.
+	AnomalyMockLocation AnomalyKind = "mock_location"
+)
+
+// LocationAnomaly is a suspicious fix of a rider, kept for the anomaly report.
+type LocationAnomaly struct {
+	ID               uint        `gorm:"primaryKey"`
+	RiderID          string      `gorm:"index"`
+	Kind             AnomalyKind `gorm:"index"`
+	Location         Location
+	PreviousLocation Location
+	// ImpliedSpeed is the speed in meters per second the rider would have needed to reach the fix, zero when unknown.
+	ImpliedSpeed float64
+	// Rejected is set when the fix was not accepted.
+	Rejected   bool
+	DetectedAt time.Time `gorm:"index"`
+}
+
+// LocationAnomalyFilter selects anomalies for the report. Zero fields do not filter.
+type LocationAnomalyFilter struct {
+	RiderID string
+	Kind    AnomalyKind
+	Since   time.Time
+	Limit   int
+}
+
+// Bounds is a box of coordinates. The zero box contains everything.
+type Bounds struct {
+	MinLatitude  float64
+	MaxLatitude  float64
+	MinLongitude float64
+	MaxLongitude float64
+}
+
+func (b Bounds) Contains(location Location) bool {
+	if b == (Bounds{}) {
+		return true
+	}
+
+	return location.Latitude >= b.MinLatitude && location.Latitude <= b.MaxLatitude &&
+		location.Longitude >= b.MinLongitude && location.Longitude <= b.MaxLongitude
+}
+
+// PlausibilityFilter checks fixes against the rider's last position before they are accepted.
+// Zero fields disable their check.
+type PlausibilityFilter struct {
+	// MaxSpeed is the highest speed in meters per second a rider can plausibly travel between two fixes.
+	MaxSpeed float64
+	// JitterRadius is the distance in meters within which a fix does not move the rider.
+	JitterRadius float64
+	Bounds       Bounds
+}
+
+// Check returns the fix as it should be applied to the rider, the anomalies it shows and, when the fix
+// has to be rejected, why.
+//
+// Fixes at null island or outside the bounds are rejected. So are fixes further from the last one than MaxSpeed
+// allows. The time between the fixes is taken from their timestamps, or, when either has none, from when the fix was
+// received and when the rider was last seen. Fixes within JitterRadius of the last one keep the rider where it was.
+// Mocked fixes are reported but not rejected, as a false positive would stop a rider from working.
+//
+// A rejected jump leaves the rider at its last accepted location. As the time since that location grows, a fix from
+// where the device really is becomes plausible again, at the latest after the distance divided by MaxSpeed. Without
+// timestamps that time is counted from when the rider was last seen, which heartbeats and location batches move
+// forward, so a rider that keeps sending those stays at its last location until it sends a single fix with a timestamp
+// or moves closer to it.
+func (filter PlausibilityFilter) Check(previous Rider, fix LocationFix, detectedAt time.Time) (LocationFix, []LocationAnomaly, []FieldError) {
+	if math.Abs(fix.Location.Latitude) < nullIslandRadius && math.Abs(fix.Location.Longitude) < nullIslandRadius {
+		return fix, nil, []FieldError{{Field: "location", Message: "is not a position, the device reported 0,0"}}
+	}
+
+	if !filter.Bounds.Contains(fix.Location) {
+		return fix, nil, []FieldError{{Field: "location", Message: "is outside of the area riders work in"}}
+	}
+
+	var anomalies []LocationAnomaly
+
+	newAnomaly := func(kind AnomalyKind, impliedSpeed float64, rejected bool) LocationAnomaly {
+		return LocationAnomaly{
+			RiderID:          previous.UserID,
+			Kind:             kind,
+			Location:         fix.Location,
+			PreviousLocation: previous.Location,
+			ImpliedSpeed:     impliedSpeed,
+			Rejected:         rejected,
+			DetectedAt:       detectedAt.UTC(),
+		}
+	}
+
+	if fix.Mocked || (fix.Telemetry.Accuracy != nil && *fix.Telemetry.Accuracy == 0) {
+		anomalies = append(anomalies, newAnomaly(AnomalyMockLocation, 0, false))
+	}
+
+	if !previous.HasLocation() {
+		return fix, anomalies, nil
+	}
+
+	distance := previous.Location.DistanceTo(fix.Location)
+
+	if elapsed, known := elapsedSince(previous, fix, detectedAt); filter.MaxSpeed > 0 && known {
+		if elapsed > 0 && distance/elapsed > filter.MaxSpeed {
+			anomalies = append(anomalies, newAnomaly(AnomalyImpossibleJump, distance/elapsed, true))
+
+			return fix, anomalies, []FieldError{{
+				Field:   "location",
+				Message: fmt.Sprintf("is %.0f meters from the last location, which is faster than %g meters per second", distance, filter.MaxSpeed),
+			}}
+		}
+	}
+
+	if distance < filter.JitterRadius {
+		fix.Location = previous.Location
+	}
+
+	return fix, anomalies, nil
+}
+
+// elapsedSince returns the seconds between the last fix of the rider and the fix, by the clock of the device when both
+// fixes have a timestamp and by the clock of the service otherwise.
+func elapsedSince(previous Rider, fix LocationFix, detectedAt time.Time) (float64, bool) {
+	switch {
+	case !fix.Timestamp.IsZero() && previous.LocationTimestamp != nil:
+		return fix.Timestamp.Sub(*previous.LocationTimestamp).Seconds(), true
+	case previous.LastSeenAt != nil:
+		return detectedAt.Sub(*previous.LastSeenAt).Seconds(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,75 @@
+package domain
+
+// LocationPrecision is how precisely the location of a rider is shared.
+type LocationPrecision string
+
+const (
+	// PrecisionExact shares the location as the rider's device reported it.
+	PrecisionExact LocationPrecision = "exact"
+	// PrecisionCoarse shares the centre of the grid cell the rider is in.
+	PrecisionCoarse LocationPrecision = "coarse"
+	// PrecisionHidden does not share the location at all.
+	PrecisionHidden LocationPrecision = "hidden"
+)
+
+// LocationPrivacy decides how precisely the location of a rider is shared and reduces it to that precision.
+type LocationPrivacy struct {
+	// GridSize is the size in degrees of the grid coarse locations are snapped to.
+	GridSize float64
+}
+
+// Precision returns how precisely the location of the rider may be shared. Offline riders do not share their location.
+// Privileged viewers, admins and dispatchers, see the exact location of riders on a delivery, everyone else a coarse one.
+func (privacy LocationPrivacy) Precision(rider Rider, privileged bool) LocationPrecision {
+	switch {
+	case rider.Status == StatusOffline:
+		return PrecisionHidden
+	case privileged && rider.OnDelivery():
+		return PrecisionExact
+	default:
+		return PrecisionCoarse
+	}
+}
+
+// Apply returns the rider with its location reduced to the precision. Telemetry that could be used
+// to narrow down a coarse location is left out, the battery level is kept.
+func (privacy LocationPrivacy) Apply(rider Rider, precision LocationPrecision) Rider {
+	if precision == PrecisionExact {
+		return rider
+	}
+
+	rider.Telemetry = Telemetry{Battery: rider.Telemetry.Battery}
+
+	if precision == PrecisionHidden {
+		rider.Location = Location{}
+		rider.LocationTimestamp = nil
+		return rider
+	}
+
+	rider.Location = rider.Location.Snap(privacy.GridSize)
+
+	return rider
+}
+
+// ApplyFixes returns the fixes with their locations reduced to the precision, nothing when the location is hidden.
+func (privacy LocationPrivacy) ApplyFixes(fixes []LocationFix, precision LocationPrecision) []LocationFix {
+	switch precision {
+	case PrecisionExact:
+		return fixes
+	case PrecisionHidden:
+		return nil
+	}
+
+	coarse := make([]LocationFix, len(fixes))
+
+	for i, fix := range fixes {
+		coarse[i] = LocationFix{
+			Location:  fix.Location.Snap(privacy.GridSize),
+			Timestamp: fix.Timestamp,
+			Sequence:  fix.Sequence,
+			Telemetry: Telemetry{Battery: fix.Telemetry.Battery},
+		}
+	}
+
+	return coarse
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestUnit_LocationPrivacy_Precision(t *testing.T) {
+	privacy := LocationPrivacy{GridSize: 0.01}
+
+	assert.Equal(t, PrecisionHidden, privacy.Precision(Rider{Status: StatusOffline}, true))
+	assert.Equal(t, PrecisionCoarse, privacy.Precision(Rider{Status: StatusAvailable}, true))
+	assert.Equal(t, PrecisionCoarse, privacy.Precision(Rider{Status: StatusDelivering}, false))
+	assert.Equal(t, PrecisionExact, privacy.Precision(Rider{Status: StatusAssigned}, true))
+	assert.Equal(t, PrecisionExact, privacy.Precision(Rider{Status: StatusDelivering}, true))
+}
+
+func TestUnit_LocationPrivacy_Apply(t *testing.T) {
+	privacy := LocationPrivacy{GridSize: 0.01}
+	timestamp := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	speed, battery := 4.2, 50
+
+	rider := Rider{
+		Location:          Location{Latitude: 51.4416, Longitude: -5.4697},
+		LocationTimestamp: &timestamp,
+		Telemetry:         Telemetry{Speed: &speed, Battery: &battery},
+	}
+
+	assert.Equal(t, rider, privacy.Apply(rider, PrecisionExact))
+
+	coarse := privacy.Apply(rider, PrecisionCoarse)
+	assert.Equal(t, Location{Latitude: 51.445, Longitude: -5.465}, coarse.Location)
+	assert.Equal(t, Telemetry{Battery: &battery}, coarse.Telemetry)
+
+	hidden := privacy.Apply(rider, PrecisionHidden)
+	assert.False(t, hidden.HasLocation())
+	assert.Nil(t, hidden.LocationTimestamp)
+}
+
+func TestUnit_LocationPrivacy_ApplyFixes(t *testing.T) {
+	privacy := LocationPrivacy{GridSize: 0.01}
+	fixes := []LocationFix{{Location: Location{Latitude: 51.4416, Longitude: 5.4697}, Sequence: 1}}
+
+	assert.Equal(t, fixes, privacy.ApplyFixes(fixes, PrecisionExact))
+	assert.Equal(t, []LocationFix{{Location: Location{Latitude: 51.445, Longitude: 5.465}, Sequence: 1}}, privacy.ApplyFixes(fixes, PrecisionCoarse))
+	assert.Empty(t, privacy.ApplyFixes(fixes, PrecisionHidden))
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// ProcessedMessage records that a message from the message bus was handled, so redeliveries can be skipped.
+type ProcessedMessage struct {
+	ID          string `gorm:"primaryKey"`
+	Topic       string
+	ProcessedAt time.Time
+	ExpiresAt   time.Time `gorm:"index"`
+}
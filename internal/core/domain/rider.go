@@ -1,13 +1,41 @@
 package domain
 
+import "time"
+
+const (
+	// StatusOffline is the status of a rider that is not available. Riders are created offline
+	// and are set offline again when they stop sending locations and heartbeats.
+	StatusOffline = 0
+	// StatusAvailable is the status of a rider that can take a delivery.
+	StatusAvailable = 1
+	// StatusAssigned is the status of a rider on the way to pick up a delivery.
+	StatusAssigned = 2
+	// StatusDelivering is the status of a rider carrying a delivery.
+	StatusDelivering = 3
+)
+
 type Rider struct {
 	UserID        string `gorm:"primaryKey"`
 	User          User
 	Status        int
 	ServiceAreaID int
 	ServiceArea   ServiceArea
-	Capacity      Dimensions `gorm:"embedded"`
-	Location      Location
+	Capacity      Dimensions  `gorm:"embedded"`
+	VehicleType   VehicleType `gorm:"not null;default:bicycle"`
+	// DeliveryID is the delivery the rider is carrying, or assigned to when it carries none. It is empty while the
+	// rider has no delivery.
+	DeliveryID string `gorm:"index"`
+	// Parcels are in the rider's box or have room kept free for them.
+	Parcels  Parcels
+	Location Location
+	// LocationTimestamp and LocationSequence belong to the last accepted fix and are used to drop older fixes.
+	LocationTimestamp *time.Time
+	LocationSequence  int64
+	// Telemetry was reported with the last accepted fix.
+	Telemetry Telemetry `gorm:"embedded"`
+	// LastSeenAt is when the rider last sent a location or a heartbeat.
+	LastSeenAt *time.Time `gorm:"index"`
+	Version    int        `gorm:"not null;default:1"`
 }
 
 func NewRider(user User, status int, serviceArea int, capacity Dimensions) Rider {
@@ -17,5 +45,58 @@ func NewRider(user User, status int, serviceArea int, capacity Dimensions) Rider
 		Status:        status,
 		ServiceAreaID: serviceArea,
 		Capacity:      capacity,
+		VehicleType:   VehicleBicycle,
+		Version:       1,
+	}
+}
+
+// HasLocation reports whether the rider ever sent a location. Riders start out at 0,0, which is not a plausible fix.
+func (rider Rider) HasLocation() bool {
+	return rider.Location != (Location{})
+}
+
+// OnDelivery reports whether the rider is assigned to a delivery or carrying one.
+func (rider Rider) OnDelivery() bool {
+	return rider.Status == StatusAssigned || rider.Status == StatusDelivering
+}
+
+// RemainingCapacity returns the room left in the rider's box, see Parcels.RemainingIn.
+func (rider Rider) RemainingCapacity() Dimensions {
+	return rider.Parcels.RemainingIn(rider.Capacity)
+}
+
+// LastFix returns the last accepted fix of the rider.
+func (rider Rider) LastFix() LocationFix {
+	fix := LocationFix{
+		Location: rider.Location,
+		Sequence: rider.LocationSequence,
 	}
+
+	if rider.LocationTimestamp != nil {
+		fix.Timestamp = *rider.LocationTimestamp
+	}
+
+	return fix
+}
+
+// RiderFilter selects riders. Zero fields do not filter.
+type RiderFilter struct {
+	Status        *int
+	ServiceAreaID int
+	// Fits only selects riders with room left for a parcel of the size. The remaining capacity is not stored, so
+	// repositories check it with Matches after reading the riders.
+	Fits *Dimensions
+}
+
+// Matches reports whether the rider is selected by the filter.
+func (filter RiderFilter) Matches(rider Rider) bool {
+	if filter.Status != nil && rider.Status != *filter.Status {
+		return false
+	}
+
+	if filter.Fits != nil && !filter.Fits.FitsIn(rider.RemainingCapacity()) {
+		return false
+	}
+
+	return filter.ServiceAreaID == 0 || rider.ServiceAreaID == filter.ServiceAreaID
 }
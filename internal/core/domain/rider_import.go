@@ -0,0 +1,30 @@
+package domain
+
+// RiderImportRow is a rider to create in a bulk import.
+type RiderImportRow struct {
+	// Line is where the row starts in the imported file.
+	Line          int
+	UserID        string
+	ServiceAreaID int
+	Capacity      Dimensions
+	// Invalid lists what was wrong with the row when it was read, like a capacity that is not a number.
+	Invalid []FieldError
+}
+
+type RiderImportStatus string
+
+const (
+	// ImportCreated is a row a rider was created for.
+	ImportCreated RiderImportStatus = "created"
+	// ImportValid is a row that would have been created if the import was not a dry run.
+	ImportValid RiderImportStatus = "valid"
+	// ImportFailed is a row no rider could be created for, Err tells why.
+	ImportFailed RiderImportStatus = "failed"
+)
+
+type RiderImportResult struct {
+	Line   int
+	UserID string
+	Status RiderImportStatus
+	Err    error
+}
@@ -0,0 +1,115 @@
+package domain
+
+// RiderPatch holds the fields of a partial rider update. Nil fields are left untouched.
+type RiderPatch struct {
+	Status      *int
+	ServiceArea *int
+	Capacity    *DimensionsPatch
+	VehicleType *VehicleType
+}
+
+type DimensionsPatch struct {
+	Width  *int
+	Height *int
+	Depth  *int
+}
+
+// Validate only checks the fields that are part of the patch.
+func (patch RiderPatch) Validate() error {
+	var fields []FieldError
+
+	if patch.Status != nil && *patch.Status < 0 {
+		fields = append(fields, FieldError{Field: "status", Message: "can not be negative"})
+	}
+
+	if patch.ServiceArea != nil && *patch.ServiceArea <= 0 {
+		fields = append(fields, FieldError{Field: "serviceArea", Message: "must be a valid id"})
+	}
+
+	if patch.Capacity != nil {
+		for _, dimension := range []struct {
+			name  string
+			value *int
+		}{{"width", patch.Capacity.Width}, {"height", patch.Capacity.Height}, {"depth", patch.Capacity.Depth}} {
+			if dimension.value != nil && *dimension.value <= 0 {
+				fields = append(fields, FieldError{Field: "capacity." + dimension.name, Message: "must be positive"})
+			}
+		}
+	}
+
+	if patch.VehicleType != nil {
+		fields = append(fields, patch.VehicleType.Validate("vehicleType")...)
+	}
+
+	if len(fields) > 0 {
+		return NewValidationError(fields...)
+	}
+
+	return nil
+}
+
+func (patch RiderPatch) Apply(rider Rider) Rider {
+	if patch.Status != nil {
+		rider.Status = *patch.Status
+	}
+
+	if patch.ServiceArea != nil && *patch.ServiceArea != rider.ServiceAreaID {
+		rider.ServiceAreaID = *patch.ServiceArea
+		rider.ServiceArea = ServiceArea{}
+	}
+
+	if patch.Capacity != nil {
+		if patch.Capacity.Width != nil {
+			rider.Capacity.Width = *patch.Capacity.Width
+		}
+
+		if patch.Capacity.Height != nil {
+			rider.Capacity.Height = *patch.Capacity.Height
+		}
+
+		if patch.Capacity.Depth != nil {
+			rider.Capacity.Depth = *patch.Capacity.Depth
+		}
+	}
+
+	if patch.VehicleType != nil {
+		rider.VehicleType = *patch.VehicleType
+	}
+
+	return rider
+}
+
+// ChangedFields lists the fields that differ between two versions of a rider, using the names of the REST API.
+func ChangedFields(before, after Rider) []string {
+	changed := make([]string, 0)
+
+	if before.Status != after.Status {
+		changed = append(changed, "status")
+	}
+
+	if before.ServiceAreaID != after.ServiceAreaID {
+		changed = append(changed, "serviceArea")
+	}
+
+	if before.Capacity != after.Capacity {
+		changed = append(changed, "capacity")
+	}
+
+	if before.VehicleType != after.VehicleType {
+		changed = append(changed, "vehicleType")
+	}
+
+	if before.DeliveryID != after.DeliveryID {
+		changed = append(changed, "deliveryId")
+	}
+
+	if !before.Parcels.Equal(after.Parcels) {
+		changed = append(changed, "parcels")
+	}
+
+	if before.Location != after.Location {
+		changed = append(changed, "location")
+	}
+
+	return changed
+}
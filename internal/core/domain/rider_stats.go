@@ -0,0 +1,104 @@
+package domain
+
+import "time"
+
+// AverageDimensions is the average of a number of dimensions.
+type AverageDimensions struct {
+	Width  float64
+	Height float64
+	Depth  float64
+}
+
+// RiderStats is an overview of a group of riders.
+type RiderStats struct {
+	// ByStatus counts the riders per status, statuses without riders can be left out.
+	ByStatus map[int]int
+	// AverageCapacity is the average capacity of the riders that are online.
+	AverageCapacity AverageDimensions
+	// StaleLocations counts the riders that are online, but were not seen since the stale time or never sent a location.
+	StaleLocations int
+}
+
+// Riders returns the number of riders.
+func (stats RiderStats) Riders() int {
+	riders := 0
+
+	for _, count := range stats.ByStatus {
+		riders += count
+	}
+
+	return riders
+}
+
+// Online returns the number of riders that are not offline.
+func (stats RiderStats) Online() int {
+	return stats.Riders() - stats.ByStatus[StatusOffline]
+}
+
+// Add returns the stats of the riders of both groups.
+func (stats RiderStats) Add(other RiderStats) RiderStats {
+	sum := RiderStats{
+		ByStatus:       map[int]int{},
+		StaleLocations: stats.StaleLocations + other.StaleLocations,
+	}
+
+	for status, count := range stats.ByStatus {
+		sum.ByStatus[status] += count
+	}
+
+	for status, count := range other.ByStatus {
+		sum.ByStatus[status] += count
+	}
+
+	// The averages are weighed by the number of riders they were taken over.
+	online, otherOnline := float64(stats.Online()), float64(other.Online())
+
+	if online+otherOnline > 0 {
+		average := func(value, otherValue float64) float64 {
+			return (value*online + otherValue*otherOnline) / (online + otherOnline)
+		}
+
+		sum.AverageCapacity = AverageDimensions{
+			Width:  average(stats.AverageCapacity.Width, other.AverageCapacity.Width),
+			Height: average(stats.AverageCapacity.Height, other.AverageCapacity.Height),
+			Depth:  average(stats.AverageCapacity.Depth, other.AverageCapacity.Depth),
+		}
+	}
+
+	return sum
+}
+
+// NewRiderStats returns the stats of a single rider, its location is stale when it was not seen since staleBefore.
+func NewRiderStats(rider Rider, staleBefore time.Time) RiderStats {
+	stats := RiderStats{ByStatus: map[int]int{rider.Status: 1}}
+
+	if rider.Status == StatusOffline {
+		return stats
+	}
+
+	stats.AverageCapacity = AverageDimensions{
+		Width:  float64(rider.Capacity.Width),
+		Height: float64(rider.Capacity.Height),
+		Depth:  float64(rider.Capacity.Depth),
+	}
+
+	if rider.LastSeenAt == nil || rider.LastSeenAt.Before(staleBefore) {
+		stats.StaleLocations = 1
+	}
+
+	return stats
+}
+
+// ServiceAreaStats is an overview of the riders of a service area.
+type ServiceAreaStats struct {
+	ServiceArea ServiceArea
+	Riders      RiderStats
+	ComputedAt  time.Time
+}
+
+// StatsSummary is an overview of the riders of every service area, with the totals of all areas.
+type StatsSummary struct {
+	ServiceAreas []ServiceAreaStats
+	Total        RiderStats
+	ComputedAt   time.Time
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestUnit_RiderStats_Add(t *testing.T) {
+	seenAt := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	available := NewRider(User{ID: "available"}, StatusAvailable, 1, Dimensions{Width: 10, Height: 20, Depth: 30})
+	available.LastSeenAt = &seenAt
+	delivering := NewRider(User{ID: "delivering"}, StatusDelivering, 1, Dimensions{Width: 40, Height: 40, Depth: 40})
+	offline := NewRider(User{ID: "offline"}, StatusOffline, 1, Dimensions{Width: 100, Height: 100, Depth: 100})
+
+	stats := RiderStats{}
+
+	for _, rider := range []Rider{available, available, delivering, offline} {
+		stats = stats.Add(NewRiderStats(rider, seenAt))
+	}
+
+	assert.Equal(t, map[int]int{StatusAvailable: 2, StatusDelivering: 1, StatusOffline: 1}, stats.ByStatus)
+	assert.Equal(t, 4, stats.Riders())
+	assert.Equal(t, 3, stats.Online())
+	assert.Equal(t, AverageDimensions{Width: 20, Height: 80.0 / 3, Depth: 100.0 / 3}, stats.AverageCapacity)
+	assert.Equal(t, 1, stats.StaleLocations, "only the online rider that was never seen is stale")
+}
+
+func TestUnit_RiderStats_Add_Empty(t *testing.T) {
+	stats := RiderStats{}.Add(RiderStats{})
+
+	assert.Equal(t, 0, stats.Riders())
+	assert.Equal(t, AverageDimensions{}, stats.AverageCapacity)
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// VehicleType is what a rider rides, it decides how fast the rider is expected to travel.
+type VehicleType string
+
+const (
+	VehicleBicycle   VehicleType = "bicycle"
+	VehicleEBike     VehicleType = "e-bike"
+	VehicleCargoBike VehicleType = "cargo-bike"
+)
+
+// VehicleTypes lists every vehicle type a rider can have.
+var VehicleTypes = []VehicleType{VehicleBicycle, VehicleEBike, VehicleCargoBike}
+
+// Validate checks that the vehicle type is one of VehicleTypes.
+func (vehicle VehicleType) Validate(field string) []FieldError {
+	names := make([]string, 0, len(VehicleTypes))
+
+	for _, known := range VehicleTypes {
+		if vehicle == known {
+			return nil
+		}
+
+		names = append(names, string(known))
+	}
+
+	return []FieldError{{Field: field, Message: fmt.Sprintf("must be one of %s", strings.Join(names, ", "))}}
+}
+
+// RouteOrigin is where a rider starts a route from, with the vehicle the route is ridden with.
+type RouteOrigin struct {
+	Location    Location
+	VehicleType VehicleType
+}
+
+// Route is an estimate of how far, and how long, a ride to a destination is.
+type Route struct {
+	// Distance is in meters.
+	Distance float64
+	Duration time.Duration
+	// Unreachable is set when no route to the destination was found, the route has no distance or duration then.
+	Unreachable bool
+}
+
+// RiderETA is the estimated route of a rider to a destination. Err is set when there is no route for the rider,
+// because the rider does not exist, never sent a location or can not reach the destination.
+type RiderETA struct {
+	RiderID     string
+	VehicleType VehicleType
+	// LastSeenAt is when the rider was at the location the route starts from, or sent a heartbeat there.
+	LastSeenAt  *time.Time
+	Route       Route
+	EstimatedAt time.Time
+	Err         error
+}
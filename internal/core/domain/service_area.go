@@ -1,6 +1,18 @@
 package domain
 
+import "time"
+
 type ServiceArea struct {
 	ID         int
 	Identifier string
+	// Version is set by the service area service. Updates with a lower version than the stored area are ignored.
+	Version int `gorm:"not null;default:0"`
+	// DeletedAt is set when the service area service deleted the area. Deleted areas are kept, so the riders
+	// that could not be moved to another area still have one, but they are no longer listed.
+	DeletedAt *time.Time `gorm:"index"`
+}
+
+// IsDeleted reports whether the service area service deleted the area.
+func (area ServiceArea) IsDeleted() bool {
+	return area.DeletedAt != nil
 }
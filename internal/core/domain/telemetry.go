@@ -0,0 +1,63 @@
+package domain
+
+import "fmt"
+
+// LowBatteryLevel is the battery percentage at or below which a rider is reported to have a low battery.
+const LowBatteryLevel = 20
+
+// Telemetry is what a rider's device reports along with a fix. Every field is optional and nil when it was not sent.
+type Telemetry struct {
+	// Accuracy is the radius in meters the real position is likely within.
+	Accuracy *float64
+	// Speed is in meters per second.
+	Speed *float64
+	// Heading is in degrees clockwise from true north.
+	Heading *float64
+	// Altitude is in meters above the WGS84 ellipsoid.
+	Altitude *float64
+	// Battery is the charge of the device in percent.
+	Battery *int
+}
+
+// Validate checks the ranges of the fields that were sent.
+func (t Telemetry) Validate(field string) []FieldError {
+	var fields []FieldError
+
+	if t.Accuracy != nil && *t.Accuracy < 0 {
+		fields = append(fields, FieldError{Field: field + ".accuracy", Message: "can not be negative"})
+	}
+
+	if t.Speed != nil && *t.Speed < 0 {
+		fields = append(fields, FieldError{Field: field + ".speed", Message: "can not be negative"})
+	}
+
+	if t.Heading != nil && (*t.Heading < 0 || *t.Heading >= 360) {
+		fields = append(fields, FieldError{Field: field + ".heading", Message: "must be at least 0 and less than 360"})
+	}
+
+	if t.Battery != nil && (*t.Battery < 0 || *t.Battery > 100) {
+		fields = append(fields, FieldError{Field: field + ".battery", Message: "must be between 0 and 100"})
+	}
+
+	return fields
+}
+
+// IsAccurate reports whether the accuracy is within maxAccuracy meters. Fixes without an accuracy
+// are accepted, as is everything when maxAccuracy is zero.
+func (t Telemetry) IsAccurate(maxAccuracy float64) bool {
+	return maxAccuracy <= 0 || t.Accuracy == nil || *t.Accuracy <= maxAccuracy
+}
+
+// ValidateAccuracy returns an error for the accuracy field when the fix is not accurate enough.
+func (t Telemetry) ValidateAccuracy(field string, maxAccuracy float64) []FieldError {
+	if t.IsAccurate(maxAccuracy) {
+		return nil
+	}
+
+	return []FieldError{{Field: field + ".accuracy", Message: fmt.Sprintf("must be at most %g meters", maxAccuracy)}}
+}
+
+// HasLowBattery reports whether the battery level is known and at or below LowBatteryLevel.
+func (t Telemetry) HasLowBattery() bool {
+	return t.Battery != nil && *t.Battery <= LowBatteryLevel
+}
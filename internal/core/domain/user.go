@@ -4,4 +4,6 @@ type User struct {
 	ID       string
 	Name     string
 	LastName string
+	// Version is set by the user service. Updates with a lower version than the stored user are ignored.
+	Version int `gorm:"not null;default:0"`
 }
@@ -7,6 +7,15 @@ import (
 
 type MessageBusPublisher interface {
 	CreateRider(ctx context.Context, rider domain.Rider) error
-	UpdateRider(ctx context.Context, rider domain.Rider) error
-	UpdateRiderLocation(ctx context.Context, serviceArea domain.ServiceArea, id string, newLocation domain.Location) error
+	UpdateRider(ctx context.Context, rider domain.Rider, changedFields []string) error
+	// UpdateRiderLocation publishes the rider's new location with the telemetry of the fix and whether its battery is low.
+	UpdateRiderLocation(ctx context.Context, serviceArea domain.ServiceArea, id string, newLocation domain.Location, telemetry domain.Telemetry) error
+	// UpdateRiderLocationBatch publishes the newest of the fixes as the rider's location, together with all fixes of the batch.
+	UpdateRiderLocationBatch(ctx context.Context, serviceArea domain.ServiceArea, id string, fixes []domain.LocationFix) error
+	// RiderPresenceLost publishes that a rider was set offline because it stopped sending locations and heartbeats.
+	RiderPresenceLost(ctx context.Context, rider domain.Rider) error
+	// LocationAnomaly publishes a suspicious fix of a rider.
+	LocationAnomaly(ctx context.Context, anomaly domain.LocationAnomaly) error
+	// ServiceAreaStats publishes the overview of the riders of a service area to the stats topic of the area.
+	ServiceAreaStats(ctx context.Context, stats domain.ServiceAreaStats) error
 }
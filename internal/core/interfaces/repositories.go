@@ -3,17 +3,61 @@ package interfaces
 import (
 	"context"
 	"rider-service/internal/core/domain"
+	"time"
 )
 
 type RiderRepository interface {
-	GetAll(ctx context.Context) ([]domain.Rider, error)
+	// GetAll returns the riders that match the filter, with their user and service area.
+	GetAll(ctx context.Context, filter domain.RiderFilter) ([]domain.Rider, error)
+	// StreamAll calls fn for every rider that matches the filter, with its user and service area, ordered by id.
+	// Riders are read in batches, so they are never all in memory at once. The first error of fn stops the stream and is returned.
+	StreamAll(ctx context.Context, filter domain.RiderFilter, fn func(domain.Rider) error) error
 	Get(ctx context.Context, id string) (domain.Rider, error)
 	Save(ctx context.Context, rider domain.Rider) (domain.Rider, error)
+	// Update writes the rider unless it changed since it was read. Its location is not written and its last seen time
+	// only moves forward, both are written by UpdateLocations.
 	Update(ctx context.Context, rider domain.Rider) (domain.Rider, error)
 	SaveOrUpdateUser(ctx context.Context, user domain.User) error
 	GetUser(ctx context.Context, id string) (domain.User, error)
+	GetServiceArea(ctx context.Context, id int) (domain.ServiceArea, error)
+	// GetSilentRiders returns the riders that are not offline and were last seen before the time, with their service area.
+	// Riders that were never seen are returned as well.
+	GetSilentRiders(ctx context.Context, seenBefore time.Time) ([]domain.Rider, error)
+	// UpdateLocations only writes the location, location timestamp, location sequence, telemetry and last seen time of the riders.
+	// It does not change their version, so location updates do not conflict with changes to the rider.
+	UpdateLocations(ctx context.Context, riders []domain.Rider) error
+	// SaveLocationBatches updates the locations of the riders and adds the fixes to their location history in a single transaction.
+	SaveLocationBatches(ctx context.Context, riders []domain.Rider, history []domain.RiderLocation) error
+	SaveLocationAnomalies(ctx context.Context, anomalies []domain.LocationAnomaly) error
+	// GetLocationAnomalies returns the anomalies that match the filter, newest first.
+	GetLocationAnomalies(ctx context.Context, filter domain.LocationAnomalyFilter) ([]domain.LocationAnomaly, error)
+	// GetRiderStats returns the stats of the riders that match the filter by the id of their service area. Areas without
+	// riders are left out, the Fits of the filter is ignored. Locations of riders that were not seen since staleBefore are stale.
+	GetRiderStats(ctx context.Context, filter domain.RiderFilter, staleBefore time.Time) (map[int]domain.RiderStats, error)
+	// GetHeatmap counts the locations the filter selects per geohash cell, ordered by geohash. Cells without
+	// locations are left out, as are riders that never sent a location.
+	GetHeatmap(ctx context.Context, filter domain.HeatmapFilter) ([]domain.HeatmapCell, error)
 }
 
 type ServiceAreaRepository interface {
 	SaveOrUpdateServiceArea(serviceArea domain.ServiceArea) error
+	// GetServiceAreas returns the service areas that are not deleted, ordered by id.
+	GetServiceAreas(ctx context.Context) ([]domain.ServiceArea, error)
+	// GetServiceArea returns the service area, also when it is deleted.
+	GetServiceArea(ctx context.Context, id int) (domain.ServiceArea, error)
+	// DeleteServiceArea marks the service area as deleted at the version. Deleting an area that is already deleted,
+	// or that is stored with a higher version, does nothing.
+	DeleteServiceArea(ctx context.Context, id int, version int) error
+}
+
+type IdempotencyRepository interface {
+	// GetIdempotencyRecord returns a not found error when the key is unknown or its record has expired.
+	GetIdempotencyRecord(ctx context.Context, key string) (domain.IdempotencyRecord, error)
+	SaveIdempotencyRecord(ctx context.Context, record domain.IdempotencyRecord) error
+}
+
+type InboxRepository interface {
+	// IsProcessed reports whether a message with the id was handled and its record has not expired yet.
+	IsProcessed(ctx context.Context, id string) (bool, error)
+	MarkProcessed(ctx context.Context, message domain.ProcessedMessage) error
 }
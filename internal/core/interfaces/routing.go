@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"context"
+	"rider-service/internal/core/domain"
+)
+
+// RouteEstimator estimates how far, and how long, riders are from a destination.
+type RouteEstimator interface {
+	// EstimateRoutes returns the route from every origin to the destination, in the order of the origins.
+	EstimateRoutes(ctx context.Context, origins []domain.RouteOrigin, destination domain.Location) ([]domain.Route, error)
+}
@@ -6,14 +6,80 @@ import (
 )
 
 type RiderService interface {
-	GetAll(ctx context.Context) ([]domain.Rider, error)
+	GetAll(ctx context.Context, filter domain.RiderFilter) ([]domain.Rider, error)
+	// StreamAll calls fn for every rider that matches the filter without loading all riders at once.
+	// The first error of fn stops the stream and is returned.
+	StreamAll(ctx context.Context, filter domain.RiderFilter, fn func(domain.Rider) error) error
 	Get(ctx context.Context, id string) (domain.Rider, error)
 	Create(ctx context.Context, userId string, serviceArea int, capacity domain.Dimensions) (domain.Rider, error)
-	Update(ctx context.Context, id string, status int, serviceArea int, capacity domain.Dimensions) (domain.Rider, error)
-	UpdateLocation(ctx context.Context, id string, location domain.Location) (domain.Rider, error)
+	// ImportRiders creates a rider for every row, or only validates the rows when commit is not set.
+	ImportRiders(ctx context.Context, rows []domain.RiderImportRow, commit bool) ([]domain.RiderImportResult, error)
+	// Update changes a rider. A non-zero version makes the update conditional on the rider still being at that version.
+	Update(ctx context.Context, id string, status int, serviceArea int, capacity domain.Dimensions, version int) (domain.Rider, error)
+	// Patch applies a partial update. Only the fields set in the patch are validated and changed.
+	Patch(ctx context.Context, id string, patch domain.RiderPatch, version int) (domain.Rider, error)
+	UpdateLocation(ctx context.Context, id string, fix domain.LocationFix) (domain.Rider, error)
+	// UpdateLocationBatch stores the fixes a rider's device buffered and moves the rider to the newest one.
+	UpdateLocationBatch(ctx context.Context, id string, fixes []domain.LocationFix) (domain.Rider, error)
+	// UpdateLocationBatches does the same as UpdateLocationBatch for several riders at once, all or nothing.
+	UpdateLocationBatches(ctx context.Context, batches []domain.LocationBatch) ([]domain.Rider, error)
+	// Heartbeat records that a rider is still there, like a location update without a location.
+	Heartbeat(ctx context.Context, id string) (domain.Rider, error)
+	// GetLocationAnomalies returns the suspicious fixes that match the filter, newest first.
+	GetLocationAnomalies(ctx context.Context, filter domain.LocationAnomalyFilter) ([]domain.LocationAnomaly, error)
 	SaveOrUpdateUser(ctx context.Context, user domain.User) error
+	// ReassignServiceArea moves every rider of the service area to another one, or sets them offline when to is 0.
+	// It returns the riders that changed.
+	ReassignServiceArea(ctx context.Context, from int, to int) ([]domain.Rider, error)
+	// HandleDeliveryEvent moves the rider of the delivery along with it, see domain.DeliveryEvent.Apply.
+	HandleDeliveryEvent(ctx context.Context, event domain.DeliveryEvent) (domain.Rider, error)
+	// LoadParcel puts a parcel in the rider's box, or replaces the parcel with the same id. It has to fit in the room
+	// that is left.
+	LoadParcel(ctx context.Context, riderID string, parcel domain.Parcel) (domain.Rider, error)
+	// UnloadParcel takes a parcel out of the rider's box. Parcels of a delivery are only unloaded by its events.
+	UnloadParcel(ctx context.Context, riderID string, parcelID string) (domain.Rider, error)
+}
+
+// LocationFlusher writes buffered rider locations to the repository.
+type LocationFlusher interface {
+	FlushLocations(ctx context.Context) error
+}
+
+// PresenceMonitor sets riders offline that stopped sending locations and heartbeats.
+type PresenceMonitor interface {
+	SetSilentRidersOffline(ctx context.Context) ([]domain.Rider, error)
+}
+
+type StatsService interface {
+	// GetServiceAreaStats returns an overview of the riders of the service area, also of a deleted one.
+	GetServiceAreaStats(ctx context.Context, id int) (domain.ServiceAreaStats, error)
+	// GetStatsSummary returns an overview of the riders of every service area that is not deleted, and their total.
+	GetStatsSummary(ctx context.Context) (domain.StatsSummary, error)
+	// GetServiceAreaHeatmap counts the locations of the riders of the service area per geohash cell. The service area
+	// id of the filter is required, its other fields default to the configured ones.
+	GetServiceAreaHeatmap(ctx context.Context, filter domain.HeatmapFilter) (domain.Heatmap, error)
+}
+
+type ETAService interface {
+	// GetETA estimates how long the rider takes to reach the destination from its last location.
+	GetETA(ctx context.Context, riderID string, destination domain.Location) (domain.RiderETA, error)
+	// GetETAs estimates the routes of several riders to the destination, fastest first. Riders without a route
+	// are listed last with the reason in their Err.
+	GetETAs(ctx context.Context, riderIDs []string, destination domain.Location) ([]domain.RiderETA, error)
+}
+
+// StatsPublisher publishes the overview of the riders of every service area for dashboards.
+type StatsPublisher interface {
+	PublishStats(ctx context.Context) error
 }
 
 type ServiceAreaService interface {
 	SaveOrUpdateServiceArea(serviceArea domain.ServiceArea) error
+	// GetServiceAreas returns the service areas that are not deleted.
+	GetServiceAreas(ctx context.Context) ([]domain.ServiceArea, error)
+	// GetServiceArea returns the service area, also when it is deleted.
+	GetServiceArea(ctx context.Context, id int) (domain.ServiceArea, error)
+	// DeleteServiceArea deletes the service area and moves its riders to the area reassignTo. When reassignTo is 0,
+	// or not an area that can take riders, the riders are set offline and stay in the deleted area until they are moved.
+	DeleteServiceArea(ctx context.Context, id int, version int, reassignTo int) error
 }
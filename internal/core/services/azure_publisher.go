@@ -3,10 +3,10 @@ package services
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"rider-service/config"
 	"rider-service/internal/core/domain"
 	"rider-service/pkg/azure"
+	"rider-service/pkg/events"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
 )
@@ -15,56 +15,76 @@ type azurePublisher struct {
 	serviceBus *azure.ServiceBus
 	sender     *azservicebus.Sender
 	config     *config.Config
+	// send sends a message to the topic, tests replace it to see what is published.
+	send func(ctx context.Context, topic string, message *azservicebus.Message) error
 }
 
 func NewAzurePublisher(serviceBus *azure.ServiceBus, cfg *config.Config) *azurePublisher {
-	return &azurePublisher{serviceBus: serviceBus, config: cfg}
+	az := &azurePublisher{serviceBus: serviceBus, config: cfg}
+	az.send = az.sendMessage
+
+	return az
 }
 
 func (az *azurePublisher) CreateRider(ctx context.Context, rider domain.Rider) error {
-	return az.publishJson(ctx, "create", rider)
+	return az.publishEvent(ctx, events.TopicRiderCreated, events.RiderCreatedV1{Rider: newRiderEvent(rider)})
 }
 
-func (az *azurePublisher) UpdateRider(ctx context.Context, rider domain.Rider) error {
-	return az.publishJson(ctx, "update", rider)
+func (az *azurePublisher) UpdateRider(ctx context.Context, rider domain.Rider, changedFields []string) error {
+	return az.publishEvent(ctx, events.TopicRiderUpdated, newRiderUpdatedEvent(rider, changedFields))
 }
 
-func (az *azurePublisher) UpdateRiderLocation(ctx context.Context, serviceArea domain.ServiceArea, id string, newLocation domain.Location) error {
-	message := struct {
-		Id       string
-		Location domain.Location
-	}{Id: id, Location: newLocation}
+func (az *azurePublisher) UpdateRiderLocation(ctx context.Context, serviceArea domain.ServiceArea, id string, newLocation domain.Location, telemetry domain.Telemetry) error {
+	return az.publishEvent(ctx, events.LocationTopic(serviceArea.Identifier), newLocationUpdatedEvent(id, newLocation, telemetry))
+}
 
-	return az.publishJson(ctx, serviceArea.Identifier+".update.location", message)
+func (az *azurePublisher) UpdateRiderLocationBatch(ctx context.Context, serviceArea domain.ServiceArea, id string, fixes []domain.LocationFix) error {
+	return az.publishEvent(ctx, events.LocationTopic(serviceArea.Identifier), newLocationBatchEvent(id, fixes))
 }
 
-func (az *azurePublisher) publishJson(ctx context.Context, topic string, body interface{}) error {
-	js, err := json.Marshal(body)
+func (az *azurePublisher) RiderPresenceLost(ctx context.Context, rider domain.Rider) error {
+	return az.publishEvent(ctx, events.TopicRiderPresenceLost, newPresenceLostEvent(rider))
+}
 
-	if err != nil {
-		return err
-	}
+func (az *azurePublisher) LocationAnomaly(ctx context.Context, anomaly domain.LocationAnomaly) error {
+	return az.publishEvent(ctx, events.TopicLocationAnomaly, newLocationAnomalyEvent(anomaly))
+}
 
-	topic = fmt.Sprintf("customer.%s", topic)
+func (az *azurePublisher) ServiceAreaStats(ctx context.Context, stats domain.ServiceAreaStats) error {
+	return az.publishEvent(ctx, events.StatsTopic(stats.ServiceArea.Identifier), newServiceAreaStatsEvent(stats))
+}
 
-	sender, err := az.serviceBus.Client.NewSender(topic, nil)
+// Topic returns the name of the topic on Azure Service Bus.
+func (az *azurePublisher) Topic(name string) string {
+	return "customer." + name
+}
 
-	defer func(sender *azservicebus.Sender, ctx context.Context) {
-		_ = sender.Close(ctx)
-	}(sender, ctx)
+func (az *azurePublisher) publishEvent(ctx context.Context, topic string, event events.Event) error {
+	js, err := json.Marshal(event)
 
 	if err != nil {
 		return err
 	}
 
-	err = sender.SendMessage(ctx, &azservicebus.Message{
-		Body:    js,
-		Subject: &topic,
-	}, nil)
+	topic = az.Topic(topic)
+
+	return az.send(ctx, topic, &azservicebus.Message{
+		Body:                  js,
+		Subject:               &topic,
+		ApplicationProperties: map[string]interface{}{"type": event.Schema()},
+	})
+}
+
+func (az *azurePublisher) sendMessage(ctx context.Context, topic string, message *azservicebus.Message) error {
+	sender, err := az.serviceBus.Client.NewSender(topic, nil)
 
 	if err != nil {
 		return err
 	}
 
-	return err
+	defer func(sender *azservicebus.Sender, ctx context.Context) {
+		_ = sender.Close(ctx)
+	}(sender, ctx)
+
+	return sender.SendMessage(ctx, message, nil)
 }
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rider-service/config"
+	"rider-service/internal/core/domain"
+	"rider-service/internal/core/interfaces"
+	"sort"
+	"time"
+)
+
+type etaService struct {
+	riderService interfaces.RiderService
+	estimator    interfaces.RouteEstimator
+	config       *config.Config
+	now          func() time.Time
+}
+
+// NewETAService estimates routes from the riders as the rider service has them, so locations that are still
+// buffered are used.
+func NewETAService(riderService interfaces.RiderService, estimator interfaces.RouteEstimator, cfg *config.Config) *etaService {
+	return &etaService{
+		riderService: riderService,
+		estimator:    estimator,
+		config:       cfg,
+		now:          time.Now,
+	}
+}
+
+func (srv *etaService) GetETA(ctx context.Context, riderID string, destination domain.Location) (domain.RiderETA, error) {
+	if fields := destination.Validate("destination"); len(fields) > 0 {
+		return domain.RiderETA{}, domain.NewValidationError(fields...)
+	}
+
+	rider, err := srv.riderService.Get(ctx, riderID)
+
+	if err != nil {
+		return domain.RiderETA{}, err
+	}
+
+	etas, err := srv.estimate(ctx, []domain.Rider{rider}, destination)
+
+	if err != nil {
+		return domain.RiderETA{}, err
+	}
+
+	if etas[0].Err != nil {
+		return domain.RiderETA{}, etas[0].Err
+	}
+
+	return etas[0], nil
+}
+
+// GetETAs asks the estimator for the routes of all riders at once. Riders that do not exist are listed with
+// a not found error instead of failing the whole list.
+func (srv *etaService) GetETAs(ctx context.Context, riderIDs []string, destination domain.Location) ([]domain.RiderETA, error) {
+	fields := destination.Validate("destination")
+
+	switch {
+	case len(riderIDs) == 0:
+		fields = append(fields, domain.FieldError{Field: "riders", Message: "can not be empty"})
+	case len(riderIDs) > srv.config.ETA.MaxRiders:
+		fields = append(fields, domain.FieldError{Field: "riders", Message: fmt.Sprintf("can not be more than %d", srv.config.ETA.MaxRiders)})
+	}
+
+	seen := make(map[string]bool, len(riderIDs))
+
+	for i, id := range riderIDs {
+		if seen[id] {
+			fields = append(fields, domain.FieldError{Field: fmt.Sprintf("riders[%d]", i), Message: "is listed more than once"})
+		}
+
+		seen[id] = true
+	}
+
+	if len(fields) > 0 {
+		return nil, domain.NewValidationError(fields...)
+	}
+
+	riders := make([]domain.Rider, 0, len(riderIDs))
+	var unknown []domain.RiderETA
+
+	for _, id := range riderIDs {
+		rider, err := srv.riderService.Get(ctx, id)
+
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			unknown = append(unknown, domain.RiderETA{RiderID: id, EstimatedAt: srv.now(), Err: err})
+		case err != nil:
+			return nil, err
+		default:
+			riders = append(riders, rider)
+		}
+	}
+
+	etas, err := srv.estimate(ctx, riders, destination)
+
+	if err != nil {
+		return nil, err
+	}
+
+	etas = append(etas, unknown...)
+
+	// Riders without a route go last, in the order they were asked for.
+	sort.SliceStable(etas, func(i, j int) bool {
+		if (etas[i].Err == nil) != (etas[j].Err == nil) {
+			return etas[i].Err == nil
+		}
+
+		return etas[i].Err == nil && etas[i].Route.Duration < etas[j].Route.Duration
+	})
+
+	return etas, nil
+}
+
+// estimate returns the ETA of every rider in the order of the riders. Riders that never sent a location and riders
+// that can not reach the destination get an error.
+func (srv *etaService) estimate(ctx context.Context, riders []domain.Rider, destination domain.Location) ([]domain.RiderETA, error) {
+	estimatedAt := srv.now()
+	etas := make([]domain.RiderETA, 0, len(riders))
+	origins := make([]domain.RouteOrigin, 0, len(riders))
+	var located []int
+
+	for i, rider := range riders {
+		eta := domain.RiderETA{
+			RiderID:     rider.UserID,
+			VehicleType: rider.VehicleType,
+			LastSeenAt:  rider.LastSeenAt,
+			EstimatedAt: estimatedAt,
+		}
+
+		if rider.HasLocation() {
+			origins = append(origins, domain.RouteOrigin{Location: rider.Location, VehicleType: rider.VehicleType})
+			located = append(located, i)
+		} else {
+			eta.Err = domain.NewConflictError("rider %s has not sent a location", rider.UserID)
+		}
+
+		etas = append(etas, eta)
+	}
+
+	if len(origins) == 0 {
+		return etas, nil
+	}
+
+	routes, err := srv.estimator.EstimateRoutes(ctx, origins, destination)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range located {
+		etas[i].Route = routes[j]
+
+		if routes[j].Unreachable {
+			etas[i].Err = domain.NewConflictError("rider %s can not reach the destination", etas[i].RiderID)
+		}
+	}
+
+	return etas, nil
+}
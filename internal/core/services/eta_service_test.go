@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/suite"
+	"rider-service/config"
+	"rider-service/internal/core/domain"
+	"rider-service/internal/mock"
+	"testing"
+	"time"
+)
+
+type ETAServiceTestSuite struct {
+	suite.Suite
+	MockRiderService *mock.RiderService
+	MockEstimator    *mock.RouteEstimator
+	TestService      *etaService
+	Now              time.Time
+	TestData         struct {
+		Destination domain.Location
+		Riders      []domain.Rider
+	}
+}
+
+func (suite *ETAServiceTestSuite) SetupSuite() {
+	suite.Now = time.Date(2022, 5, 1, 13, 0, 0, 0, time.UTC)
+	suite.TestData.Destination = domain.Location{Latitude: 51.45, Longitude: 5.47}
+
+	nearby := domain.NewRider(domain.User{ID: "nearby-id"}, domain.StatusAvailable, 1, domain.Dimensions{})
+	nearby.Location = domain.Location{Latitude: 51.44, Longitude: 5.46}
+	nearby.LastSeenAt = &suite.Now
+
+	far := domain.NewRider(domain.User{ID: "far-id"}, domain.StatusAvailable, 1, domain.Dimensions{})
+	far.VehicleType = domain.VehicleEBike
+	far.Location = domain.Location{Latitude: 51.40, Longitude: 5.40}
+
+	unlocated := domain.NewRider(domain.User{ID: "unlocated-id"}, domain.StatusAvailable, 1, domain.Dimensions{})
+
+	suite.TestData.Riders = []domain.Rider{nearby, far, unlocated}
+}
+
+func (suite *ETAServiceTestSuite) SetupTest() {
+	suite.MockRiderService = new(mock.RiderService)
+	suite.MockEstimator = new(mock.RouteEstimator)
+
+	suite.TestService = NewETAService(suite.MockRiderService, suite.MockEstimator, &config.Config{ETA: config.ETA{MaxRiders: 3}})
+	suite.TestService.now = func() time.Time { return suite.Now }
+}
+
+func (suite *ETAServiceTestSuite) origin(rider domain.Rider) domain.RouteOrigin {
+	return domain.RouteOrigin{Location: rider.Location, VehicleType: rider.VehicleType}
+}
+
+func (suite *ETAServiceTestSuite) TestETAService_GetETA() {
+	rider := suite.TestData.Riders[0]
+	route := domain.Route{Distance: 1500, Duration: 5 * time.Minute}
+
+	suite.MockRiderService.On("Get", rider.UserID).Return(rider, nil)
+	suite.MockEstimator.On("EstimateRoutes", []domain.RouteOrigin{suite.origin(rider)}, suite.TestData.Destination).Return([]domain.Route{route}, nil)
+
+	result, err := suite.TestService.GetETA(context.Background(), rider.UserID, suite.TestData.Destination)
+
+	suite.NoError(err)
+	suite.Equal(domain.RiderETA{
+		RiderID:     rider.UserID,
+		VehicleType: domain.VehicleBicycle,
+		LastSeenAt:  &suite.Now,
+		Route:       route,
+		EstimatedAt: suite.Now,
+	}, result)
+}
+
+func (suite *ETAServiceTestSuite) TestETAService_GetETA_NoLocation() {
+	rider := suite.TestData.Riders[2]
+
+	suite.MockRiderService.On("Get", rider.UserID).Return(rider, nil)
+
+	_, err := suite.TestService.GetETA(context.Background(), rider.UserID, suite.TestData.Destination)
+
+	suite.ErrorIs(err, domain.ErrConflict)
+	suite.MockEstimator.AssertNotCalled(suite.T(), "EstimateRoutes")
+}
+
+func (suite *ETAServiceTestSuite) TestETAService_GetETA_Unreachable() {
+	rider := suite.TestData.Riders[0]
+
+	suite.MockRiderService.On("Get", rider.UserID).Return(rider, nil)
+	suite.MockEstimator.On("EstimateRoutes", []domain.RouteOrigin{suite.origin(rider)}, suite.TestData.Destination).
+		Return([]domain.Route{{Unreachable: true}}, nil)
+
+	_, err := suite.TestService.GetETA(context.Background(), rider.UserID, suite.TestData.Destination)
+
+	suite.ErrorIs(err, domain.ErrConflict)
+}
+
+func (suite *ETAServiceTestSuite) TestETAService_GetETA_InvalidDestination() {
+	_, err := suite.TestService.GetETA(context.Background(), "nearby-id", domain.Location{Latitude: 91})
+
+	suite.ErrorIs(err, domain.ErrValidation)
+	suite.MockRiderService.AssertNotCalled(suite.T(), "Get")
+}
+
+func (suite *ETAServiceTestSuite) TestETAService_GetETAs() {
+	nearby, far, unlocated := suite.TestData.Riders[0], suite.TestData.Riders[1], suite.TestData.Riders[2]
+
+	suite.MockRiderService.On("Get", "unknown-id").Return(domain.Rider{}, domain.NewNotFoundError("rider unknown-id not found"))
+
+	for _, rider := range suite.TestData.Riders {
+		suite.MockRiderService.On("Get", rider.UserID).Return(rider, nil)
+	}
+
+	suite.MockEstimator.On("EstimateRoutes", []domain.RouteOrigin{suite.origin(far), suite.origin(nearby)}, suite.TestData.Destination).
+		Return([]domain.Route{{Distance: 9000, Duration: 20 * time.Minute}, {Distance: 1500, Duration: 5 * time.Minute}}, nil)
+
+	ids := []string{"unknown-id", far.UserID, unlocated.UserID, nearby.UserID}
+
+	_, err := suite.TestService.GetETAs(context.Background(), ids, suite.TestData.Destination)
+
+	suite.ErrorIs(err, domain.ErrValidation, "more riders than eta.maxRiders")
+
+	suite.TestService.config.ETA.MaxRiders = 4
+	result, err := suite.TestService.GetETAs(context.Background(), ids, suite.TestData.Destination)
+
+	suite.NoError(err)
+	suite.Require().Len(result, 4)
+	suite.Equal(nearby.UserID, result[0].RiderID, "the fastest rider comes first")
+	suite.Equal(far.UserID, result[1].RiderID)
+	suite.Equal(domain.VehicleEBike, result[1].VehicleType)
+	suite.Equal(unlocated.UserID, result[2].RiderID, "riders without a route come last, in the order they were asked for")
+	suite.ErrorIs(result[2].Err, domain.ErrConflict)
+	suite.Equal("unknown-id", result[3].RiderID)
+	suite.ErrorIs(result[3].Err, domain.ErrNotFound)
+}
+
+func (suite *ETAServiceTestSuite) TestETAService_GetETAs_Invalid() {
+	_, err := suite.TestService.GetETAs(context.Background(), []string{"nearby-id", "nearby-id"}, suite.TestData.Destination)
+
+	var domainErr *domain.Error
+	suite.Require().ErrorAs(err, &domainErr)
+	suite.Equal([]domain.FieldError{{Field: "riders[1]", Message: "is listed more than once"}}, domainErr.Fields)
+
+	_, err = suite.TestService.GetETAs(context.Background(), nil, suite.TestData.Destination)
+
+	suite.ErrorIs(err, domain.ErrValidation)
+	suite.MockRiderService.AssertNotCalled(suite.T(), "Get")
+}
+
+func (suite *ETAServiceTestSuite) TestETAService_GetETAs_EstimatorFails() {
+	rider := suite.TestData.Riders[0]
+
+	suite.MockRiderService.On("Get", rider.UserID).Return(rider, nil)
+	suite.MockEstimator.On("EstimateRoutes", []domain.RouteOrigin{suite.origin(rider)}, suite.TestData.Destination).
+		Return([]domain.Route{}, domain.NewUnavailableError(errors.New("test-error"), "routing service is not available"))
+
+	_, err := suite.TestService.GetETAs(context.Background(), []string{rider.UserID}, suite.TestData.Destination)
+
+	suite.ErrorIs(err, domain.ErrUnavailable)
+}
+
+func TestUnit_ETAServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ETAServiceTestSuite))
+}
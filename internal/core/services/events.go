@@ -0,0 +1,141 @@
+package services
+
+import (
+	"rider-service/internal/core/domain"
+	"rider-service/pkg/events"
+)
+
+func newDimensionsEvent(dimensions domain.Dimensions) events.Dimensions {
+	return events.Dimensions{
+		Width:  dimensions.Width,
+		Height: dimensions.Height,
+		Depth:  dimensions.Depth,
+	}
+}
+
+func newTelemetryEvent(telemetry domain.Telemetry) events.Telemetry {
+	return events.Telemetry{
+		Accuracy:   telemetry.Accuracy,
+		Speed:      telemetry.Speed,
+		Heading:    telemetry.Heading,
+		Altitude:   telemetry.Altitude,
+		Battery:    telemetry.Battery,
+		LowBattery: telemetry.HasLowBattery(),
+	}
+}
+
+func newLocationEvent(location domain.Location) events.Location {
+	return events.Location{Latitude: location.Latitude, Longitude: location.Longitude}
+}
+
+// newRiderEvent expects the rider as it is shared, a rider without a location is published without one.
+func newRiderEvent(rider domain.Rider) events.Rider {
+	event := events.Rider{
+		UserID: rider.UserID,
+		User: events.User{
+			ID:       rider.User.ID,
+			Name:     rider.User.Name,
+			LastName: rider.User.LastName,
+		},
+		Status:        rider.Status,
+		ServiceAreaID: rider.ServiceAreaID,
+		ServiceArea: events.ServiceArea{
+			ID:         rider.ServiceArea.ID,
+			Identifier: rider.ServiceArea.Identifier,
+		},
+		Capacity:          newDimensionsEvent(rider.Capacity),
+		VehicleType:       string(rider.VehicleType),
+		DeliveryID:        rider.DeliveryID,
+		RemainingCapacity: newDimensionsEvent(rider.RemainingCapacity()),
+		Telemetry:         newTelemetryEvent(rider.Telemetry),
+		LastSeenAt:        rider.LastSeenAt,
+		Version:           rider.Version,
+	}
+
+	if rider.HasLocation() {
+		location := newLocationEvent(rider.Location)
+		event.Location = &location
+	}
+
+	return event
+}
+
+func newRiderUpdatedEvent(rider domain.Rider, changedFields []string) events.RiderUpdatedV1 {
+	if changedFields == nil {
+		changedFields = []string{}
+	}
+
+	return events.RiderUpdatedV1{Rider: newRiderEvent(rider), ChangedFields: changedFields}
+}
+
+func newLocationUpdatedEvent(id string, location domain.Location, telemetry domain.Telemetry) events.RiderLocationUpdatedV1 {
+	return events.RiderLocationUpdatedV1{ID: id, Location: newLocationEvent(location), Telemetry: newTelemetryEvent(telemetry)}
+}
+
+// newLocationBatchEvent extends the location update with the fixes of the batch,
+// so consumers that only read the id, location and telemetry keep working.
+// It expects the fixes oldest first, the last one becomes the rider's location.
+// The battery level is the last one any of the fixes reported, like it is for the rider.
+func newLocationBatchEvent(id string, fixes []domain.LocationFix) events.RiderLocationUpdatedV1 {
+	batchFixes := make([]events.LocationFix, 0, len(fixes))
+
+	var rider domain.Rider
+
+	for _, fix := range fixes {
+		rider = fix.Apply(rider)
+
+		batchFix := events.LocationFix{
+			Latitude:  fix.Location.Latitude,
+			Longitude: fix.Location.Longitude,
+			Sequence:  fix.Sequence,
+			Telemetry: newTelemetryEvent(fix.Telemetry),
+		}
+
+		if !fix.Timestamp.IsZero() {
+			timestamp := fix.Timestamp.UTC()
+			batchFix.Timestamp = &timestamp
+		}
+
+		batchFixes = append(batchFixes, batchFix)
+	}
+
+	event := newLocationUpdatedEvent(id, rider.Location, rider.Telemetry)
+	event.Fixes = batchFixes
+
+	return event
+}
+
+func newPresenceLostEvent(rider domain.Rider) events.RiderPresenceLostV1 {
+	return events.RiderPresenceLostV1{ID: rider.UserID, ServiceArea: rider.ServiceArea.Identifier, LastSeenAt: rider.LastSeenAt}
+}
+
+func newLocationAnomalyEvent(anomaly domain.LocationAnomaly) events.LocationAnomalyV1 {
+	return events.LocationAnomalyV1{
+		ID:               anomaly.RiderID,
+		Kind:             string(anomaly.Kind),
+		Location:         newLocationEvent(anomaly.Location),
+		PreviousLocation: newLocationEvent(anomaly.PreviousLocation),
+		ImpliedSpeed:     anomaly.ImpliedSpeed,
+		Rejected:         anomaly.Rejected,
+		DetectedAt:       anomaly.DetectedAt,
+	}
+}
+
+func newServiceAreaStatsEvent(stats domain.ServiceAreaStats) events.ServiceAreaStatsV1 {
+	riders := stats.Riders
+
+	return events.ServiceAreaStatsV1{
+		ServiceArea: events.ServiceArea{ID: stats.ServiceArea.ID, Identifier: stats.ServiceArea.Identifier},
+		Riders:      riders.Riders(),
+		Online:      riders.Online(),
+		ByStatus: events.RidersByStatus{
+			Offline:    riders.ByStatus[domain.StatusOffline],
+			Available:  riders.ByStatus[domain.StatusAvailable],
+			Assigned:   riders.ByStatus[domain.StatusAssigned],
+			Delivering: riders.ByStatus[domain.StatusDelivering],
+		},
+		AverageCapacity: events.AverageDimensions(riders.AverageCapacity),
+		StaleLocations:  riders.StaleLocations,
+		ComputedAt:      stats.ComputedAt,
+	}
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"rider-service/config"
+	"rider-service/internal/core/domain"
+	"time"
+)
+
+// fallbackSpeed is the speed in km/h of vehicle types that have no speed and whose riders can not fall back to
+// the bicycle speed either.
+const fallbackSpeed = 15
+
+// haversineEstimator estimates routes from the distance as the crow flies, made longer by a detour factor, and the
+// average speed of the vehicle type. It needs no routing service, but does not know about roads or traffic.
+type haversineEstimator struct {
+	config *config.Config
+}
+
+func NewHaversineEstimator(cfg *config.Config) *haversineEstimator {
+	return &haversineEstimator{config: cfg}
+}
+
+func (estimator *haversineEstimator) EstimateRoutes(ctx context.Context, origins []domain.RouteOrigin, destination domain.Location) ([]domain.Route, error) {
+	detourFactor := estimator.config.ETA.DetourFactor
+
+	if detourFactor < 1 {
+		detourFactor = 1
+	}
+
+	routes := make([]domain.Route, 0, len(origins))
+
+	for _, origin := range origins {
+		distance := origin.Location.DistanceTo(destination) * detourFactor
+		metersPerSecond := estimator.speed(origin.VehicleType) / 3.6
+
+		routes = append(routes, domain.Route{
+			Distance: distance,
+			Duration: time.Duration(distance / metersPerSecond * float64(time.Second)),
+		})
+	}
+
+	return routes, nil
+}
+
+// speed returns the speed in km/h of the vehicle type, vehicle types without a speed use the bicycle speed.
+func (estimator *haversineEstimator) speed(vehicleType domain.VehicleType) float64 {
+	for _, vehicle := range []domain.VehicleType{vehicleType, domain.VehicleBicycle} {
+		if speed := estimator.config.ETA.Speeds[string(vehicle)]; speed > 0 {
+			return speed
+		}
+	}
+
+	return fallbackSpeed
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"rider-service/config"
+	"rider-service/internal/core/domain"
+	"testing"
+	"time"
+)
+
+func TestUnit_HaversineEstimator(t *testing.T) {
+	estimator := NewHaversineEstimator(&config.Config{ETA: config.ETA{
+		Speeds:       map[string]float64{"bicycle": 18, "e-bike": 36},
+		DetourFactor: 1.5,
+	}})
+
+	origin := domain.Location{Latitude: 51.4416, Longitude: 5.4697}
+	destination := domain.Location{Latitude: 51.4507, Longitude: 5.4697}
+	distance := origin.DistanceTo(destination) * 1.5
+
+	routes, err := estimator.EstimateRoutes(context.Background(), []domain.RouteOrigin{
+		{Location: origin, VehicleType: domain.VehicleBicycle},
+		{Location: origin, VehicleType: domain.VehicleEBike},
+		{Location: origin, VehicleType: domain.VehicleCargoBike},
+	}, destination)
+
+	assert.NoError(t, err)
+	assert.Len(t, routes, 3)
+	assert.InDelta(t, distance, routes[0].Distance, 0.001)
+	assert.InDelta(t, distance/5, routes[0].Duration.Seconds(), 0.001, "18 km/h is 5 m/s")
+	assert.InDelta(t, distance/10, routes[1].Duration.Seconds(), 0.001)
+	assert.Equal(t, routes[0], routes[2], "vehicle types without a speed ride at the bicycle speed")
+}
+
+func TestUnit_HaversineEstimator_Defaults(t *testing.T) {
+	estimator := NewHaversineEstimator(&config.Config{})
+
+	origin := domain.Location{Latitude: 51.4416, Longitude: 5.4697}
+	destination := domain.Location{Latitude: 51.4507, Longitude: 5.4697}
+
+	routes, err := estimator.EstimateRoutes(context.Background(), []domain.RouteOrigin{{Location: origin}}, destination)
+
+	assert.NoError(t, err)
+	assert.InDelta(t, origin.DistanceTo(destination), routes[0].Distance, 0.001, "without a detour factor routes are as the crow flies")
+	assert.InDelta(t, routes[0].Distance/(fallbackSpeed/3.6), routes[0].Duration.Seconds(), 0.001)
+	assert.Greater(t, routes[0].Duration, time.Duration(0))
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"rider-service/internal/core/domain"
+	"rider-service/internal/core/interfaces"
+	"sync"
+	"time"
+)
+
+// maxSnapshotAge is how long a cached rider is used before it is loaded again,
+// so changes made by other instances of the service are picked up.
+const maxSnapshotAge = time.Minute
+
+type bufferedRider struct {
+	rider    domain.Rider
+	loadedAt time.Time
+	// dirty is set while the position or last seen time of the rider has not been written to the repository.
+	dirty bool
+	// idle is set by a flush and cleared by an update, riders that stay idle for a whole flush are evicted.
+	idle bool
+}
+
+// see moves the last seen time of the rider forward and marks it to be written.
+func (entry *bufferedRider) see(seenAt time.Time) {
+	entry.rider.LastSeenAt = laterTime(entry.rider.LastSeenAt, &seenAt)
+	entry.dirty = true
+	entry.idle = false
+}
+
+// locationBuffer caches riders that are sending locations or heartbeats and coalesces their positions and last seen times,
+// so only the latest of each rider is written when the buffer is flushed.
+type locationBuffer struct {
+	mutex      sync.Mutex
+	flushMutex sync.Mutex
+	riders     map[string]*bufferedRider
+}
+
+func newLocationBuffer() *locationBuffer {
+	return &locationBuffer{
+		riders: make(map[string]*bufferedRider),
+	}
+}
+
+// update moves the cached rider to the fix and records that it was seen. It reports whether the fix was applied,
+// stale fixes are not, and whether the rider was cached at all. Riders cached longer than maxSnapshotAge count as not cached.
+func (buffer *locationBuffer) update(id string, fix domain.LocationFix, seenAt time.Time) (rider domain.Rider, applied bool, cached bool) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	entry, exists := buffer.cached(id)
+
+	if !exists {
+		return domain.Rider{}, false, false
+	}
+
+	entry.see(seenAt)
+
+	if fix.IsStaleFor(entry.rider) {
+		return entry.rider, false, true
+	}
+
+	entry.rider = fix.Apply(entry.rider)
+
+	return entry.rider, true, true
+}
+
+// get returns the cached rider and whether it was cached, like update does.
+func (buffer *locationBuffer) get(id string) (rider domain.Rider, cached bool) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	entry, exists := buffer.cached(id)
+
+	if !exists {
+		return domain.Rider{}, false
+	}
+
+	return entry.rider, true
+}
+
+// seen records that the cached rider was seen and reports whether the rider was cached, like update does.
+func (buffer *locationBuffer) seen(id string, seenAt time.Time) (rider domain.Rider, cached bool) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	entry, exists := buffer.cached(id)
+
+	if !exists {
+		return domain.Rider{}, false
+	}
+
+	entry.see(seenAt)
+
+	return entry.rider, true
+}
+
+// cached returns the entry of a rider unless it is older than maxSnapshotAge. Older entries are evicted once their
+// position is written, so a position that another instance wrote since is not replaced by them. The caller holds the lock.
+func (buffer *locationBuffer) cached(id string) (*bufferedRider, bool) {
+	entry, exists := buffer.riders[id]
+
+	if !exists {
+		return nil, false
+	}
+
+	if time.Since(entry.loadedAt) > maxSnapshotAge {
+		if !entry.dirty {
+			delete(buffer.riders, id)
+		}
+
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// load caches a rider that was read from the repository. A position that is already cached is kept,
+// it is at least as new as the one that was read.
+func (buffer *locationBuffer) load(rider domain.Rider) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	entry, exists := buffer.riders[rider.UserID]
+
+	if !exists {
+		buffer.riders[rider.UserID] = &bufferedRider{rider: rider, loadedAt: time.Now()}
+		return
+	}
+
+	entry.rider = withPosition(rider, entry.rider)
+	entry.loadedAt = time.Now()
+}
+
+// refresh replaces a cached rider after it was changed, keeping its cached position.
+func (buffer *locationBuffer) refresh(rider domain.Rider) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	if entry, exists := buffer.cached(rider.UserID); exists {
+		entry.rider = withPosition(rider, entry.rider)
+	}
+}
+
+// written records that the position and last seen time of a rider were written to the repository without going through the buffer.
+// When the cached position or last seen time is newer it is marked to be written again, so the repository does not keep the older one.
+func (buffer *locationBuffer) written(rider domain.Rider) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	entry, exists := buffer.cached(rider.UserID)
+
+	if !exists {
+		return
+	}
+
+	if rider.LastFix().IsStaleFor(entry.rider) {
+		entry.rider.LastSeenAt = laterTime(entry.rider.LastSeenAt, rider.LastSeenAt)
+		entry.dirty = true
+		return
+	}
+
+	entry.dirty = entry.rider.LastSeenAt != nil && (rider.LastSeenAt == nil || entry.rider.LastSeenAt.After(*rider.LastSeenAt))
+	entry.rider = withPosition(entry.rider, rider)
+}
+
+// overlay returns the rider with its cached position, which can be newer than the one in the repository.
+// Riders cached longer than maxSnapshotAge are returned as they were read.
+func (buffer *locationBuffer) overlay(rider domain.Rider) domain.Rider {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	if entry, exists := buffer.cached(rider.UserID); exists {
+		return withPosition(rider, entry.rider)
+	}
+
+	return rider
+}
+
+// flush writes the positions that changed since the last flush in a single batch and evicts idle riders.
+func (buffer *locationBuffer) flush(ctx context.Context, repository interfaces.RiderRepository) error {
+	// Flushes run one at a time, so an older batch can not be written after a newer one.
+	buffer.flushMutex.Lock()
+	defer buffer.flushMutex.Unlock()
+
+	buffer.mutex.Lock()
+
+	var pending []domain.Rider
+
+	for id, entry := range buffer.riders {
+		switch {
+		case entry.dirty:
+			pending = append(pending, entry.rider)
+			entry.dirty = false
+		case entry.idle:
+			delete(buffer.riders, id)
+		default:
+			entry.idle = true
+		}
+	}
+
+	buffer.mutex.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	err := repository.UpdateLocations(ctx, pending)
+
+	if err != nil {
+		buffer.mutex.Lock()
+		defer buffer.mutex.Unlock()
+
+		for _, rider := range pending {
+			if entry, exists := buffer.riders[rider.UserID]; exists {
+				entry.dirty = true
+			}
+		}
+	}
+
+	return err
+}
+
+// withPosition returns the rider with the location, timestamp, sequence and telemetry of the other rider.
+// The later of the two last seen times is kept.
+func withPosition(rider domain.Rider, position domain.Rider) domain.Rider {
+	rider.Location = position.Location
+	rider.LocationTimestamp = position.LocationTimestamp
+	rider.LocationSequence = position.LocationSequence
+	rider.Telemetry = position.Telemetry
+	rider.LastSeenAt = laterTime(rider.LastSeenAt, position.LastSeenAt)
+	return rider
+}
+
+func laterTime(a *time.Time, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.After(*a)) {
+		return b
+	}
+
+	return a
+}
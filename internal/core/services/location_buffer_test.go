@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/suite"
+	"rider-service/config"
+	"rider-service/internal/core/domain"
+	"rider-service/internal/core/interfaces"
+	"rider-service/internal/repositories"
+	"testing"
+	"time"
+)
+
+type LocationBufferTestSuite struct {
+	suite.Suite
+	Repository interfaces.RiderRepository
+	Buffer     *locationBuffer
+	Rider      domain.Rider
+}
+
+func (suite *LocationBufferTestSuite) SetupTest() {
+	repository := repositories.NewMemoryRepository()
+	user := domain.User{ID: "test-id", Name: "test-name", LastName: "test-lastname"}
+
+	suite.Require().NoError(repository.SaveOrUpdateUser(context.Background(), user))
+	suite.Require().NoError(repository.SaveOrUpdateServiceArea(domain.ServiceArea{ID: 1, Identifier: "test-area"}))
+
+	rider, err := repository.Save(context.Background(), domain.NewRider(user, 1, 1, domain.Dimensions{Width: 1, Height: 1, Depth: 1}))
+	suite.Require().NoError(err)
+
+	suite.Repository = repository
+	suite.Buffer = newLocationBuffer()
+	suite.Rider = rider
+}
+
+func (suite *LocationBufferTestSuite) TestLocationBuffer_FlushWritesLatestPosition() {
+	suite.Buffer.load(suite.Rider)
+
+	suite.Buffer.update(suite.Rider.UserID, domain.LocationFix{Location: domain.Location{Latitude: 1, Longitude: 1}, Sequence: 1}, time.Now())
+	suite.Buffer.update(suite.Rider.UserID, domain.LocationFix{Location: domain.Location{Latitude: 2, Longitude: 2}, Sequence: 2}, time.Now())
+
+	suite.NoError(suite.Buffer.flush(context.Background(), suite.Repository))
+
+	stored, err := suite.Repository.Get(context.Background(), suite.Rider.UserID)
+
+	suite.NoError(err)
+	suite.EqualValues(domain.Location{Latitude: 2, Longitude: 2}, stored.Location)
+	suite.EqualValues(2, stored.LocationSequence)
+}
+
+func (suite *LocationBufferTestSuite) TestLocationBuffer_FlushEvictsIdleRiders() {
+	suite.Buffer.load(suite.Rider)
+
+	suite.NoError(suite.Buffer.flush(context.Background(), suite.Repository))
+	_, _, cached := suite.Buffer.update(suite.Rider.UserID, domain.LocationFix{Sequence: 1}, time.Now())
+	suite.True(cached, "a rider is kept until it was idle for a whole flush")
+
+	suite.NoError(suite.Buffer.flush(context.Background(), suite.Repository))
+	suite.NoError(suite.Buffer.flush(context.Background(), suite.Repository))
+	suite.NoError(suite.Buffer.flush(context.Background(), suite.Repository))
+	_, _, cached = suite.Buffer.update(suite.Rider.UserID, domain.LocationFix{Sequence: 2}, time.Now())
+	suite.False(cached)
+}
+
+func (suite *LocationBufferTestSuite) TestLocationBuffer_WrittenOlderPositionIsWrittenAgain() {
+	suite.Buffer.load(suite.Rider)
+	suite.Buffer.update(suite.Rider.UserID, domain.LocationFix{Location: domain.Location{Latitude: 2, Longitude: 2}, Sequence: 5}, time.Now())
+	suite.NoError(suite.Buffer.flush(context.Background(), suite.Repository))
+
+	older := domain.LocationFix{Location: domain.Location{Latitude: 1, Longitude: 1}, Sequence: 3}.Apply(suite.Rider)
+	suite.NoError(suite.Repository.UpdateLocations(context.Background(), []domain.Rider{older}))
+	suite.Buffer.written(older)
+
+	suite.NoError(suite.Buffer.flush(context.Background(), suite.Repository))
+
+	stored, err := suite.Repository.Get(context.Background(), suite.Rider.UserID)
+
+	suite.NoError(err)
+	suite.EqualValues(5, stored.LocationSequence)
+}
+
+func (suite *LocationBufferTestSuite) TestLocationBuffer_LastSeenOnlyMovesForward() {
+	seenAt := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	suite.Buffer.load(suite.Rider)
+	suite.Buffer.seen(suite.Rider.UserID, seenAt)
+	rider, _, _ := suite.Buffer.update(suite.Rider.UserID, domain.LocationFix{Sequence: 1}, seenAt.Add(-time.Minute))
+
+	suite.Equal(seenAt, *rider.LastSeenAt)
+
+	suite.NoError(suite.Buffer.flush(context.Background(), suite.Repository))
+
+	stored, err := suite.Repository.Get(context.Background(), suite.Rider.UserID)
+
+	suite.NoError(err)
+	suite.Equal(seenAt, *stored.LastSeenAt)
+}
+
+func (suite *LocationBufferTestSuite) TestLocationBuffer_ExpiredRiderIsNotUsed() {
+	suite.Buffer.load(suite.Rider)
+	suite.Buffer.update(suite.Rider.UserID, domain.LocationFix{Location: domain.Location{Latitude: 2, Longitude: 2}, Sequence: 5}, time.Now())
+	suite.NoError(suite.Buffer.flush(context.Background(), suite.Repository))
+
+	suite.Buffer.riders[suite.Rider.UserID].loadedAt = time.Now().Add(-2 * maxSnapshotAge)
+
+	// Another instance moved the rider since it was cached.
+	moved := domain.LocationFix{Location: domain.Location{Latitude: 3, Longitude: 3}, Sequence: 7}.Apply(suite.Rider)
+	suite.NoError(suite.Repository.UpdateLocations(context.Background(), []domain.Rider{moved}))
+
+	suite.Equal(moved.Location, suite.Buffer.overlay(moved).Location)
+
+	suite.Buffer.refresh(moved)
+	suite.Buffer.written(moved)
+
+	suite.NotContains(suite.Buffer.riders, suite.Rider.UserID, "an expired rider that was written is evicted")
+
+	suite.Buffer.load(moved)
+	rider, cached := suite.Buffer.get(suite.Rider.UserID)
+
+	suite.True(cached)
+	suite.Equal(moved.Location, rider.Location, "the position of the expired rider is not brought back")
+}
+
+func (suite *LocationBufferTestSuite) TestLocationBuffer_ExpiredRiderIsStillFlushed() {
+	suite.Buffer.load(suite.Rider)
+	suite.Buffer.update(suite.Rider.UserID, domain.LocationFix{Location: domain.Location{Latitude: 2, Longitude: 2}, Sequence: 5}, time.Now())
+
+	suite.Buffer.riders[suite.Rider.UserID].loadedAt = time.Now().Add(-2 * maxSnapshotAge)
+
+	suite.Equal(suite.Rider.Location, suite.Buffer.overlay(suite.Rider).Location)
+	suite.NoError(suite.Buffer.flush(context.Background(), suite.Repository))
+
+	stored, err := suite.Repository.Get(context.Background(), suite.Rider.UserID)
+
+	suite.NoError(err)
+	suite.EqualValues(5, stored.LocationSequence, "a position that was not written yet is kept until it is")
+}
+
+func TestUnit_LocationBufferTestSuite(t *testing.T) {
+	suite.Run(t, new(LocationBufferTestSuite))
+}
+
+// databaseRoundTrip is the delay added to every repository call in the benchmarks.
+const databaseRoundTrip = 200 * time.Microsecond
+
+// slowRepository delays the calls on the location path, like a round trip to Postgres would.
+type slowRepository struct {
+	interfaces.RiderRepository
+}
+
+func (repository slowRepository) Get(ctx context.Context, id string) (domain.Rider, error) {
+	time.Sleep(databaseRoundTrip)
+	return repository.RiderRepository.Get(ctx, id)
+}
+
+func (repository slowRepository) Update(ctx context.Context, rider domain.Rider) (domain.Rider, error) {
+	time.Sleep(databaseRoundTrip)
+	return repository.RiderRepository.Update(ctx, rider)
+}
+
+func (repository slowRepository) UpdateLocations(ctx context.Context, riders []domain.Rider) error {
+	time.Sleep(databaseRoundTrip)
+	return repository.RiderRepository.UpdateLocations(ctx, riders)
+}
+
+type discardPublisher struct{}
+
+func (discardPublisher) CreateRider(ctx context.Context, rider domain.Rider) error {
+	return nil
+}
+
+func (discardPublisher) UpdateRider(ctx context.Context, rider domain.Rider, changedFields []string) error {
+	return nil
+}
+
+func (discardPublisher) UpdateRiderLocation(ctx context.Context, serviceArea domain.ServiceArea, id string, newLocation domain.Location, telemetry domain.Telemetry) error {
+	return nil
+}
+
+func (discardPublisher) UpdateRiderLocationBatch(ctx context.Context, serviceArea domain.ServiceArea, id string, fixes []domain.LocationFix) error {
+	return nil
+}
+
+func (discardPublisher) RiderPresenceLost(ctx context.Context, rider domain.Rider) error {
+	return nil
+}
+
+func (discardPublisher) LocationAnomaly(ctx context.Context, anomaly domain.LocationAnomaly) error {
+	return nil
+}
+
+func (discardPublisher) ServiceAreaStats(ctx context.Context, stats domain.ServiceAreaStats) error {
+	return nil
+}
+
+// newBenchmarkRepository returns a repository with 100 riders that are online, and their ids.
+func newBenchmarkRepository(b *testing.B) (interfaces.RiderRepository, []string) {
+	ctx := context.Background()
+	repository := repositories.NewMemoryRepository()
+
+	if err := repository.SaveOrUpdateServiceArea(domain.ServiceArea{ID: 1, Identifier: "area"}); err != nil {
+		b.Fatal(err)
+	}
+
+	riderIds := make([]string, 100)
+
+	for i := range riderIds {
+		riderIds[i] = fmt.Sprintf("rider-%d", i)
+		user := domain.User{ID: riderIds[i], Name: "name", LastName: "lastname"}
+
+		if err := repository.SaveOrUpdateUser(ctx, user); err != nil {
+			b.Fatal(err)
+		}
+
+		if _, err := repository.Save(ctx, domain.NewRider(user, 1, 1, domain.Dimensions{Width: 1, Height: 1, Depth: 1})); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	return slowRepository{repository}, riderIds
+}
+
+// benchmarkFix returns the i-th ping of the benchmarks.
+func benchmarkFix(i int) domain.LocationFix {
+	return domain.LocationFix{
+		Location: domain.Location{Latitude: 51.44, Longitude: 5.47},
+		Sequence: int64(i + 1),
+	}
+}
+
+// benchmarkUpdateLocation sends pings of 100 riders in turn, flushing after every 1000 pings when buffering.
+func benchmarkUpdateLocation(b *testing.B, flushInterval time.Duration) {
+	ctx := context.Background()
+	repository, riderIds := newBenchmarkRepository(b)
+
+	cfg := &config.Config{}
+	cfg.Rider.LocationFlushInterval = flushInterval
+	srv := NewRiderService(repository, discardPublisher{}, cfg)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := srv.UpdateLocation(ctx, riderIds[i%len(riderIds)], benchmarkFix(i)); err != nil {
+			b.Fatal(err)
+		}
+
+		if flushInterval > 0 && i%1000 == 999 {
+			if err := srv.FlushLocations(ctx); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkRiderService_UpdateLocation_WriteThrough sends the pings the way UpdateLocation handled them before
+// locations were buffered: every ping reads the rider with its associations, writes the whole rider and publishes
+// the location.
+func BenchmarkRiderService_UpdateLocation_WriteThrough(b *testing.B) {
+	ctx := context.Background()
+	repository, riderIds := newBenchmarkRepository(b)
+	publisher := discardPublisher{}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		fix := benchmarkFix(i)
+		rider, err := repository.Get(ctx, riderIds[i%len(riderIds)])
+
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if fix.IsStaleFor(rider) {
+			continue
+		}
+
+		if rider, err = repository.Update(ctx, fix.Apply(rider)); err != nil {
+			b.Fatal(err)
+		}
+
+		if err = publisher.UpdateRiderLocation(ctx, rider.ServiceArea, rider.UserID, rider.Location, rider.Telemetry); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkRiderService_UpdateLocation_Unbuffered goes through the location cache, but writes every ping right away.
+func BenchmarkRiderService_UpdateLocation_Unbuffered(b *testing.B) {
+	benchmarkUpdateLocation(b, 0)
+}
+
+func BenchmarkRiderService_UpdateLocation_Buffered(b *testing.B) {
+	benchmarkUpdateLocation(b, time.Second)
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/url"
+	"rider-service/config"
+	"rider-service/internal/core/domain"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// osrmEstimator estimates routes with the table service of an OSRM compatible routing service, which knows the
+// roads but not the traffic. Origins are sent in one request per routing profile.
+type osrmEstimator struct {
+	client *http.Client
+	tracer trace.Tracer
+	config *config.Config
+}
+
+func NewOSRMEstimator(tracerProvider trace.TracerProvider, cfg *config.Config) *osrmEstimator {
+	return &osrmEstimator{
+		client: &http.Client{Timeout: cfg.ETA.OSRM.Timeout},
+		tracer: tracerProvider.Tracer("OSRM.Estimator"),
+		config: cfg,
+	}
+}
+
+// osrmTable is the response of the table service. Durations and distances are null for origins without a route.
+type osrmTable struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Durations [][]*float64 `json:"durations"`
+	Distances [][]*float64 `json:"distances"`
+}
+
+func (estimator *osrmEstimator) EstimateRoutes(ctx context.Context, origins []domain.RouteOrigin, destination domain.Location) ([]domain.Route, error) {
+	byProfile := map[string][]int{}
+
+	for i, origin := range origins {
+		profile := estimator.profile(origin.VehicleType)
+		byProfile[profile] = append(byProfile[profile], i)
+	}
+
+	profiles := make([]string, 0, len(byProfile))
+
+	for profile := range byProfile {
+		profiles = append(profiles, profile)
+	}
+
+	sort.Strings(profiles)
+
+	routes := make([]domain.Route, len(origins))
+
+	for _, profile := range profiles {
+		indexes := byProfile[profile]
+		locations := make([]domain.Location, 0, len(indexes))
+
+		for _, i := range indexes {
+			locations = append(locations, origins[i].Location)
+		}
+
+		profileRoutes, err := estimator.table(ctx, profile, locations, destination)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for j, i := range indexes {
+			routes[i] = profileRoutes[j]
+		}
+	}
+
+	return routes, nil
+}
+
+// profile returns the routing profile of the vehicle type, vehicle types without a profile use the bicycle one.
+func (estimator *osrmEstimator) profile(vehicleType domain.VehicleType) string {
+	for _, vehicle := range []domain.VehicleType{vehicleType, domain.VehicleBicycle} {
+		if profile := estimator.config.ETA.OSRM.Profiles[string(vehicle)]; profile != "" {
+			return profile
+		}
+	}
+
+	return "bike"
+}
+
+// table asks for the routes from the origins to the destination, which is added as the last coordinate.
+func (estimator *osrmEstimator) table(ctx context.Context, profile string, origins []domain.Location, destination domain.Location) ([]domain.Route, error) {
+	ctx, span := estimator.tracer.Start(ctx, "osrm table")
+	defer span.End()
+
+	// The coordinates are not traced, they are rider locations.
+	span.SetAttributes(attribute.String("profile", profile), attribute.Int("origins", len(origins)))
+
+	coordinates := make([]string, 0, len(origins)+1)
+	sources := make([]string, 0, len(origins))
+
+	for i, location := range origins {
+		coordinates = append(coordinates, osrmCoordinate(location))
+		sources = append(sources, strconv.Itoa(i))
+	}
+
+	coordinates = append(coordinates, osrmCoordinate(destination))
+
+	// The separators are sent as they are, like the OSRM documentation does.
+	endpoint := strings.TrimRight(estimator.config.ETA.OSRM.URL, "/") + "/table/v1/" + url.PathEscape(profile) + "/" +
+		strings.Join(coordinates, ";") +
+		"?sources=" + strings.Join(sources, ";") +
+		"&destinations=" + strconv.Itoa(len(origins)) +
+		"&annotations=duration,distance"
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := estimator.client.Do(request)
+
+	if err != nil {
+		return nil, domain.NewUnavailableError(err, "routing service is not available")
+	}
+
+	defer response.Body.Close()
+
+	var table osrmTable
+
+	if err = json.NewDecoder(response.Body).Decode(&table); err != nil {
+		return nil, domain.NewUnavailableError(fmt.Errorf("status %d: %w", response.StatusCode, err), "routing service returned an invalid response")
+	}
+
+	if table.Code != "Ok" || len(table.Durations) != len(origins) || len(table.Distances) != len(origins) {
+		return nil, domain.NewUnavailableError(fmt.Errorf("status %d, code %s: %s", response.StatusCode, table.Code, table.Message), "routing service returned no routes")
+	}
+
+	routes := make([]domain.Route, 0, len(origins))
+
+	for i := range origins {
+		if len(table.Durations[i]) == 0 || len(table.Distances[i]) == 0 || table.Durations[i][0] == nil || table.Distances[i][0] == nil {
+			routes = append(routes, domain.Route{Unreachable: true})
+			continue
+		}
+
+		routes = append(routes, domain.Route{
+			Distance: *table.Distances[i][0],
+			Duration: time.Duration(*table.Durations[i][0] * float64(time.Second)),
+		})
+	}
+
+	return routes, nil
+}
+
+// osrmCoordinate formats the location longitude first, as OSRM expects.
+func osrmCoordinate(location domain.Location) string {
+	return strconv.FormatFloat(location.Longitude, 'f', -1, 64) + "," + strconv.FormatFloat(location.Latitude, 'f', -1, 64)
+}
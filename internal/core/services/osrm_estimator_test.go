@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"net/http"
+	"net/http/httptest"
+	"rider-service/config"
+	"rider-service/internal/core/domain"
+	"testing"
+	"time"
+)
+
+// OSRMEstimatorTestSuite runs the estimator against a stub of the OSRM table service.
+type OSRMEstimatorTestSuite struct {
+	suite.Suite
+	Server   *httptest.Server
+	Requests []*http.Request
+	Response string
+	Status   int
+	Config   *config.Config
+	TestData struct {
+		Destination domain.Location
+		Origins     []domain.RouteOrigin
+	}
+}
+
+func (suite *OSRMEstimatorTestSuite) SetupSuite() {
+	suite.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.Requests = append(suite.Requests, r)
+		w.WriteHeader(suite.Status)
+		_, _ = w.Write([]byte(suite.Response))
+	}))
+
+	suite.TestData.Destination = domain.Location{Latitude: 51.45, Longitude: 5.47}
+	suite.TestData.Origins = []domain.RouteOrigin{
+		{Location: domain.Location{Latitude: 51.44, Longitude: 5.46}, VehicleType: domain.VehicleBicycle},
+		{Location: domain.Location{Latitude: 51.43, Longitude: 5.45}, VehicleType: domain.VehicleCargoBike},
+		{Location: domain.Location{Latitude: 51.42, Longitude: 5.44}, VehicleType: domain.VehicleEBike},
+	}
+}
+
+func (suite *OSRMEstimatorTestSuite) TearDownSuite() {
+	suite.Server.Close()
+}
+
+func (suite *OSRMEstimatorTestSuite) SetupTest() {
+	suite.Requests = nil
+	suite.Status = http.StatusOK
+	suite.Config = &config.Config{ETA: config.ETA{OSRM: config.OSRM{
+		URL:      suite.Server.URL + "/",
+		Profiles: map[string]string{"bicycle": "bike", "cargo-bike": "cargo"},
+		Timeout:  time.Second,
+	}}}
+}
+
+func (suite *OSRMEstimatorTestSuite) estimator() *osrmEstimator {
+	return NewOSRMEstimator(trace.NewTracerProvider(), suite.Config)
+}
+
+func (suite *OSRMEstimatorTestSuite) TestOSRMEstimator_EstimateRoutes() {
+	suite.Response = `{"code": "Ok", "durations": [[120.5], [300]], "distances": [[800], [2000.5]]}`
+	origins := []domain.RouteOrigin{suite.TestData.Origins[0], suite.TestData.Origins[2]}
+
+	routes, err := suite.estimator().EstimateRoutes(context.Background(), origins, suite.TestData.Destination)
+
+	suite.NoError(err)
+	suite.Equal([]domain.Route{
+		{Distance: 800, Duration: 120500 * time.Millisecond},
+		{Distance: 2000.5, Duration: 300 * time.Second},
+	}, routes)
+
+	suite.Require().Len(suite.Requests, 1, "the e-bike has no profile and uses the bicycle one")
+	suite.Equal("/table/v1/bike/5.46,51.44;5.44,51.42;5.47,51.45", suite.Requests[0].URL.Path)
+	suite.Equal("sources=0;1&destinations=2&annotations=duration,distance", suite.Requests[0].URL.RawQuery)
+}
+
+func (suite *OSRMEstimatorTestSuite) TestOSRMEstimator_EstimateRoutes_PerProfile() {
+	suite.Response = `{"code": "Ok", "durations": [[60]], "distances": [[100]]}`
+
+	routes, err := suite.estimator().EstimateRoutes(context.Background(), suite.TestData.Origins[:2], suite.TestData.Destination)
+
+	suite.NoError(err)
+	suite.Len(routes, 2)
+	suite.Require().Len(suite.Requests, 2)
+	suite.Equal("/table/v1/bike/5.46,51.44;5.47,51.45", suite.Requests[0].URL.Path)
+	suite.Equal("/table/v1/cargo/5.45,51.43;5.47,51.45", suite.Requests[1].URL.Path)
+}
+
+func (suite *OSRMEstimatorTestSuite) TestOSRMEstimator_EstimateRoutes_Unreachable() {
+	suite.Response = `{"code": "Ok", "durations": [[null]], "distances": [[null]]}`
+
+	routes, err := suite.estimator().EstimateRoutes(context.Background(), suite.TestData.Origins[:1], suite.TestData.Destination)
+
+	suite.NoError(err)
+	suite.Equal([]domain.Route{{Unreachable: true}}, routes)
+}
+
+func (suite *OSRMEstimatorTestSuite) TestOSRMEstimator_EstimateRoutes_Error() {
+	suite.Status = http.StatusBadRequest
+	suite.Response = `{"code": "NoSegment", "message": "Could not find a matching segment for coordinate 0"}`
+
+	_, err := suite.estimator().EstimateRoutes(context.Background(), suite.TestData.Origins[:1], suite.TestData.Destination)
+
+	suite.ErrorIs(err, domain.ErrUnavailable)
+	suite.ErrorContains(err, "NoSegment")
+}
+
+func (suite *OSRMEstimatorTestSuite) TestOSRMEstimator_EstimateRoutes_Down() {
+	suite.Config.ETA.OSRM.URL = "http://127.0.0.1:1"
+
+	_, err := suite.estimator().EstimateRoutes(context.Background(), suite.TestData.Origins[:1], suite.TestData.Destination)
+
+	suite.ErrorIs(err, domain.ErrUnavailable)
+}
+
+func TestUnit_OSRMEstimatorTestSuite(t *testing.T) {
+	suite.Run(t, new(OSRMEstimatorTestSuite))
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"rider-service/config"
+	"rider-service/internal/core/domain"
+	"rider-service/internal/core/interfaces"
+	"rider-service/pkg/events"
+	"strings"
+	"testing"
+	"time"
+)
+
+const eventSchemaDir = "../../../docs/events"
+
+// topicPublisher is a publisher that names its topics, like the AsyncAPI document does.
+type topicPublisher interface {
+	interfaces.MessageBusPublisher
+	Topic(name string) string
+}
+
+// publishedMessage is a message as a publisher handed it to the broker.
+type publishedMessage struct {
+	topic  string
+	schema string
+	body   []byte
+}
+
+// PublisherContractTestSuite checks that what the publishers send follows the schemas in docs/events,
+// which consumers in other services rely on.
+type PublisherContractTestSuite struct {
+	suite.Suite
+	Schemas map[string]*jsonschema.Schema
+}
+
+func (suite *PublisherContractTestSuite) SetupSuite() {
+	files, err := filepath.Glob(filepath.Join(eventSchemaDir, "*.json"))
+	suite.Require().NoError(err)
+	suite.Require().NotEmpty(files)
+
+	suite.Schemas = map[string]*jsonschema.Schema{}
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat = true
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		suite.Require().NoError(err)
+		suite.Require().NoError(compiler.AddResource(filepath.Base(file), bytes.NewReader(data)))
+
+		schema, err := compiler.Compile(filepath.Base(file))
+		suite.Require().NoError(err, file)
+
+		suite.Schemas[strings.TrimSuffix(filepath.Base(file), ".json")] = schema
+	}
+}
+
+func (suite *PublisherContractTestSuite) rabbitmqPublisher(published *[]publishedMessage) topicPublisher {
+	return &rabbitmqPublisher{
+		tracer: trace.NewTracerProvider().Tracer("test"),
+		config: &config.Config{},
+		publish: func(exchange string, key string, mandatory bool, immediate bool, msg amqp.Publishing) error {
+			*published = append(*published, publishedMessage{topic: key, schema: msg.Type, body: msg.Body})
+			return nil
+		},
+	}
+}
+
+func (suite *PublisherContractTestSuite) azurePublisher(published *[]publishedMessage) topicPublisher {
+	return &azurePublisher{
+		config: &config.Config{},
+		send: func(ctx context.Context, topic string, message *azservicebus.Message) error {
+			schema, _ := message.ApplicationProperties["type"].(string)
+			*published = append(*published, publishedMessage{topic: topic, schema: schema, body: message.Body})
+			return nil
+		},
+	}
+}
+
+// publishAll calls every method of the publisher, with riders and fixes that leave out what they can.
+func (suite *PublisherContractTestSuite) publishAll(publisher interfaces.MessageBusPublisher) {
+	ctx := context.Background()
+	battery := 15
+	speed := 4.5
+	now := time.Now()
+	area := domain.ServiceArea{ID: 1, Identifier: "test-area"}
+
+	rider := domain.NewRider(domain.User{ID: "test-id", Name: "test-name", LastName: "test-lastname"}, domain.StatusDelivering, 1, domain.Dimensions{Width: 10, Height: 20, Depth: 30})
+	rider.ServiceArea = area
+	rider.Location = domain.Location{Latitude: 1, Longitude: 2}
+	rider.Telemetry = domain.Telemetry{Battery: &battery, Speed: &speed}
+	rider.LastSeenAt = &now
+
+	offline := domain.NewRider(domain.User{ID: "offline-id"}, domain.StatusOffline, 1, domain.Dimensions{})
+
+	suite.NoError(publisher.CreateRider(ctx, rider))
+	suite.NoError(publisher.CreateRider(ctx, offline))
+	suite.NoError(publisher.UpdateRider(ctx, rider, []string{"status"}))
+	suite.NoError(publisher.UpdateRider(ctx, offline, nil))
+	suite.NoError(publisher.UpdateRiderLocation(ctx, area, rider.UserID, rider.Location, rider.Telemetry))
+	suite.NoError(publisher.UpdateRiderLocation(ctx, area, rider.UserID, rider.Location, domain.Telemetry{}))
+	suite.NoError(publisher.UpdateRiderLocationBatch(ctx, area, rider.UserID, []domain.LocationFix{
+		{Location: domain.Location{Latitude: 1, Longitude: 2}, Timestamp: now, Sequence: 1},
+		{Location: domain.Location{Latitude: 1.1, Longitude: 2.1}, Telemetry: rider.Telemetry},
+	}))
+	suite.NoError(publisher.RiderPresenceLost(ctx, rider))
+	suite.NoError(publisher.RiderPresenceLost(ctx, offline))
+	suite.NoError(publisher.LocationAnomaly(ctx, domain.LocationAnomaly{
+		RiderID:          rider.UserID,
+		Kind:             domain.AnomalyImpossibleJump,
+		Location:         domain.Location{Latitude: 10, Longitude: 20},
+		PreviousLocation: rider.Location,
+		ImpliedSpeed:     300,
+		Rejected:         true,
+		DetectedAt:       now,
+	}))
+	suite.NoError(publisher.LocationAnomaly(ctx, domain.LocationAnomaly{RiderID: rider.UserID, Kind: domain.AnomalyMockLocation, DetectedAt: now}))
+	suite.NoError(publisher.ServiceAreaStats(ctx, domain.ServiceAreaStats{
+		ServiceArea: area,
+		Riders: domain.RiderStats{
+			ByStatus:        map[int]int{domain.StatusOffline: 2, domain.StatusDelivering: 1},
+			AverageCapacity: domain.AverageDimensions{Width: 10, Height: 20, Depth: 30},
+			StaleLocations:  1,
+		},
+		ComputedAt: now,
+	}))
+	suite.NoError(publisher.ServiceAreaStats(ctx, domain.ServiceAreaStats{ServiceArea: area, ComputedAt: now}))
+}
+
+// documentedTopic returns the topic of events.Topics the message was published to.
+func documentedTopic(publisher topicPublisher, message publishedMessage) (events.Topic, bool) {
+	for _, topic := range events.Topics {
+		pattern := "^" + strings.Replace(regexp.QuoteMeta(publisher.Topic(topic.Name)), `\{serviceArea\}`, `[^.]+`, 1) + "$"
+
+		if regexp.MustCompile(pattern).MatchString(message.topic) {
+			return topic, true
+		}
+	}
+
+	return events.Topic{}, false
+}
+
+// checkContract validates every published message against the schema it names and checks
+// that every method of the publisher and every schema was covered.
+func (suite *PublisherContractTestSuite) checkContract(publisher topicPublisher, published []publishedMessage) {
+	covered := map[string]bool{}
+
+	for _, message := range published {
+		topic, documented := documentedTopic(publisher, message)
+
+		if suite.True(documented, "%s is not in events.Topics, so it is not in the AsyncAPI document", message.topic) {
+			suite.Equal(topic.Event.Schema(), message.schema, "%s is documented with another event", message.topic)
+
+			// The schemas allow added fields for consumers, the publishers only send the fields of the event.
+			decoder := json.NewDecoder(bytes.NewReader(message.body))
+			decoder.DisallowUnknownFields()
+			suite.NoError(decoder.Decode(reflect.New(reflect.TypeOf(topic.Event)).Interface()), "%s sends fields %s does not have", message.topic, message.schema)
+		}
+
+		schema, exists := suite.Schemas[message.schema]
+
+		if !suite.True(exists, "%s was published with schema %q, which is not in docs/events", message.topic, message.schema) {
+			continue
+		}
+
+		covered[message.schema] = true
+
+		var body interface{}
+		suite.Require().NoError(json.Unmarshal(message.body, &body))
+		suite.NoError(schema.Validate(body), "%s does not match %s: %s", message.topic, message.schema, message.body)
+	}
+
+	for name := range suite.Schemas {
+		suite.True(covered[name], "nothing was published with schema %s", name)
+	}
+
+	suite.GreaterOrEqual(len(published), reflect.TypeOf((*interfaces.MessageBusPublisher)(nil)).Elem().NumMethod(),
+		"every method of the publisher is called")
+}
+
+func (suite *PublisherContractTestSuite) TestPublisherContract_RabbitMQ() {
+	var published []publishedMessage
+
+	publisher := suite.rabbitmqPublisher(&published)
+
+	suite.publishAll(publisher)
+	suite.checkContract(publisher, published)
+	suite.Equal("rider.create", published[0].topic)
+	suite.Equal("rider.test-area.update.location", published[4].topic)
+}
+
+func (suite *PublisherContractTestSuite) TestPublisherContract_Azure() {
+	var published []publishedMessage
+
+	publisher := suite.azurePublisher(&published)
+
+	suite.publishAll(publisher)
+	suite.checkContract(publisher, published)
+	suite.Equal("customer.create", published[0].topic)
+	suite.Equal("customer.test-area.update.location", published[4].topic)
+}
+
+func (suite *PublisherContractTestSuite) TestPublisherContract_DetectsMismatch() {
+	schema := suite.Schemas["rider.update.location.v1"]
+
+	var body interface{}
+	suite.Require().NoError(json.Unmarshal([]byte(`{"Id":"test-id","Location":{"Latitude":1,"Longitude":2}}`), &body))
+
+	suite.Error(schema.Validate(body), "the message as it was published before the schemas does not match")
+}
+
+func TestUnit_PublisherContractTestSuite(t *testing.T) {
+	suite.Run(t, new(PublisherContractTestSuite))
+}
@@ -3,12 +3,13 @@ package services
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	amqp "github.com/rabbitmq/amqp091-go"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"rider-service/config"
 	"rider-service/internal/core/domain"
+	"rider-service/pkg/events"
+	"rider-service/pkg/logging"
 	"rider-service/pkg/rabbitmq"
 )
 
@@ -16,31 +17,54 @@ type rabbitmqPublisher struct {
 	rabbitmq *rabbitmq.RabbitMQ
 	tracer   trace.Tracer
 	config   *config.Config
+	// publish is the Publish of the channel, tests replace it to see what is published.
+	publish func(exchange string, key string, mandatory bool, immediate bool, msg amqp.Publishing) error
 }
 
 func NewRabbitMQPublisher(rabbitmq *rabbitmq.RabbitMQ, tracerProvider trace.TracerProvider, cfg *config.Config) *rabbitmqPublisher {
-	return &rabbitmqPublisher{rabbitmq: rabbitmq, tracer: tracerProvider.Tracer("RabbitMQ.Publisher"), config: cfg}
+	return &rabbitmqPublisher{
+		rabbitmq: rabbitmq,
+		tracer:   tracerProvider.Tracer("RabbitMQ.Publisher"),
+		config:   cfg,
+		publish:  rabbitmq.Channel.Publish,
+	}
 }
 
 func (rmq *rabbitmqPublisher) CreateRider(ctx context.Context, rider domain.Rider) error {
-	return rmq.publishJson(ctx, "create", rider)
+	return rmq.publishEvent(ctx, events.TopicRiderCreated, events.RiderCreatedV1{Rider: newRiderEvent(rider)})
+}
+
+func (rmq *rabbitmqPublisher) UpdateRider(ctx context.Context, rider domain.Rider, changedFields []string) error {
+	return rmq.publishEvent(ctx, events.TopicRiderUpdated, newRiderUpdatedEvent(rider, changedFields))
 }
 
-func (rmq *rabbitmqPublisher) UpdateRider(ctx context.Context, rider domain.Rider) error {
-	return rmq.publishJson(ctx, "update", rider)
+func (rmq *rabbitmqPublisher) UpdateRiderLocation(ctx context.Context, serviceArea domain.ServiceArea, id string, newLocation domain.Location, telemetry domain.Telemetry) error {
+	return rmq.publishEvent(ctx, events.LocationTopic(serviceArea.Identifier), newLocationUpdatedEvent(id, newLocation, telemetry))
 }
 
-func (rmq *rabbitmqPublisher) UpdateRiderLocation(ctx context.Context, serviceArea domain.ServiceArea, id string, newLocation domain.Location) error {
-	message := struct {
-		Id       string
-		Location domain.Location
-	}{Id: id, Location: newLocation}
+func (rmq *rabbitmqPublisher) UpdateRiderLocationBatch(ctx context.Context, serviceArea domain.ServiceArea, id string, fixes []domain.LocationFix) error {
+	return rmq.publishEvent(ctx, events.LocationTopic(serviceArea.Identifier), newLocationBatchEvent(id, fixes))
+}
 
-	return rmq.publishJson(ctx, serviceArea.Identifier+".update.location", message)
+func (rmq *rabbitmqPublisher) RiderPresenceLost(ctx context.Context, rider domain.Rider) error {
+	return rmq.publishEvent(ctx, events.TopicRiderPresenceLost, newPresenceLostEvent(rider))
 }
 
-func (rmq *rabbitmqPublisher) publishJson(ctx context.Context, topic string, body interface{}) error {
-	js, err := json.Marshal(body)
+func (rmq *rabbitmqPublisher) LocationAnomaly(ctx context.Context, anomaly domain.LocationAnomaly) error {
+	return rmq.publishEvent(ctx, events.TopicLocationAnomaly, newLocationAnomalyEvent(anomaly))
+}
+
+func (rmq *rabbitmqPublisher) ServiceAreaStats(ctx context.Context, stats domain.ServiceAreaStats) error {
+	return rmq.publishEvent(ctx, events.StatsTopic(stats.ServiceArea.Identifier), newServiceAreaStatsEvent(stats))
+}
+
+// Topic returns the routing key of the topic on RabbitMQ.
+func (rmq *rabbitmqPublisher) Topic(name string) string {
+	return "rider." + name
+}
+
+func (rmq *rabbitmqPublisher) publishEvent(ctx context.Context, topic string, event events.Event) error {
+	js, err := json.Marshal(event)
 
 	if err != nil {
 		return err
@@ -48,21 +72,29 @@ func (rmq *rabbitmqPublisher) publishJson(ctx context.Context, topic string, bod
 
 	_, span := rmq.tracer.Start(ctx, "publish")
 
+	tracedBody := string(js)
+
+	if rmq.config.Privacy.RedactLocations {
+		tracedBody = logging.RedactJSON(js)
+	}
+
 	span.AddEvent(
 		"Published message to rabbitmq",
 		trace.WithAttributes(
 			attribute.String("topic", topic),
-			attribute.String("body", string(js))))
+			attribute.String("schema", event.Schema()),
+			attribute.String("body", tracedBody)))
 	span.End()
 
-	err = rmq.rabbitmq.Channel.Publish(
+	err = rmq.publish(
 		rmq.config.RabbitMQ.Exchange,
-		fmt.Sprintf("rider.%s", topic),
+		rmq.Topic(topic),
 		false,
 		false,
 		amqp.Publishing{
 			DeliveryMode: amqp.Persistent,
 			ContentType:  "text/plain",
+			Type:         event.Schema(),
 			Body:         js,
 		},
 	)
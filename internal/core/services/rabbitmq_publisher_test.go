@@ -10,6 +10,7 @@ import (
 	"rider-service/internal/core/domain"
 	"rider-service/internal/core/interfaces"
 	"rider-service/internal/mock"
+	"rider-service/pkg/events"
 	"rider-service/pkg/rabbitmq"
 	"testing"
 )
@@ -84,6 +85,21 @@ func (suite *RabbitMQPublisherTestSuite) SetupSuite() {
 	}
 }
 
+// expectedRider is the test rider as it is published.
+func (suite *RabbitMQPublisherTestSuite) expectedRider() events.Rider {
+	return events.Rider{
+		UserID:        "test-id",
+		User:          events.User{ID: "test-id", Name: "test-name", LastName: "test-lastname"},
+		Status:        1,
+		ServiceAreaID: 1,
+		ServiceArea:   events.ServiceArea{ID: 1, Identifier: "test-area"},
+		Capacity:      events.Dimensions{Width: 100, Height: 100, Depth: 100},
+		Location:      &events.Location{Latitude: 1, Longitude: 2},
+		// The test rider carries no parcels.
+		RemainingCapacity: events.Dimensions{Width: 100, Height: 100, Depth: 100},
+	}
+}
+
 func (suite *RabbitMQPublisherTestSuite) TestRabbitMQPublisher_CreateRider() {
 	ch, err := suite.TestRabbitMQ.Connection.Channel()
 
@@ -130,13 +146,14 @@ func (suite *RabbitMQPublisherTestSuite) TestRabbitMQPublisher_CreateRider() {
 
 	for msg := range msgs {
 		suite.Equal("rider.create", msg.RoutingKey)
+		suite.Equal("rider.create.v1", msg.Type)
 
-		var rider domain.Rider
+		var event events.RiderCreatedV1
 
-		err = json.Unmarshal(msg.Body, &rider)
+		err = json.Unmarshal(msg.Body, &event)
 		suite.NoError(err)
 
-		suite.Equal(suite.TestData.Rider, rider)
+		suite.Equal(suite.expectedRider(), event.Rider)
 
 		err = msg.Ack(true)
 
@@ -191,19 +208,21 @@ func (suite *RabbitMQPublisherTestSuite) TestRabbitMQPublisher_UpdateRider() {
 
 	suite.NoError(err)
 
-	err = suite.TestPublisher.UpdateRider(context.Background(), suite.TestData.Rider)
+	err = suite.TestPublisher.UpdateRider(context.Background(), suite.TestData.Rider, []string{"status"})
 
 	suite.NoError(err)
 
 	for msg := range msgs {
 		suite.Equal("rider.update", msg.RoutingKey)
+		suite.Equal("rider.update.v1", msg.Type)
 
-		var rider domain.Rider
+		var event events.RiderUpdatedV1
 
-		err = json.Unmarshal(msg.Body, &rider)
+		err = json.Unmarshal(msg.Body, &event)
 		suite.NoError(err)
 
-		suite.Equal(suite.TestData.Rider, rider)
+		suite.Equal(suite.expectedRider(), event.Rider)
+		suite.Equal([]string{"status"}, event.ChangedFields)
 
 		err = msg.Ack(true)
 
@@ -257,23 +276,29 @@ func (suite *RabbitMQPublisherTestSuite) TestRabbitMQPublisher_UpdateRiderLocati
 
 	suite.NoError(err)
 
-	err = suite.TestPublisher.UpdateRiderLocation(context.Background(), suite.TestData.Rider.ServiceArea, suite.TestData.Rider.UserID, suite.TestData.Location)
+	speed := 4.5
+	battery := 15
+
+	err = suite.TestPublisher.UpdateRiderLocation(context.Background(), suite.TestData.Rider.ServiceArea, suite.TestData.Rider.UserID, suite.TestData.Location, domain.Telemetry{Speed: &speed, Battery: &battery})
 
 	suite.NoError(err)
 
 	for msg := range msgs {
 		suite.Equal("rider."+suite.TestData.Rider.ServiceArea.Identifier+".update.location", msg.RoutingKey)
 
-		var message struct {
-			Id       string
-			Location domain.Location
-		}
+		suite.Equal("rider.update.location.v1", msg.Type)
+
+		var message events.RiderLocationUpdatedV1
 
 		err = json.Unmarshal(msg.Body, &message)
 		suite.NoError(err)
 
-		suite.Equal(suite.TestData.Rider.UserID, message.Id)
-		suite.Equal(suite.TestData.Location, message.Location)
+		suite.Equal(suite.TestData.Rider.UserID, message.ID)
+		suite.Equal(events.Location{Latitude: suite.TestData.Location.Latitude, Longitude: suite.TestData.Location.Longitude}, message.Location)
+		suite.Equal(&speed, message.Speed)
+		suite.Nil(message.Heading, "telemetry that was not reported is left out")
+		suite.Equal(&battery, message.Battery)
+		suite.True(message.LowBattery)
 
 		err = msg.Ack(true)
 
@@ -3,24 +3,49 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"rider-service/config"
 	"rider-service/internal/core/domain"
 	"rider-service/internal/core/interfaces"
+	"strings"
+	"time"
 )
 
+// maxClockSkew is how far a client timestamp may be ahead of the server clock.
+const maxClockSkew = time.Minute
+
 type riderService struct {
 	riderRepository  interfaces.RiderRepository
 	messagePublisher interfaces.MessageBusPublisher
+	config           *config.Config
+	locations        *locationBuffer
+	now              func() time.Time
 }
 
-func NewRiderService(riderRepository interfaces.RiderRepository, messagePublisher interfaces.MessageBusPublisher) *riderService {
+func NewRiderService(riderRepository interfaces.RiderRepository, messagePublisher interfaces.MessageBusPublisher, cfg *config.Config) *riderService {
 	return &riderService{
 		riderRepository:  riderRepository,
 		messagePublisher: messagePublisher,
+		config:           cfg,
+		locations:        newLocationBuffer(),
+		now:              time.Now,
 	}
 }
 
-func (srv *riderService) GetAll(ctx context.Context) ([]domain.Rider, error) {
-	return srv.riderRepository.GetAll(ctx)
+func (srv *riderService) GetAll(ctx context.Context, filter domain.RiderFilter) ([]domain.Rider, error) {
+	riders, err := srv.riderRepository.GetAll(ctx, filter)
+
+	for i := range riders {
+		riders[i] = srv.locations.overlay(riders[i])
+	}
+
+	return riders, err
+}
+
+func (srv *riderService) StreamAll(ctx context.Context, filter domain.RiderFilter, fn func(domain.Rider) error) error {
+	return srv.riderRepository.StreamAll(ctx, filter, func(rider domain.Rider) error {
+		return fn(srv.locations.overlay(rider))
+	})
 }
 
 func (srv *riderService) Get(ctx context.Context, id string) (domain.Rider, error) {
@@ -30,85 +55,748 @@ func (srv *riderService) Get(ctx context.Context, id string) (domain.Rider, erro
 		return rider, err
 	}
 
-	if (rider == domain.Rider{}) {
-		return rider, errors.New("could not find rider with id: " + id)
+	if rider.UserID == "" {
+		return rider, domain.NewNotFoundError("rider %s not found", id)
 	}
 
-	return rider, nil
+	return srv.locations.overlay(rider), nil
 }
 
 func (srv *riderService) Create(ctx context.Context, userId string, serviceArea int, capacity domain.Dimensions) (domain.Rider, error) {
-	user, err := srv.riderRepository.GetUser(ctx, userId)
+	rider, err := srv.newRider(ctx, userId, serviceArea, capacity)
 
 	if err != nil {
 		return domain.Rider{}, err
 	}
 
-	rider := domain.NewRider(user, 0, serviceArea, capacity)
-
 	rider, err = srv.riderRepository.Save(ctx, rider)
 
 	if err != nil {
-		return domain.Rider{}, errors.New("saving new rider failed")
+		return domain.Rider{}, err
 	}
 
-	_ = srv.messagePublisher.CreateRider(ctx, rider)
+	_ = srv.messagePublisher.CreateRider(ctx, srv.shared(rider))
 
 	return rider, nil
 }
 
-func (srv *riderService) Update(ctx context.Context, id string, status int, serviceArea int, capacity domain.Dimensions) (domain.Rider, error) {
+// newRider returns a validated, but not yet saved, rider for the user.
+func (srv *riderService) newRider(ctx context.Context, userId string, serviceArea int, capacity domain.Dimensions) (domain.Rider, error) {
+	user, err := srv.riderRepository.GetUser(ctx, userId)
+
+	if err != nil {
+		return domain.Rider{}, err
+	}
+
+	return srv.validateChanges(ctx, domain.Rider{}, domain.NewRider(user, domain.StatusOffline, serviceArea, capacity))
+}
+
+// ImportRiders creates a rider for every row through Create, so every rider is published like one created on its own.
+// Creates are spread out to the configured publish rate, so an import does not flood the message bus. A dry run only
+// validates the rows. A failing row does not stop the import, its result tells why it failed.
+func (srv *riderService) ImportRiders(ctx context.Context, rows []domain.RiderImportRow, commit bool) ([]domain.RiderImportResult, error) {
+	if srv.config.Import.MaxRows > 0 && len(rows) > srv.config.Import.MaxRows {
+		return nil, domain.NewValidationError(domain.FieldError{Field: "rows", Message: fmt.Sprintf("can not be more than %d", srv.config.Import.MaxRows)})
+	}
+
+	var throttle <-chan time.Time
+
+	if commit && srv.config.Import.PublishRate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / srv.config.Import.PublishRate))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	results := make([]domain.RiderImportResult, 0, len(rows))
+	userIds := make(map[string]bool, len(rows))
+	created := 0
+
+	for _, row := range rows {
+		result := domain.RiderImportResult{Line: row.Line, UserID: row.UserID, Status: domain.ImportFailed}
+
+		switch {
+		case len(row.Invalid) > 0:
+			result.Err = domain.NewValidationError(row.Invalid...)
+		case userIds[row.UserID]:
+			result.Err = domain.NewValidationError(domain.FieldError{Field: "id", Message: "is listed more than once"})
+		case !commit:
+			result.Err = srv.validateImport(ctx, row)
+			result.Status = domain.ImportValid
+		default:
+			if created > 0 && throttle != nil {
+				select {
+				case <-ctx.Done():
+					return results, ctx.Err()
+				case <-throttle:
+				}
+			}
+
+			_, result.Err = srv.Create(ctx, row.UserID, row.ServiceAreaID, row.Capacity)
+			result.Status = domain.ImportCreated
+			created++
+		}
+
+		if result.Err != nil {
+			result.Status = domain.ImportFailed
+		}
+
+		userIds[row.UserID] = true
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// validateImport checks a row like Create would, without creating the rider.
+func (srv *riderService) validateImport(ctx context.Context, row domain.RiderImportRow) error {
+	if _, err := srv.newRider(ctx, row.UserID, row.ServiceAreaID, row.Capacity); err != nil {
+		return err
+	}
+
+	_, err := srv.riderRepository.Get(ctx, row.UserID)
+
+	switch {
+	case err == nil:
+		return domain.NewConflictError("rider %s already exists", row.UserID)
+	case errors.Is(err, domain.ErrNotFound):
+		return nil
+	default:
+		return err
+	}
+}
+
+func (srv *riderService) Update(ctx context.Context, id string, status int, serviceArea int, capacity domain.Dimensions, version int) (domain.Rider, error) {
 	rider, err := srv.Get(ctx, id)
 
 	if err != nil {
-		return domain.Rider{}, errors.New("could not find rider with id")
+		return domain.Rider{}, err
+	}
+
+	if version != 0 && rider.Version != version {
+		return domain.Rider{}, domain.ErrVersionConflict
 	}
 
-	rider.ServiceAreaID = serviceArea
-	rider.Status = status
+	updated := rider
+	updated.Status = status
+
+	if serviceArea != 0 {
+		updated.ServiceAreaID = serviceArea
+	}
 
 	if capacity != (domain.Dimensions{}) {
-		rider.Capacity = capacity
+		updated.Capacity = capacity
 	}
 
-	rider, err = srv.riderRepository.Update(ctx, rider)
+	return srv.saveChanges(ctx, rider, updated)
+}
+
+func (srv *riderService) Patch(ctx context.Context, id string, patch domain.RiderPatch, version int) (domain.Rider, error) {
+	if err := patch.Validate(); err != nil {
+		return domain.Rider{}, err
+	}
+
+	rider, err := srv.Get(ctx, id)
 
 	if err != nil {
-		return domain.Rider{}, errors.New("saving new rider failed")
+		return domain.Rider{}, err
 	}
 
-	_ = srv.messagePublisher.UpdateRider(ctx, rider)
+	if version != 0 && rider.Version != version {
+		return domain.Rider{}, domain.ErrVersionConflict
+	}
 
-	return rider, nil
+	return srv.saveChanges(ctx, rider, patch.Apply(rider))
 }
 
-func (srv *riderService) UpdateLocation(ctx context.Context, id string, location domain.Location) (domain.Rider, error) {
-	rider, err := srv.Get(ctx, id)
+// saveChanges stores an updated rider and publishes which of its fields changed.
+func (srv *riderService) saveChanges(ctx context.Context, original domain.Rider, updated domain.Rider) (domain.Rider, error) {
+	changedFields := domain.ChangedFields(original, updated)
+
+	if len(changedFields) == 0 {
+		return original, nil
+	}
+
+	updated, err := srv.validateChanges(ctx, original, updated)
 
 	if err != nil {
-		return domain.Rider{}, errors.New("could not find rider with id")
+		return domain.Rider{}, err
 	}
 
-	rider.Location = location
+	// Going online counts as being seen, so a rider whose app never sends a location is still set offline.
+	if original.Status == domain.StatusOffline && updated.Status != domain.StatusOffline {
+		now := srv.now()
+		updated.LastSeenAt = laterTime(updated.LastSeenAt, &now)
+	}
 
-	rider, err = srv.riderRepository.Update(ctx, rider)
+	rider, err := srv.riderRepository.Update(ctx, updated)
 
 	if err != nil {
-		return domain.Rider{}, errors.New("saving new rider failed")
+		return domain.Rider{}, err
+	}
+
+	srv.locations.refresh(rider)
+
+	_ = srv.messagePublisher.UpdateRider(ctx, srv.shared(rider), changedFields)
+
+	return rider, nil
+}
+
+// validateChanges checks the fields that differ from the original rider, collecting every failing field.
+// A changed service area is loaded, so the returned rider carries the new area.
+func (srv *riderService) validateChanges(ctx context.Context, original domain.Rider, updated domain.Rider) (domain.Rider, error) {
+	var fields []domain.FieldError
+
+	if updated.Status < 0 {
+		fields = append(fields, domain.FieldError{Field: "status", Message: "can not be negative"})
+	}
+
+	if updated.Capacity != original.Capacity {
+		fields = append(fields, updated.Capacity.Validate("capacity", srv.config.Rider.MaxCapacityDimension)...)
+	}
+
+	if updated.Location != original.Location {
+		fields = append(fields, updated.Location.Validate("location")...)
+	}
+
+	if updated.ServiceAreaID != original.ServiceAreaID {
+		serviceArea, err := srv.riderRepository.GetServiceArea(ctx, updated.ServiceAreaID)
+
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			fields = append(fields, domain.FieldError{Field: "serviceArea", Message: "does not exist"})
+		case err != nil:
+			return domain.Rider{}, err
+		case serviceArea.IsDeleted():
+			fields = append(fields, domain.FieldError{Field: "serviceArea", Message: "is deleted"})
+		default:
+			updated.ServiceArea = serviceArea
+		}
+	} else if updated.ServiceArea.IsDeleted() && updated.Status != domain.StatusOffline && updated.Status != original.Status {
+		// Riders that were left in a deleted area are offline, they have to be moved to another area to go online.
+		fields = append(fields, domain.FieldError{Field: "status", Message: "can not be changed while the service area is deleted"})
+	}
+
+	if len(fields) > 0 {
+		return domain.Rider{}, domain.NewValidationError(fields...)
 	}
 
-	err = srv.messagePublisher.UpdateRiderLocation(ctx, rider.ServiceArea, rider.UserID, location)
+	return updated, nil
+}
+
+// UpdateLocation moves a rider to a new fix and records that the rider was seen. Fixes that are older than
+// the last accepted one are dropped and the rider is returned with only its last seen time changed.
+// Implausible fixes are rejected and suspicious ones are reported, see domain.PlausibilityFilter.
+//
+// Riders that send locations are cached, so a fix normally needs no database access at all. The latest position
+// of each rider is written in batches by FlushLocations, or right away when no flush interval is configured.
+// The location is always published right away.
+func (srv *riderService) UpdateLocation(ctx context.Context, id string, fix domain.LocationFix) (domain.Rider, error) {
+	fields := fix.Location.Validate("location")
+	fields = append(fields, fix.Telemetry.Validate("location")...)
+	fields = append(fields, fix.Telemetry.ValidateAccuracy("location", srv.config.Rider.MaxLocationAccuracy)...)
+
+	if fix.Timestamp.After(srv.now().Add(maxClockSkew)) {
+		fields = append(fields, domain.FieldError{Field: "timestamp", Message: "can not be in the future"})
+	}
+
+	if fix.Sequence < 0 {
+		fields = append(fields, domain.FieldError{Field: "sequence", Message: "can not be negative"})
+	}
+
+	if len(fields) > 0 {
+		return domain.Rider{}, domain.NewValidationError(fields...)
+	}
+
+	seenAt := srv.now()
+	current, cached := srv.locations.get(id)
+
+	if !cached {
+		loaded, err := srv.Get(ctx, id)
+
+		if err != nil {
+			return domain.Rider{}, err
+		}
+
+		srv.locations.load(loaded)
+		current = loaded
+	}
+
+	if !fix.IsStaleFor(current) {
+		checked, anomalies, fields := srv.plausibility().Check(current, fix, seenAt)
+
+		srv.reportAnomalies(ctx, anomalies)
+
+		if len(fields) > 0 {
+			return domain.Rider{}, domain.NewValidationError(fields...)
+		}
+
+		fix = checked
+	}
+
+	rider, applied, cached := srv.locations.update(id, fix, seenAt)
+
+	if !cached {
+		// A flush evicted the rider in the meantime.
+		srv.locations.load(current)
+		rider, applied, _ = srv.locations.update(id, fix, seenAt)
+	}
+
+	// A stale fix still shows the rider is there, so the last seen time is written either way.
+	if srv.config.Rider.LocationFlushInterval <= 0 {
+		if err := srv.FlushLocations(ctx); err != nil {
+			return domain.Rider{}, err
+		}
+	}
+
+	precision := srv.privacy().Precision(rider, true)
+
+	if !applied || precision == domain.PrecisionHidden {
+		return rider, nil
+	}
+
+	shared := srv.privacy().Apply(rider, precision)
+	err := srv.messagePublisher.UpdateRiderLocation(ctx, shared.ServiceArea, shared.UserID, shared.Location, shared.Telemetry)
 
 	if err != nil {
-		return rider, err
+		return rider, domain.NewUnavailableError(err, "could not publish location of rider %s", rider.UserID)
+	}
+
+	return rider, nil
+}
+
+func (srv *riderService) UpdateLocationBatch(ctx context.Context, id string, fixes []domain.LocationFix) (domain.Rider, error) {
+	if fields := validateFixes("fixes", fixes, srv.now()); len(fields) > 0 {
+		return domain.Rider{}, domain.NewValidationError(fields...)
+	}
+
+	riders, err := srv.saveLocationBatches(ctx, []domain.LocationBatch{{RiderID: id, Fixes: fixes}})
+
+	if len(riders) == 0 {
+		return domain.Rider{}, err
+	}
+
+	return riders[0], err
+}
+
+func (srv *riderService) UpdateLocationBatches(ctx context.Context, batches []domain.LocationBatch) ([]domain.Rider, error) {
+	var fields []domain.FieldError
+	riderIds := make(map[string]bool, len(batches))
+
+	for i, batch := range batches {
+		if riderIds[batch.RiderID] {
+			fields = append(fields, domain.FieldError{Field: fmt.Sprintf("riders[%d].id", i), Message: "is listed more than once"})
+		}
+
+		riderIds[batch.RiderID] = true
+		fields = append(fields, validateFixes(fmt.Sprintf("riders[%d].fixes", i), batch.Fixes, srv.now())...)
+	}
+
+	if len(fields) > 0 {
+		return nil, domain.NewValidationError(fields...)
+	}
+
+	return srv.saveLocationBatches(ctx, batches)
+}
+
+// saveLocationBatches moves every rider to the newest fresh fix of its batch and stores all fresh fixes in one go.
+// Fixes that are not accurate enough or not plausible count as stale. Every rider is recorded as seen, riders without fresh fixes keep their location.
+// One location event is published per rider that moved, with the precision the rider shares its location at.
+func (srv *riderService) saveLocationBatches(ctx context.Context, batches []domain.LocationBatch) ([]domain.Rider, error) {
+	riders := make([]domain.Rider, len(batches))
+	freshFixes := make([][]domain.LocationFix, len(batches))
+	seenAt := srv.now()
+
+	var history []domain.RiderLocation
+
+	for i, batch := range batches {
+		rider, err := srv.Get(ctx, batch.RiderID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		batch.Fixes = accurateFixes(batch.Fixes, srv.config.Rider.MaxLocationAccuracy)
+		freshFixes[i] = srv.plausibleFixes(ctx, rider, batch.FreshFixes(rider), seenAt)
+
+		for _, fix := range freshFixes[i] {
+			history = append(history, domain.NewRiderLocation(rider.UserID, fix))
+		}
+
+		if len(freshFixes[i]) > 0 {
+			rider = freshFixes[i][len(freshFixes[i])-1].Apply(rider)
+		}
+
+		rider.LastSeenAt = &seenAt
+		riders[i] = rider
+	}
+
+	if err := srv.riderRepository.SaveLocationBatches(ctx, riders, history); err != nil {
+		return nil, err
+	}
+
+	var publishErr error
+
+	for i, rider := range riders {
+		srv.locations.written(rider)
+
+		sharedFixes := srv.privacy().ApplyFixes(freshFixes[i], srv.privacy().Precision(rider, true))
+
+		if len(sharedFixes) == 0 {
+			continue
+		}
+
+		err := srv.messagePublisher.UpdateRiderLocationBatch(ctx, rider.ServiceArea, rider.UserID, sharedFixes)
+
+		if err != nil && publishErr == nil {
+			publishErr = domain.NewUnavailableError(err, "could not publish locations of rider %s", rider.UserID)
+		}
+	}
+
+	return riders, publishErr
+}
+
+// plausibleFixes checks the fixes, oldest first, against the position the rider would have after the fixes before them.
+// Rejected fixes are left out and the anomalies of all fixes are reported.
+func (srv *riderService) plausibleFixes(ctx context.Context, rider domain.Rider, fixes []domain.LocationFix, detectedAt time.Time) []domain.LocationFix {
+	filter := srv.plausibility()
+	plausible := make([]domain.LocationFix, 0, len(fixes))
+
+	var anomalies []domain.LocationAnomaly
+
+	for _, fix := range fixes {
+		checked, fixAnomalies, fields := filter.Check(rider, fix, detectedAt)
+		anomalies = append(anomalies, fixAnomalies...)
+
+		if len(fields) > 0 {
+			continue
+		}
+
+		plausible = append(plausible, checked)
+		rider = checked.Apply(rider)
+	}
+
+	srv.reportAnomalies(ctx, anomalies)
+
+	return plausible
+}
+
+func (srv *riderService) plausibility() domain.PlausibilityFilter {
+	return domain.PlausibilityFilter{
+		MaxSpeed:     srv.config.Plausibility.MaxSpeed,
+		JitterRadius: srv.config.Plausibility.JitterRadius,
+		Bounds:       domain.Bounds(srv.config.Plausibility.Bounds),
+	}
+}
+
+func (srv *riderService) privacy() domain.LocationPrivacy {
+	return domain.LocationPrivacy{GridSize: srv.config.Privacy.CoarseGridSize}
+}
+
+// shared returns the rider as it may be published. Consumers of the message bus are trusted like dispatchers,
+// they see the exact location of riders on a delivery only.
+func (srv *riderService) shared(rider domain.Rider) domain.Rider {
+	privacy := srv.privacy()
+	return privacy.Apply(rider, privacy.Precision(rider, true))
+}
+
+// reportAnomalies stores anomalies for the report and publishes them. Reporting is best effort,
+// it never fails the location update that found the anomalies.
+func (srv *riderService) reportAnomalies(ctx context.Context, anomalies []domain.LocationAnomaly) {
+	if len(anomalies) == 0 {
+		return
+	}
+
+	_ = srv.riderRepository.SaveLocationAnomalies(ctx, anomalies)
+
+	for _, anomaly := range anomalies {
+		_ = srv.messagePublisher.LocationAnomaly(ctx, anomaly)
+	}
+}
+
+// GetLocationAnomalies returns the anomalies that match the filter, newest first.
+func (srv *riderService) GetLocationAnomalies(ctx context.Context, filter domain.LocationAnomalyFilter) ([]domain.LocationAnomaly, error) {
+	return srv.riderRepository.GetLocationAnomalies(ctx, filter)
+}
+
+// accurateFixes leaves out the fixes that are less accurate than maxAccuracy meters.
+func accurateFixes(fixes []domain.LocationFix, maxAccuracy float64) []domain.LocationFix {
+	accurate := make([]domain.LocationFix, 0, len(fixes))
+
+	for _, fix := range fixes {
+		if fix.Telemetry.IsAccurate(maxAccuracy) {
+			accurate = append(accurate, fix)
+		}
+	}
+
+	return accurate
+}
+
+// validateFixes checks fixes the same way UpdateLocation does, reporting them by their index in the field.
+// Inaccurate fixes are not an error in a batch, they are skipped like stale ones.
+func validateFixes(field string, fixes []domain.LocationFix, now time.Time) []domain.FieldError {
+	var fields []domain.FieldError
+
+	if len(fixes) == 0 {
+		return []domain.FieldError{{Field: field, Message: "can not be empty"}}
+	}
+
+	for i, fix := range fixes {
+		fixField := fmt.Sprintf("%s[%d]", field, i)
+
+		fields = append(fields, fix.Location.Validate(fixField)...)
+		fields = append(fields, fix.Telemetry.Validate(fixField)...)
+
+		if fix.Timestamp.After(now.Add(maxClockSkew)) {
+			fields = append(fields, domain.FieldError{Field: fixField + ".timestamp", Message: "can not be in the future"})
+		}
+
+		if fix.Sequence < 0 {
+			fields = append(fields, domain.FieldError{Field: fixField + ".sequence", Message: "can not be negative"})
+		}
+	}
+
+	return fields
+}
+
+// FlushLocations writes the latest positions of riders that moved since the last flush to the repository.
+func (srv *riderService) FlushLocations(ctx context.Context) error {
+	return srv.locations.flush(ctx, srv.riderRepository)
+}
+
+// Heartbeat records that a rider is still there without sending a location.
+func (srv *riderService) Heartbeat(ctx context.Context, id string) (domain.Rider, error) {
+	seenAt := srv.now()
+	rider, cached := srv.locations.seen(id, seenAt)
+
+	if !cached {
+		loaded, err := srv.Get(ctx, id)
+
+		if err != nil {
+			return domain.Rider{}, err
+		}
+
+		srv.locations.load(loaded)
+		rider, _ = srv.locations.seen(id, seenAt)
+	}
+
+	if srv.config.Rider.LocationFlushInterval <= 0 {
+		if err := srv.FlushLocations(ctx); err != nil {
+			return domain.Rider{}, err
+		}
 	}
 
 	return rider, nil
 }
 
+// SetSilentRidersOffline sets riders offline that have not sent a location or heartbeat within the presence timeout
+// of their service area, and publishes that their presence was lost.  Online riders without a last seen time are silent
+// as well. Riders that change while this runs are left
+// alone until the next check.
+func (srv *riderService) SetSilentRidersOffline(ctx context.Context) ([]domain.Rider, error) {
+	// Buffered riders can have been seen after what the repository knows.
+	if err := srv.FlushLocations(ctx); err != nil {
+		return nil, err
+	}
+
+	now := srv.now()
+	candidates, err := srv.riderRepository.GetSilentRiders(ctx, now.Add(-srv.shortestPresenceTimeout()))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var offline []domain.Rider
+
+	for _, rider := range candidates {
+		rider = srv.locations.overlay(rider)
+
+		if rider.LastSeenAt != nil && now.Sub(*rider.LastSeenAt) < srv.presenceTimeout(rider.ServiceArea) {
+			continue
+		}
+
+		updated := rider
+		updated.Status = domain.StatusOffline
+
+		updated, err = srv.riderRepository.Update(ctx, updated)
+
+		if errors.Is(err, domain.ErrVersionConflict) {
+			continue
+		}
+
+		if err != nil {
+			return offline, err
+		}
+
+		srv.locations.refresh(updated)
+
+		_ = srv.messagePublisher.UpdateRider(ctx, srv.shared(updated), domain.ChangedFields(rider, updated))
+		_ = srv.messagePublisher.RiderPresenceLost(ctx, updated)
+
+		offline = append(offline, updated)
+	}
+
+	return offline, nil
+}
+
+// ReassignServiceArea changes the riders through saveChanges, so every change is validated and published like any
+// other update. A rider that fails does not stop the others, the first error is returned once all were tried.
+func (srv *riderService) ReassignServiceArea(ctx context.Context, from int, to int) ([]domain.Rider, error) {
+	riders, err := srv.GetAll(ctx, domain.RiderFilter{ServiceAreaID: from})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []domain.Rider
+	var firstErr error
+
+	for _, rider := range riders {
+		updated := rider
+
+		if to != 0 {
+			updated.ServiceAreaID = to
+		} else {
+			updated.Status = domain.StatusOffline
+		}
+
+		if len(domain.ChangedFields(rider, updated)) == 0 {
+			continue
+		}
+
+		updated, err = srv.saveChanges(ctx, rider, updated)
+
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+
+			continue
+		}
+
+		changed = append(changed, updated)
+	}
+
+	return changed, firstErr
+}
+
+// HandleDeliveryEvent goes through saveChanges, so the rider is only changed when it is still at the version it was
+// read at and the change is published like any other update.
+func (srv *riderService) HandleDeliveryEvent(ctx context.Context, event domain.DeliveryEvent) (domain.Rider, error) {
+	if err := event.Validate(); err != nil {
+		return domain.Rider{}, err
+	}
+
+	rider, err := srv.Get(ctx, event.RiderID)
+
+	if err != nil {
+		return domain.Rider{}, err
+	}
+
+	updated, err := event.Apply(rider)
+
+	if err != nil {
+		return domain.Rider{}, err
+	}
+
+	return srv.saveChanges(ctx, rider, updated)
+}
+
+func (srv *riderService) LoadParcel(ctx context.Context, riderID string, parcel domain.Parcel) (domain.Rider, error) {
+	if err := parcel.Validate(srv.config.Rider.MaxCapacityDimension); err != nil {
+		return domain.Rider{}, err
+	}
+
+	rider, err := srv.Get(ctx, riderID)
+
+	if err != nil {
+		return domain.Rider{}, err
+	}
+
+	parcels := rider.Parcels
+
+	if i := parcels.Find(parcel.ID); i >= 0 {
+		if parcels[i].DeliveryID != "" {
+			return domain.Rider{}, domain.NewConflictError("parcel %s belongs to delivery %s", parcel.ID, parcels[i].DeliveryID)
+		}
+
+		parcels = parcels.Without(i)
+	}
+
+	if !parcel.Size.FitsIn(parcels.RemainingIn(rider.Capacity)) {
+		return domain.Rider{}, domain.NewConflictError("parcel %s does not fit in the remaining capacity of rider %s", parcel.ID, riderID)
+	}
+
+	parcel.DeliveryID = ""
+	parcel.PickedUp = true
+
+	updated := rider
+	updated.Parcels = append(append(domain.Parcels(nil), parcels...), parcel)
+
+	return srv.saveChanges(ctx, rider, updated)
+}
+
+func (srv *riderService) UnloadParcel(ctx context.Context, riderID string, parcelID string) (domain.Rider, error) {
+	rider, err := srv.Get(ctx, riderID)
+
+	if err != nil {
+		return domain.Rider{}, err
+	}
+
+	i := rider.Parcels.Find(parcelID)
+
+	if i < 0 {
+		return domain.Rider{}, domain.NewNotFoundError("rider %s does not carry parcel %s", riderID, parcelID)
+	}
+
+	if rider.Parcels[i].DeliveryID != "" {
+		return domain.Rider{}, domain.NewConflictError("parcel %s belongs to delivery %s, it is unloaded when the delivery is completed or cancelled", parcelID, rider.Parcels[i].DeliveryID)
+	}
+
+	updated := rider
+	updated.Parcels = rider.Parcels.Without(i)
+
+	return srv.saveChanges(ctx, rider, updated)
+}
+
+// presenceTimeout returns how long riders in the service area can stay silent before they are set offline.
+func (srv *riderService) presenceTimeout(serviceArea domain.ServiceArea) time.Duration {
+	// Viper lower cases map keys, so the identifier is looked up in lower case as well.
+	if timeout, exists := srv.config.Presence.AreaTimeouts[strings.ToLower(serviceArea.Identifier)]; exists {
+		return timeout
+	}
+
+	return srv.config.Presence.Timeout
+}
+
+func (srv *riderService) shortestPresenceTimeout() time.Duration {
+	shortest := srv.config.Presence.Timeout
+
+	for _, timeout := range srv.config.Presence.AreaTimeouts {
+		if timeout < shortest {
+			shortest = timeout
+		}
+	}
+
+	return shortest
+}
+
 func (srv *riderService) SaveOrUpdateUser(ctx context.Context, user domain.User) error {
-	if user.Name == "" || user.LastName == "" || user.ID == "" {
-		return errors.New("incomplete user data")
+	var fields []domain.FieldError
+
+	if user.ID == "" {
+		fields = append(fields, domain.FieldError{Field: "id", Message: "is required"})
+	}
+
+	if user.Name == "" {
+		fields = append(fields, domain.FieldError{Field: "name", Message: "is required"})
+	}
+
+	if user.LastName == "" {
+		fields = append(fields, domain.FieldError{Field: "lastName", Message: "is required"})
+	}
+
+	if len(fields) > 0 {
+		return domain.NewValidationError(fields...)
 	}
 
 	err := srv.riderRepository.SaveOrUpdateUser(ctx, user)
@@ -5,10 +5,12 @@ import (
 	"github.com/pkg/errors"
 	mock2 "github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"rider-service/config"
 	"rider-service/internal/core/domain"
 	"rider-service/internal/core/interfaces"
 	"rider-service/internal/mock"
 	"testing"
+	"time"
 )
 
 type RiderServiceTestSuite struct {
@@ -16,7 +18,10 @@ type RiderServiceTestSuite struct {
 	MockRepository *mock.RiderRepository
 	MockPublisher  *mock.MessageBusPublisher
 	TestService    interfaces.RiderService
-	TestData       struct {
+	Cfg            *config.Config
+	// Now is the time the services of the suite see riders at.
+	Now      time.Time
+	TestData struct {
 		Rider    domain.Rider
 		Location domain.Location
 	}
@@ -26,11 +31,16 @@ func (suite *RiderServiceTestSuite) SetupSuite() {
 	repository := new(mock.RiderRepository)
 	publisher := new(mock.MessageBusPublisher)
 
-	srv := NewRiderService(repository, publisher)
+	cfg, err := config.UseConfig("../../../test/rider.config")
+
+	if err != nil {
+		panic(errors.WithStack(err))
+	}
 
 	suite.MockRepository = repository
 	suite.MockPublisher = publisher
-	suite.TestService = srv
+	suite.Cfg = cfg
+	suite.Now = time.Date(2022, 5, 1, 13, 0, 0, 0, time.UTC)
 	suite.TestData = struct {
 		Rider    domain.Rider
 		Location domain.Location
@@ -42,7 +52,8 @@ func (suite *RiderServiceTestSuite) SetupSuite() {
 				Name:     "test-name",
 				LastName: "test-lastname",
 			},
-			Status:        1,
+			// On a delivery, so events carry the exact location.
+			Status:        domain.StatusDelivering,
 			ServiceAreaID: 1,
 			ServiceArea: domain.ServiceArea{
 				ID:         1,
@@ -57,6 +68,7 @@ func (suite *RiderServiceTestSuite) SetupSuite() {
 				Latitude:  1,
 				Longitude: 2,
 			},
+			Version: 1,
 		},
 		Location: domain.Location{
 			Latitude:  2,
@@ -67,17 +79,36 @@ func (suite *RiderServiceTestSuite) SetupSuite() {
 
 func (suite *RiderServiceTestSuite) SetupTest() {
 	suite.MockPublisher.ExpectedCalls = nil
+	suite.MockPublisher.Calls = nil
 	suite.MockRepository.ExpectedCalls = nil
+	suite.MockRepository.Calls = nil
+
+	// Every test gets a new service, so no locations stay buffered between tests.
+	suite.TestService = suite.newService(suite.Cfg)
+}
+
+// newService creates a rider service on the suite's mocks with a clock that stands still at Now.
+func (suite *RiderServiceTestSuite) newService(cfg *config.Config) *riderService {
+	srv := NewRiderService(suite.MockRepository, suite.MockPublisher, cfg)
+	srv.now = func() time.Time { return suite.Now }
+	return srv
+}
+
+// seen returns the rider with the suite's Now as the time it was last seen.
+func (suite *RiderServiceTestSuite) seen(rider domain.Rider) domain.Rider {
+	seenAt := suite.Now
+	rider.LastSeenAt = &seenAt
+	return rider
 }
 
 func (suite *RiderServiceTestSuite) TestRiderService_GetAll() {
-	suite.MockRepository.On("GetAll").Return([]domain.Rider{suite.TestData.Rider}, nil)
+	suite.MockRepository.On("GetAll", domain.RiderFilter{}).Return([]domain.Rider{suite.TestData.Rider}, nil)
 
-	result, err := suite.TestService.GetAll(context.Background())
+	result, err := suite.TestService.GetAll(context.Background(), domain.RiderFilter{})
 
 	suite.NoError(err)
 
-	suite.MockRepository.AssertCalled(suite.T(), "GetAll")
+	suite.MockRepository.AssertCalled(suite.T(), "GetAll", domain.RiderFilter{})
 	suite.Equal(1, len(result))
 	suite.EqualValues(suite.TestData.Rider, result[0])
 }
@@ -106,6 +137,7 @@ func (suite *RiderServiceTestSuite) TestRiderService_Get_NotFound() {
 
 func (suite *RiderServiceTestSuite) TestRiderService_Create() {
 	suite.MockRepository.On("GetUser", suite.TestData.Rider.UserID).Return(suite.TestData.Rider.User, nil)
+	suite.MockRepository.On("GetServiceArea", suite.TestData.Rider.ServiceAreaID).Return(suite.TestData.Rider.ServiceArea, nil)
 	suite.MockRepository.On("Save", mock2.Anything).Return(suite.TestData.Rider, nil)
 	suite.MockPublisher.On("CreateRider", suite.TestData.Rider).Return(nil)
 
@@ -117,6 +149,80 @@ func (suite *RiderServiceTestSuite) TestRiderService_Create() {
 	suite.EqualValues(suite.TestData.Rider, result)
 }
 
+func (suite *RiderServiceTestSuite) TestRiderService_ImportRiders_DryRun() {
+	capacity := suite.TestData.Rider.Capacity
+	rows := []domain.RiderImportRow{
+		{Line: 2, UserID: "new-id", ServiceAreaID: 1, Capacity: capacity},
+		{Line: 3, UserID: suite.TestData.Rider.UserID, ServiceAreaID: 1, Capacity: capacity},
+		{Line: 4, UserID: "new-id", ServiceAreaID: 1, Capacity: capacity},
+		{Line: 5, Invalid: []domain.FieldError{{Field: "id", Message: "is required"}}},
+	}
+
+	suite.MockRepository.On("GetUser", "new-id").Return(domain.User{ID: "new-id"}, nil)
+	suite.MockRepository.On("GetUser", suite.TestData.Rider.UserID).Return(suite.TestData.Rider.User, nil)
+	suite.MockRepository.On("GetServiceArea", 1).Return(suite.TestData.Rider.ServiceArea, nil)
+	suite.MockRepository.On("Get", "new-id").Return(domain.Rider{}, domain.NewNotFoundError("rider new-id not found"))
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
+
+	results, err := suite.TestService.ImportRiders(context.Background(), rows, false)
+
+	suite.NoError(err)
+	suite.Require().Len(results, 4)
+
+	suite.Equal(domain.ImportValid, results[0].Status)
+	suite.NoError(results[0].Err)
+
+	suite.Equal(domain.ImportFailed, results[1].Status)
+	suite.ErrorIs(results[1].Err, domain.ErrConflict, "riders that exist already can not be imported")
+
+	suite.Equal(domain.ImportFailed, results[2].Status)
+	suite.ErrorIs(results[2].Err, domain.ErrValidation, "a rider can only be imported once")
+
+	suite.Equal(domain.ImportFailed, results[3].Status)
+	suite.ErrorIs(results[3].Err, domain.ErrValidation)
+
+	suite.MockRepository.AssertNotCalled(suite.T(), "Save", mock2.Anything)
+	suite.MockPublisher.AssertNotCalled(suite.T(), "CreateRider", mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_ImportRiders_Commit() {
+	cfg := *suite.Cfg
+	cfg.Import.PublishRate = 50
+	srv := suite.newService(&cfg)
+
+	rows := []domain.RiderImportRow{
+		{Line: 2, UserID: suite.TestData.Rider.UserID, ServiceAreaID: 1, Capacity: suite.TestData.Rider.Capacity},
+		{Line: 3, UserID: suite.TestData.Rider.UserID, ServiceAreaID: 1, Capacity: suite.TestData.Rider.Capacity},
+		{Line: 4, UserID: "other-id", ServiceAreaID: 1, Capacity: suite.TestData.Rider.Capacity},
+	}
+
+	suite.MockRepository.On("GetUser", mock2.Anything).Return(suite.TestData.Rider.User, nil)
+	suite.MockRepository.On("GetServiceArea", 1).Return(suite.TestData.Rider.ServiceArea, nil)
+	suite.MockRepository.On("Save", mock2.Anything).Return(suite.TestData.Rider, nil)
+	suite.MockPublisher.On("CreateRider", mock2.Anything).Return(nil)
+
+	started := time.Now()
+	results, err := srv.ImportRiders(context.Background(), rows, true)
+
+	suite.NoError(err)
+	suite.Require().Len(results, 3)
+	suite.Equal(domain.ImportCreated, results[0].Status)
+	suite.Equal(domain.ImportFailed, results[1].Status)
+	suite.Equal(domain.ImportCreated, results[2].Status)
+
+	suite.MockPublisher.AssertNumberOfCalls(suite.T(), "CreateRider", 2)
+	suite.GreaterOrEqual(time.Since(started), 20*time.Millisecond, "the second create waits for the publish rate")
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_ImportRiders_TooManyRows() {
+	rows := make([]domain.RiderImportRow, suite.Cfg.Import.MaxRows+1)
+
+	_, err := suite.TestService.ImportRiders(context.Background(), rows, true)
+
+	suite.ErrorIs(err, domain.ErrValidation)
+	suite.MockRepository.AssertNotCalled(suite.T(), "Save", mock2.Anything)
+}
+
 func (suite *RiderServiceTestSuite) TestRiderService_Create_UserNotFound() {
 	suite.MockRepository.On("GetUser", suite.TestData.Rider.UserID).Return(domain.User{}, errors.New("user not found"))
 
@@ -126,8 +232,28 @@ func (suite *RiderServiceTestSuite) TestRiderService_Create_UserNotFound() {
 	suite.Error(err)
 }
 
+func (suite *RiderServiceTestSuite) TestRiderService_Create_Invalid() {
+	suite.MockRepository.On("GetUser", suite.TestData.Rider.UserID).Return(suite.TestData.Rider.User, nil)
+	suite.MockRepository.On("GetServiceArea", 99).Return(domain.ServiceArea{}, domain.NewNotFoundError("service area 99 not found"))
+
+	_, err := suite.TestService.Create(context.Background(), suite.TestData.Rider.UserID, 99, domain.Dimensions{Width: -1, Height: 1000, Depth: 10})
+
+	suite.ErrorIs(err, domain.ErrValidation)
+
+	var domainErr *domain.Error
+	suite.ErrorAs(err, &domainErr)
+	suite.ElementsMatch([]domain.FieldError{
+		{Field: "capacity.width", Message: "must be positive"},
+		{Field: "capacity.height", Message: "can not be larger than 200"},
+		{Field: "serviceArea", Message: "does not exist"},
+	}, domainErr.Fields)
+
+	suite.MockRepository.AssertNotCalled(suite.T(), "Save", mock2.Anything)
+}
+
 func (suite *RiderServiceTestSuite) TestRiderService_Create_CouldNotSave() {
 	suite.MockRepository.On("GetUser", suite.TestData.Rider.UserID).Return(suite.TestData.Rider.User, nil)
+	suite.MockRepository.On("GetServiceArea", suite.TestData.Rider.ServiceAreaID).Return(suite.TestData.Rider.ServiceArea, nil)
 	suite.MockRepository.On("Save", mock2.Anything).Return(domain.Rider{}, errors.New("could not save rider"))
 	suite.MockPublisher.On("CreateRider", suite.TestData.Rider).Return(nil)
 
@@ -144,32 +270,951 @@ func (suite *RiderServiceTestSuite) TestRiderService_Update() {
 
 	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
 	suite.MockRepository.On("Update", updated).Return(updated, nil)
-	suite.MockPublisher.On("UpdateRider", updated).Return(nil)
+	suite.MockPublisher.On("UpdateRider", updated, []string{"capacity"}).Return(nil)
 
-	result, err := suite.TestService.Update(context.Background(), suite.TestData.Rider.UserID, suite.TestData.Rider.Status, suite.TestData.Rider.ServiceAreaID, updated.Capacity)
+	result, err := suite.TestService.Update(context.Background(), suite.TestData.Rider.UserID, suite.TestData.Rider.Status, suite.TestData.Rider.ServiceAreaID, updated.Capacity, suite.TestData.Rider.Version)
 
 	suite.NoError(err)
 
-	suite.MockPublisher.AssertCalled(suite.T(), "UpdateRider", updated)
+	suite.MockPublisher.AssertCalled(suite.T(), "UpdateRider", updated, []string{"capacity"})
 	suite.EqualValues(updated, result)
 }
 
-func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocation() {
+func (suite *RiderServiceTestSuite) TestRiderService_Patch() {
+	status := 0
+	width := 20
 	updated := suite.TestData.Rider
-	updated.Location = suite.TestData.Location
+	updated.Status = status
+	updated.Capacity.Width = width
+
+	// An offline rider does not share its location.
+	published := updated
+	published.Location = domain.Location{}
+
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
+	suite.MockRepository.On("Update", updated).Return(updated, nil)
+	suite.MockPublisher.On("UpdateRider", published, []string{"status", "capacity"}).Return(nil)
+
+	result, err := suite.TestService.Patch(context.Background(), suite.TestData.Rider.UserID, domain.RiderPatch{
+		Status:   &status,
+		Capacity: &domain.DimensionsPatch{Width: &width},
+	}, 0)
+
+	suite.NoError(err)
+
+	suite.MockPublisher.AssertCalled(suite.T(), "UpdateRider", published, []string{"status", "capacity"})
+	suite.EqualValues(updated, result)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_Patch_GoingOnlineCountsAsSeen() {
+	status := domain.StatusAvailable
+
+	offline := suite.TestData.Rider
+	offline.Status = domain.StatusOffline
+
+	updated := offline
+	updated.Status = status
+	updated.LastSeenAt = &suite.Now
+
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(offline, nil)
+	suite.MockRepository.On("Update", updated).Return(updated, nil)
+	suite.MockPublisher.On("UpdateRider", mock2.Anything, []string{"status"}).Return(nil)
+
+	result, err := suite.TestService.Patch(context.Background(), suite.TestData.Rider.UserID, domain.RiderPatch{Status: &status}, 0)
+
+	suite.NoError(err)
+	suite.Require().NotNil(result.LastSeenAt)
+	suite.True(suite.Now.Equal(*result.LastSeenAt), "a rider whose app never sends a location is still set offline")
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_Patch_NoChanges() {
+	status := suite.TestData.Rider.Status
+
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
+
+	result, err := suite.TestService.Patch(context.Background(), suite.TestData.Rider.UserID, domain.RiderPatch{Status: &status}, 0)
+
+	suite.NoError(err)
+
+	suite.MockRepository.AssertNotCalled(suite.T(), "Update", mock2.Anything)
+	suite.MockPublisher.AssertNotCalled(suite.T(), "UpdateRider", mock2.Anything, mock2.Anything)
+	suite.EqualValues(suite.TestData.Rider, result)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_Patch_Invalid() {
+	depth := -1
+
+	_, err := suite.TestService.Patch(context.Background(), suite.TestData.Rider.UserID, domain.RiderPatch{
+		Capacity: &domain.DimensionsPatch{Depth: &depth},
+	}, 0)
+
+	suite.ErrorIs(err, domain.ErrValidation)
+
+	suite.MockRepository.AssertNotCalled(suite.T(), "Get", mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_Patch_VehicleType() {
+	vehicleType := domain.VehicleCargoBike
+	updated := suite.TestData.Rider
+	updated.VehicleType = vehicleType
 
 	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
 	suite.MockRepository.On("Update", updated).Return(updated, nil)
-	suite.MockPublisher.On("UpdateRiderLocation", updated.ServiceArea, updated.UserID, updated.Location).Return(nil)
+	suite.MockPublisher.On("UpdateRider", updated, []string{"vehicleType"}).Return(nil)
+
+	result, err := suite.TestService.Patch(context.Background(), suite.TestData.Rider.UserID, domain.RiderPatch{VehicleType: &vehicleType}, 0)
+
+	suite.NoError(err)
+	suite.Equal(domain.VehicleCargoBike, result.VehicleType)
+
+	unknown := domain.VehicleType("scooter")
+	_, err = suite.TestService.Patch(context.Background(), suite.TestData.Rider.UserID, domain.RiderPatch{VehicleType: &unknown}, 0)
+
+	suite.ErrorIs(err, domain.ErrValidation)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_Update_StaleVersion() {
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
+
+	_, err := suite.TestService.Update(context.Background(), suite.TestData.Rider.UserID, suite.TestData.Rider.Status, suite.TestData.Rider.ServiceAreaID, suite.TestData.Rider.Capacity, suite.TestData.Rider.Version+1)
+
+	suite.ErrorIs(err, domain.ErrVersionConflict)
+
+	suite.MockRepository.AssertNotCalled(suite.T(), "Update", mock2.Anything)
+	suite.MockPublisher.AssertNotCalled(suite.T(), "UpdateRider", mock2.Anything, mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_Update_ConcurrentModification() {
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
+	suite.MockRepository.On("Update", mock2.Anything).Return(domain.Rider{}, domain.ErrVersionConflict)
+
+	_, err := suite.TestService.Update(context.Background(), suite.TestData.Rider.UserID, 2, suite.TestData.Rider.ServiceAreaID, suite.TestData.Rider.Capacity, 0)
+
+	suite.ErrorIs(err, domain.ErrVersionConflict)
+
+	suite.MockPublisher.AssertNotCalled(suite.T(), "UpdateRider", mock2.Anything, mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocation() {
+	updated := suite.seen(suite.TestData.Rider)
+	updated.Location = suite.TestData.Location
+
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
+	suite.MockRepository.On("UpdateLocations", []domain.Rider{updated}).Return(nil)
+	suite.MockPublisher.On("UpdateRiderLocation", updated.ServiceArea, updated.UserID, updated.Location, updated.Telemetry).Return(nil)
+
+	result, err := suite.TestService.UpdateLocation(context.Background(), suite.TestData.Rider.UserID, domain.LocationFix{Location: updated.Location})
+
+	suite.NoError(err)
+
+	suite.MockRepository.AssertCalled(suite.T(), "UpdateLocations", []domain.Rider{updated})
+
+	suite.MockPublisher.AssertCalled(suite.T(), "UpdateRiderLocation", updated.ServiceArea, updated.UserID, updated.Location, updated.Telemetry)
+	suite.EqualValues(updated, result)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocation_PublishesCoarseLocation() {
+	available := suite.TestData.Rider
+	available.Status = domain.StatusAvailable
+
+	fix := domain.LocationFix{Location: domain.Location{Latitude: 2.0012, Longitude: 3.0087}}
+	speed := 4.2
+	fix.Telemetry.Speed = &speed
+
+	updated := fix.Apply(suite.seen(available))
+
+	suite.MockRepository.On("Get", available.UserID).Return(available, nil)
+	suite.MockRepository.On("UpdateLocations", []domain.Rider{updated}).Return(nil)
+	suite.MockPublisher.On("UpdateRiderLocation", updated.ServiceArea, updated.UserID, domain.Location{Latitude: 2.005, Longitude: 3.005}, domain.Telemetry{}).Return(nil)
+
+	result, err := suite.TestService.UpdateLocation(context.Background(), available.UserID, fix)
+
+	suite.NoError(err)
+	suite.EqualValues(updated, result, "the rider itself keeps the exact location")
+
+	suite.MockPublisher.AssertCalled(suite.T(), "UpdateRiderLocation", updated.ServiceArea, updated.UserID, domain.Location{Latitude: 2.005, Longitude: 3.005}, domain.Telemetry{})
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocation_OfflineIsNotPublished() {
+	offline := suite.TestData.Rider
+	offline.Status = domain.StatusOffline
+
+	updated := suite.seen(offline)
+	updated.Location = suite.TestData.Location
+
+	suite.MockRepository.On("Get", offline.UserID).Return(offline, nil)
+	suite.MockRepository.On("UpdateLocations", []domain.Rider{updated}).Return(nil)
+
+	result, err := suite.TestService.UpdateLocation(context.Background(), offline.UserID, domain.LocationFix{Location: suite.TestData.Location})
+
+	suite.NoError(err)
+	suite.EqualValues(updated, result)
+
+	suite.MockPublisher.AssertNotCalled(suite.T(), "UpdateRiderLocation", mock2.Anything, mock2.Anything, mock2.Anything, mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocation_OutOfRange() {
+	_, err := suite.TestService.UpdateLocation(context.Background(), suite.TestData.Rider.UserID, domain.LocationFix{Location: domain.Location{Latitude: 500, Longitude: 2}})
+
+	suite.ErrorIs(err, domain.ErrValidation)
+
+	suite.MockRepository.AssertNotCalled(suite.T(), "UpdateLocations", mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocation_Sequence() {
+	fix := domain.LocationFix{Location: suite.TestData.Location, Sequence: 8}
+
+	updated := suite.seen(suite.TestData.Rider)
+	updated.Location = suite.TestData.Location
+	updated.LocationSequence = 8
+
+	current := suite.TestData.Rider
+	current.LocationSequence = 7
+
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(current, nil)
+	suite.MockRepository.On("UpdateLocations", []domain.Rider{updated}).Return(nil)
+	suite.MockPublisher.On("UpdateRiderLocation", updated.ServiceArea, updated.UserID, updated.Location, updated.Telemetry).Return(nil)
+
+	result, err := suite.TestService.UpdateLocation(context.Background(), suite.TestData.Rider.UserID, fix)
+
+	suite.NoError(err)
+	suite.EqualValues(updated, result)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocation_StaleSequence() {
+	current := suite.TestData.Rider
+	current.LocationSequence = 8
+
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(current, nil)
+	suite.MockRepository.On("UpdateLocations", []domain.Rider{suite.seen(current)}).Return(nil)
+
+	result, err := suite.TestService.UpdateLocation(context.Background(), suite.TestData.Rider.UserID, domain.LocationFix{Location: suite.TestData.Location, Sequence: 8})
+
+	suite.NoError(err)
+	suite.EqualValues(suite.seen(current), result, "a stale fix only records that the rider was seen")
+
+	suite.MockRepository.AssertCalled(suite.T(), "UpdateLocations", []domain.Rider{suite.seen(current)})
+	suite.MockPublisher.AssertNotCalled(suite.T(), "UpdateRiderLocation", mock2.Anything, mock2.Anything, mock2.Anything, mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocation_StaleTimestamp() {
+	last := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	current := suite.TestData.Rider
+	current.LocationTimestamp = &last
+
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(current, nil)
+	suite.MockRepository.On("UpdateLocations", []domain.Rider{suite.seen(current)}).Return(nil)
+
+	result, err := suite.TestService.UpdateLocation(context.Background(), suite.TestData.Rider.UserID, domain.LocationFix{Location: suite.TestData.Location, Timestamp: last.Add(-time.Second)})
+
+	suite.NoError(err)
+	suite.EqualValues(suite.seen(current), result)
+
+	suite.MockPublisher.AssertNotCalled(suite.T(), "UpdateRiderLocation", mock2.Anything, mock2.Anything, mock2.Anything, mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocation_FutureTimestamp() {
+	_, err := suite.TestService.UpdateLocation(context.Background(), suite.TestData.Rider.UserID, domain.LocationFix{Location: suite.TestData.Location, Timestamp: suite.Now.Add(time.Hour)})
+
+	suite.ErrorIs(err, domain.ErrValidation)
+
+	suite.MockRepository.AssertNotCalled(suite.T(), "Get", mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocation_Buffered() {
+	cfg := *suite.Cfg
+	cfg.Rider.LocationFlushInterval = time.Second
+	srv := suite.newService(&cfg)
 
-	result, err := suite.TestService.UpdateLocation(context.Background(), suite.TestData.Rider.UserID, updated.Location)
+	first := domain.LocationFix{Location: domain.Location{Latitude: 1.5, Longitude: 2.5}, Sequence: 1}
+	second := domain.LocationFix{Location: suite.TestData.Location, Sequence: 2}
+
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil).Once()
+	suite.MockRepository.On("UpdateLocations", []domain.Rider{second.Apply(suite.seen(suite.TestData.Rider))}).Return(nil)
+	suite.MockPublisher.On("UpdateRiderLocation", suite.TestData.Rider.ServiceArea, suite.TestData.Rider.UserID, mock2.Anything, mock2.Anything).Return(nil)
+
+	_, err := srv.UpdateLocation(context.Background(), suite.TestData.Rider.UserID, first)
+	suite.NoError(err)
+
+	_, err = srv.UpdateLocation(context.Background(), suite.TestData.Rider.UserID, second)
+	suite.NoError(err)
+
+	suite.MockRepository.AssertNumberOfCalls(suite.T(), "Get", 1)
+	suite.MockRepository.AssertNotCalled(suite.T(), "UpdateLocations", mock2.Anything)
+	suite.MockPublisher.AssertNumberOfCalls(suite.T(), "UpdateRiderLocation", 2)
+
+	suite.NoError(srv.FlushLocations(context.Background()))
+
+	suite.MockRepository.AssertNumberOfCalls(suite.T(), "UpdateLocations", 1)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocation_BufferedFlushFailure() {
+	cfg := *suite.Cfg
+	cfg.Rider.LocationFlushInterval = time.Second
+	srv := suite.newService(&cfg)
+
+	fix := domain.LocationFix{Location: suite.TestData.Location, Sequence: 1}
+
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
+	suite.MockRepository.On("UpdateLocations", mock2.Anything).Return(domain.NewUnavailableError(errors.New("connection refused"), "could not write")).Once()
+	suite.MockRepository.On("UpdateLocations", []domain.Rider{fix.Apply(suite.seen(suite.TestData.Rider))}).Return(nil).Once()
+	suite.MockPublisher.On("UpdateRiderLocation", mock2.Anything, mock2.Anything, mock2.Anything, mock2.Anything).Return(nil)
+
+	_, err := srv.UpdateLocation(context.Background(), suite.TestData.Rider.UserID, fix)
+	suite.NoError(err)
+
+	suite.ErrorIs(srv.FlushLocations(context.Background()), domain.ErrUnavailable)
+	suite.NoError(srv.FlushLocations(context.Background()), "the position is written again after a failed flush")
+
+	suite.MockRepository.AssertNumberOfCalls(suite.T(), "UpdateLocations", 2)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_Get_BufferedLocation() {
+	cfg := *suite.Cfg
+	cfg.Rider.LocationFlushInterval = time.Second
+	srv := suite.newService(&cfg)
+
+	fix := domain.LocationFix{Location: suite.TestData.Location, Sequence: 1}
+
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
+	suite.MockPublisher.On("UpdateRiderLocation", mock2.Anything, mock2.Anything, mock2.Anything, mock2.Anything).Return(nil)
+
+	_, err := srv.UpdateLocation(context.Background(), suite.TestData.Rider.UserID, fix)
+	suite.NoError(err)
+
+	result, err := srv.Get(context.Background(), suite.TestData.Rider.UserID)
+
+	suite.NoError(err)
+	suite.EqualValues(fix.Apply(suite.seen(suite.TestData.Rider)), result)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocation_Telemetry() {
+	speed := 4.5
+	battery := 15
+
+	current := suite.TestData.Rider
+	current.Telemetry.Battery = &battery
+
+	fix := domain.LocationFix{Location: suite.TestData.Location, Telemetry: domain.Telemetry{Speed: &speed}}
+
+	updated := suite.seen(current)
+	updated.Location = suite.TestData.Location
+	updated.Telemetry = domain.Telemetry{Speed: &speed, Battery: &battery}
+
+	suite.MockRepository.On("Get", current.UserID).Return(current, nil)
+	suite.MockRepository.On("UpdateLocations", []domain.Rider{updated}).Return(nil)
+	suite.MockPublisher.On("UpdateRiderLocation", updated.ServiceArea, updated.UserID, updated.Location, updated.Telemetry).Return(nil)
+
+	result, err := suite.TestService.UpdateLocation(context.Background(), current.UserID, fix)
 
 	suite.NoError(err)
+	suite.EqualValues(updated, result, "the battery level is kept when a fix does not report it")
+	suite.True(result.Telemetry.HasLowBattery())
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocation_Inaccurate() {
+	accuracy := suite.Cfg.Rider.MaxLocationAccuracy + 1
+	heading := 360.0
+
+	_, err := suite.TestService.UpdateLocation(context.Background(), suite.TestData.Rider.UserID, domain.LocationFix{
+		Location:  suite.TestData.Location,
+		Telemetry: domain.Telemetry{Accuracy: &accuracy, Heading: &heading},
+	})
+
+	var domainErr *domain.Error
+	suite.ErrorAs(err, &domainErr)
+	suite.ElementsMatch([]domain.FieldError{
+		{Field: "location.heading", Message: "must be at least 0 and less than 360"},
+		{Field: "location.accuracy", Message: "must be at most 100 meters"},
+	}, domainErr.Fields)
+
+	suite.MockRepository.AssertNotCalled(suite.T(), "Get", mock2.Anything)
+}
 
-	suite.MockPublisher.AssertCalled(suite.T(), "UpdateRiderLocation", updated.ServiceArea, updated.UserID, updated.Location)
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocationBatch_SkipsInaccurate() {
+	accurate := 5.0
+	inaccurate := suite.Cfg.Rider.MaxLocationAccuracy * 2
+
+	good := domain.LocationFix{Location: domain.Location{Latitude: 1.5, Longitude: 2.5}, Sequence: 1, Telemetry: domain.Telemetry{Accuracy: &accurate}}
+	bad := domain.LocationFix{Location: domain.Location{Latitude: 9, Longitude: 9}, Sequence: 2, Telemetry: domain.Telemetry{Accuracy: &inaccurate}}
+
+	updated := good.Apply(suite.seen(suite.TestData.Rider))
+
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
+	suite.MockRepository.On("SaveLocationBatches", []domain.Rider{updated}, []domain.RiderLocation{domain.NewRiderLocation(updated.UserID, good)}).Return(nil)
+	suite.MockPublisher.On("UpdateRiderLocationBatch", updated.ServiceArea, updated.UserID, []domain.LocationFix{good}).Return(nil)
+
+	result, err := suite.TestService.UpdateLocationBatch(context.Background(), suite.TestData.Rider.UserID, []domain.LocationFix{good, bad})
+
+	suite.NoError(err)
 	suite.EqualValues(updated, result)
 }
 
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocation_ImpossibleJump() {
+	last := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	current := suite.TestData.Rider
+	current.LocationTimestamp = &last
+
+	jump := domain.LocationFix{Location: domain.Location{Latitude: 40, Longitude: 2}, Timestamp: last.Add(time.Minute)}
+
+	suite.MockRepository.On("Get", current.UserID).Return(current, nil)
+	suite.MockRepository.On("SaveLocationAnomalies", mock2.Anything).Return(nil)
+	suite.MockPublisher.On("LocationAnomaly", mock2.Anything).Return(nil)
+
+	_, err := suite.TestService.UpdateLocation(context.Background(), current.UserID, jump)
+
+	suite.ErrorIs(err, domain.ErrValidation)
+
+	suite.MockRepository.AssertNotCalled(suite.T(), "UpdateLocations", mock2.Anything)
+	suite.MockPublisher.AssertNotCalled(suite.T(), "UpdateRiderLocation", mock2.Anything, mock2.Anything, mock2.Anything, mock2.Anything)
+
+	anomalies := suite.MockRepository.Calls[1].Arguments.Get(0).([]domain.LocationAnomaly)
+	suite.Require().Len(anomalies, 1)
+	suite.Equal(domain.AnomalyImpossibleJump, anomalies[0].Kind)
+	suite.Equal(current.Location, anomalies[0].PreviousLocation)
+	suite.True(anomalies[0].Rejected)
+	suite.Greater(anomalies[0].ImpliedSpeed, suite.Cfg.Plausibility.MaxSpeed)
+
+	suite.MockPublisher.AssertCalled(suite.T(), "LocationAnomaly", anomalies[0])
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocation_ImpossibleJumpWithoutTimestamps() {
+	seen := suite.Now.Add(-time.Minute)
+
+	current := suite.TestData.Rider
+	current.LocationTimestamp = nil
+	current.LastSeenAt = &seen
+
+	jump := domain.LocationFix{Location: domain.Location{Latitude: 40, Longitude: 2}}
+
+	suite.MockRepository.On("Get", current.UserID).Return(current, nil)
+	suite.MockRepository.On("SaveLocationAnomalies", mock2.Anything).Return(nil)
+	suite.MockPublisher.On("LocationAnomaly", mock2.Anything).Return(nil)
+
+	_, err := suite.TestService.UpdateLocation(context.Background(), current.UserID, jump)
+
+	suite.ErrorIs(err, domain.ErrValidation, "the speed is taken from when the rider was last seen")
+
+	suite.MockRepository.AssertNotCalled(suite.T(), "UpdateLocations", mock2.Anything)
+	suite.MockPublisher.AssertCalled(suite.T(), "LocationAnomaly", mock2.MatchedBy(func(anomaly domain.LocationAnomaly) bool {
+		return anomaly.Kind == domain.AnomalyImpossibleJump && anomaly.Rejected
+	}))
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocation_JumpWithoutTimestampsAfterSilence() {
+	seen := suite.Now.Add(-24 * time.Hour)
+
+	current := suite.TestData.Rider
+	current.LocationTimestamp = nil
+	current.LastSeenAt = &seen
+
+	jump := domain.LocationFix{Location: suite.TestData.Location}
+
+	suite.MockRepository.On("Get", current.UserID).Return(current, nil)
+	suite.MockRepository.On("UpdateLocations", mock2.Anything).Return(nil)
+	suite.MockPublisher.On("UpdateRiderLocation", mock2.Anything, mock2.Anything, mock2.Anything, mock2.Anything).Return(nil)
+
+	result, err := suite.TestService.UpdateLocation(context.Background(), current.UserID, jump)
+
+	suite.NoError(err, "a rider that was gone for a day can be anywhere nearby")
+	suite.Equal(jump.Location, result.Location)
+
+	suite.MockRepository.AssertNotCalled(suite.T(), "SaveLocationAnomalies", mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocation_NullIsland() {
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
+
+	_, err := suite.TestService.UpdateLocation(context.Background(), suite.TestData.Rider.UserID, domain.LocationFix{})
+
+	suite.ErrorIs(err, domain.ErrValidation)
+
+	suite.MockRepository.AssertNotCalled(suite.T(), "UpdateLocations", mock2.Anything)
+	suite.MockRepository.AssertNotCalled(suite.T(), "SaveLocationAnomalies", mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocation_Jitter() {
+	nearby := domain.Location{Latitude: suite.TestData.Rider.Location.Latitude + 0.00001, Longitude: suite.TestData.Rider.Location.Longitude}
+	speed := 0.5
+
+	updated := suite.seen(suite.TestData.Rider)
+	updated.Telemetry.Speed = &speed
+
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
+	suite.MockRepository.On("UpdateLocations", []domain.Rider{updated}).Return(nil)
+	suite.MockPublisher.On("UpdateRiderLocation", updated.ServiceArea, updated.UserID, updated.Location, updated.Telemetry).Return(nil)
+
+	result, err := suite.TestService.UpdateLocation(context.Background(), suite.TestData.Rider.UserID, domain.LocationFix{Location: nearby, Telemetry: domain.Telemetry{Speed: &speed}})
+
+	suite.NoError(err)
+	suite.EqualValues(updated, result, "a fix about a meter away does not move the rider")
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocation_Mocked() {
+	updated := suite.seen(suite.TestData.Rider)
+	updated.Location = suite.TestData.Location
+
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
+	suite.MockRepository.On("SaveLocationAnomalies", mock2.Anything).Return(nil)
+	suite.MockRepository.On("UpdateLocations", []domain.Rider{updated}).Return(nil)
+	suite.MockPublisher.On("LocationAnomaly", mock2.Anything).Return(nil)
+	suite.MockPublisher.On("UpdateRiderLocation", mock2.Anything, mock2.Anything, mock2.Anything, mock2.Anything).Return(nil)
+
+	result, err := suite.TestService.UpdateLocation(context.Background(), suite.TestData.Rider.UserID, domain.LocationFix{Location: suite.TestData.Location, Mocked: true})
+
+	suite.NoError(err)
+	suite.EqualValues(updated, result, "mocked fixes are reported but still accepted")
+
+	suite.MockPublisher.AssertCalled(suite.T(), "LocationAnomaly", mock2.MatchedBy(func(anomaly domain.LocationAnomaly) bool {
+		return anomaly.Kind == domain.AnomalyMockLocation && !anomaly.Rejected
+	}))
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocationBatch_SkipsImplausible() {
+	last := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	current := suite.TestData.Rider
+	current.LocationTimestamp = &last
+
+	jump := domain.LocationFix{Location: domain.Location{Latitude: 40, Longitude: 2}, Timestamp: last.Add(time.Minute)}
+	back := domain.LocationFix{Location: domain.Location{Latitude: 1.001, Longitude: 2}, Timestamp: last.Add(2 * time.Minute)}
+
+	updated := back.Apply(suite.seen(current))
+
+	suite.MockRepository.On("Get", current.UserID).Return(current, nil)
+	suite.MockRepository.On("SaveLocationAnomalies", mock2.Anything).Return(nil)
+	suite.MockRepository.On("SaveLocationBatches", []domain.Rider{updated}, []domain.RiderLocation{domain.NewRiderLocation(current.UserID, back)}).Return(nil)
+	suite.MockPublisher.On("LocationAnomaly", mock2.Anything).Return(nil)
+	suite.MockPublisher.On("UpdateRiderLocationBatch", current.ServiceArea, current.UserID, []domain.LocationFix{back}).Return(nil)
+
+	result, err := suite.TestService.UpdateLocationBatch(context.Background(), current.UserID, []domain.LocationFix{jump, back})
+
+	suite.NoError(err)
+	suite.EqualValues(updated, result, "the fix after a jump is checked against the last accepted one")
+
+	suite.MockPublisher.AssertNumberOfCalls(suite.T(), "LocationAnomaly", 1)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocationBatch() {
+	last := time.Date(2022, 5, 1, 11, 0, 0, 0, time.UTC)
+
+	current := suite.TestData.Rider
+	current.LocationTimestamp = &last
+
+	stale := domain.LocationFix{Location: domain.Location{Latitude: 9, Longitude: 9}, Timestamp: last.Add(-time.Minute)}
+	older := domain.LocationFix{Location: domain.Location{Latitude: 1.5, Longitude: 2.5}, Timestamp: last.Add(time.Hour)}
+	newest := domain.LocationFix{Location: suite.TestData.Location, Timestamp: last.Add(2 * time.Hour)}
+
+	updated := newest.Apply(suite.seen(current))
+
+	history := []domain.RiderLocation{
+		domain.NewRiderLocation(current.UserID, older),
+		domain.NewRiderLocation(current.UserID, newest),
+	}
+
+	suite.MockRepository.On("Get", current.UserID).Return(current, nil)
+	suite.MockRepository.On("SaveLocationBatches", []domain.Rider{updated}, history).Return(nil)
+	suite.MockPublisher.On("UpdateRiderLocationBatch", current.ServiceArea, current.UserID, []domain.LocationFix{older, newest}).Return(nil)
+
+	result, err := suite.TestService.UpdateLocationBatch(context.Background(), current.UserID, []domain.LocationFix{newest, stale, older})
+
+	suite.NoError(err)
+	suite.EqualValues(updated, result)
+
+	suite.MockPublisher.AssertNumberOfCalls(suite.T(), "UpdateRiderLocationBatch", 1)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocationBatch_AllStale() {
+	current := suite.TestData.Rider
+	current.LocationSequence = 10
+
+	suite.MockRepository.On("Get", current.UserID).Return(current, nil)
+	suite.MockRepository.On("SaveLocationBatches", []domain.Rider{suite.seen(current)}, []domain.RiderLocation(nil)).Return(nil)
+
+	result, err := suite.TestService.UpdateLocationBatch(context.Background(), current.UserID, []domain.LocationFix{
+		{Location: suite.TestData.Location, Sequence: 9},
+		{Location: suite.TestData.Location, Sequence: 10},
+	})
+
+	suite.NoError(err)
+	suite.EqualValues(suite.seen(current), result, "the rider is still recorded as seen")
+
+	suite.MockRepository.AssertCalled(suite.T(), "SaveLocationBatches", []domain.Rider{suite.seen(current)}, []domain.RiderLocation(nil))
+	suite.MockPublisher.AssertNotCalled(suite.T(), "UpdateRiderLocationBatch", mock2.Anything, mock2.Anything, mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocationBatch_Invalid() {
+	_, err := suite.TestService.UpdateLocationBatch(context.Background(), suite.TestData.Rider.UserID, []domain.LocationFix{
+		{Location: suite.TestData.Location},
+		{Location: domain.Location{Latitude: 500, Longitude: 2}, Sequence: -1},
+		{Location: suite.TestData.Location, Timestamp: suite.Now.Add(time.Hour)},
+	})
+
+	var domainErr *domain.Error
+	suite.ErrorAs(err, &domainErr)
+	suite.ElementsMatch([]domain.FieldError{
+		{Field: "fixes[1].latitude", Message: "must be between -90 and 90"},
+		{Field: "fixes[1].sequence", Message: "can not be negative"},
+		{Field: "fixes[2].timestamp", Message: "can not be in the future"},
+	}, domainErr.Fields)
+
+	suite.MockRepository.AssertNotCalled(suite.T(), "Get", mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocationBatches() {
+	other := suite.TestData.Rider
+	other.UserID = "other-id"
+	other.User.ID = "other-id"
+
+	fix := domain.LocationFix{Location: suite.TestData.Location, Sequence: 1}
+
+	updated := []domain.Rider{fix.Apply(suite.seen(suite.TestData.Rider)), fix.Apply(suite.seen(other))}
+
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
+	suite.MockRepository.On("Get", other.UserID).Return(other, nil)
+	suite.MockRepository.On("SaveLocationBatches", updated, mock2.Anything).Return(nil)
+	suite.MockPublisher.On("UpdateRiderLocationBatch", mock2.Anything, mock2.Anything, []domain.LocationFix{fix}).Return(nil)
+
+	result, err := suite.TestService.UpdateLocationBatches(context.Background(), []domain.LocationBatch{
+		{RiderID: suite.TestData.Rider.UserID, Fixes: []domain.LocationFix{fix}},
+		{RiderID: other.UserID, Fixes: []domain.LocationFix{fix}},
+	})
+
+	suite.NoError(err)
+	suite.EqualValues(updated, result)
+
+	suite.MockRepository.AssertNumberOfCalls(suite.T(), "SaveLocationBatches", 1)
+	suite.MockPublisher.AssertNumberOfCalls(suite.T(), "UpdateRiderLocationBatch", 2)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocationBatches_DuplicateRider() {
+	fixes := []domain.LocationFix{{Location: suite.TestData.Location}}
+
+	_, err := suite.TestService.UpdateLocationBatches(context.Background(), []domain.LocationBatch{
+		{RiderID: suite.TestData.Rider.UserID, Fixes: fixes},
+		{RiderID: suite.TestData.Rider.UserID, Fixes: fixes},
+	})
+
+	suite.ErrorIs(err, domain.ErrValidation)
+
+	suite.MockRepository.AssertNotCalled(suite.T(), "Get", mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UpdateLocationBatches_UnknownRider() {
+	suite.MockRepository.On("Get", "unknown").Return(domain.Rider{}, domain.NewNotFoundError("rider unknown not found"))
+
+	_, err := suite.TestService.UpdateLocationBatches(context.Background(), []domain.LocationBatch{
+		{RiderID: "unknown", Fixes: []domain.LocationFix{{Location: suite.TestData.Location}}},
+	})
+
+	suite.ErrorIs(err, domain.ErrNotFound)
+
+	suite.MockRepository.AssertNotCalled(suite.T(), "SaveLocationBatches", mock2.Anything, mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_Heartbeat() {
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
+	suite.MockRepository.On("UpdateLocations", []domain.Rider{suite.seen(suite.TestData.Rider)}).Return(nil)
+
+	result, err := suite.TestService.Heartbeat(context.Background(), suite.TestData.Rider.UserID)
+
+	suite.NoError(err)
+	suite.EqualValues(suite.seen(suite.TestData.Rider), result)
+
+	suite.MockRepository.AssertCalled(suite.T(), "UpdateLocations", []domain.Rider{suite.seen(suite.TestData.Rider)})
+	suite.MockPublisher.AssertNotCalled(suite.T(), "UpdateRiderLocation", mock2.Anything, mock2.Anything, mock2.Anything, mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_Heartbeat_NotFound() {
+	suite.MockRepository.On("Get", "unknown").Return(domain.Rider{}, domain.NewNotFoundError("rider unknown not found"))
+
+	_, err := suite.TestService.Heartbeat(context.Background(), "unknown")
+
+	suite.ErrorIs(err, domain.ErrNotFound)
+
+	suite.MockRepository.AssertNotCalled(suite.T(), "UpdateLocations", mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_SetSilentRidersOffline() {
+	cfg := *suite.Cfg
+	cfg.Presence.Timeout = 5 * time.Minute
+	cfg.Presence.AreaTimeouts = map[string]time.Duration{"fast-area": time.Minute}
+	srv := suite.newService(&cfg)
+
+	tenMinutesAgo := suite.Now.Add(-10 * time.Minute)
+	twoMinutesAgo := suite.Now.Add(-2 * time.Minute)
+
+	silent := suite.TestData.Rider
+	silent.LastSeenAt = &tenMinutesAgo
+
+	fast := suite.TestData.Rider
+	fast.UserID = "fast-id"
+	fast.ServiceArea = domain.ServiceArea{ID: 2, Identifier: "Fast-Area"}
+	fast.ServiceAreaID = 2
+	fast.LastSeenAt = &twoMinutesAgo
+
+	recent := suite.TestData.Rider
+	recent.UserID = "recent-id"
+	recent.LastSeenAt = &twoMinutesAgo
+
+	neverSeen := suite.TestData.Rider
+	neverSeen.UserID = "never-seen-id"
+
+	var expected []domain.Rider
+
+	for _, rider := range []domain.Rider{silent, fast, neverSeen} {
+		offline := rider
+		offline.Status = domain.StatusOffline
+
+		saved := offline
+		saved.Version++
+
+		published := saved
+		published.Location = domain.Location{}
+
+		suite.MockRepository.On("Update", offline).Return(saved, nil)
+		suite.MockPublisher.On("UpdateRider", published, []string{"status"}).Return(nil)
+		suite.MockPublisher.On("RiderPresenceLost", saved).Return(nil)
+
+		expected = append(expected, saved)
+	}
+
+	suite.MockRepository.On("GetSilentRiders", suite.Now.Add(-time.Minute)).Return([]domain.Rider{silent, fast, recent, neverSeen}, nil)
+
+	result, err := srv.SetSilentRidersOffline(context.Background())
+
+	suite.NoError(err)
+	suite.EqualValues(expected, result, "the rider in the default area seen two minutes ago stays online")
+
+	suite.MockRepository.AssertNumberOfCalls(suite.T(), "Update", 3)
+	suite.MockPublisher.AssertNumberOfCalls(suite.T(), "RiderPresenceLost", 3)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_SetSilentRidersOffline_ConcurrentModification() {
+	tenMinutesAgo := suite.Now.Add(-10 * time.Minute)
+
+	silent := suite.TestData.Rider
+	silent.LastSeenAt = &tenMinutesAgo
+
+	suite.MockRepository.On("GetSilentRiders", mock2.Anything).Return([]domain.Rider{silent}, nil)
+	suite.MockRepository.On("Update", mock2.Anything).Return(domain.Rider{}, domain.ErrVersionConflict)
+
+	result, err := suite.TestService.(*riderService).SetSilentRidersOffline(context.Background())
+
+	suite.NoError(err)
+	suite.Empty(result, "riders that changed are checked again next time")
+
+	suite.MockPublisher.AssertNotCalled(suite.T(), "RiderPresenceLost", mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_SaveOrUpdateUser_Incomplete() {
+	err := suite.TestService.SaveOrUpdateUser(context.Background(), domain.User{ID: "test-id"})
+
+	suite.ErrorIs(err, domain.ErrValidation)
+
+	var domainErr *domain.Error
+	suite.ErrorAs(err, &domainErr)
+	suite.Len(domainErr.Fields, 2)
+
+	suite.MockRepository.AssertNotCalled(suite.T(), "SaveOrUpdateUser", mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_Update_DeletedServiceArea() {
+	deletedAt := suite.Now
+	deleted := domain.ServiceArea{ID: 2, Identifier: "deleted-area", DeletedAt: &deletedAt}
+
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
+	suite.MockRepository.On("GetServiceArea", deleted.ID).Return(deleted, nil)
+
+	_, err := suite.TestService.Update(context.Background(), suite.TestData.Rider.UserID, suite.TestData.Rider.Status, deleted.ID, domain.Dimensions{}, 0)
+
+	suite.ErrorIs(err, domain.ErrValidation)
+	suite.Contains(err.Error(), "serviceArea is deleted")
+	suite.MockRepository.AssertNotCalled(suite.T(), "Update", mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_Update_OnlineInDeletedServiceArea() {
+	deletedAt := suite.Now
+	rider := suite.TestData.Rider
+	rider.Status = domain.StatusOffline
+	rider.ServiceArea.DeletedAt = &deletedAt
+
+	suite.MockRepository.On("Get", rider.UserID).Return(rider, nil)
+
+	_, err := suite.TestService.Update(context.Background(), rider.UserID, domain.StatusAvailable, 0, domain.Dimensions{}, 0)
+
+	suite.ErrorIs(err, domain.ErrValidation)
+	suite.MockRepository.AssertNotCalled(suite.T(), "Update", mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_ReassignServiceArea() {
+	area := domain.ServiceArea{ID: 2, Identifier: "other-area"}
+	moved := suite.TestData.Rider
+	moved.ServiceAreaID = area.ID
+	moved.ServiceArea = area
+
+	suite.MockRepository.On("GetAll", domain.RiderFilter{ServiceAreaID: 1}).Return([]domain.Rider{suite.TestData.Rider}, nil)
+	suite.MockRepository.On("GetServiceArea", area.ID).Return(area, nil)
+	suite.MockRepository.On("Update", moved).Return(moved, nil)
+	suite.MockPublisher.On("UpdateRider", moved, []string{"serviceArea"}).Return(nil)
+
+	result, err := suite.TestService.ReassignServiceArea(context.Background(), 1, area.ID)
+
+	suite.NoError(err)
+	suite.Equal([]domain.Rider{moved}, result)
+	suite.MockPublisher.AssertCalled(suite.T(), "UpdateRider", moved, []string{"serviceArea"})
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_ReassignServiceArea_SetsOffline() {
+	offline := suite.TestData.Rider
+	offline.UserID = "offline-id"
+	offline.Status = domain.StatusOffline
+
+	flagged := suite.TestData.Rider
+	flagged.Status = domain.StatusOffline
+
+	// An offline rider does not share its location.
+	published := flagged
+	published.Location = domain.Location{}
+
+	suite.MockRepository.On("GetAll", domain.RiderFilter{ServiceAreaID: 1}).Return([]domain.Rider{suite.TestData.Rider, offline}, nil)
+	suite.MockRepository.On("Update", flagged).Return(flagged, nil)
+	suite.MockPublisher.On("UpdateRider", published, []string{"status"}).Return(nil)
+
+	result, err := suite.TestService.ReassignServiceArea(context.Background(), 1, 0)
+
+	suite.NoError(err)
+	suite.Equal([]domain.Rider{flagged}, result, "riders that are offline already do not change")
+	suite.MockRepository.AssertNumberOfCalls(suite.T(), "Update", 1)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_ReassignServiceArea_ContinuesAfterError() {
+	other := suite.TestData.Rider
+	other.UserID = "other-id"
+
+	failed := suite.TestData.Rider
+	failed.Status = domain.StatusOffline
+
+	moved := other
+	moved.Status = domain.StatusOffline
+
+	suite.MockRepository.On("GetAll", domain.RiderFilter{ServiceAreaID: 1}).Return([]domain.Rider{suite.TestData.Rider, other}, nil)
+	suite.MockRepository.On("Update", failed).Return(domain.Rider{}, domain.ErrVersionConflict)
+	suite.MockRepository.On("Update", moved).Return(moved, nil)
+	suite.MockPublisher.On("UpdateRider", mock2.Anything, []string{"status"}).Return(nil)
+
+	result, err := suite.TestService.ReassignServiceArea(context.Background(), 1, 0)
+
+	suite.ErrorIs(err, domain.ErrVersionConflict)
+	suite.Equal([]domain.Rider{moved}, result)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_HandleDeliveryEvent() {
+	available := suite.TestData.Rider
+	available.Status = domain.StatusAvailable
+
+	assigned := available
+	assigned.Status = domain.StatusAssigned
+	assigned.DeliveryID = "delivery-id"
+	assigned.Parcels = domain.Parcels{{ID: "delivery-id", DeliveryID: "delivery-id", Size: domain.Dimensions{Width: 10, Height: 20, Depth: 30}, Weight: 2.5}}
+
+	suite.MockRepository.On("Get", available.UserID).Return(available, nil)
+	suite.MockRepository.On("Update", assigned).Return(assigned, nil)
+	suite.MockPublisher.On("UpdateRider", assigned, []string{"status", "deliveryId", "parcels"}).Return(nil)
+
+	result, err := suite.TestService.HandleDeliveryEvent(context.Background(), domain.DeliveryEvent{
+		Kind:       domain.DeliveryAssigned,
+		DeliveryID: "delivery-id",
+		RiderID:    available.UserID,
+		Parcel:     domain.Dimensions{Width: 10, Height: 20, Depth: 30},
+		Weight:     2.5,
+	})
+
+	suite.NoError(err)
+	suite.Equal(assigned, result)
+	suite.MockPublisher.AssertCalled(suite.T(), "UpdateRider", assigned, []string{"status", "deliveryId", "parcels"})
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_HandleDeliveryEvent_OtherDelivery() {
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
+
+	result, err := suite.TestService.HandleDeliveryEvent(context.Background(), domain.DeliveryEvent{
+		Kind:       domain.DeliveryCompleted,
+		DeliveryID: "other-id",
+		RiderID:    suite.TestData.Rider.UserID,
+	})
+
+	suite.NoError(err)
+	suite.Equal(suite.TestData.Rider, result, "a delivery the rider is not on does not change the rider")
+	suite.MockRepository.AssertNotCalled(suite.T(), "Update", mock2.Anything)
+
+	_, err = suite.TestService.HandleDeliveryEvent(context.Background(), domain.DeliveryEvent{Kind: "lost", RiderID: suite.TestData.Rider.UserID})
+
+	suite.ErrorIs(err, domain.ErrValidation)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_LoadParcel() {
+	parcel := domain.Parcel{ID: "parcel-id", Size: domain.Dimensions{Width: 50, Height: 60, Depth: 40}, Weight: 1.5}
+
+	loaded := suite.TestData.Rider
+	loaded.Parcels = domain.Parcels{{ID: "parcel-id", Size: parcel.Size, Weight: 1.5, PickedUp: true}}
+
+	suite.MockRepository.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
+	suite.MockRepository.On("Update", loaded).Return(loaded, nil)
+	suite.MockPublisher.On("UpdateRider", loaded, []string{"parcels"}).Return(nil)
+
+	result, err := suite.TestService.LoadParcel(context.Background(), suite.TestData.Rider.UserID, parcel)
+
+	suite.NoError(err)
+	suite.Equal(domain.Dimensions{Width: 100, Height: 60, Depth: 100}, result.RemainingCapacity())
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_LoadParcel_DoesNotFit() {
+	rider := suite.TestData.Rider
+	rider.Parcels = domain.Parcels{{ID: "delivery-id", DeliveryID: "delivery-id", Size: domain.Dimensions{Width: 100, Height: 70, Depth: 100}}}
+
+	suite.MockRepository.On("Get", rider.UserID).Return(rider, nil)
+
+	_, err := suite.TestService.LoadParcel(context.Background(), rider.UserID, domain.Parcel{ID: "parcel-id", Size: domain.Dimensions{Width: 40, Height: 40, Depth: 40}})
+
+	suite.ErrorIs(err, domain.ErrConflict)
+
+	_, err = suite.TestService.LoadParcel(context.Background(), rider.UserID, domain.Parcel{ID: "delivery-id", Size: domain.Dimensions{Width: 1, Height: 1, Depth: 1}})
+
+	suite.ErrorIs(err, domain.ErrConflict, "parcels of a delivery are changed by its events")
+
+	_, err = suite.TestService.LoadParcel(context.Background(), rider.UserID, domain.Parcel{ID: "parcel-id"})
+
+	suite.ErrorIs(err, domain.ErrValidation)
+	suite.MockRepository.AssertNotCalled(suite.T(), "Update", mock2.Anything)
+}
+
+func (suite *RiderServiceTestSuite) TestRiderService_UnloadParcel() {
+	rider := suite.TestData.Rider
+	rider.Parcels = domain.Parcels{
+		{ID: "delivery-id", DeliveryID: "delivery-id"},
+		{ID: "parcel-id", Size: domain.Dimensions{Width: 1, Height: 1, Depth: 1}, PickedUp: true},
+	}
+
+	unloaded := rider
+	unloaded.Parcels = rider.Parcels[:1]
+
+	suite.MockRepository.On("Get", rider.UserID).Return(rider, nil)
+	suite.MockRepository.On("Update", unloaded).Return(unloaded, nil)
+	suite.MockPublisher.On("UpdateRider", unloaded, []string{"parcels"}).Return(nil)
+
+	_, err := suite.TestService.UnloadParcel(context.Background(), rider.UserID, "parcel-id")
+	suite.NoError(err)
+
+	_, err = suite.TestService.UnloadParcel(context.Background(), rider.UserID, "delivery-id")
+	suite.ErrorIs(err, domain.ErrConflict)
+
+	_, err = suite.TestService.UnloadParcel(context.Background(), rider.UserID, "unknown")
+	suite.ErrorIs(err, domain.ErrNotFound)
+}
+
 func TestUnit_RiderServiceTestSuite(t *testing.T) {
 	repoSuite := new(RiderServiceTestSuite)
 	suite.Run(t, repoSuite)
@@ -0,0 +1,20 @@
+package services
+
+import (
+	"fmt"
+	"go.opentelemetry.io/otel/trace"
+	"rider-service/config"
+	"rider-service/internal/core/interfaces"
+)
+
+// NewRouteEstimator returns the estimator eta.estimator names.
+func NewRouteEstimator(tracerProvider trace.TracerProvider, cfg *config.Config) (interfaces.RouteEstimator, error) {
+	switch cfg.ETA.Estimator {
+	case "", "haversine":
+		return NewHaversineEstimator(cfg), nil
+	case "osrm":
+		return NewOSRMEstimator(tracerProvider, cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown eta estimator %q, use haversine or osrm", cfg.ETA.Estimator)
+	}
+}
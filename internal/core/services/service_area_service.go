@@ -1,20 +1,91 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"rider-service/internal/core/domain"
 	"rider-service/internal/core/interfaces"
 )
 
 type serviceAreaService struct {
 	serviceAreaRepository interfaces.ServiceAreaRepository
+	riderService          interfaces.RiderService
 }
 
-func NewServiceAreaService(serviceAreaRepository interfaces.ServiceAreaRepository) *serviceAreaService {
+func NewServiceAreaService(serviceAreaRepository interfaces.ServiceAreaRepository, riderService interfaces.RiderService) *serviceAreaService {
 	return &serviceAreaService{
 		serviceAreaRepository: serviceAreaRepository,
+		riderService:          riderService,
 	}
 }
 
 func (s *serviceAreaService) SaveOrUpdateServiceArea(serviceArea domain.ServiceArea) error {
 	return s.serviceAreaRepository.SaveOrUpdateServiceArea(serviceArea)
 }
+
+func (s *serviceAreaService) GetServiceAreas(ctx context.Context) ([]domain.ServiceArea, error) {
+	return s.serviceAreaRepository.GetServiceAreas(ctx)
+}
+
+func (s *serviceAreaService) GetServiceArea(ctx context.Context, id int) (domain.ServiceArea, error) {
+	return s.serviceAreaRepository.GetServiceArea(ctx, id)
+}
+
+// DeleteServiceArea moves the riders every time it is called for a deleted area, so a delete message that is
+// redelivered because some riders could not be moved finishes the job.
+func (s *serviceAreaService) DeleteServiceArea(ctx context.Context, id int, version int, reassignTo int) error {
+	err := s.serviceAreaRepository.DeleteServiceArea(ctx, id, version)
+
+	// An area that was never created has no riders.
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	serviceArea, err := s.serviceAreaRepository.GetServiceArea(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	// A newer version of the area is stored, so the delete was outdated.
+	if !serviceArea.IsDeleted() {
+		return nil
+	}
+
+	canTakeRiders, err := s.canTakeRiders(ctx, reassignTo, id)
+
+	if err != nil {
+		return err
+	}
+
+	if !canTakeRiders {
+		reassignTo = 0
+	}
+
+	_, err = s.riderService.ReassignServiceArea(ctx, id, reassignTo)
+
+	return err
+}
+
+// canTakeRiders reports whether the riders of the deleted area can be moved to the area.
+func (s *serviceAreaService) canTakeRiders(ctx context.Context, id int, deleted int) (bool, error) {
+	if id == 0 || id == deleted {
+		return false, nil
+	}
+
+	serviceArea, err := s.serviceAreaRepository.GetServiceArea(ctx, id)
+
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return !serviceArea.IsDeleted(), nil
+}
@@ -1,39 +1,55 @@
 package services
 
 import (
+	"context"
 	"github.com/stretchr/testify/suite"
 	"rider-service/internal/core/domain"
 	"rider-service/internal/core/interfaces"
 	"rider-service/internal/mock"
 	"testing"
+	"time"
 )
 
 type ServiceAreaServiceTestSuite struct {
 	suite.Suite
-	MockRepository *mock.ServiceAreaRepository
-	TestService    interfaces.ServiceAreaService
-	TestData       struct {
+	MockRepository   *mock.ServiceAreaRepository
+	MockRiderService *mock.RiderService
+	TestService      interfaces.ServiceAreaService
+	TestData         struct {
 		ServiceArea domain.ServiceArea
+		Deleted     domain.ServiceArea
 	}
 }
 
 func (suite *ServiceAreaServiceTestSuite) SetupSuite() {
-	repository := new(mock.ServiceAreaRepository)
-
-	srv := NewServiceAreaService(repository)
+	deletedAt := time.Date(2022, 5, 1, 13, 0, 0, 0, time.UTC)
 
-	suite.MockRepository = repository
-	suite.TestService = srv
 	suite.TestData = struct {
 		ServiceArea domain.ServiceArea
+		Deleted     domain.ServiceArea
 	}{
 		ServiceArea: domain.ServiceArea{
 			ID:         1,
 			Identifier: "test-area",
 		},
+		Deleted: domain.ServiceArea{
+			ID:         2,
+			Identifier: "deleted-area",
+			Version:    3,
+			DeletedAt:  &deletedAt,
+		},
 	}
 }
 
+func (suite *ServiceAreaServiceTestSuite) SetupTest() {
+	repository := new(mock.ServiceAreaRepository)
+	riderService := new(mock.RiderService)
+
+	suite.MockRepository = repository
+	suite.MockRiderService = riderService
+	suite.TestService = NewServiceAreaService(repository, riderService)
+}
+
 func (suite *ServiceAreaServiceTestSuite) TestServiceAreaService_SaveOrUpdateServiceArea() {
 	suite.MockRepository.On("SaveOrUpdateServiceArea", suite.TestData.ServiceArea).Return(nil)
 
@@ -46,6 +62,87 @@ func (suite *ServiceAreaServiceTestSuite) TestServiceAreaService_SaveOrUpdateSer
 	suite.MockRepository.AssertCalled(suite.T(), "SaveOrUpdateServiceArea", suite.TestData.ServiceArea)
 }
 
+func (suite *ServiceAreaServiceTestSuite) TestServiceAreaService_GetServiceAreas() {
+	suite.MockRepository.On("GetServiceAreas").Return([]domain.ServiceArea{suite.TestData.ServiceArea}, nil)
+
+	result, err := suite.TestService.GetServiceAreas(context.Background())
+
+	suite.NoError(err)
+	suite.Equal([]domain.ServiceArea{suite.TestData.ServiceArea}, result)
+}
+
+func (suite *ServiceAreaServiceTestSuite) TestServiceAreaService_DeleteServiceArea_Reassigns() {
+	deleted := suite.TestData.Deleted
+
+	suite.MockRepository.On("DeleteServiceArea", deleted.ID, deleted.Version).Return(nil)
+	suite.MockRepository.On("GetServiceArea", deleted.ID).Return(deleted, nil)
+	suite.MockRepository.On("GetServiceArea", suite.TestData.ServiceArea.ID).Return(suite.TestData.ServiceArea, nil)
+	suite.MockRiderService.On("ReassignServiceArea", deleted.ID, suite.TestData.ServiceArea.ID).Return([]domain.Rider{}, nil)
+
+	err := suite.TestService.DeleteServiceArea(context.Background(), deleted.ID, deleted.Version, suite.TestData.ServiceArea.ID)
+
+	suite.NoError(err)
+	suite.MockRiderService.AssertCalled(suite.T(), "ReassignServiceArea", deleted.ID, suite.TestData.ServiceArea.ID)
+}
+
+func (suite *ServiceAreaServiceTestSuite) TestServiceAreaService_DeleteServiceArea_FlagsWhenAreaIsUnknown() {
+	deleted := suite.TestData.Deleted
+
+	suite.MockRepository.On("DeleteServiceArea", deleted.ID, deleted.Version).Return(nil)
+	suite.MockRepository.On("GetServiceArea", deleted.ID).Return(deleted, nil)
+	suite.MockRepository.On("GetServiceArea", 9).Return(domain.ServiceArea{}, domain.NewNotFoundError("service area 9 not found"))
+	suite.MockRiderService.On("ReassignServiceArea", deleted.ID, 0).Return([]domain.Rider{}, nil)
+
+	err := suite.TestService.DeleteServiceArea(context.Background(), deleted.ID, deleted.Version, 9)
+
+	suite.NoError(err)
+	suite.MockRiderService.AssertCalled(suite.T(), "ReassignServiceArea", deleted.ID, 0)
+}
+
+func (suite *ServiceAreaServiceTestSuite) TestServiceAreaService_DeleteServiceArea_FlagsWhenAreaIsDeleted() {
+	deleted := suite.TestData.Deleted
+
+	suite.MockRepository.On("DeleteServiceArea", deleted.ID, deleted.Version).Return(nil)
+	suite.MockRepository.On("GetServiceArea", deleted.ID).Return(deleted, nil)
+	suite.MockRiderService.On("ReassignServiceArea", deleted.ID, 0).Return([]domain.Rider{}, nil)
+
+	err := suite.TestService.DeleteServiceArea(context.Background(), deleted.ID, deleted.Version, deleted.ID)
+
+	suite.NoError(err)
+	suite.MockRiderService.AssertCalled(suite.T(), "ReassignServiceArea", deleted.ID, 0)
+}
+
+func (suite *ServiceAreaServiceTestSuite) TestServiceAreaService_DeleteServiceArea_StaleVersion() {
+	suite.MockRepository.On("DeleteServiceArea", suite.TestData.ServiceArea.ID, 1).Return(nil)
+	suite.MockRepository.On("GetServiceArea", suite.TestData.ServiceArea.ID).Return(suite.TestData.ServiceArea, nil)
+
+	err := suite.TestService.DeleteServiceArea(context.Background(), suite.TestData.ServiceArea.ID, 1, 0)
+
+	suite.NoError(err)
+	suite.MockRiderService.AssertNotCalled(suite.T(), "ReassignServiceArea", suite.TestData.ServiceArea.ID, 0)
+}
+
+func (suite *ServiceAreaServiceTestSuite) TestServiceAreaService_DeleteServiceArea_Unknown() {
+	suite.MockRepository.On("DeleteServiceArea", 9, 1).Return(domain.NewNotFoundError("service area 9 not found"))
+
+	err := suite.TestService.DeleteServiceArea(context.Background(), 9, 1, 0)
+
+	suite.NoError(err)
+	suite.MockRiderService.AssertNotCalled(suite.T(), "ReassignServiceArea", 9, 0)
+}
+
+func (suite *ServiceAreaServiceTestSuite) TestServiceAreaService_DeleteServiceArea_ReassignFails() {
+	deleted := suite.TestData.Deleted
+
+	suite.MockRepository.On("DeleteServiceArea", deleted.ID, deleted.Version).Return(nil)
+	suite.MockRepository.On("GetServiceArea", deleted.ID).Return(deleted, nil)
+	suite.MockRiderService.On("ReassignServiceArea", deleted.ID, 0).Return([]domain.Rider{}, domain.ErrVersionConflict)
+
+	err := suite.TestService.DeleteServiceArea(context.Background(), deleted.ID, deleted.Version, 0)
+
+	suite.ErrorIs(err, domain.ErrVersionConflict, "the message is redelivered to move the remaining riders")
+}
+
 func TestUnit_ServiceAreaServiceTestSuite(t *testing.T) {
 	repoSuite := new(ServiceAreaServiceTestSuite)
 	suite.Run(t, repoSuite)
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"rider-service/config"
+	"rider-service/internal/core/domain"
+	"rider-service/internal/core/interfaces"
+	"time"
+)
+
+type statsService struct {
+	riderRepository       interfaces.RiderRepository
+	serviceAreaRepository interfaces.ServiceAreaRepository
+	messagePublisher      interfaces.MessageBusPublisher
+	config                *config.Config
+	now                   func() time.Time
+}
+
+func NewStatsService(riderRepository interfaces.RiderRepository, serviceAreaRepository interfaces.ServiceAreaRepository, messagePublisher interfaces.MessageBusPublisher, cfg *config.Config) *statsService {
+	return &statsService{
+		riderRepository:       riderRepository,
+		serviceAreaRepository: serviceAreaRepository,
+		messagePublisher:      messagePublisher,
+		config:                cfg,
+		now:                   time.Now,
+	}
+}
+
+// GetServiceAreaStats is computed by the database, riders are not loaded.
+func (srv *statsService) GetServiceAreaStats(ctx context.Context, id int) (domain.ServiceAreaStats, error) {
+	serviceArea, err := srv.serviceAreaRepository.GetServiceArea(ctx, id)
+
+	if err != nil {
+		return domain.ServiceAreaStats{}, err
+	}
+
+	now := srv.now()
+	stats, err := srv.riderRepository.GetRiderStats(ctx, domain.RiderFilter{ServiceAreaID: id}, now.Add(-srv.config.Stats.StaleAfter))
+
+	if err != nil {
+		return domain.ServiceAreaStats{}, err
+	}
+
+	return domain.ServiceAreaStats{ServiceArea: serviceArea, Riders: stats[id], ComputedAt: now}, nil
+}
+
+// GetStatsSummary lists areas without riders too. Riders of deleted areas, which are offline until they are moved,
+// are left out of the total.
+func (srv *statsService) GetStatsSummary(ctx context.Context) (domain.StatsSummary, error) {
+	serviceAreas, err := srv.serviceAreaRepository.GetServiceAreas(ctx)
+
+	if err != nil {
+		return domain.StatsSummary{}, err
+	}
+
+	now := srv.now()
+	stats, err := srv.riderRepository.GetRiderStats(ctx, domain.RiderFilter{}, now.Add(-srv.config.Stats.StaleAfter))
+
+	if err != nil {
+		return domain.StatsSummary{}, err
+	}
+
+	summary := domain.StatsSummary{
+		ServiceAreas: make([]domain.ServiceAreaStats, 0, len(serviceAreas)),
+		ComputedAt:   now,
+	}
+
+	for _, serviceArea := range serviceAreas {
+		summary.ServiceAreas = append(summary.ServiceAreas, domain.ServiceAreaStats{
+			ServiceArea: serviceArea,
+			Riders:      stats[serviceArea.ID],
+			ComputedAt:  now,
+		})
+		summary.Total = summary.Total.Add(stats[serviceArea.ID])
+	}
+
+	return summary, nil
+}
+
+// GetServiceAreaHeatmap is computed by the database, like the stats.
+func (srv *statsService) GetServiceAreaHeatmap(ctx context.Context, filter domain.HeatmapFilter) (domain.Heatmap, error) {
+	now := srv.now()
+
+	if filter.Source == "" {
+		filter.Source = domain.HeatmapRiders
+	}
+
+	if filter.Precision == 0 {
+		filter.Precision = srv.config.Heatmap.Precision
+	}
+
+	if filter.Source == domain.HeatmapHistory && filter.Since.IsZero() {
+		filter.Since = now.Add(-srv.config.Heatmap.HistoryWindow)
+	}
+
+	var fields []domain.FieldError
+
+	if filter.Source != domain.HeatmapRiders && filter.Source != domain.HeatmapHistory {
+		fields = append(fields, domain.FieldError{Field: "source", Message: fmt.Sprintf("must be %s or %s", domain.HeatmapRiders, domain.HeatmapHistory)})
+	}
+
+	if filter.Precision < 1 || filter.Precision > srv.config.Heatmap.MaxPrecision {
+		fields = append(fields, domain.FieldError{Field: "precision", Message: fmt.Sprintf("must be between 1 and %d", srv.config.Heatmap.MaxPrecision)})
+	}
+
+	if filter.Since.After(now) {
+		fields = append(fields, domain.FieldError{Field: "since", Message: "can not be in the future"})
+	}
+
+	if len(fields) > 0 {
+		return domain.Heatmap{}, domain.NewValidationError(fields...)
+	}
+
+	serviceArea, err := srv.serviceAreaRepository.GetServiceArea(ctx, filter.ServiceAreaID)
+
+	if err != nil {
+		return domain.Heatmap{}, err
+	}
+
+	cells, err := srv.riderRepository.GetHeatmap(ctx, filter)
+
+	if err != nil {
+		return domain.Heatmap{}, err
+	}
+
+	return domain.Heatmap{ServiceArea: serviceArea, Filter: filter, Cells: cells, ComputedAt: now}, nil
+}
+
+// PublishStats publishes the stats of every area of the summary, also when publishing an earlier area failed.
+// The first error is returned.
+func (srv *statsService) PublishStats(ctx context.Context) error {
+	summary, err := srv.GetStatsSummary(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+
+	for _, stats := range summary.ServiceAreas {
+		if err = srv.messagePublisher.ServiceAreaStats(ctx, stats); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
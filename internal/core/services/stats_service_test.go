@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/suite"
+	"rider-service/config"
+	"rider-service/internal/core/domain"
+	"rider-service/internal/mock"
+	"testing"
+	"time"
+)
+
+type StatsServiceTestSuite struct {
+	suite.Suite
+	MockRiderRepository       *mock.RiderRepository
+	MockServiceAreaRepository *mock.ServiceAreaRepository
+	MockPublisher             *mock.MessageBusPublisher
+	TestService               *statsService
+	Now                       time.Time
+	TestData                  struct {
+		ServiceAreas []domain.ServiceArea
+		Stats        map[int]domain.RiderStats
+	}
+}
+
+func (suite *StatsServiceTestSuite) SetupSuite() {
+	suite.Now = time.Date(2022, 5, 1, 13, 0, 0, 0, time.UTC)
+	suite.TestData.ServiceAreas = []domain.ServiceArea{
+		{ID: 1, Identifier: "test-area"},
+		{ID: 2, Identifier: "empty-area"},
+	}
+	suite.TestData.Stats = map[int]domain.RiderStats{
+		1: {
+			ByStatus:        map[int]int{domain.StatusOffline: 1, domain.StatusAvailable: 2},
+			AverageCapacity: domain.AverageDimensions{Width: 10, Height: 20, Depth: 30},
+			StaleLocations:  1,
+		},
+		// The riders of a deleted area are left out of the summary.
+		3: {ByStatus: map[int]int{domain.StatusOffline: 4}},
+	}
+}
+
+func (suite *StatsServiceTestSuite) SetupTest() {
+	suite.MockRiderRepository = new(mock.RiderRepository)
+	suite.MockServiceAreaRepository = new(mock.ServiceAreaRepository)
+	suite.MockPublisher = new(mock.MessageBusPublisher)
+
+	suite.TestService = NewStatsService(suite.MockRiderRepository, suite.MockServiceAreaRepository, suite.MockPublisher, &config.Config{
+		Stats:   config.Stats{StaleAfter: time.Minute},
+		Heatmap: config.Heatmap{Precision: 6, MaxPrecision: 7, HistoryWindow: time.Hour},
+	})
+	suite.TestService.now = func() time.Time { return suite.Now }
+}
+
+func (suite *StatsServiceTestSuite) TestStatsService_GetServiceAreaStats() {
+	area := suite.TestData.ServiceAreas[0]
+
+	suite.MockServiceAreaRepository.On("GetServiceArea", area.ID).Return(area, nil)
+	suite.MockRiderRepository.On("GetRiderStats", domain.RiderFilter{ServiceAreaID: area.ID}, suite.Now.Add(-time.Minute)).
+		Return(map[int]domain.RiderStats{area.ID: suite.TestData.Stats[1]}, nil)
+
+	result, err := suite.TestService.GetServiceAreaStats(context.Background(), area.ID)
+
+	suite.NoError(err)
+	suite.Equal(domain.ServiceAreaStats{ServiceArea: area, Riders: suite.TestData.Stats[1], ComputedAt: suite.Now}, result)
+}
+
+func (suite *StatsServiceTestSuite) TestStatsService_GetServiceAreaStats_NotFound() {
+	suite.MockServiceAreaRepository.On("GetServiceArea", 9).Return(domain.ServiceArea{}, domain.NewNotFoundError("service area 9 not found"))
+
+	_, err := suite.TestService.GetServiceAreaStats(context.Background(), 9)
+
+	suite.ErrorIs(err, domain.ErrNotFound)
+	suite.MockRiderRepository.AssertNotCalled(suite.T(), "GetRiderStats")
+}
+
+func (suite *StatsServiceTestSuite) TestStatsService_GetStatsSummary() {
+	suite.MockServiceAreaRepository.On("GetServiceAreas").Return(suite.TestData.ServiceAreas, nil)
+	suite.MockRiderRepository.On("GetRiderStats", domain.RiderFilter{}, suite.Now.Add(-time.Minute)).Return(suite.TestData.Stats, nil)
+
+	result, err := suite.TestService.GetStatsSummary(context.Background())
+
+	suite.NoError(err)
+	suite.Require().Len(result.ServiceAreas, 2)
+	suite.Equal(suite.TestData.Stats[1], result.ServiceAreas[0].Riders)
+	suite.Equal(0, result.ServiceAreas[1].Riders.Riders(), "areas without riders are listed")
+	suite.Equal(3, result.Total.Riders())
+	suite.Equal(suite.TestData.Stats[1].AverageCapacity, result.Total.AverageCapacity)
+	suite.Equal(suite.Now, result.ComputedAt)
+}
+
+func (suite *StatsServiceTestSuite) TestStatsService_PublishStats() {
+	suite.MockServiceAreaRepository.On("GetServiceAreas").Return(suite.TestData.ServiceAreas, nil)
+	suite.MockRiderRepository.On("GetRiderStats", domain.RiderFilter{}, suite.Now.Add(-time.Minute)).Return(suite.TestData.Stats, nil)
+	suite.MockPublisher.On("ServiceAreaStats", domain.ServiceAreaStats{ServiceArea: suite.TestData.ServiceAreas[0], Riders: suite.TestData.Stats[1], ComputedAt: suite.Now}).
+		Return(errors.New("test-error"))
+	suite.MockPublisher.On("ServiceAreaStats", domain.ServiceAreaStats{ServiceArea: suite.TestData.ServiceAreas[1], ComputedAt: suite.Now}).Return(nil)
+
+	err := suite.TestService.PublishStats(context.Background())
+
+	suite.EqualError(err, "test-error")
+	suite.MockPublisher.AssertNumberOfCalls(suite.T(), "ServiceAreaStats", 2)
+}
+
+func (suite *StatsServiceTestSuite) TestStatsService_GetServiceAreaHeatmap_Defaults() {
+	area := suite.TestData.ServiceAreas[0]
+	cells := []domain.HeatmapCell{{Geohash: "u15u", Count: 2, Bounds: domain.GeohashBounds("u15u")}}
+	filter := domain.HeatmapFilter{ServiceAreaID: area.ID, Source: domain.HeatmapRiders, Precision: 6}
+
+	suite.MockServiceAreaRepository.On("GetServiceArea", area.ID).Return(area, nil)
+	suite.MockRiderRepository.On("GetHeatmap", filter).Return(cells, nil)
+
+	result, err := suite.TestService.GetServiceAreaHeatmap(context.Background(), domain.HeatmapFilter{ServiceAreaID: area.ID})
+
+	suite.NoError(err)
+	suite.Equal(domain.Heatmap{ServiceArea: area, Filter: filter, Cells: cells, ComputedAt: suite.Now}, result)
+}
+
+func (suite *StatsServiceTestSuite) TestStatsService_GetServiceAreaHeatmap_HistoryWindow() {
+	area := suite.TestData.ServiceAreas[0]
+	filter := domain.HeatmapFilter{ServiceAreaID: area.ID, Source: domain.HeatmapHistory, Precision: 7, Since: suite.Now.Add(-time.Hour)}
+
+	suite.MockServiceAreaRepository.On("GetServiceArea", area.ID).Return(area, nil)
+	suite.MockRiderRepository.On("GetHeatmap", filter).Return([]domain.HeatmapCell{}, nil)
+
+	result, err := suite.TestService.GetServiceAreaHeatmap(context.Background(), domain.HeatmapFilter{ServiceAreaID: area.ID, Source: domain.HeatmapHistory, Precision: 7})
+
+	suite.NoError(err)
+	suite.Equal(filter, result.Filter)
+}
+
+func (suite *StatsServiceTestSuite) TestStatsService_GetServiceAreaHeatmap_Invalid() {
+	_, err := suite.TestService.GetServiceAreaHeatmap(context.Background(), domain.HeatmapFilter{
+		ServiceAreaID: 1,
+		Source:        "orders",
+		Precision:     8,
+		Since:         suite.Now.Add(time.Minute),
+	})
+
+	var domainErr *domain.Error
+	suite.Require().ErrorAs(err, &domainErr)
+	suite.ErrorIs(err, domain.ErrValidation)
+	suite.Len(domainErr.Fields, 3)
+	suite.MockServiceAreaRepository.AssertNotCalled(suite.T(), "GetServiceArea")
+}
+
+func (suite *StatsServiceTestSuite) TestStatsService_GetServiceAreaHeatmap_NotFound() {
+	suite.MockServiceAreaRepository.On("GetServiceArea", 9).Return(domain.ServiceArea{}, domain.NewNotFoundError("service area 9 not found"))
+
+	_, err := suite.TestService.GetServiceAreaHeatmap(context.Background(), domain.HeatmapFilter{ServiceAreaID: 9})
+
+	suite.ErrorIs(err, domain.ErrNotFound)
+	suite.MockRiderRepository.AssertNotCalled(suite.T(), "GetHeatmap")
+}
+
+func TestUnit_StatsServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(StatsServiceTestSuite))
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"rider-service/pkg/asyncapi"
+	"rider-service/pkg/events"
+)
+
+// TopicPublisher is a message bus publisher that tells the name of a topic on its broker.
+type TopicPublisher interface {
+	Topic(name string) string
+}
+
+// TopicConsumer is a message bus handler that tells the topics it consumes.
+type TopicConsumer interface {
+	Topics() []string
+}
+
+// asyncAPIVersion is the version of the AsyncAPI document, increase it when a topic or an event changes.
+const asyncAPIVersion = "1.0.0"
+
+type consumedTopic struct {
+	description string
+	event       events.Event
+}
+
+// consumedTopics documents the topics the message bus handlers consume. The AsyncAPI document can not be
+// created for a handler that consumes a topic that is not here.
+var consumedTopics = map[string]consumedTopic{
+	"user.create": {
+		description: "Published by the user service when a user is created, the rider service keeps a copy of the user.",
+		event:       events.UserV1{},
+	},
+	"user.update": {
+		description: "Published by the user service when a user is updated.",
+		event:       events.UserV1{},
+	},
+	"service_area.create": {
+		description: "Published by the service area service when a service area is created, the rider service keeps a copy of the service area.",
+		event:       events.ServiceAreaV1{},
+	},
+	"service_area.update": {
+		description: "Published by the service area service when a service area is updated.",
+		event:       events.ServiceAreaV1{},
+	},
+	"service_area.delete": {
+		description: "Published by the service area service when a service area is deleted, its riders are moved to another service area or set offline.",
+		event:       events.ServiceAreaDeletedV1{},
+	},
+	"delivery.assigned": {
+		description: "Published by the delivery service when a delivery is assigned to a rider, the rider is set assigned.",
+		event:       events.DeliveryV1{},
+	},
+	"delivery.picked_up": {
+		description: "Published by the delivery service when a rider picked up a delivery, the rider is set delivering.",
+		event:       events.DeliveryV1{},
+	},
+	"delivery.completed": {
+		description: "Published by the delivery service when a delivery is completed, its rider is set available again.",
+		event:       events.DeliveryV1{},
+	},
+	"delivery.cancelled": {
+		description: "Published by the delivery service when a delivery is cancelled, its rider is set available again.",
+		event:       events.DeliveryV1{},
+	},
+}
+
+// newAsyncAPI documents the topics the publisher publishes to and the topics the consumer consumes.
+func newAsyncAPI(info asyncapi.Info, publisher TopicPublisher, consumer TopicConsumer) (*asyncapi.Document, error) {
+	document := asyncapi.New(info)
+
+	for _, topic := range events.Topics {
+		if err := document.AddPublished(publisher.Topic(topic.Name), topic.Description, topic.Event); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range consumer.Topics() {
+		topic, documented := consumedTopics[name]
+
+		if !documented {
+			return nil, fmt.Errorf("consumed topic %s is not documented", name)
+		}
+
+		if err := document.AddConsumed(name, topic.description, topic.event); err != nil {
+			return nil, err
+		}
+	}
+
+	return document, nil
+}
+
+// SetupAsyncAPI serves the AsyncAPI document of the message bus at /asyncapi, like SetupSwagger does for the REST API.
+func (handler *HTTPHandler) SetupAsyncAPI(publisher TopicPublisher, consumer TopicConsumer) error {
+	document, err := newAsyncAPI(asyncapi.Info{
+		Title:       handler.config.Server.Service + " messages",
+		Version:     asyncAPIVersion,
+		Description: handler.config.Server.Description,
+	}, publisher, consumer)
+
+	if err != nil {
+		return err
+	}
+
+	handler.router.GET("/asyncapi", func(c *gin.Context) {
+		c.JSON(http.StatusOK, document)
+	})
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"rider-service/config"
+	"rider-service/internal/repositories"
+	"rider-service/pkg/asyncapi"
+	"rider-service/pkg/events"
+	"rider-service/pkg/logging"
+	"testing"
+)
+
+// prefixPublisher names topics like the publishers do, with the prefix of the broker.
+type prefixPublisher string
+
+func (prefix prefixPublisher) Topic(name string) string {
+	return string(prefix) + name
+}
+
+type topicList []string
+
+func (topics topicList) Topics() []string {
+	return topics
+}
+
+type AsyncAPITestSuite struct {
+	suite.Suite
+	Cfg *config.Config
+}
+
+func (suite *AsyncAPITestSuite) SetupSuite() {
+	cfg, err := config.UseConfig("../../test/rider.config")
+
+	if err != nil {
+		panic(errors.WithStack(err))
+	}
+
+	suite.Cfg = cfg
+}
+
+func (suite *AsyncAPITestSuite) TestAsyncAPI_DocumentsEveryTopic() {
+	brokers := map[string]struct {
+		publisher TopicPublisher
+		consumer  TopicConsumer
+	}{
+		"rabbitmq": {prefixPublisher("rider."), NewRabbitMQ(nil, nil, nil, nil, suite.Cfg)},
+		"azure":    {prefixPublisher("customer."), NewAzure(nil, nil, nil, nil, suite.Cfg)},
+	}
+
+	for broker, registrations := range brokers {
+		document, err := newAsyncAPI(asyncapi.Info{Title: "test", Version: "1"}, registrations.publisher, registrations.consumer)
+
+		if !suite.NoError(err, broker) {
+			continue
+		}
+
+		for _, topic := range registrations.consumer.Topics() {
+			channel, documented := document.Channels[topic]
+
+			if suite.True(documented, "%s consumes %s, which is not documented", broker, topic) {
+				suite.NotNil(channel.Publish, "%s: %s is consumed", broker, topic)
+				suite.NotEmpty(channel.Description)
+			}
+		}
+
+		for _, topic := range events.Topics {
+			channel, documented := document.Channels[registrations.publisher.Topic(topic.Name)]
+
+			if suite.True(documented, "%s publishes to %s, which is not documented", broker, topic.Name) {
+				suite.NotNil(channel.Subscribe, "%s: %s is published", broker, topic.Name)
+				suite.Equal(topic.Event.Schema(), channel.Subscribe.Message.Name)
+			}
+		}
+
+		suite.Len(document.Channels, len(events.Topics)+len(registrations.consumer.Topics()), broker)
+	}
+}
+
+func (suite *AsyncAPITestSuite) TestAsyncAPI_UndocumentedTopic() {
+	_, err := newAsyncAPI(asyncapi.Info{}, prefixPublisher("rider."), topicList{"user.create", "user.delete"})
+
+	suite.EqualError(err, "consumed topic user.delete is not documented")
+}
+
+func (suite *AsyncAPITestSuite) TestAsyncAPI_Endpoint() {
+	router := gin.New()
+	gin.SetMode(gin.TestMode)
+
+	handler := NewHTTPHandler(nil, nil, nil, nil, repositories.NewMemoryRepository(), router, logging.MockLogger{}, suite.Cfg)
+	suite.Require().NoError(handler.SetupAsyncAPI(prefixPublisher("rider."), NewRabbitMQ(nil, nil, nil, nil, suite.Cfg)))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/asyncapi", nil))
+
+	suite.Equal(http.StatusOK, w.Code)
+
+	var document struct {
+		AsyncAPI string `json:"asyncapi"`
+		Info     struct {
+			Title string `json:"title"`
+		} `json:"info"`
+		Channels map[string]struct {
+			Parameters map[string]interface{} `json:"parameters"`
+			Subscribe  struct {
+				Message struct {
+					Payload struct {
+						Required []string `json:"required"`
+					} `json:"payload"`
+				} `json:"message"`
+			} `json:"subscribe"`
+		} `json:"channels"`
+	}
+
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &document))
+	suite.Equal(asyncapi.Version, document.AsyncAPI)
+	suite.Equal(suite.Cfg.Server.Service+" messages", document.Info.Title)
+	suite.Contains(document.Channels, "user.create")
+
+	location := document.Channels["rider.{serviceArea}.update.location"]
+	suite.Contains(location.Parameters, "serviceArea")
+	suite.Equal([]string{"id", "location"}, location.Subscribe.Message.Payload.Required)
+}
+
+func TestUnit_AsyncAPITestSuite(t *testing.T) {
+	suite.Run(t, new(AsyncAPITestSuite))
+}
@@ -2,41 +2,57 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"golang.org/x/exp/maps"
 	"rider-service/config"
 	"rider-service/internal/core/domain"
 	"rider-service/internal/core/interfaces"
 	"rider-service/pkg/azure"
+	"rider-service/pkg/events"
+	"sort"
+	"sync"
 )
 
 type azureHandler struct {
 	serviceBus         *azure.ServiceBus
 	service            interfaces.RiderService
 	serviceAreaService interfaces.ServiceAreaService
+	inbox              interfaces.InboxRepository
 	handlers           map[string]func(topic string, body []byte, handler *azureHandler) error
 	config             *config.Config
-	channel            chan bool
+	quit               context.Context
+	stop               context.CancelFunc
+	running            sync.WaitGroup
 }
 
-func NewAzure(serviceBus *azure.ServiceBus, service interfaces.RiderService, serviceAreaService interfaces.ServiceAreaService, config *config.Config) *azureHandler {
+func NewAzure(serviceBus *azure.ServiceBus, service interfaces.RiderService, serviceAreaService interfaces.ServiceAreaService, inbox interfaces.InboxRepository, config *config.Config) *azureHandler {
+	quit, stop := context.WithCancel(context.Background())
+
 	return &azureHandler{
 		serviceBus:         serviceBus,
 		service:            service,
 		serviceAreaService: serviceAreaService,
+		inbox:              inbox,
 		handlers: map[string]func(topic string, body []byte, handler *azureHandler) error{
 			"user.create":         userCreateOrUpdate,
 			"user.update":         userCreateOrUpdate,
 			"service_area.create": serviceAreaCreateOrUpdate,
 			"service_area.update": serviceAreaCreateOrUpdate,
+			"service_area.delete": serviceAreaDelete,
+			"delivery.assigned":   deliveryChanged,
+			"delivery.picked_up":  deliveryChanged,
+			"delivery.completed":  deliveryChanged,
+			"delivery.cancelled":  deliveryChanged,
 		},
 		config: config,
+		quit:   quit,
+		stop:   stop,
 	}
 }
 
 func serviceAreaCreateOrUpdate(topic string, body []byte, handler *azureHandler) error {
 	var serviceArea domain.ServiceArea
-	if err := json.Unmarshal(body, &serviceArea); err != nil {
+	if err := decodeBody(body, &serviceArea); err != nil {
 		return err
 	}
 
@@ -47,10 +63,31 @@ func serviceAreaCreateOrUpdate(topic string, body []byte, handler *azureHandler)
 	return nil
 }
 
+func serviceAreaDelete(topic string, body []byte, handler *azureHandler) error {
+	var deleted events.ServiceAreaDeletedV1
+	if err := decodeBody(body, &deleted); err != nil {
+		return err
+	}
+
+	return handler.serviceAreaService.DeleteServiceArea(context.Background(), deleted.ID, deleted.Version, deleted.ReassignTo)
+}
+
+func deliveryChanged(topic string, body []byte, handler *azureHandler) error {
+	event, err := deliveryEvent(topic, body)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = handler.service.HandleDeliveryEvent(context.Background(), event)
+
+	return err
+}
+
 func userCreateOrUpdate(topic string, body []byte, handler *azureHandler) error {
 	var user domain.User
 
-	if err := json.Unmarshal(body, &user); err != nil {
+	if err := decodeBody(body, &user); err != nil {
 		return err
 	}
 
@@ -73,51 +110,70 @@ func (handler *azureHandler) Listen() {
 		return
 	}
 
-	handler.channel = make(chan bool)
+	handler.running.Add(1)
 
 	go func() {
+		defer handler.running.Done()
+
 		for {
-			select {
-			case <-handler.channel:
+			msgs, err := receiver.ReceiveMessages(
+				handler.quit,
+				1,
+				nil,
+			)
+
+			if handler.quit.Err() != nil {
 				return
-			default:
-				msgs, err := receiver.ReceiveMessages(
-					context.Background(),
-					1,
-					nil,
-				)
-
-				if err != nil {
-					fmt.Println(err)
-					return
-				}
+			}
+
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
 
-				for _, msg := range msgs {
+			for _, msg := range msgs {
 
-					if msg.Subject != nil && *msg.Subject != "" {
-						fun, exist := handler.handlers[*msg.Subject]
+				if msg.Subject != nil && *msg.Subject != "" {
+					fun, exist := handler.handlers[*msg.Subject]
 
-						if exist {
-							err = fun(*msg.Subject, msg.Body, handler)
-							if err == nil {
-								_ = receiver.CompleteMessage(context.Background(), msg, nil)
-								continue
-							}
+					if exist {
+						err = handleOnce(context.Background(), handler.inbox, handler.config.Inbox.Retention, msg.MessageID, *msg.Subject, func() error {
+							return fun(*msg.Subject, msg.Body, handler)
+						})
+						if err == nil {
+							_ = receiver.CompleteMessage(context.Background(), msg, nil)
+							continue
 						}
-					} else {
-						fmt.Println("Message contains no subject: ", msg.MessageID)
-						_ = receiver.CompleteMessage(context.Background(), msg, nil)
-						continue
 					}
+				} else {
+					fmt.Println("Message contains no subject: ", msg.MessageID)
+					_ = receiver.CompleteMessage(context.Background(), msg, nil)
+					continue
+				}
+
+				fmt.Println(err)
 
-					fmt.Println(err)
-					_ = receiver.AbandonMessage(context.Background(), msg, nil)
+				if !redeliver(err) {
+					_ = receiver.DeadLetterMessage(context.Background(), msg, nil)
+					continue
 				}
+
+				_ = receiver.AbandonMessage(context.Background(), msg, nil)
 			}
 		}
 	}()
 }
 
+// Topics returns the subjects the handler consumes, sorted.
+func (handler *azureHandler) Topics() []string {
+	topics := maps.Keys(handler.handlers)
+	sort.Strings(topics)
+
+	return topics
+}
+
+// Quit stops receiving messages and waits for the message that is being handled.
 func (handler *azureHandler) Quit() {
-	handler.channel <- true
+	handler.stop()
+	handler.running.Wait()
 }
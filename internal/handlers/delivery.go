@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"rider-service/internal/core/domain"
+	"rider-service/pkg/events"
+	"strings"
+)
+
+// deliveryEvent decodes a message of one of the delivery topics, the topic tells what happened to the delivery.
+func deliveryEvent(topic string, body []byte) (domain.DeliveryEvent, error) {
+	var delivery events.DeliveryV1
+
+	if err := decodeBody(body, &delivery); err != nil {
+		return domain.DeliveryEvent{}, err
+	}
+
+	event := domain.DeliveryEvent{
+		Kind:       domain.DeliveryEventKind(strings.TrimPrefix(topic, "delivery.")),
+		DeliveryID: delivery.ID,
+		RiderID:    delivery.RiderID,
+	}
+
+	if delivery.Parcel != nil {
+		event.Parcel = domain.Dimensions{Width: delivery.Parcel.Width, Height: delivery.Parcel.Height, Depth: delivery.Parcel.Depth}
+		event.Weight = delivery.Parcel.Weight
+	}
+
+	return event, nil
+}
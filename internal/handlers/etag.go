@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+)
+
+func formatETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// parseIfMatch returns the rider version an If-Match header refers to.
+// An empty header or a wildcard returns 0, which means the update is unconditional.
+func parseIfMatch(header string) (int, error) {
+	header = strings.TrimSpace(header)
+
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	header = strings.TrimPrefix(header, "W/")
+
+	value, err := strconv.Unquote(header)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(value)
+}
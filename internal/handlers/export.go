@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"rider-service/internal/core/domain"
+	"rider-service/pkg/dto"
+)
+
+// riderEncoder writes riders one at a time in an export format.
+type riderEncoder interface {
+	contentType() string
+	fileExtension() string
+	begin(w io.Writer) error
+	encode(w io.Writer, rider domain.Rider, precision domain.LocationPrecision) error
+	end(w io.Writer) error
+}
+
+// newRiderEncoder returns the encoder of a format, GeoJSON when the format is empty.
+func newRiderEncoder(format string) riderEncoder {
+	switch format {
+	case "csv":
+		return &csvRiderEncoder{}
+	case "ndjson":
+		return ndjsonRiderEncoder{}
+	default:
+		return &geoJSONRiderEncoder{}
+	}
+}
+
+// geoJSONRiderEncoder writes a FeatureCollection, opening the array of features in begin and closing it in end.
+type geoJSONRiderEncoder struct {
+	features int
+}
+
+func (*geoJSONRiderEncoder) contentType() string {
+	return "application/geo+json"
+}
+
+func (*geoJSONRiderEncoder) fileExtension() string {
+	return "geojson"
+}
+
+func (*geoJSONRiderEncoder) begin(w io.Writer) error {
+	_, err := io.WriteString(w, `{"type":"FeatureCollection","features":[`)
+	return err
+}
+
+func (encoder *geoJSONRiderEncoder) encode(w io.Writer, rider domain.Rider, precision domain.LocationPrecision) error {
+	feature, err := json.Marshal(dto.CreateRiderFeature(rider, precision))
+
+	if err != nil {
+		return err
+	}
+
+	if encoder.features > 0 {
+		if _, err = io.WriteString(w, ","); err != nil {
+			return err
+		}
+	}
+
+	encoder.features++
+	_, err = w.Write(feature)
+
+	return err
+}
+
+func (*geoJSONRiderEncoder) end(w io.Writer) error {
+	_, err := io.WriteString(w, "]}")
+	return err
+}
+
+type csvRiderEncoder struct {
+	writer *csv.Writer
+}
+
+func (*csvRiderEncoder) contentType() string {
+	return "text/csv"
+}
+
+func (*csvRiderEncoder) fileExtension() string {
+	return "csv"
+}
+
+func (encoder *csvRiderEncoder) begin(w io.Writer) error {
+	encoder.writer = csv.NewWriter(w)
+	return encoder.writer.Write(dto.RiderCSVHeader)
+}
+
+func (encoder *csvRiderEncoder) encode(_ io.Writer, rider domain.Rider, precision domain.LocationPrecision) error {
+	return encoder.writer.Write(dto.CreateRiderCSVRecord(rider, precision))
+}
+
+func (encoder *csvRiderEncoder) end(io.Writer) error {
+	encoder.writer.Flush()
+	return encoder.writer.Error()
+}
+
+// ndjsonRiderEncoder writes every rider as a rider response on its own line.
+type ndjsonRiderEncoder struct{}
+
+func (ndjsonRiderEncoder) contentType() string {
+	return "application/x-ndjson"
+}
+
+func (ndjsonRiderEncoder) fileExtension() string {
+	return "ndjson"
+}
+
+func (ndjsonRiderEncoder) begin(io.Writer) error {
+	return nil
+}
+
+func (ndjsonRiderEncoder) encode(w io.Writer, rider domain.Rider, precision domain.LocationPrecision) error {
+	return json.NewEncoder(w).Encode(dto.CreateRiderResponse(rider, precision))
+}
+
+func (ndjsonRiderEncoder) end(io.Writer) error {
+	return nil
+}
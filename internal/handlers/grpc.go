@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"rider-service/config"
+	"rider-service/internal/core/domain"
+	"rider-service/internal/core/interfaces"
+	"rider-service/pkg/authorization"
+	"rider-service/pkg/logging"
+	"rider-service/pkg/pb"
+	"time"
+)
+
+// defaultWatchInterval is used when no watch interval is configured.
+const defaultWatchInterval = 2 * time.Second
+
+// GrpcHandler serves the rider operations over gRPC. It calls the same core as HTTPHandler,
+// authentication, tracing and logging are done by the interceptors in grpc_interceptors.go.
+type GrpcHandler struct {
+	pb.UnimplementedRiderServiceServer
+	riderService interfaces.RiderService
+	server       *grpc.Server
+	logger       logging.Logger
+	config       *config.Config
+}
+
+func NewGrpcHandler(riderService interfaces.RiderService, server *grpc.Server, logger logging.Logger, config *config.Config) *GrpcHandler {
+	return &GrpcHandler{
+		riderService: riderService,
+		server:       server,
+		logger:       logger,
+		config:       config,
+	}
+}
+
+func (handler *GrpcHandler) SetupEndpoints() {
+	pb.RegisterRiderServiceServer(handler.server, handler)
+}
+
+// SetupHealthprobe registers the standard gRPC health service, which always reports serving like the /health endpoint.
+func (handler *GrpcHandler) SetupHealthprobe() {
+	grpc_health_v1.RegisterHealthServer(handler.server, health.NewServer())
+}
+
+// riderMessage creates the message of a rider with its location as precise as the caller may see it.
+func (handler *GrpcHandler) riderMessage(auth *authorization.GrpcAuthorization, rider domain.Rider) *pb.Rider {
+	privacy := domain.LocationPrivacy{GridSize: handler.config.Privacy.CoarseGridSize}
+	precision := privacy.Precision(rider, auth.AuthorizeAdmin() || auth.AuthorizeDispatcher())
+
+	return riderMessage(privacy.Apply(rider, precision), precision)
+}
+
+func (handler *GrpcHandler) GetRider(ctx context.Context, request *pb.GetRiderRequest) (*pb.Rider, error) {
+	auth := authorization.GrpcFromContext(ctx)
+
+	if !(auth.AuthorizeAdmin() || auth.AuthorizeDispatcher() || auth.AuthorizeMatchingId(request.GetId())) {
+		return nil, errPermissionDenied
+	}
+
+	rider, err := handler.riderService.Get(ctx, request.GetId())
+
+	if err != nil {
+		return nil, handler.grpcError(ctx, err)
+	}
+
+	return handler.riderMessage(auth, rider), nil
+}
+
+func (handler *GrpcHandler) ListRiders(ctx context.Context, request *pb.ListRidersRequest) (*pb.ListRidersResponse, error) {
+	auth := authorization.GrpcFromContext(ctx)
+
+	if !auth.AuthorizeAdmin() {
+		return nil, errPermissionDenied
+	}
+
+	riders, err := handler.riderService.GetAll(ctx, riderFilterFromMessage(request.GetFilter()))
+
+	if err != nil {
+		return nil, handler.grpcError(ctx, err)
+	}
+
+	response := &pb.ListRidersResponse{Riders: make([]*pb.Rider, 0, len(riders))}
+
+	for _, rider := range riders {
+		response.Riders = append(response.Riders, handler.riderMessage(auth, rider))
+	}
+
+	return response, nil
+}
+
+func (handler *GrpcHandler) CreateRider(ctx context.Context, request *pb.CreateRiderRequest) (*pb.Rider, error) {
+	auth := authorization.GrpcFromContext(ctx)
+
+	if !(auth.AuthorizeAdmin() || auth.AuthorizeMatchingId(request.GetId())) {
+		return nil, errPermissionDenied
+	}
+
+	rider, err := handler.riderService.Create(ctx, request.GetId(), int(request.GetServiceArea()), dimensionsFromMessage(request.GetCapacity()))
+
+	if err != nil {
+		return nil, handler.grpcError(ctx, err)
+	}
+
+	return handler.riderMessage(auth, rider), nil
+}
+
+func (handler *GrpcHandler) UpdateRider(ctx context.Context, request *pb.UpdateRiderRequest) (*pb.Rider, error) {
+	auth := authorization.GrpcFromContext(ctx)
+
+	if !(auth.AuthorizeAdmin() || auth.AuthorizeMatchingId(request.GetId())) {
+		return nil, errPermissionDenied
+	}
+
+	rider, err := handler.riderService.Update(ctx, request.GetId(), int(request.GetStatus()), int(request.GetServiceArea()), dimensionsFromMessage(request.GetCapacity()), int(request.GetVersion()))
+
+	if err != nil {
+		return nil, handler.grpcError(ctx, err)
+	}
+
+	return handler.riderMessage(auth, rider), nil
+}
+
+func (handler *GrpcHandler) UpdateRiderLocation(ctx context.Context, request *pb.UpdateRiderLocationRequest) (*pb.Rider, error) {
+	auth := authorization.GrpcFromContext(ctx)
+
+	if !(auth.AuthorizeAdmin() || auth.AuthorizeMatchingId(request.GetId())) {
+		return nil, errPermissionDenied
+	}
+
+	if request.GetLocation() == nil {
+		return nil, handler.grpcError(ctx, domain.NewValidationError(domain.FieldError{Field: "location", Message: "is required"}))
+	}
+
+	rider, err := handler.riderService.UpdateLocation(ctx, request.GetId(), locationFixFromMessage(request))
+
+	if err != nil {
+		return nil, handler.grpcError(ctx, err)
+	}
+
+	return handler.riderMessage(auth, rider), nil
+}
+
+// WatchRiders polls the riders that match the filter at every watch interval and sends the ones that changed.
+// Polling picks up changes made through any API and any instance, once they are written to the database.
+func (handler *GrpcHandler) WatchRiders(request *pb.WatchRidersRequest, stream pb.RiderService_WatchRidersServer) error {
+	ctx := stream.Context()
+	auth := authorization.GrpcFromContext(ctx)
+
+	if !(auth.AuthorizeAdmin() || auth.AuthorizeDispatcher()) {
+		return errPermissionDenied
+	}
+
+	interval := handler.config.Grpc.WatchInterval
+
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	filter := riderFilterFromMessage(request.GetFilter())
+	sent := make(map[string]*pb.Rider)
+
+	for {
+		if err := handler.sendRiderChanges(ctx, auth, filter, sent, stream); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendRiderChanges sends the riders that differ from what was sent before, and the riders that no longer match the filter.
+func (handler *GrpcHandler) sendRiderChanges(ctx context.Context, auth *authorization.GrpcAuthorization, filter domain.RiderFilter, sent map[string]*pb.Rider, stream pb.RiderService_WatchRidersServer) error {
+	matching := make(map[string]bool, len(sent))
+
+	err := handler.riderService.StreamAll(ctx, filter, func(rider domain.Rider) error {
+		message := handler.riderMessage(auth, rider)
+		matching[message.Id] = true
+
+		if previous, exists := sent[message.Id]; exists && proto.Equal(previous, message) {
+			return nil
+		}
+
+		sent[message.Id] = message
+
+		return stream.Send(&pb.WatchRidersResponse{Rider: message})
+	})
+
+	if err != nil {
+		return handler.grpcError(ctx, err)
+	}
+
+	for id, message := range sent {
+		if matching[id] {
+			continue
+		}
+
+		delete(sent, id)
+
+		if err := stream.Send(&pb.WatchRidersResponse{Rider: message, Removed: true}); err != nil {
+			return handler.grpcError(ctx, err)
+		}
+	}
+
+	return nil
+}
+
+var errPermissionDenied = status.Error(codes.PermissionDenied, "not allowed to access this rider")
+
+// grpcError converts an error returned by the core into a status, like writeError does for REST.
+// Validation errors carry their fields as a BadRequest detail.
+func (handler *GrpcHandler) grpcError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+
+	if _, isStatus := status.FromError(err); isStatus {
+		return err
+	}
+
+	var domainErr *domain.Error
+
+	if !errors.As(err, &domainErr) {
+		handler.logger.Error(ctx, err.Error())
+		return status.Error(codes.Internal, "")
+	}
+
+	switch domainErr.Kind {
+	case domain.ErrorKindNotFound:
+		return status.Error(codes.NotFound, domainErr.Message)
+	case domain.ErrorKindValidation:
+		return validationStatus(domainErr)
+	case domain.ErrorKindConflict:
+		if errors.Is(err, domain.ErrVersionConflict) {
+			return status.Error(codes.Aborted, domainErr.Message)
+		}
+		return status.Error(codes.AlreadyExists, domainErr.Message)
+	case domain.ErrorKindForbidden:
+		return status.Error(codes.PermissionDenied, domainErr.Message)
+	case domain.ErrorKindUnavailable:
+		handler.logger.Error(ctx, err.Error())
+		return status.Error(codes.Unavailable, domainErr.Message)
+	default:
+		handler.logger.Error(ctx, err.Error())
+		return status.Error(codes.Internal, "")
+	}
+}
+
+func validationStatus(domainErr *domain.Error) error {
+	st := status.New(codes.InvalidArgument, domainErr.Message)
+	badRequest := &errdetails.BadRequest{}
+
+	for _, field := range domainErr.Fields {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field.Field,
+			Description: field.Message,
+		})
+	}
+
+	if detailed, err := st.WithDetails(badRequest); err == nil {
+		st = detailed
+	}
+
+	return st.Err()
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"rider-service/pkg/authorization"
+	"rider-service/pkg/logging"
+	"strings"
+	"time"
+)
+
+// healthService is the prefix of the health check methods, which load balancers call without credentials.
+const healthService = "/grpc.health.v1.Health/"
+
+var errUnauthenticated = status.Error(codes.Unauthenticated, "x-user-id or x-user-claims metadata is required")
+
+// UnaryAuthInterceptor rejects calls without a user or claims and stores the authorization in the context of the call.
+// Whether the user may do the call is decided by the handler, like the REST handlers do.
+func UnaryAuthInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if strings.HasPrefix(info.FullMethod, healthService) {
+		return handler(ctx, req)
+	}
+
+	auth := authorization.NewGrpc(ctx)
+
+	if !auth.Authenticated() {
+		return nil, errUnauthenticated
+	}
+
+	return handler(authorization.WithGrpc(ctx, auth), req)
+}
+
+// StreamAuthInterceptor does the same as UnaryAuthInterceptor for streaming calls.
+func StreamAuthInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if strings.HasPrefix(info.FullMethod, healthService) {
+		return handler(srv, stream)
+	}
+
+	auth := authorization.NewGrpc(stream.Context())
+
+	if !auth.Authenticated() {
+		return errUnauthenticated
+	}
+
+	return handler(srv, contextStream{ServerStream: stream, ctx: authorization.WithGrpc(stream.Context(), auth)})
+}
+
+// contextStream replaces the context of a stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream contextStream) Context() context.Context {
+	return stream.ctx
+}
+
+// UnaryLoggingInterceptor logs every call with its status code and duration. Successful calls are only
+// logged at debug level, as the location updates would flood the log otherwise.
+func UnaryLoggingInterceptor(logger logging.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, logger, info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+// StreamLoggingInterceptor logs every streaming call like UnaryLoggingInterceptor, once the stream ended.
+func StreamLoggingInterceptor(logger logging.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, stream)
+		logCall(stream.Context(), logger, info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+func logCall(ctx context.Context, logger logging.Logger, method string, start time.Time, err error) {
+	code := status.Code(err)
+	keysAndValues := []interface{}{"method", method, "code", code.String(), "duration", time.Since(start)}
+
+	if code == codes.OK {
+		logger.Debug(ctx, "grpc call", keysAndValues...)
+		return
+	}
+
+	logger.Warning(ctx, "grpc call failed", append(keysAndValues, "error", status.Convert(err).Message())...)
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"rider-service/internal/core/domain"
+	"rider-service/pkg/pb"
+)
+
+var locationPrecisionMessages = map[domain.LocationPrecision]pb.LocationPrecision{
+	domain.PrecisionExact:  pb.LocationPrecision_LOCATION_PRECISION_EXACT,
+	domain.PrecisionCoarse: pb.LocationPrecision_LOCATION_PRECISION_COARSE,
+	domain.PrecisionHidden: pb.LocationPrecision_LOCATION_PRECISION_HIDDEN,
+}
+
+// riderMessage creates the message of a rider whose location was already reduced to the precision, like dto.CreateRiderResponse.
+func riderMessage(rider domain.Rider, precision domain.LocationPrecision) *pb.Rider {
+	message := &pb.Rider{
+		Id: rider.UserID,
+		User: &pb.User{
+			Id:       rider.User.ID,
+			Name:     rider.User.Name,
+			LastName: rider.User.LastName,
+		},
+		Status: int32(rider.Status),
+		ServiceArea: &pb.ServiceArea{
+			Id:         int32(rider.ServiceArea.ID),
+			Identifier: rider.ServiceArea.Identifier,
+		},
+		Capacity:          dimensionsMessage(rider.Capacity),
+		VehicleType:       string(rider.VehicleType),
+		DeliveryId:        rider.DeliveryID,
+		RemainingCapacity: dimensionsMessage(rider.RemainingCapacity()),
+		LocationPrecision: locationPrecisionMessages[precision],
+		Telemetry:         telemetryMessage(rider.Telemetry),
+		LowBattery:        rider.Telemetry.HasLowBattery(),
+		Version:           int32(rider.Version),
+	}
+
+	if precision != domain.PrecisionHidden {
+		message.Location = &pb.Location{Latitude: rider.Location.Latitude, Longitude: rider.Location.Longitude}
+	}
+
+	if rider.LastSeenAt != nil {
+		message.LastSeenAt = timestamppb.New(*rider.LastSeenAt)
+	}
+
+	return message
+}
+
+func dimensionsMessage(dimensions domain.Dimensions) *pb.Dimensions {
+	return &pb.Dimensions{
+		Width:  int32(dimensions.Width),
+		Height: int32(dimensions.Height),
+		Depth:  int32(dimensions.Depth),
+	}
+}
+
+// dimensionsFromMessage returns the zero dimensions for a message that was not set.
+func dimensionsFromMessage(message *pb.Dimensions) domain.Dimensions {
+	return domain.Dimensions{
+		Width:  int(message.GetWidth()),
+		Height: int(message.GetHeight()),
+		Depth:  int(message.GetDepth()),
+	}
+}
+
+func telemetryMessage(telemetry domain.Telemetry) *pb.Telemetry {
+	message := &pb.Telemetry{
+		Accuracy: telemetry.Accuracy,
+		Speed:    telemetry.Speed,
+		Heading:  telemetry.Heading,
+		Altitude: telemetry.Altitude,
+	}
+
+	if telemetry.Battery != nil {
+		battery := int32(*telemetry.Battery)
+		message.Battery = &battery
+	}
+
+	return message
+}
+
+func telemetryFromMessage(message *pb.Telemetry) domain.Telemetry {
+	if message == nil {
+		return domain.Telemetry{}
+	}
+
+	telemetry := domain.Telemetry{
+		Accuracy: message.Accuracy,
+		Speed:    message.Speed,
+		Heading:  message.Heading,
+		Altitude: message.Altitude,
+	}
+
+	if message.Battery != nil {
+		battery := int(*message.Battery)
+		telemetry.Battery = &battery
+	}
+
+	return telemetry
+}
+
+func locationFixFromMessage(request *pb.UpdateRiderLocationRequest) domain.LocationFix {
+	fix := domain.LocationFix{
+		Location: domain.Location{
+			Latitude:  request.GetLocation().GetLatitude(),
+			Longitude: request.GetLocation().GetLongitude(),
+		},
+		Sequence:  request.GetSequence(),
+		Telemetry: telemetryFromMessage(request.GetTelemetry()),
+		Mocked:    request.GetMocked(),
+	}
+
+	if request.GetTimestamp() != nil {
+		fix.Timestamp = request.GetTimestamp().AsTime()
+	}
+
+	return fix
+}
+
+func riderFilterFromMessage(message *pb.RiderFilter) domain.RiderFilter {
+	filter := domain.RiderFilter{ServiceAreaID: int(message.GetServiceArea())}
+
+	if message != nil && message.Status != nil {
+		status := int(*message.Status)
+		filter.Status = &status
+	}
+
+	return filter
+}
@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"rider-service/config"
+	"rider-service/internal/core/domain"
+	"rider-service/internal/mock"
+	"rider-service/pkg/logging"
+	"rider-service/pkg/pb"
+	"testing"
+)
+
+type GrpcHandlerTestSuite struct {
+	suite.Suite
+	MockService *mock.RiderService
+	Server      *grpc.Server
+	Conn        *grpc.ClientConn
+	Client      pb.RiderServiceClient
+	Cfg         *config.Config
+	TestData    struct {
+		Rider domain.Rider
+	}
+}
+
+func (suite *GrpcHandlerTestSuite) SetupSuite() {
+	cfgPath := "../../test/rider.config"
+	cfg, err := config.UseConfig(cfgPath)
+
+	if err != nil {
+		panic(errors.WithStack(err))
+	}
+
+	logger := logging.MockLogger{}
+
+	mockService := new(mock.RiderService)
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryLoggingInterceptor(logger), UnaryAuthInterceptor),
+		grpc.ChainStreamInterceptor(StreamLoggingInterceptor(logger), StreamAuthInterceptor),
+	)
+
+	grpcHandler := NewGrpcHandler(mockService, server, logger, cfg)
+	grpcHandler.SetupEndpoints()
+	grpcHandler.SetupHealthprobe()
+
+	listener := bufconn.Listen(1024 * 1024)
+	go func() { _ = server.Serve(listener) }()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+
+	if err != nil {
+		panic(errors.WithStack(err))
+	}
+
+	suite.Cfg = cfg
+	suite.MockService = mockService
+	suite.Server = server
+	suite.Conn = conn
+	suite.Client = pb.NewRiderServiceClient(conn)
+	suite.TestData.Rider = domain.Rider{
+		UserID: "test-id",
+		User: domain.User{
+			ID:       "test-id",
+			Name:     "test-name",
+			LastName: "test-lastname",
+		},
+		Status:        domain.StatusAvailable,
+		ServiceAreaID: 1,
+		ServiceArea: domain.ServiceArea{
+			ID:         1,
+			Identifier: "test-area",
+		},
+		Capacity: domain.Dimensions{
+			Width:  100,
+			Height: 100,
+			Depth:  100,
+		},
+		Location: domain.Location{
+			Latitude:  1,
+			Longitude: 2,
+		},
+		Version: 1,
+	}
+}
+
+func (suite *GrpcHandlerTestSuite) TearDownSuite() {
+	_ = suite.Conn.Close()
+	suite.Server.Stop()
+}
+
+func (suite *GrpcHandlerTestSuite) SetupTest() {
+	suite.MockService.ExpectedCalls = nil
+	suite.MockService.Calls = nil
+}
+
+// as returns a context that calls as the user with the claims.
+func as(userId string, claims string) context.Context {
+	md := metadata.Pairs("x-user-id", userId)
+
+	if claims != "" {
+		md.Set("x-user-claims", claims)
+	}
+
+	return metadata.NewOutgoingContext(context.Background(), md)
+}
+
+func (suite *GrpcHandlerTestSuite) TestGrpcHandler_GetRider() {
+	suite.MockService.On("Get", suite.TestData.Rider.UserID).Return(suite.TestData.Rider, nil)
+
+	rider, err := suite.Client.GetRider(as("test-id", ""), &pb.GetRiderRequest{Id: "test-id"})
+
+	suite.NoError(err)
+	suite.Equal(suite.TestData.Rider.UserID, rider.Id)
+	suite.Equal(suite.TestData.Rider.User.Name, rider.User.Name)
+	suite.EqualValues(suite.TestData.Rider.ServiceArea.ID, rider.ServiceArea.Id)
+	suite.EqualValues(suite.TestData.Rider.Capacity.Width, rider.Capacity.Width)
+	suite.EqualValues(suite.TestData.Rider.Version, rider.Version)
+	suite.Equal(pb.LocationPrecision_LOCATION_PRECISION_COARSE, rider.LocationPrecision)
+	suite.Equal(1.005, rider.Location.Latitude)
+	suite.Equal(2.005, rider.Location.Longitude)
+}
+
+func (suite *GrpcHandlerTestSuite) TestGrpcHandler_GetRider_DispatcherSeesExactLocationOnDelivery() {
+	rider := suite.TestData.Rider
+	rider.Status = domain.StatusDelivering
+	suite.MockService.On("Get", rider.UserID).Return(rider, nil)
+
+	response, err := suite.Client.GetRider(as("dispatch-id", `{"dispatcher": true}`), &pb.GetRiderRequest{Id: rider.UserID})
+
+	suite.NoError(err)
+	suite.Equal(pb.LocationPrecision_LOCATION_PRECISION_EXACT, response.LocationPrecision)
+	suite.Equal(rider.Location.Latitude, response.Location.Latitude)
+	suite.Equal(rider.Location.Longitude, response.Location.Longitude)
+}
+
+func (suite *GrpcHandlerTestSuite) TestGrpcHandler_GetRider_OfflineHidesLocation() {
+	rider := suite.TestData.Rider
+	rider.Status = domain.StatusOffline
+	suite.MockService.On("Get", rider.UserID).Return(rider, nil)
+
+	response, err := suite.Client.GetRider(as("admin-id", `{"admin": true}`), &pb.GetRiderRequest{Id: rider.UserID})
+
+	suite.NoError(err)
+	suite.Equal(pb.LocationPrecision_LOCATION_PRECISION_HIDDEN, response.LocationPrecision)
+	suite.Nil(response.Location)
+}
+
+func (suite *GrpcHandlerTestSuite) TestGrpcHandler_GetRider_Unauthenticated() {
+	_, err := suite.Client.GetRider(context.Background(), &pb.GetRiderRequest{Id: "test-id"})
+
+	suite.Equal(codes.Unauthenticated, status.Code(err))
+	suite.MockService.AssertNotCalled(suite.T(), "Get", "test-id")
+}
+
+func (suite *GrpcHandlerTestSuite) TestGrpcHandler_GetRider_OtherRider() {
+	_, err := suite.Client.GetRider(as("other-id", ""), &pb.GetRiderRequest{Id: "test-id"})
+
+	suite.Equal(codes.PermissionDenied, status.Code(err))
+	suite.MockService.AssertNotCalled(suite.T(), "Get", "test-id")
+}
+
+func (suite *GrpcHandlerTestSuite) TestGrpcHandler_GetRider_NotFound() {
+	suite.MockService.On("Get", "test-id").Return(domain.Rider{}, domain.ErrNotFound)
+
+	_, err := suite.Client.GetRider(as("test-id", ""), &pb.GetRiderRequest{Id: "test-id"})
+
+	suite.Equal(codes.NotFound, status.Code(err))
+}
+
+func (suite *GrpcHandlerTestSuite) TestGrpcHandler_GetRider_InternalError() {
+	suite.MockService.On("Get", "test-id").Return(domain.Rider{}, errors.New("connection refused"))
+
+	_, err := suite.Client.GetRider(as("test-id", ""), &pb.GetRiderRequest{Id: "test-id"})
+
+	suite.Equal(codes.Internal, status.Code(err))
+	suite.Empty(status.Convert(err).Message(), "internal errors are not passed on to the caller")
+}
+
+func (suite *GrpcHandlerTestSuite) TestGrpcHandler_ListRiders() {
+	available := domain.StatusAvailable
+	availableMessage := int32(available)
+	suite.MockService.On("GetAll", domain.RiderFilter{Status: &available, ServiceAreaID: 1}).Return([]domain.Rider{suite.TestData.Rider}, nil)
+
+	response, err := suite.Client.ListRiders(as("admin-id", `{"admin": true}`), &pb.ListRidersRequest{
+		Filter: &pb.RiderFilter{Status: &availableMessage, ServiceArea: 1},
+	})
+
+	suite.NoError(err)
+	suite.Len(response.Riders, 1)
+	suite.Equal(suite.TestData.Rider.UserID, response.Riders[0].Id)
+}
+
+func (suite *GrpcHandlerTestSuite) TestGrpcHandler_ListRiders_NotAdmin() {
+	_, err := suite.Client.ListRiders(as("test-id", ""), &pb.ListRidersRequest{})
+
+	suite.Equal(codes.PermissionDenied, status.Code(err))
+}
+
+func (suite *GrpcHandlerTestSuite) TestGrpcHandler_CreateRider() {
+	capacity := domain.Dimensions{Width: 10, Height: 20, Depth: 30}
+	suite.MockService.On("Create", "test-id", 1, capacity).Return(suite.TestData.Rider, nil)
+
+	rider, err := suite.Client.CreateRider(as("test-id", ""), &pb.CreateRiderRequest{
+		Id:          "test-id",
+		ServiceArea: 1,
+		Capacity:    &pb.Dimensions{Width: 10, Height: 20, Depth: 30},
+	})
+
+	suite.NoError(err)
+	suite.Equal(suite.TestData.Rider.UserID, rider.Id)
+}
+
+func (suite *GrpcHandlerTestSuite) TestGrpcHandler_CreateRider_AlreadyExists() {
+	suite.MockService.On("Create", "test-id", 1, domain.Dimensions{}).Return(domain.Rider{}, domain.NewConflictError("rider test-id already exists"))
+
+	_, err := suite.Client.CreateRider(as("test-id", ""), &pb.CreateRiderRequest{Id: "test-id", ServiceArea: 1})
+
+	suite.Equal(codes.AlreadyExists, status.Code(err))
+}
+
+func (suite *GrpcHandlerTestSuite) TestGrpcHandler_UpdateRider() {
+	suite.MockService.On("Update", "test-id", domain.StatusAvailable, 2, domain.Dimensions{}, 1).Return(suite.TestData.Rider, nil)
+
+	rider, err := suite.Client.UpdateRider(as("test-id", ""), &pb.UpdateRiderRequest{
+		Id:          "test-id",
+		Status:      domain.StatusAvailable,
+		ServiceArea: 2,
+		Version:     1,
+	})
+
+	suite.NoError(err)
+	suite.Equal(suite.TestData.Rider.UserID, rider.Id)
+}
+
+func (suite *GrpcHandlerTestSuite) TestGrpcHandler_UpdateRider_VersionConflict() {
+	suite.MockService.On("Update", "test-id", domain.StatusAvailable, 0, domain.Dimensions{}, 1).Return(domain.Rider{}, domain.ErrVersionConflict)
+
+	_, err := suite.Client.UpdateRider(as("test-id", ""), &pb.UpdateRiderRequest{Id: "test-id", Status: domain.StatusAvailable, Version: 1})
+
+	suite.Equal(codes.Aborted, status.Code(err))
+}
+
+func (suite *GrpcHandlerTestSuite) TestGrpcHandler_UpdateRiderLocation() {
+	battery := 80
+	batteryMessage := int32(battery)
+	expected := domain.LocationFix{
+		Location:  domain.Location{Latitude: 2, Longitude: 3},
+		Sequence:  4,
+		Telemetry: domain.Telemetry{Battery: &battery},
+	}
+	suite.MockService.On("UpdateLocation", "test-id", expected).Return(suite.TestData.Rider, nil)
+
+	rider, err := suite.Client.UpdateRiderLocation(as("test-id", ""), &pb.UpdateRiderLocationRequest{
+		Id:        "test-id",
+		Location:  &pb.Location{Latitude: 2, Longitude: 3},
+		Sequence:  4,
+		Telemetry: &pb.Telemetry{Battery: &batteryMessage},
+	})
+
+	suite.NoError(err)
+	suite.Equal(suite.TestData.Rider.UserID, rider.Id)
+}
+
+func (suite *GrpcHandlerTestSuite) TestGrpcHandler_UpdateRiderLocation_InvalidLocation() {
+	fields := []domain.FieldError{{Field: "location.latitude", Message: "must be between -90 and 90"}}
+	suite.MockService.On("UpdateLocation", "test-id", domain.LocationFix{Location: domain.Location{Latitude: 100, Longitude: 3}, Telemetry: domain.Telemetry{}}).
+		Return(domain.Rider{}, domain.NewValidationError(fields...))
+
+	_, err := suite.Client.UpdateRiderLocation(as("test-id", ""), &pb.UpdateRiderLocationRequest{
+		Id:       "test-id",
+		Location: &pb.Location{Latitude: 100, Longitude: 3},
+	})
+
+	st := status.Convert(err)
+	suite.Equal(codes.InvalidArgument, st.Code())
+	suite.Require().Len(st.Details(), 1)
+
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	suite.Require().True(ok)
+	suite.Require().Len(badRequest.FieldViolations, 1)
+	suite.Equal("location.latitude", badRequest.FieldViolations[0].Field)
+	suite.Equal("must be between -90 and 90", badRequest.FieldViolations[0].Description)
+}
+
+func (suite *GrpcHandlerTestSuite) TestGrpcHandler_UpdateRiderLocation_MissingLocation() {
+	_, err := suite.Client.UpdateRiderLocation(as("test-id", ""), &pb.UpdateRiderLocationRequest{Id: "test-id"})
+
+	suite.Equal(codes.InvalidArgument, status.Code(err))
+	suite.MockService.AssertNotCalled(suite.T(), "UpdateLocation", "test-id", domain.LocationFix{})
+}
+
+func (suite *GrpcHandlerTestSuite) TestGrpcHandler_WatchRiders() {
+	moved := suite.TestData.Rider
+	moved.Location = domain.Location{Latitude: 1.5, Longitude: 2.5}
+
+	suite.MockService.On("StreamAll", domain.RiderFilter{}).Return([]domain.Rider{suite.TestData.Rider}, nil).Once()
+	suite.MockService.On("StreamAll", domain.RiderFilter{}).Return([]domain.Rider{suite.TestData.Rider}, nil).Once()
+	suite.MockService.On("StreamAll", domain.RiderFilter{}).Return([]domain.Rider{moved}, nil).Once()
+	suite.MockService.On("StreamAll", domain.RiderFilter{}).Return([]domain.Rider{}, nil)
+
+	ctx, cancel := context.WithCancel(as("dispatch-id", `{"dispatcher": true}`))
+	defer cancel()
+
+	stream, err := suite.Client.WatchRiders(ctx, &pb.WatchRidersRequest{})
+	suite.Require().NoError(err)
+
+	first, err := stream.Recv()
+	suite.Require().NoError(err)
+	suite.Equal(suite.TestData.Rider.UserID, first.Rider.Id)
+	suite.False(first.Removed)
+
+	second, err := stream.Recv()
+	suite.Require().NoError(err)
+	suite.Equal(1.505, second.Rider.Location.Latitude, "an unchanged rider is not sent again")
+	suite.False(second.Removed)
+
+	third, err := stream.Recv()
+	suite.Require().NoError(err)
+	suite.Equal(suite.TestData.Rider.UserID, third.Rider.Id)
+	suite.True(third.Removed)
+}
+
+func (suite *GrpcHandlerTestSuite) TestGrpcHandler_WatchRiders_NotDispatcher() {
+	stream, err := suite.Client.WatchRiders(as("test-id", ""), &pb.WatchRidersRequest{})
+	suite.Require().NoError(err)
+
+	_, err = stream.Recv()
+
+	suite.Equal(codes.PermissionDenied, status.Code(err))
+}
+
+func (suite *GrpcHandlerTestSuite) TestGrpcHandler_HealthCheck_WithoutCredentials() {
+	response, err := grpc_health_v1.NewHealthClient(suite.Conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+
+	suite.NoError(err)
+	suite.Equal(grpc_health_v1.HealthCheckResponse_SERVING, response.Status)
+}
+
+func TestUnit_GrpcHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(GrpcHandlerTestSuite))
+}
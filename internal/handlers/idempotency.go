@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"rider-service/internal/core/domain"
+	"time"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	defaultIdempotencyTTL     = 24 * time.Hour
+)
+
+// idempotentWriter keeps a copy of the response body so it can be stored for replays.
+type idempotentWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotentWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotentWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotent makes a request safe to retry when the client sends an Idempotency-Key header.
+// The first response for a key is stored and replayed for retries with the same body,
+// reusing the key for a different body is rejected. Server errors are not stored so they can be retried.
+func (handler *HTTPHandler) idempotent(c *gin.Context) {
+	key := c.GetHeader(idempotencyKeyHeader)
+
+	if key == "" {
+		c.Next()
+		return
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		writeBadRequest(c, "Idempotency-Key header is too long")
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+
+	if err != nil {
+		writeBadRequest(c, "could not read request body")
+		return
+	}
+
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	ctx := c.Request.Context()
+	hash := sha256.Sum256(body)
+	requestHash := hex.EncodeToString(hash[:])
+	scopedKey := c.GetHeader("X-User-Id") + " " + c.Request.Method + " " + c.Request.URL.Path + " " + key
+
+	record, err := handler.idempotencyRepository.GetIdempotencyRecord(ctx, scopedKey)
+
+	switch {
+	case err == nil:
+		if record.RequestHash != requestHash {
+			writeProblem(c, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request", nil)
+			return
+		}
+
+		c.Header(idempotencyReplayedHeader, "true")
+		c.Data(record.StatusCode, record.ContentType, record.Body)
+		c.Abort()
+		return
+	case !errors.Is(err, domain.ErrNotFound):
+		handler.writeError(c, err)
+		return
+	}
+
+	if _, running := handler.inFlight.LoadOrStore(scopedKey, struct{}{}); running {
+		writeProblem(c, http.StatusConflict, "a request with this Idempotency-Key is still being processed", nil)
+		return
+	}
+
+	defer handler.inFlight.Delete(scopedKey)
+
+	writer := &idempotentWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+
+	c.Next()
+
+	if writer.Status() >= http.StatusInternalServerError {
+		return
+	}
+
+	ttl := handler.config.Idempotency.TTL
+
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+
+	err = handler.idempotencyRepository.SaveIdempotencyRecord(ctx, domain.IdempotencyRecord{
+		Key:         scopedKey,
+		RequestHash: requestHash,
+		StatusCode:  writer.Status(),
+		ContentType: writer.Header().Get("Content-Type"),
+		Body:        writer.body.Bytes(),
+		ExpiresAt:   time.Now().Add(ttl),
+	})
+
+	if err != nil {
+		handler.logger.Error(ctx, "could not store idempotency key", "error", err)
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"rider-service/internal/core/domain"
+	"rider-service/internal/core/interfaces"
+	"time"
+)
+
+const defaultInboxRetention = 7 * 24 * time.Hour
+
+// handleOnce runs handle for a message unless a message with the same id was handled before.
+// Messages without an id can not be recognised when they are redelivered, so they are always handled.
+func handleOnce(ctx context.Context, inbox interfaces.InboxRepository, retention time.Duration, id string, topic string, handle func() error) error {
+	if id == "" {
+		return handle()
+	}
+
+	processed, err := inbox.IsProcessed(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	if processed {
+		return nil
+	}
+
+	if err := handle(); err != nil {
+		return err
+	}
+
+	if retention <= 0 {
+		retention = defaultInboxRetention
+	}
+
+	now := time.Now()
+
+	return inbox.MarkProcessed(ctx, domain.ProcessedMessage{
+		ID:          id,
+		Topic:       topic,
+		ProcessedAt: now,
+		ExpiresAt:   now.Add(retention),
+	})
+}
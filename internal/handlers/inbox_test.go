@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+	"rider-service/internal/core/interfaces"
+	"rider-service/internal/repositories"
+	"testing"
+	"time"
+)
+
+type InboxTestSuite struct {
+	suite.Suite
+	Inbox interfaces.InboxRepository
+}
+
+func (suite *InboxTestSuite) SetupTest() {
+	suite.Inbox = repositories.NewMemoryRepository()
+}
+
+func (suite *InboxTestSuite) TestInbox_HandleOnce_SkipsRedelivery() {
+	calls := 0
+	handle := func() error {
+		calls++
+		return nil
+	}
+
+	suite.NoError(handleOnce(context.Background(), suite.Inbox, time.Hour, "message-id", "user.update", handle))
+	suite.NoError(handleOnce(context.Background(), suite.Inbox, time.Hour, "message-id", "user.update", handle))
+
+	suite.Equal(1, calls)
+}
+
+func (suite *InboxTestSuite) TestInbox_HandleOnce_RetriesFailedMessage() {
+	calls := 0
+	handle := func() error {
+		calls++
+		if calls == 1 {
+			return errors.New("could not handle")
+		}
+		return nil
+	}
+
+	suite.Error(handleOnce(context.Background(), suite.Inbox, time.Hour, "message-id", "user.update", handle))
+	suite.NoError(handleOnce(context.Background(), suite.Inbox, time.Hour, "message-id", "user.update", handle))
+
+	suite.Equal(2, calls)
+}
+
+func (suite *InboxTestSuite) TestInbox_HandleOnce_WithoutId() {
+	calls := 0
+	handle := func() error {
+		calls++
+		return nil
+	}
+
+	suite.NoError(handleOnce(context.Background(), suite.Inbox, time.Hour, "", "user.update", handle))
+	suite.NoError(handleOnce(context.Background(), suite.Inbox, time.Hour, "", "user.update", handle))
+
+	suite.Equal(2, calls)
+}
+
+func (suite *InboxTestSuite) TestInbox_HandleOnce_Expired() {
+	calls := 0
+	handle := func() error {
+		calls++
+		return nil
+	}
+
+	suite.NoError(handleOnce(context.Background(), suite.Inbox, time.Nanosecond, "message-id", "user.update", handle))
+	time.Sleep(time.Millisecond)
+	suite.NoError(handleOnce(context.Background(), suite.Inbox, time.Nanosecond, "message-id", "user.update", handle))
+
+	suite.Equal(2, calls)
+}
+
+func TestUnit_InboxTestSuite(t *testing.T) {
+	suite.Run(t, new(InboxTestSuite))
+}
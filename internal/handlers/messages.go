@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"rider-service/internal/core/domain"
+)
+
+// decodeBody decodes the JSON body of a message. A body that can not be decoded is a validation error, it will not
+// decode on a redelivery either.
+func decodeBody(body []byte, v interface{}) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return domain.NewValidationError(domain.FieldError{Field: "body", Message: err.Error()})
+	}
+
+	return nil
+}
+
+// redeliver reports whether a message that could not be handled should be handled again. Invalid messages and
+// messages about something that does not exist fail the same way every time, so they are dropped or dead-lettered.
+func redeliver(err error) bool {
+	return !errors.Is(err, domain.ErrValidation) && !errors.Is(err, domain.ErrNotFound)
+}
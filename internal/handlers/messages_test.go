@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	mock2 "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"rider-service/internal/core/domain"
+	"rider-service/internal/mock"
+	"testing"
+)
+
+type MessagesTestSuite struct {
+	suite.Suite
+	MockRiderService       *mock.RiderService
+	MockServiceAreaService *mock.ServiceAreaService
+	RabbitMQHandler        *rabbitmqHandler
+	AzureHandler           *azureHandler
+}
+
+func (suite *MessagesTestSuite) SetupTest() {
+	suite.MockRiderService = new(mock.RiderService)
+	suite.MockServiceAreaService = new(mock.ServiceAreaService)
+	suite.RabbitMQHandler = &rabbitmqHandler{service: suite.MockRiderService, serviceAreaService: suite.MockServiceAreaService}
+	suite.AzureHandler = &azureHandler{service: suite.MockRiderService, serviceAreaService: suite.MockServiceAreaService}
+}
+
+func (suite *MessagesTestSuite) TestMessages_DeliveryOfUnknownRider() {
+	suite.MockRiderService.On("HandleDeliveryEvent", mock2.Anything).Return(domain.Rider{}, domain.NewNotFoundError("rider unknown not found"))
+
+	body := []byte(`{"id": "delivery-id", "riderId": "unknown"}`)
+
+	err := DeliveryChanged("delivery.assigned", body, suite.RabbitMQHandler)
+	suite.ErrorIs(err, domain.ErrNotFound)
+	suite.False(redeliver(err), "the rider will not exist on a redelivery either")
+
+	err = deliveryChanged("delivery.assigned", body, suite.AzureHandler)
+	suite.ErrorIs(err, domain.ErrNotFound)
+	suite.False(redeliver(err))
+}
+
+func (suite *MessagesTestSuite) TestMessages_MalformedServiceAreaDelete() {
+	body := []byte(`{"id": "not a number"}`)
+
+	err := ServiceAreaDelete("service_area.delete", body, suite.RabbitMQHandler)
+	suite.ErrorIs(err, domain.ErrValidation)
+	suite.False(redeliver(err), "the body will not decode on a redelivery either")
+
+	err = serviceAreaDelete("service_area.delete", body, suite.AzureHandler)
+	suite.ErrorIs(err, domain.ErrValidation)
+	suite.False(redeliver(err))
+
+	suite.MockServiceAreaService.AssertNotCalled(suite.T(), "DeleteServiceArea", mock2.Anything, mock2.Anything, mock2.Anything)
+}
+
+func (suite *MessagesTestSuite) TestMessages_RedeliverOtherErrors() {
+	suite.True(redeliver(domain.NewUnavailableError(nil, "database is not available")))
+}
+
+func TestUnit_MessagesTestSuite(t *testing.T) {
+	suite.Run(t, new(MessagesTestSuite))
+}
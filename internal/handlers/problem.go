@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"rider-service/internal/core/domain"
+	"rider-service/pkg/authorization"
+	"rider-service/pkg/dto"
+)
+
+const problemContentType = "application/problem+json"
+
+// writeError renders an error returned by the core as a problem details response.
+// Errors that are not domain errors are logged and reported as internal server errors without details.
+func (handler *HTTPHandler) writeError(c *gin.Context, err error) {
+	var domainErr *domain.Error
+
+	if !errors.As(err, &domainErr) {
+		handler.logger.Error(c.Request.Context(), err.Error())
+		writeProblem(c, http.StatusInternalServerError, "", nil)
+		return
+	}
+
+	switch domainErr.Kind {
+	case domain.ErrorKindNotFound:
+		writeProblem(c, http.StatusNotFound, domainErr.Message, nil)
+	case domain.ErrorKindValidation:
+		writeProblem(c, http.StatusBadRequest, domainErr.Message, domainErr.Fields)
+	case domain.ErrorKindConflict:
+		if errors.Is(err, domain.ErrVersionConflict) {
+			writeProblem(c, http.StatusPreconditionFailed, domainErr.Message, nil)
+			return
+		}
+		writeProblem(c, http.StatusConflict, domainErr.Message, nil)
+	case domain.ErrorKindForbidden:
+		writeProblem(c, http.StatusForbidden, domainErr.Message, nil)
+	case domain.ErrorKindUnavailable:
+		handler.logger.Error(c.Request.Context(), err.Error())
+		writeProblem(c, http.StatusServiceUnavailable, domainErr.Message, nil)
+	default:
+		handler.logger.Error(c.Request.Context(), err.Error())
+		writeProblem(c, http.StatusInternalServerError, "", nil)
+	}
+}
+
+func writeProblem(c *gin.Context, status int, detail string, fields []domain.FieldError) {
+	problem := dto.ProblemResponse{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+	}
+
+	if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.HasTraceID() {
+		problem.TraceID = spanContext.TraceID().String()
+	}
+
+	for _, field := range fields {
+		problem.Errors = append(problem.Errors, dto.ProblemFieldError(field))
+	}
+
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(status, problem)
+}
+
+func writeBadRequest(c *gin.Context, detail string) {
+	writeProblem(c, http.StatusBadRequest, detail, nil)
+}
+
+// writeNotAllowed rejects a request the caller may not make. Requests without a user or claims are unauthorized,
+// the gateway did not authenticate them, the others are forbidden.
+func writeNotAllowed(c *gin.Context) {
+	if !authorization.NewRest(c).Authenticated() {
+		writeProblem(c, http.StatusUnauthorized, "X-User-Id or X-User-Claims header is required", nil)
+		return
+	}
+
+	writeProblem(c, http.StatusForbidden, "not allowed to access this rider", nil)
+}
@@ -2,41 +2,57 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"golang.org/x/exp/maps"
 	"rider-service/config"
 	"rider-service/internal/core/domain"
 	"rider-service/internal/core/interfaces"
+	"rider-service/pkg/events"
 	"rider-service/pkg/rabbitmq"
+	"sort"
+	"sync"
 )
 
 type rabbitmqHandler struct {
 	rabbitmq           *rabbitmq.RabbitMQ
 	service            interfaces.RiderService
 	serviceAreaService interfaces.ServiceAreaService
+	inbox              interfaces.InboxRepository
 	handlers           map[string]func(topic string, body []byte, handler *rabbitmqHandler) error
 	config             *config.Config
-	channel            chan bool
+	quit               context.Context
+	stop               context.CancelFunc
+	running            sync.WaitGroup
 }
 
-func NewRabbitMQ(rabbitmq *rabbitmq.RabbitMQ, service interfaces.RiderService, serviceAreaService interfaces.ServiceAreaService, config *config.Config) *rabbitmqHandler {
+func NewRabbitMQ(rabbitmq *rabbitmq.RabbitMQ, service interfaces.RiderService, serviceAreaService interfaces.ServiceAreaService, inbox interfaces.InboxRepository, config *config.Config) *rabbitmqHandler {
+	quit, stop := context.WithCancel(context.Background())
+
 	return &rabbitmqHandler{
 		rabbitmq:           rabbitmq,
 		service:            service,
 		serviceAreaService: serviceAreaService,
+		inbox:              inbox,
 		handlers: map[string]func(topic string, body []byte, handler *rabbitmqHandler) error{
 			"user.create":         UserCreateOrUpdate,
 			"user.update":         UserCreateOrUpdate,
 			"service_area.create": ServiceAreaCreateOrUpdate,
+			"service_area.update": ServiceAreaCreateOrUpdate,
+			"service_area.delete": ServiceAreaDelete,
+			"delivery.assigned":   DeliveryChanged,
+			"delivery.picked_up":  DeliveryChanged,
+			"delivery.completed":  DeliveryChanged,
+			"delivery.cancelled":  DeliveryChanged,
 		},
 		config: config,
+		quit:   quit,
+		stop:   stop,
 	}
 }
 
 func ServiceAreaCreateOrUpdate(topic string, body []byte, handler *rabbitmqHandler) error {
 	var serviceArea domain.ServiceArea
-	if err := json.Unmarshal(body, &serviceArea); err != nil {
+	if err := decodeBody(body, &serviceArea); err != nil {
 		return err
 	}
 
@@ -47,10 +63,31 @@ func ServiceAreaCreateOrUpdate(topic string, body []byte, handler *rabbitmqHandl
 	return nil
 }
 
+func ServiceAreaDelete(topic string, body []byte, handler *rabbitmqHandler) error {
+	var deleted events.ServiceAreaDeletedV1
+	if err := decodeBody(body, &deleted); err != nil {
+		return err
+	}
+
+	return handler.serviceAreaService.DeleteServiceArea(context.Background(), deleted.ID, deleted.Version, deleted.ReassignTo)
+}
+
+func DeliveryChanged(topic string, body []byte, handler *rabbitmqHandler) error {
+	event, err := deliveryEvent(topic, body)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = handler.service.HandleDeliveryEvent(context.Background(), event)
+
+	return err
+}
+
 func UserCreateOrUpdate(topic string, body []byte, handler *rabbitmqHandler) error {
 	var user domain.User
 
-	if err := json.Unmarshal(body, &user); err != nil {
+	if err := decodeBody(body, &user); err != nil {
 		return err
 	}
 
@@ -102,18 +139,26 @@ func (handler *rabbitmqHandler) Listen() {
 		panic(err)
 	}
 
-	handler.channel = make(chan bool)
+	handler.running.Add(1)
 
 	go func() {
-		for msg := range msgs {
+		defer handler.running.Done()
+
+		for {
 			select {
-			case <-handler.channel:
+			case <-handler.quit.Done():
 				return
-			default:
+			case msg, open := <-msgs:
+				if !open {
+					return
+				}
+
 				fun, exist := handler.handlers[msg.RoutingKey]
 
 				if exist {
-					err = fun(msg.RoutingKey, msg.Body, handler)
+					err = handleOnce(context.Background(), handler.inbox, handler.config.Inbox.Retention, msg.MessageId, msg.RoutingKey, func() error {
+						return fun(msg.RoutingKey, msg.Body, handler)
+					})
 					if err == nil {
 						_ = msg.Ack(false)
 						continue
@@ -121,15 +166,26 @@ func (handler *rabbitmqHandler) Listen() {
 				}
 
 				fmt.Println(err)
-				_ = msg.Nack(false, true)
+				// Messages that can never be processed are dropped instead of being redelivered forever.
+				_ = msg.Nack(false, redeliver(err))
 			}
-
 		}
 	}()
 }
 
+// Topics returns the routing keys the handler consumes, sorted.
+func (handler *rabbitmqHandler) Topics() []string {
+	topics := maps.Keys(handler.handlers)
+	sort.Strings(topics)
+
+	return topics
+}
+
+// Quit stops consuming messages and waits for the message that is being handled. Messages that were delivered but
+// not handled yet are redelivered by RabbitMQ.
 func (handler *rabbitmqHandler) Quit() {
-	handler.channel <- true
+	handler.stop()
+	handler.running.Wait()
 }
 
 type MessageHandler struct {
@@ -9,6 +9,8 @@ import (
 	"rider-service/config"
 	"rider-service/internal/core/domain"
 	"rider-service/internal/mock"
+	"rider-service/internal/repositories"
+	"rider-service/pkg/events"
 	"rider-service/pkg/rabbitmq"
 	"testing"
 	"time"
@@ -44,7 +46,7 @@ func (suite *RabbitMQHandlerTestSuite) SetupSuite() {
 		panic(errors.WithStack(err))
 	}
 
-	handler := NewRabbitMQ(rabbitMQ, mockRiderService, mockServiceAreaService, cfg)
+	handler := NewRabbitMQ(rabbitMQ, mockRiderService, mockServiceAreaService, repositories.NewMemoryRepository(), cfg)
 
 	go handler.Listen()
 
@@ -93,6 +95,25 @@ func (suite *RabbitMQHandlerTestSuite) TestHandler_ServiceAreaCreateOrUpdate() {
 	suite.MockServiceAreaService.AssertCalled(suite.T(), "SaveOrUpdateServiceArea", suite.TestData.ServiceArea)
 }
 
+func (suite *RabbitMQHandlerTestSuite) TestHandler_ServiceAreaDelete() {
+	suite.MockServiceAreaService.On("DeleteServiceArea", mock2.Anything, mock2.Anything, mock2.Anything).Return(nil)
+
+	calls := len(suite.MockServiceAreaService.Calls)
+
+	err := publishJson(suite.TestRabbitMQ, suite.Cfg.RabbitMQ.Exchange, "service_area.delete", events.ServiceAreaDeletedV1{
+		ID:         suite.TestData.ServiceArea.ID,
+		Version:    2,
+		ReassignTo: 3,
+	})
+
+	suite.NoError(err)
+
+	for len(suite.MockServiceAreaService.Calls) <= calls {
+	}
+
+	suite.MockServiceAreaService.AssertCalled(suite.T(), "DeleteServiceArea", suite.TestData.ServiceArea.ID, 2, 3)
+}
+
 func (suite *RabbitMQHandlerTestSuite) TestHandler_UserCreateOrUpdate_Create() {
 	suite.MockServiceAreaService.On("SaveOrUpdateServiceArea", mock2.Anything).Return(nil)
 	suite.MockRiderService.On("SaveOrUpdateUser", mock2.Anything).Return(nil)
@@ -121,6 +142,49 @@ func (suite *RabbitMQHandlerTestSuite) TestHandler_UserCreateOrUpdate_Update() {
 	suite.MockRiderService.AssertCalled(suite.T(), "SaveOrUpdateUser", suite.TestData.User)
 }
 
+func (suite *RabbitMQHandlerTestSuite) TestHandler_DeliveryChanged() {
+	suite.MockRiderService.On("HandleDeliveryEvent", mock2.Anything).Return(domain.Rider{}, nil)
+
+	err := publishJson(suite.TestRabbitMQ, suite.Cfg.RabbitMQ.Exchange, "delivery.picked_up", events.DeliveryV1{
+		ID:      "delivery-id",
+		RiderID: suite.TestData.User.ID,
+		Parcel:  &events.Parcel{Width: 10, Height: 20, Depth: 30, Weight: 1.5},
+	})
+
+	suite.NoError(err)
+
+	for len(suite.MockRiderService.Calls) < 1 {
+	}
+
+	suite.MockRiderService.AssertCalled(suite.T(), "HandleDeliveryEvent", domain.DeliveryEvent{
+		Kind:       domain.DeliveryPickedUp,
+		DeliveryID: "delivery-id",
+		RiderID:    suite.TestData.User.ID,
+		Parcel:     domain.Dimensions{Width: 10, Height: 20, Depth: 30},
+		Weight:     1.5,
+	})
+}
+
+func (suite *RabbitMQHandlerTestSuite) TestHandler_DeliveryChanged_UnknownRider() {
+	suite.MockRiderService.On("HandleDeliveryEvent", mock2.Anything).Return(domain.Rider{}, domain.NewNotFoundError("rider unknown not found"))
+
+	calls := len(suite.MockRiderService.Calls)
+
+	err := publishJson(suite.TestRabbitMQ, suite.Cfg.RabbitMQ.Exchange, "delivery.assigned", events.DeliveryV1{
+		ID:      "delivery-id",
+		RiderID: "unknown",
+	})
+
+	suite.NoError(err)
+
+	for len(suite.MockRiderService.Calls) <= calls {
+	}
+
+	time.Sleep(time.Second)
+
+	suite.Len(suite.MockRiderService.Calls, calls+1, "the message is not redelivered")
+}
+
 func publishJson(rabbitmq *rabbitmq.RabbitMQ, exchange, topic string, body interface{}) error {
 	js, err := json.Marshal(body)
 
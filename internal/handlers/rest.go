@@ -10,6 +10,8 @@ import (
 	"rider-service/pkg/authorization"
 	"rider-service/pkg/dto"
 	"rider-service/pkg/logging"
+	"strconv"
+	"sync"
 
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"rider-service/docs"
@@ -18,28 +20,92 @@ import (
 import "github.com/gin-gonic/gin"
 
 type HTTPHandler struct {
-	riderService interfaces.RiderService
-	router       *gin.Engine
-	logger       logging.Logger
-	config       *config.Config
+	riderService          interfaces.RiderService
+	serviceAreaService    interfaces.ServiceAreaService
+	statsService          interfaces.StatsService
+	etaService            interfaces.ETAService
+	idempotencyRepository interfaces.IdempotencyRepository
+	router                *gin.Engine
+	logger                logging.Logger
+	config                *config.Config
+	inFlight              sync.Map
 }
 
-func NewHTTPHandler(riderService interfaces.RiderService, router *gin.Engine, logger logging.Logger, config *config.Config) *HTTPHandler {
+func NewHTTPHandler(riderService interfaces.RiderService, serviceAreaService interfaces.ServiceAreaService, statsService interfaces.StatsService, etaService interfaces.ETAService, idempotencyRepository interfaces.IdempotencyRepository, router *gin.Engine, logger logging.Logger, config *config.Config) *HTTPHandler {
 	return &HTTPHandler{
-		riderService: riderService,
-		router:       router,
-		logger:       logger,
-		config:       config,
+		riderService:          riderService,
+		serviceAreaService:    serviceAreaService,
+		statsService:          statsService,
+		etaService:            etaService,
+		idempotencyRepository: idempotencyRepository,
+		router:                router,
+		logger:                logger,
+		config:                config,
 	}
 }
 
 func (handler *HTTPHandler) SetupEndpoints() {
 	api := handler.router.Group("/api")
 	api.GET("/riders", handler.GetAll)
+	api.GET("/riders/export", handler.Export)
+	api.GET("/riders/eta", handler.GetETAs)
 	api.GET("/riders/:id", handler.Get)
-	api.POST("/riders", handler.Create)
+	api.GET("/riders/:id/eta", handler.GetETA)
+	api.POST("/riders", handler.idempotent, handler.Create)
+	api.POST("/riders/import", handler.idempotent, handler.ImportRiders)
 	api.PUT("/riders/:id", handler.UpdateRider)
-	api.PUT("/riders/:id/location", handler.UpdateLocation)
+	api.PATCH("/riders/:id", handler.PatchRider)
+	api.PUT("/riders/:id/location", handler.idempotent, handler.UpdateLocation)
+	api.PUT("/riders/:id/parcels/:parcel", handler.LoadParcel)
+	api.DELETE("/riders/:id/parcels/:parcel", handler.UnloadParcel)
+	api.GET("/location-anomalies", handler.GetLocationAnomalies)
+	api.GET("/service-areas", handler.GetServiceAreas)
+	api.GET("/service-areas/stats", handler.GetStatsSummary)
+	api.GET("/service-areas/:id/riders", handler.GetServiceAreaRiders)
+	api.GET("/service-areas/:id/stats", handler.GetServiceAreaStats)
+	api.GET("/service-areas/:id/heatmap", handler.GetServiceAreaHeatmap)
+
+	// gin can not route a literal colon next to a parameter, so these endpoints share a parameter and are dispatched on its value.
+	api.POST("/riders/:id", handler.idempotent, routeParam("id", map[string]gin.HandlerFunc{
+		"locations:batch": handler.UpdateLocationBatches,
+	}))
+	api.POST("/riders/:id/:action", handler.idempotent, routeParam("action", map[string]gin.HandlerFunc{
+		"locations:batch": handler.UpdateLocationBatch,
+		"heartbeat":       handler.Heartbeat,
+	}))
+}
+
+// routeParam calls the handler registered for the value of the path parameter, other values are not found.
+func routeParam(param string, routes map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route, exists := routes[c.Param(param)]
+
+		if !exists {
+			writeProblem(c, http.StatusNotFound, "", nil)
+			return
+		}
+
+		route(c)
+	}
+}
+
+// riderResponse creates the response of a rider with its location as precise as the caller may see it.
+func (handler *HTTPHandler) riderResponse(c *gin.Context, rider domain.Rider) dto.RiderResponse {
+	auth := authorization.NewRest(c)
+	privacy := domain.LocationPrivacy{GridSize: handler.config.Privacy.CoarseGridSize}
+	precision := privacy.Precision(rider, auth.AuthorizeAdmin() || auth.AuthorizeDispatcher())
+
+	return dto.CreateRiderResponse(privacy.Apply(rider, precision), precision)
+}
+
+func (handler *HTTPHandler) riderResponses(c *gin.Context, riders []domain.Rider) []dto.RiderResponse {
+	response := make([]dto.RiderResponse, 0, len(riders))
+
+	for _, rider := range riders {
+		response = append(response, handler.riderResponse(c, rider))
+	}
+
+	return response
 }
 
 func (handler *HTTPHandler) SetupSwagger() {
@@ -60,8 +126,14 @@ func (handler *HTTPHandler) SetupHealthprobe() {
 // @Schemes
 // @Description  gets all riders in the system
 // @Accept       json
+// @Param        status       query  int  false  "Only riders with this status"  minimum(0)
+// @Param        serviceArea  query  int  false  "Only riders in this service area"  minimum(1)
+// @Param        fitsWidth    query  int  false  "Only riders with room left for a parcel this wide, with fitsHeight and fitsDepth"  minimum(1)
+// @Param        fitsHeight   query  int  false  "Only riders with room left for a parcel this high, with fitsWidth and fitsDepth"  minimum(1)
+// @Param        fitsDepth    query  int  false  "Only riders with room left for a parcel this deep, with fitsWidth and fitsHeight"  minimum(1)
 // @Produce      json
 // @Success      200  {object}  dto.RiderListResponse
+// @Failure      default  {object}  dto.ProblemResponse
 // @Router       /api/riders [get]
 func (handler *HTTPHandler) GetAll(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -69,11 +141,17 @@ func (handler *HTTPHandler) GetAll(c *gin.Context) {
 	defer span.End()
 
 	if authorization.NewRest(c).AuthorizeAdmin() {
-		riders, err := handler.riderService.GetAll(ctx)
+		query := dto.QueryRiders{}
+
+		if err := bindQuery(c, &query); err != nil {
+			handler.writeError(c, err)
+			return
+		}
+
+		riders, err := handler.riderService.GetAll(ctx, query.ToDomain())
 
 		if err != nil {
-			handler.logger.Error(ctx, err.Error(), "error", err)
-			c.AbortWithStatus(http.StatusNotFound)
+			handler.writeError(c, err)
 			return
 		}
 
@@ -81,17 +159,143 @@ func (handler *HTTPHandler) GetAll(c *gin.Context) {
 		return
 	}
 
-	c.AbortWithStatus(http.StatusUnauthorized)
+	writeNotAllowed(c)
+
+}
+
+// Export godoc
+// @Summary  export riders
+// @Schemes
+// @Description  streams the riders as a GeoJSON FeatureCollection of points, CSV or newline delimited rider responses, with the same filters as the rider list. Locations are as precise as admins may see them
+// @Param        format       query  string  false  "Export format, geojson by default"  Enums(geojson, csv, ndjson)
+// @Param        status       query  int     false  "Only riders with this status"  minimum(0)
+// @Param        serviceArea  query  int     false  "Only riders in this service area"  minimum(1)
+// @Param        fitsWidth    query  int     false  "Only riders with room left for a parcel this wide, with fitsHeight and fitsDepth"  minimum(1)
+// @Param        fitsHeight   query  int     false  "Only riders with room left for a parcel this high, with fitsWidth and fitsDepth"  minimum(1)
+// @Param        fitsDepth    query  int     false  "Only riders with room left for a parcel this deep, with fitsWidth and fitsHeight"  minimum(1)
+// @Produce      application/geo+json
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Success      200  {object}  dto.RiderFeature  "One feature of the collection"
+// @Failure      default  {object}  dto.ProblemResponse
+// @Router       /api/riders/export [get]
+func (handler *HTTPHandler) Export(c *gin.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	if !authorization.NewRest(c).AuthorizeAdmin() {
+		writeNotAllowed(c)
+		return
+	}
+
+	query := dto.QueryRiderExport{}
+
+	if err := bindQuery(c, &query); err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	encoder := newRiderEncoder(query.Format)
+	privacy := domain.LocationPrivacy{GridSize: handler.config.Privacy.CoarseGridSize}
+	started := false
+
+	// The response is only started with the first rider, so a failing query can still be answered with a problem.
+	start := func() error {
+		started = true
+
+		c.Header("Content-Type", encoder.contentType())
+		c.Header("Content-Disposition", `attachment; filename="riders.`+encoder.fileExtension()+`"`)
+		c.Status(http.StatusOK)
 
+		return encoder.begin(c.Writer)
+	}
+
+	err := handler.riderService.StreamAll(ctx, query.ToDomain(), func(rider domain.Rider) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		precision := privacy.Precision(rider, true)
+		return encoder.encode(c.Writer, privacy.Apply(rider, precision), precision)
+	})
+
+	if err == nil && !started {
+		err = start()
+	}
+
+	if err == nil {
+		err = encoder.end(c.Writer)
+	}
+
+	if err != nil && !started {
+		handler.writeError(c, err)
+		return
+	}
+
+	if err != nil {
+		// The status is sent already, the client sees a truncated export.
+		handler.logger.Error(ctx, "could not export riders", "error", err)
+	}
+}
+
+// ImportRiders godoc
+// @Summary  import riders
+// @Schemes
+// @Description  creates riders in bulk from CSV, with an id, serviceArea, width, height and depth column, or NDJSON with one create body per line. Without commit the rows are only validated. Every row is reported, a failing row does not stop the import
+// @Accept       text/csv
+// @Accept       application/x-ndjson
+// @Param        format  query  string  false  "Format of the body, csv by default"  Enums(csv, ndjson)
+// @Param        commit  query  bool    false  "Create the riders instead of only validating them"
+// @Param        Idempotency-Key  header  string  false  "Key that makes retries of this request safe"
+// @Produce      json
+// @Success      200  {object}  dto.RiderImportResponse
+// @Failure      default  {object}  dto.ProblemResponse
+// @Router       /api/riders/import [post]
+func (handler *HTTPHandler) ImportRiders(c *gin.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	if !authorization.NewRest(c).AuthorizeAdmin() {
+		writeNotAllowed(c)
+		return
+	}
+
+	query := dto.QueryRiderImport{}
+
+	if err := bindQuery(c, &query); err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	rows, err := dto.ParseRiderImport(c.Request.Body, query.Format)
+
+	if err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	results, err := handler.riderService.ImportRiders(ctx, rows, query.Commit)
+
+	if err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CreateRiderImportResponse(results, !query.Commit))
 }
 
 // Get godoc
 // @Summary  get rider
 // @Schemes
 // @Param        id     path  string           true  "Rider id"
-// @Description  gets a rider from the system by its ID
+// @Description  gets a rider from the system by its ID. The location is only exact for admins and dispatchers while the rider is on a delivery, coarse otherwise and left out while the rider is offline
 // @Produce      json
 // @Success      200  {object}  dto.RiderResponse
+// @Failure      default  {object}  dto.ProblemResponse
 // @Router       /api/riders/{id} [get]
 func (handler *HTTPHandler) Get(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -100,20 +304,101 @@ func (handler *HTTPHandler) Get(c *gin.Context) {
 
 	auth := authorization.NewRest(c)
 
-	if auth.AuthorizeAdmin() || auth.AuthorizeMatchingId(c.Param("id")) {
+	if auth.AuthorizeAdmin() || auth.AuthorizeDispatcher() || auth.AuthorizeMatchingId(c.Param("id")) {
 
 		rider, err := handler.riderService.Get(ctx, c.Param("id"))
 
 		if err != nil {
-			c.AbortWithStatus(http.StatusNotFound)
+			handler.writeError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, dto.CreateRiderResponse(rider))
+		c.Header("ETag", formatETag(rider.Version))
+		c.JSON(http.StatusOK, handler.riderResponse(c, rider))
+		return
+	}
+
+	writeNotAllowed(c)
+}
+
+// GetETA godoc
+// @Summary  get rider ETA
+// @Schemes
+// @Description  estimates how far, and how long, a rider is from a destination, starting from its last location. Riders that never sent a location or can not reach the destination are a conflict
+// @Param        id   path   string  true  "Rider id"
+// @Param        lat  query  number  true  "Latitude of the destination"  minimum(-90)  maximum(90)
+// @Param        lon  query  number  true  "Longitude of the destination"  minimum(-180)  maximum(180)
+// @Produce      json
+// @Success      200  {object}  dto.RiderETAResponse
+// @Failure      default  {object}  dto.ProblemResponse
+// @Router       /api/riders/{id}/eta [get]
+func (handler *HTTPHandler) GetETA(c *gin.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	auth := authorization.NewRest(c)
+
+	if !auth.AuthorizeAdmin() && !auth.AuthorizeDispatcher() {
+		writeNotAllowed(c)
+		return
+	}
+
+	query := dto.QueryETA{}
+
+	if err := bindQuery(c, &query); err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	eta, err := handler.etaService.GetETA(ctx, c.Param("id"), query.Destination())
+
+	if err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CreateRiderETAResponse(eta))
+}
+
+// GetETAs godoc
+// @Summary  get ETAs of riders
+// @Schemes
+// @Description  estimates the routes of a list of candidate riders to a destination, fastest first. Riders without a route are listed last with an error instead of failing the list. At most eta.maxRiders riders can be asked for at once
+// @Param        lat    query  number    true  "Latitude of the destination"  minimum(-90)  maximum(90)
+// @Param        lon    query  number    true  "Longitude of the destination"  minimum(-180)  maximum(180)
+// @Param        rider  query  []string  true  "Rider id, repeated for every rider"  collectionFormat(multi)
+// @Produce      json
+// @Success      200  {array}  dto.RiderETAResponse
+// @Failure      default  {object}  dto.ProblemResponse
+// @Router       /api/riders/eta [get]
+func (handler *HTTPHandler) GetETAs(c *gin.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	auth := authorization.NewRest(c)
+
+	if !auth.AuthorizeAdmin() && !auth.AuthorizeDispatcher() {
+		writeNotAllowed(c)
 		return
 	}
 
-	c.AbortWithStatus(http.StatusUnauthorized)
+	query := dto.QueryETAs{}
+
+	if err := bindQuery(c, &query); err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	etas, err := handler.etaService.GetETAs(ctx, query.Riders, query.Destination())
+
+	if err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CreateRiderETAResponses(etas))
 }
 
 // Create godoc
@@ -122,8 +407,10 @@ func (handler *HTTPHandler) Get(c *gin.Context) {
 // @Description  creates a new rider
 // @Accept       json
 // @Param        rider  body  dto.BodyCreateRider  true  "Add rider"
+// @Param        Idempotency-Key  header  string  false  "Key that makes retries of this request safe"
 // @Produce      json
 // @Success      200  {object}  dto.RiderResponse
+// @Failure      default  {object}  dto.ProblemResponse
 // @Router       /api/riders [post]
 func (handler *HTTPHandler) Create(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -131,10 +418,9 @@ func (handler *HTTPHandler) Create(c *gin.Context) {
 	defer span.End()
 
 	body := dto.BodyCreateRider{}
-	err := c.BindJSON(&body)
 
-	if err != nil || body.ID == "" {
-		c.AbortWithStatus(http.StatusBadRequest)
+	if err := bindJSON(c, &body); err != nil {
+		handler.writeError(c, err)
 		return
 	}
 
@@ -145,16 +431,15 @@ func (handler *HTTPHandler) Create(c *gin.Context) {
 		rider, err := handler.riderService.Create(ctx, body.ID, body.ServiceArea, domain.Dimensions(body.Capacity))
 
 		if err != nil {
-			c.AbortWithStatus(http.StatusInternalServerError)
-			handler.logger.Error(ctx, err.Error())
+			handler.writeError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, dto.CreateRiderResponse(rider))
+		c.JSON(http.StatusOK, handler.riderResponse(c, rider))
 		return
 	}
 
-	c.AbortWithStatus(http.StatusUnauthorized)
+	writeNotAllowed(c)
 }
 
 // UpdateRider godoc
@@ -162,21 +447,30 @@ func (handler *HTTPHandler) Create(c *gin.Context) {
 // @Schemes
 // @Description  updates a rider's information
 // @Accept       json
-// @Param        rider  body  dto.BodyCreateRider  true  "Update rider"
+// @Param        rider  body  dto.BodyUpdateRider  true  "Update rider"
 // @Param        id     path  string      true  "Rider id"
+// @Param        If-Match  header  string  false  "ETag of the rider the update is based on"
 // @Produce      json
 // @Success      200  {object}  dto.RiderResponse
+// @Failure      default  {object}  dto.ProblemResponse
+// @Failure      412  "Rider was modified since the given ETag"
 // @Router       /api/riders/{id} [put]
 func (handler *HTTPHandler) UpdateRider(c *gin.Context) {
 	ctx := c.Request.Context()
 	span := trace.SpanFromContext(ctx)
 	defer span.End()
 
-	body := dto.BodyCreateRider{}
-	err := c.BindJSON(&body)
+	body := dto.BodyUpdateRider{}
+
+	if err := bindJSON(c, &body); err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	version, err := parseIfMatch(c.GetHeader("If-Match"))
 
 	if err != nil {
-		c.AbortWithStatus(http.StatusBadRequest)
+		writeBadRequest(c, "If-Match header is not a valid ETag")
 		return
 	}
 
@@ -187,19 +481,85 @@ func (handler *HTTPHandler) UpdateRider(c *gin.Context) {
 
 		handler.logger.Info(ctx, "Updating rider position", "rider", riderId, "body", body)
 
-		rider, err := handler.riderService.Update(ctx, riderId, body.Status, body.ServiceArea, domain.Dimensions(body.Capacity))
+		rider, err := handler.riderService.Update(ctx, riderId, body.Status, body.ServiceArea, domain.Dimensions(body.Capacity), version)
 
 		if err != nil {
-			c.AbortWithStatus(http.StatusInternalServerError)
-			handler.logger.Error(ctx, err.Error())
+			handler.writeError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, dto.CreateRiderResponse(rider))
+		c.Header("ETag", formatETag(rider.Version))
+		c.JSON(http.StatusOK, handler.riderResponse(c, rider))
 		return
 	}
 
-	c.AbortWithStatus(http.StatusUnauthorized)
+	writeNotAllowed(c)
+}
+
+// PatchRider godoc
+// @Summary  partially update rider
+// @Schemes
+// @Description  updates only the given fields of a rider using a JSON Merge Patch (RFC 7396)
+// @Accept       json
+// @Accept       application/merge-patch+json
+// @Param        rider  body  dto.BodyPatchRider  true  "Rider fields to change"
+// @Param        id     path  string      true  "Rider id"
+// @Param        If-Match  header  string  false  "ETag of the rider the update is based on"
+// @Produce      json
+// @Success      200  {object}  dto.RiderResponse
+// @Failure      default  {object}  dto.ProblemResponse
+// @Failure      412  "Rider was modified since the given ETag"
+// @Failure      415  "Body is not a merge patch"
+// @Router       /api/riders/{id} [patch]
+func (handler *HTTPHandler) PatchRider(c *gin.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	if contentType := c.ContentType(); contentType != "application/merge-patch+json" && contentType != "application/json" {
+		writeProblem(c, http.StatusUnsupportedMediaType, "body must be a JSON merge patch", nil)
+		return
+	}
+
+	data, err := c.GetRawData()
+
+	if err != nil {
+		writeBadRequest(c, "could not read request body")
+		return
+	}
+
+	body, err := dto.ParseRiderMergePatch(data)
+
+	if err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	version, err := parseIfMatch(c.GetHeader("If-Match"))
+
+	if err != nil {
+		writeBadRequest(c, "If-Match header is not a valid ETag")
+		return
+	}
+
+	auth := authorization.NewRest(c)
+	riderId := c.Param("id")
+
+	if auth.AuthorizeAdmin() || auth.AuthorizeMatchingId(riderId) {
+
+		rider, err := handler.riderService.Patch(ctx, riderId, body.ToDomain(), version)
+
+		if err != nil {
+			handler.writeError(c, err)
+			return
+		}
+
+		c.Header("ETag", formatETag(rider.Version))
+		c.JSON(http.StatusOK, handler.riderResponse(c, rider))
+		return
+	}
+
+	writeNotAllowed(c)
 }
 
 // UpdateLocation godoc
@@ -209,8 +569,10 @@ func (handler *HTTPHandler) UpdateRider(c *gin.Context) {
 // @Accept       json
 // @Param        rider  body  dto.BodyLocation  true  "Update rider"
 // @Param        id  path  string  true  "Rider id"
+// @Param        Idempotency-Key  header  string  false  "Key that makes retries of this request safe"
 // @Produce      json
 // @Success      200  {object}  dto.RiderResponse
+// @Failure      default  {object}  dto.ProblemResponse
 // @Router       /api/riders/{id}/location [put]
 func (handler *HTTPHandler) UpdateLocation(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -218,30 +580,467 @@ func (handler *HTTPHandler) UpdateLocation(c *gin.Context) {
 	defer span.End()
 
 	body := dto.BodyLocation{}
-	err := c.BindJSON(&body)
+
+	if err := bindJSON(c, &body); err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	auth := authorization.NewRest(c)
+
+	if auth.AuthorizeAdmin() || auth.AuthorizeMatchingId(c.Param("id")) {
+
+		id := c.Param("id")
+
+		rider, err := handler.riderService.UpdateLocation(ctx, id, body.ToDomain())
+
+		if err != nil {
+			handler.writeError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, handler.riderResponse(c, rider))
+		return
+	}
+
+	writeNotAllowed(c)
+}
+
+// LoadParcel godoc
+// @Summary  load parcel
+// @Schemes
+// @Description  puts a parcel in the rider's box, or replaces the parcel with the same id. A parcel that does not fit in the remaining capacity is a conflict, as is a parcel of a delivery
+// @Accept       json
+// @Param        size    body  dto.BodyParcel  true  "Size and weight of the parcel"
+// @Param        id      path  string  true  "Rider id"
+// @Param        parcel  path  string  true  "Parcel id"
+// @Produce      json
+// @Success      200  {object}  dto.RiderResponse
+// @Failure      default  {object}  dto.ProblemResponse
+// @Router       /api/riders/{id}/parcels/{parcel} [put]
+func (handler *HTTPHandler) LoadParcel(c *gin.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	body := dto.BodyParcel{}
+
+	if err := bindJSON(c, &body); err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	auth := authorization.NewRest(c)
+	riderId := c.Param("id")
+
+	if auth.AuthorizeAdmin() || auth.AuthorizeDispatcher() || auth.AuthorizeMatchingId(riderId) {
+
+		rider, err := handler.riderService.LoadParcel(ctx, riderId, body.ToDomain(c.Param("parcel")))
+
+		if err != nil {
+			handler.writeError(c, err)
+			return
+		}
+
+		c.Header("ETag", formatETag(rider.Version))
+		c.JSON(http.StatusOK, handler.riderResponse(c, rider))
+		return
+	}
+
+	writeNotAllowed(c)
+}
+
+// UnloadParcel godoc
+// @Summary  unload parcel
+// @Schemes
+// @Description  takes a parcel out of the rider's box. Parcels of a delivery are unloaded when the delivery is completed or cancelled, unloading them is a conflict
+// @Param        id      path  string  true  "Rider id"
+// @Param        parcel  path  string  true  "Parcel id"
+// @Produce      json
+// @Success      200  {object}  dto.RiderResponse
+// @Failure      default  {object}  dto.ProblemResponse
+// @Router       /api/riders/{id}/parcels/{parcel} [delete]
+func (handler *HTTPHandler) UnloadParcel(c *gin.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	auth := authorization.NewRest(c)
+	riderId := c.Param("id")
+
+	if auth.AuthorizeAdmin() || auth.AuthorizeDispatcher() || auth.AuthorizeMatchingId(riderId) {
+
+		rider, err := handler.riderService.UnloadParcel(ctx, riderId, c.Param("parcel"))
+
+		if err != nil {
+			handler.writeError(c, err)
+			return
+		}
+
+		c.Header("ETag", formatETag(rider.Version))
+		c.JSON(http.StatusOK, handler.riderResponse(c, rider))
+		return
+	}
+
+	writeNotAllowed(c)
+}
+
+// GetLocationAnomalies godoc
+// @Summary  get location anomalies
+// @Schemes
+// @Description  reports suspicious rider locations, like impossible jumps and mocked locations, newest first
+// @Param        rider  query  string  false  "Rider id"
+// @Param        kind   query  string  false  "Kind of anomaly"  Enums(impossible_jump, mock_location)
+// @Param        since  query  string  false  "Only anomalies detected at or after this RFC 3339 time"
+// @Param        limit  query  int     false  "Maximum number of anomalies, 100 by default"  minimum(1)  maximum(1000)
+// @Produce      json
+// @Success      200  {array}  dto.LocationAnomalyResponse
+// @Failure      default  {object}  dto.ProblemResponse
+// @Router       /api/location-anomalies [get]
+func (handler *HTTPHandler) GetLocationAnomalies(c *gin.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	if !authorization.NewRest(c).AuthorizeAdmin() {
+		writeNotAllowed(c)
+		return
+	}
+
+	query := dto.QueryLocationAnomalies{}
+
+	if err := bindQuery(c, &query); err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	anomalies, err := handler.riderService.GetLocationAnomalies(ctx, query.ToDomain())
 
 	if err != nil {
-		c.AbortWithStatus(http.StatusBadRequest)
+		handler.writeError(c, err)
 		return
 	}
 
+	c.JSON(http.StatusOK, dto.CreateLocationAnomalyResponses(anomalies))
+}
+
+// GetServiceAreas godoc
+// @Summary  get service areas
+// @Schemes
+// @Description  gets the service areas riders can be assigned to, deleted service areas are left out
+// @Produce      json
+// @Success      200  {array}  dto.ServiceAreaResponse
+// @Failure      default  {object}  dto.ProblemResponse
+// @Router       /api/service-areas [get]
+func (handler *HTTPHandler) GetServiceAreas(c *gin.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	serviceAreas, err := handler.serviceAreaService.GetServiceAreas(ctx)
+
+	if err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CreateServiceAreaResponses(serviceAreas))
+}
+
+// GetServiceAreaRiders godoc
+// @Summary  get riders of service area
+// @Schemes
+// @Description  gets the riders of a service area, also of a deleted one to find the riders that still have to be moved
+// @Param        id  path  int  true  "Service area id"
+// @Produce      json
+// @Success      200  {array}  dto.RiderResponse
+// @Failure      default  {object}  dto.ProblemResponse
+// @Router       /api/service-areas/{id}/riders [get]
+func (handler *HTTPHandler) GetServiceAreaRiders(c *gin.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	auth := authorization.NewRest(c)
+
+	if !auth.AuthorizeAdmin() && !auth.AuthorizeDispatcher() {
+		writeNotAllowed(c)
+		return
+	}
+
+	id, err := serviceAreaID(c)
+
+	if err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	if _, err = handler.serviceAreaService.GetServiceArea(ctx, id); err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	riders, err := handler.riderService.GetAll(ctx, domain.RiderFilter{ServiceAreaID: id})
+
+	if err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, handler.riderResponses(c, riders))
+}
+
+// serviceAreaID returns the service area id of the path, an id that is not a number is an area that does not exist.
+func serviceAreaID(c *gin.Context) (int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+
+	if err != nil || id < 1 {
+		return 0, domain.NewNotFoundError("service area %s not found", c.Param("id"))
+	}
+
+	return id, nil
+}
+
+// GetServiceAreaStats godoc
+// @Summary  get service area stats
+// @Schemes
+// @Description  gets an overview of the riders of a service area: riders by status, the average capacity of the riders that are online and the riders whose location is stale
+// @Param        id  path  int  true  "Service area id"
+// @Produce      json
+// @Success      200  {object}  dto.ServiceAreaStatsResponse
+// @Failure      default  {object}  dto.ProblemResponse
+// @Router       /api/service-areas/{id}/stats [get]
+func (handler *HTTPHandler) GetServiceAreaStats(c *gin.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	auth := authorization.NewRest(c)
+
+	if !auth.AuthorizeAdmin() && !auth.AuthorizeDispatcher() {
+		writeNotAllowed(c)
+		return
+	}
+
+	id, err := serviceAreaID(c)
+
+	if err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	stats, err := handler.statsService.GetServiceAreaStats(ctx, id)
+
+	if err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CreateServiceAreaStatsResponse(stats))
+}
+
+// GetServiceAreaHeatmap godoc
+// @Summary  get service area heatmap
+// @Schemes
+// @Description  counts the riders of a service area per geohash cell, as a GeoJSON FeatureCollection of the cells. The riders source counts the current locations of the riders that are online, the history source the locations riders sent since a moment. Precision can not be higher than heatmap.maxPrecision
+// @Param        id         path   int     true   "Service area id"
+// @Param        source     query  string  false  "What to count, riders by default"  Enums(riders, history)
+// @Param        precision  query  int     false  "Geohash length of the cells, heatmap.precision by default"  minimum(1)  maximum(12)
+// @Param        since      query  string  false  "Only history sent at or after this RFC 3339 time, heatmap.historyWindow ago by default"
+// @Produce      json
+// @Success      200  {object}  dto.HeatmapResponse
+// @Failure      default  {object}  dto.ProblemResponse
+// @Router       /api/service-areas/{id}/heatmap [get]
+func (handler *HTTPHandler) GetServiceAreaHeatmap(c *gin.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	auth := authorization.NewRest(c)
+
+	if !auth.AuthorizeAdmin() && !auth.AuthorizeDispatcher() {
+		writeNotAllowed(c)
+		return
+	}
+
+	id, err := serviceAreaID(c)
+
+	if err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	query := dto.QueryHeatmap{}
+
+	if err = bindQuery(c, &query); err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	heatmap, err := handler.statsService.GetServiceAreaHeatmap(ctx, query.ToDomain(id))
+
+	if err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CreateHeatmapResponse(heatmap))
+}
+
+// GetStatsSummary godoc
+// @Summary  get stats of all service areas
+// @Schemes
+// @Description  gets the overview of the riders of every service area that is not deleted, and the total of all areas
+// @Produce      json
+// @Success      200  {object}  dto.StatsSummaryResponse
+// @Failure      default  {object}  dto.ProblemResponse
+// @Router       /api/service-areas/stats [get]
+func (handler *HTTPHandler) GetStatsSummary(c *gin.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	auth := authorization.NewRest(c)
+
+	if !auth.AuthorizeAdmin() && !auth.AuthorizeDispatcher() {
+		writeNotAllowed(c)
+		return
+	}
+
+	summary, err := handler.statsService.GetStatsSummary(ctx)
+
+	if err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CreateStatsSummaryResponse(summary))
+}
+
+// Heartbeat godoc
+// @Summary  send rider heartbeat
+// @Schemes
+// @Description  records that a rider is still there without sending a location, riders that send neither are set offline after a while
+// @Param        id  path  string  true  "Rider id"
+// @Produce      json
+// @Success      200  {object}  dto.RiderResponse
+// @Failure      default  {object}  dto.ProblemResponse
+// @Router       /api/riders/{id}/heartbeat [post]
+func (handler *HTTPHandler) Heartbeat(c *gin.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
 	auth := authorization.NewRest(c)
 
 	if auth.AuthorizeAdmin() || auth.AuthorizeMatchingId(c.Param("id")) {
 
 		id := c.Param("id")
 
-		rider, err := handler.riderService.UpdateLocation(ctx, id, domain.Location(body))
+		rider, err := handler.riderService.Heartbeat(ctx, id)
+
+		if err != nil {
+			handler.writeError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, handler.riderResponse(c, rider))
+		return
+	}
+
+	writeNotAllowed(c)
+}
+
+// UpdateLocationBatch godoc
+// @Summary  update rider location from buffered fixes
+// @Schemes
+// @Description  stores the fixes a rider's device buffered while offline and moves the rider to the newest one, fixes older than the rider's current location are skipped
+// @Accept       json
+// @Param        fixes  body  dto.BodyLocationBatch  true  "Buffered fixes"
+// @Param        id  path  string  true  "Rider id"
+// @Param        Idempotency-Key  header  string  false  "Key that makes retries of this request safe"
+// @Produce      json
+// @Success      200  {object}  dto.RiderResponse
+// @Failure      default  {object}  dto.ProblemResponse
+// @Router       /api/riders/{id}/locations:batch [post]
+func (handler *HTTPHandler) UpdateLocationBatch(c *gin.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	body := dto.BodyLocationBatch{}
+
+	if err := bindJSON(c, &body); err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	auth := authorization.NewRest(c)
+	id := c.Param("id")
+
+	if auth.AuthorizeAdmin() || auth.AuthorizeMatchingId(id) {
+
+		rider, err := handler.riderService.UpdateLocationBatch(ctx, id, body.ToDomain())
 
 		if err != nil {
-			c.AbortWithStatus(http.StatusInternalServerError)
-			handler.logger.Error(ctx, err.Error())
+			handler.writeError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, dto.CreateRiderResponse(rider))
+		c.JSON(http.StatusOK, handler.riderResponse(c, rider))
 		return
 	}
 
-	c.AbortWithStatus(http.StatusUnauthorized)
+	writeNotAllowed(c)
+}
+
+// UpdateLocationBatches godoc
+// @Summary  update locations of several riders from buffered fixes
+// @Schemes
+// @Description  stores buffered fixes of several riders in one transaction, meant for gateways that forward fixes of many devices
+// @Accept       json
+// @Param        riders  body  dto.BodyLocationBatches  true  "Buffered fixes per rider"
+// @Param        Idempotency-Key  header  string  false  "Key that makes retries of this request safe"
+// @Produce      json
+// @Success      200  {array}  dto.RiderResponse
+// @Failure      default  {object}  dto.ProblemResponse
+// @Router       /api/riders/locations:batch [post]
+func (handler *HTTPHandler) UpdateLocationBatches(c *gin.Context) {
+	ctx := c.Request.Context()
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	body := dto.BodyLocationBatches{}
+
+	if err := bindJSON(c, &body); err != nil {
+		handler.writeError(c, err)
+		return
+	}
+
+	auth := authorization.NewRest(c)
+
+	if auth.AuthorizeAdmin() || authorizeAllRiders(auth, body.Riders) {
+
+		riders, err := handler.riderService.UpdateLocationBatches(ctx, body.ToDomain())
+
+		if err != nil {
+			handler.writeError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, handler.riderResponses(c, riders))
+		return
+	}
+
+	writeNotAllowed(c)
+}
+
+func authorizeAllRiders(auth *authorization.RestAuthorization, riders []dto.BodyRiderLocationBatch) bool {
+	for _, rider := range riders {
+		if !auth.AuthorizeMatchingId(rider.ID) {
+			return false
+		}
+	}
+
+	return true
 }
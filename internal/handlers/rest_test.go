@@ -5,25 +5,31 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
+	mock2 "github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"net/http"
 	"net/http/httptest"
 	"rider-service/config"
 	"rider-service/internal/core/domain"
 	"rider-service/internal/mock"
+	"rider-service/internal/repositories"
 	"rider-service/pkg/dto"
 	"rider-service/pkg/logging"
 	"strings"
 	"testing"
+	"time"
 )
 
 type RestHandlerTestSuite struct {
 	suite.Suite
-	MockService *mock.RiderService
-	TestHandler *HTTPHandler
-	TestRouter  *gin.Engine
-	Cfg         *config.Config
-	TestData    struct {
+	MockService            *mock.RiderService
+	MockServiceAreaService *mock.ServiceAreaService
+	MockStatsService       *mock.StatsService
+	MockETAService         *mock.ETAService
+	TestHandler            *HTTPHandler
+	TestRouter             *gin.Engine
+	Cfg                    *config.Config
+	TestData               struct {
 		Rider    domain.Rider
 		Location domain.Location
 	}
@@ -40,15 +46,21 @@ func (suite *RestHandlerTestSuite) SetupSuite() {
 	logger := logging.MockLogger{}
 
 	mockService := new(mock.RiderService)
+	mockServiceAreaService := new(mock.ServiceAreaService)
+	mockStatsService := new(mock.StatsService)
+	mockETAService := new(mock.ETAService)
 
 	router := gin.New()
 	gin.SetMode(gin.TestMode)
 
-	deliveryHandler := NewHTTPHandler(mockService, router, logger, cfg)
+	deliveryHandler := NewHTTPHandler(mockService, mockServiceAreaService, mockStatsService, mockETAService, repositories.NewMemoryRepository(), router, logger, cfg)
 	deliveryHandler.SetupEndpoints()
 
 	suite.Cfg = cfg
 	suite.MockService = mockService
+	suite.MockServiceAreaService = mockServiceAreaService
+	suite.MockStatsService = mockStatsService
+	suite.MockETAService = mockETAService
 	suite.TestRouter = router
 	suite.TestHandler = deliveryHandler
 	suite.TestData = struct {
@@ -77,6 +89,7 @@ func (suite *RestHandlerTestSuite) SetupSuite() {
 				Latitude:  1,
 				Longitude: 2,
 			},
+			Version: 1,
 		},
 		Location: domain.Location{
 			Latitude:  2,
@@ -87,10 +100,17 @@ func (suite *RestHandlerTestSuite) SetupSuite() {
 
 func (suite *RestHandlerTestSuite) SetupTest() {
 	suite.MockService.ExpectedCalls = nil
+	suite.MockService.Calls = nil
+	suite.MockServiceAreaService.ExpectedCalls = nil
+	suite.MockServiceAreaService.Calls = nil
+	suite.MockStatsService.ExpectedCalls = nil
+	suite.MockStatsService.Calls = nil
+	suite.MockETAService.ExpectedCalls = nil
+	suite.MockETAService.Calls = nil
 }
 
 func (suite *RestHandlerTestSuite) TestHandler_GetAll() {
-	suite.MockService.On("GetAll").Return([]domain.Rider{suite.TestData.Rider}, nil)
+	suite.MockService.On("GetAll", domain.RiderFilter{}).Return([]domain.Rider{suite.TestData.Rider}, nil)
 
 	rr := httptest.NewRecorder()
 
@@ -116,8 +136,176 @@ func (suite *RestHandlerTestSuite) TestHandler_GetAll() {
 	suite.EqualValues(suite.TestData.Rider.Status, responseObject[0].Status)
 }
 
+func (suite *RestHandlerTestSuite) TestHandler_GetAll_Filter() {
+	status := domain.StatusAvailable
+	suite.MockService.On("GetAll", domain.RiderFilter{Status: &status, ServiceAreaID: 1}).Return([]domain.Rider{suite.TestData.Rider}, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/riders?status=1&serviceArea=1", nil)
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetAll_Fits() {
+	suite.MockService.On("GetAll", domain.RiderFilter{Fits: &domain.Dimensions{Width: 10, Height: 20, Depth: 30}}).Return([]domain.Rider{suite.TestData.Rider}, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/riders?fitsWidth=10&fitsHeight=20&fitsDepth=30", nil)
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetAll_FitsIncomplete() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/riders?fitsWidth=10", nil)
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusBadRequest, rr.Code)
+	suite.MockService.AssertNotCalled(suite.T(), "GetAll", mock2.Anything)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Export_GeoJSON() {
+	offline := suite.TestData.Rider
+	offline.UserID = "offline-id"
+	offline.Status = domain.StatusOffline
+
+	suite.MockService.On("StreamAll", domain.RiderFilter{ServiceAreaID: 1}).Return([]domain.Rider{suite.TestData.Rider, offline}, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/riders/export?serviceArea=1", nil)
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+	suite.Equal("application/geo+json", rr.Header().Get("Content-Type"))
+
+	var responseObject struct {
+		Type     string             `json:"type"`
+		Features []dto.RiderFeature `json:"features"`
+	}
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+
+	suite.Equal("FeatureCollection", responseObject.Type)
+	suite.Require().Len(responseObject.Features, 2)
+	suite.Equal(&dto.GeoJSONPoint{Type: "Point", Coordinates: [2]float64{2.005, 1.005}}, responseObject.Features[0].Geometry)
+	suite.Nil(responseObject.Features[1].Geometry, "offline riders are exported without a location")
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Export_CSV() {
+	suite.MockService.On("StreamAll", domain.RiderFilter{}).Return([]domain.Rider{suite.TestData.Rider}, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/riders/export?format=csv", nil)
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+	suite.Equal("text/csv", rr.Header().Get("Content-Type"))
+	suite.Equal(
+		"id,name,lastName,status,serviceAreaId,serviceArea,latitude,longitude,locationPrecision,lastSeenAt\n"+
+			"test-id,test-name,test-lastname,1,1,test-area,1.005,2.005,coarse,\n",
+		rr.Body.String())
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Export_NDJSON() {
+	suite.MockService.On("StreamAll", domain.RiderFilter{}).Return([]domain.Rider{suite.TestData.Rider, suite.TestData.Rider}, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/riders/export?format=ndjson", nil)
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+
+	lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+	suite.Require().Len(lines, 2)
+
+	var responseObject dto.RiderResponse
+	suite.NoError(json.Unmarshal([]byte(lines[0]), &responseObject))
+	suite.Equal(suite.TestData.Rider.UserID, responseObject.ID)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Export_Failed() {
+	suite.MockService.On("StreamAll", domain.RiderFilter{}).Return([]domain.Rider{}, domain.NewUnavailableError(errors.New("connection refused"), "could not access riders"))
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/riders/export", nil)
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusServiceUnavailable, rr.Code, "an export that fails before the first rider gets a problem")
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Export_InvalidFormat() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/riders/export?format=xlsx", nil)
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusBadRequest, rr.Code)
+
+	var responseObject dto.ProblemResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+	suite.Equal([]dto.ProblemFieldError{{Field: "format", Message: "must be one of geojson, csv, ndjson"}}, responseObject.Errors)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Export_NotAdmin() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/riders/export", nil)
+	request.Header.Set("X-User-Id", "export")
+
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusForbidden, rr.Code)
+	suite.MockService.AssertNotCalled(suite.T(), "StreamAll", mock2.Anything)
+}
+
 func (suite *RestHandlerTestSuite) TestHandler_GetAll_NoneFound() {
-	suite.MockService.On("GetAll").Return([]domain.Rider{}, errors.New("Not found"))
+	suite.MockService.On("GetAll", domain.RiderFilter{}).Return([]domain.Rider{}, domain.NewNotFoundError("no riders found"))
 
 	rr := httptest.NewRecorder()
 
@@ -151,9 +339,66 @@ func (suite *RestHandlerTestSuite) TestHandler_Get() {
 	suite.NoError(err)
 
 	suite.EqualValues(suite.TestData.Rider.UserID, responseObject.ID)
-	suite.EqualValues(suite.TestData.Rider.ServiceArea, domain.ServiceArea(responseObject.ServiceArea))
+	suite.EqualValues(suite.TestData.Rider.ServiceArea.ID, responseObject.ServiceArea.ID)
+	suite.EqualValues(suite.TestData.Rider.ServiceArea.Identifier, responseObject.ServiceArea.Identifier)
 	suite.EqualValues(suite.TestData.Rider.Capacity, domain.Dimensions(responseObject.Capacity))
-	suite.EqualValues(suite.TestData.Rider.Location, domain.Location(responseObject.Location))
+	suite.EqualValues(domain.Location{Latitude: 1.005, Longitude: 2.005}, *responseObject.Location, "a rider that is not on a delivery shares a coarse location")
+	suite.Equal(domain.PrecisionCoarse, responseObject.LocationPrecision)
+	suite.Equal(`"1"`, rr.Header().Get("ETag"))
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Get_DispatcherSeesExactLocation() {
+	rider := suite.TestData.Rider
+	rider.Status = domain.StatusDelivering
+
+	suite.MockService.On("Get", rider.UserID).Return(rider, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/riders/%s", rider.UserID), nil)
+	request.Header.Set("X-User-Id", "dispatcher-id")
+	request.Header.Set("X-User-Claims", `{"dispatcher": true}`)
+
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+
+	var responseObject dto.RiderResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+
+	suite.EqualValues(rider.Location, *responseObject.Location)
+	suite.Equal(domain.PrecisionExact, responseObject.LocationPrecision)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Get_OfflineHidesLocation() {
+	rider := suite.TestData.Rider
+	rider.Status = domain.StatusOffline
+
+	suite.MockService.On("Get", rider.UserID).Return(rider, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/riders/%s", rider.UserID), nil)
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+	suite.NotContains(rr.Body.String(), `"location":`)
+
+	var responseObject dto.RiderResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+
+	suite.Nil(responseObject.Location)
+	suite.Equal(domain.PrecisionHidden, responseObject.LocationPrecision)
 }
 
 func (suite *RestHandlerTestSuite) TestHandler_Get_BadID() {
@@ -168,11 +413,25 @@ func (suite *RestHandlerTestSuite) TestHandler_Get_BadID() {
 
 	suite.TestRouter.ServeHTTP(rr, request)
 
+	suite.Equal(http.StatusForbidden, rr.Code)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Get_Unauthenticated() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/riders/%s", suite.TestData.Rider.UserID), nil)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
 	suite.Equal(http.StatusUnauthorized, rr.Code)
+	suite.Equal("application/problem+json", rr.Header().Get("Content-Type"))
+
+	suite.MockService.AssertNotCalled(suite.T(), "Get", mock2.Anything)
 }
 
 func (suite *RestHandlerTestSuite) TestHandler_Get_NotFound() {
-	suite.MockService.On("Get", suite.TestData.Rider.UserID).Return(domain.Rider{}, errors.New("Not found"))
+	suite.MockService.On("Get", suite.TestData.Rider.UserID).Return(domain.Rider{}, domain.NewNotFoundError("rider %s not found", suite.TestData.Rider.UserID))
 
 	rr := httptest.NewRecorder()
 
@@ -184,6 +443,31 @@ func (suite *RestHandlerTestSuite) TestHandler_Get_NotFound() {
 	suite.TestRouter.ServeHTTP(rr, request)
 
 	suite.Equal(http.StatusNotFound, rr.Code)
+	suite.Equal("application/problem+json", rr.Header().Get("Content-Type"))
+
+	var responseObject dto.ProblemResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+
+	suite.Equal(http.StatusNotFound, responseObject.Status)
+	suite.Equal("rider test-id not found", responseObject.Detail)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Get_Unavailable() {
+	suite.MockService.On("Get", suite.TestData.Rider.UserID).Return(domain.Rider{}, domain.NewUnavailableError(errors.New("connection refused"), "could not access rider"))
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/riders/%s", suite.TestData.Rider.UserID), nil)
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusServiceUnavailable, rr.Code)
+	suite.NotContains(rr.Body.String(), "connection refused")
 }
 
 func (suite *RestHandlerTestSuite) TestHandler_Create() {
@@ -238,6 +522,30 @@ func (suite *RestHandlerTestSuite) TestHandler_Create_BadInput() {
 	suite.Equal(http.StatusBadRequest, rr.Code)
 }
 
+func (suite *RestHandlerTestSuite) TestHandler_Create_InvalidFields() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPost, "/api/riders", strings.NewReader(`{"serviceArea": 1, "capacity": {"width": -5, "height": 10, "depth": 0}}`))
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusBadRequest, rr.Code)
+
+	var responseObject dto.ProblemResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+
+	suite.ElementsMatch([]dto.ProblemFieldError{
+		{Field: "id", Message: "is required"},
+		{Field: "capacity.width", Message: "must be greater than 0"},
+		{Field: "capacity.depth", Message: "must be greater than 0"},
+	}, responseObject.Errors)
+}
+
 func (suite *RestHandlerTestSuite) TestHandler_Create_CouldNotCreate() {
 	suite.MockService.On("Create", suite.TestData.Rider.UserID, suite.TestData.Rider.ServiceAreaID, suite.TestData.Rider.Capacity).Return(domain.Rider{}, errors.New("could not create"))
 
@@ -261,8 +569,101 @@ func (suite *RestHandlerTestSuite) TestHandler_Create_CouldNotCreate() {
 	suite.Equal(http.StatusInternalServerError, rr.Code)
 }
 
+func (suite *RestHandlerTestSuite) TestHandler_Create_IdempotencyKeyReplay() {
+	suite.MockService.On("Create", suite.TestData.Rider.UserID, suite.TestData.Rider.ServiceAreaID, suite.TestData.Rider.Capacity).Return(suite.TestData.Rider, nil).Once()
+
+	data, err := json.Marshal(dto.BodyCreateRider{
+		ID:          suite.TestData.Rider.UserID,
+		ServiceArea: suite.TestData.Rider.ServiceAreaID,
+		Capacity:    dto.CreateDimensions(suite.TestData.Rider.Capacity),
+	})
+
+	suite.NoError(err)
+
+	var responses []*httptest.ResponseRecorder
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+
+		request, err := http.NewRequest(http.MethodPost, "/api/riders", strings.NewReader(string(data)))
+		request.Header.Set("X-User-Claims", `{"admin": true}`)
+		request.Header.Set("Idempotency-Key", "create-replay")
+		suite.NoError(err)
+
+		suite.TestRouter.ServeHTTP(rr, request)
+
+		responses = append(responses, rr)
+	}
+
+	suite.Equal(http.StatusOK, responses[1].Code)
+	suite.Equal("true", responses[1].Header().Get("Idempotent-Replayed"))
+	suite.Empty(responses[0].Header().Get("Idempotent-Replayed"))
+	suite.Equal(responses[0].Body.String(), responses[1].Body.String())
+	suite.MockService.AssertNumberOfCalls(suite.T(), "Create", 1)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Create_IdempotencyKeyReusedForDifferentBody() {
+	suite.MockService.On("Create", mock2.Anything, mock2.Anything, mock2.Anything).Return(suite.TestData.Rider, nil)
+
+	bodies := []string{
+		`{"id": "test-id", "serviceArea": 1, "capacity": {"width": 100, "height": 100, "depth": 100}}`,
+		`{"id": "test-id", "serviceArea": 2, "capacity": {"width": 100, "height": 100, "depth": 100}}`,
+	}
+
+	var responses []*httptest.ResponseRecorder
+
+	for _, body := range bodies {
+		rr := httptest.NewRecorder()
+
+		request, err := http.NewRequest(http.MethodPost, "/api/riders", strings.NewReader(body))
+		request.Header.Set("X-User-Claims", `{"admin": true}`)
+		request.Header.Set("Idempotency-Key", "create-mismatch")
+		suite.NoError(err)
+
+		suite.TestRouter.ServeHTTP(rr, request)
+
+		responses = append(responses, rr)
+	}
+
+	suite.Equal(http.StatusOK, responses[0].Code)
+	suite.Equal(http.StatusUnprocessableEntity, responses[1].Code)
+	suite.Equal("application/problem+json", responses[1].Header().Get("Content-Type"))
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Create_IdempotencyKeyNotStoredOnServerError() {
+	suite.MockService.On("Create", suite.TestData.Rider.UserID, suite.TestData.Rider.ServiceAreaID, suite.TestData.Rider.Capacity).Return(domain.Rider{}, errors.New("could not create")).Once()
+	suite.MockService.On("Create", suite.TestData.Rider.UserID, suite.TestData.Rider.ServiceAreaID, suite.TestData.Rider.Capacity).Return(suite.TestData.Rider, nil).Once()
+
+	data, err := json.Marshal(dto.BodyCreateRider{
+		ID:          suite.TestData.Rider.UserID,
+		ServiceArea: suite.TestData.Rider.ServiceAreaID,
+		Capacity:    dto.CreateDimensions(suite.TestData.Rider.Capacity),
+	})
+
+	suite.NoError(err)
+
+	var responses []*httptest.ResponseRecorder
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+
+		request, err := http.NewRequest(http.MethodPost, "/api/riders", strings.NewReader(string(data)))
+		request.Header.Set("X-User-Claims", `{"admin": true}`)
+		request.Header.Set("Idempotency-Key", "create-retry")
+		suite.NoError(err)
+
+		suite.TestRouter.ServeHTTP(rr, request)
+
+		responses = append(responses, rr)
+	}
+
+	suite.Equal(http.StatusInternalServerError, responses[0].Code)
+	suite.Equal(http.StatusOK, responses[1].Code)
+	suite.Empty(responses[1].Header().Get("Idempotent-Replayed"))
+}
+
 func (suite *RestHandlerTestSuite) TestHandler_Update() {
-	suite.MockService.On("Update", suite.TestData.Rider.UserID, 2, suite.TestData.Rider.ServiceAreaID, suite.TestData.Rider.Capacity).Return(suite.TestData.Rider, nil)
+	suite.MockService.On("Update", suite.TestData.Rider.UserID, 2, suite.TestData.Rider.ServiceAreaID, suite.TestData.Rider.Capacity, 0).Return(suite.TestData.Rider, nil)
 
 	rr := httptest.NewRecorder()
 
@@ -293,7 +694,7 @@ func (suite *RestHandlerTestSuite) TestHandler_Update() {
 }
 
 func (suite *RestHandlerTestSuite) TestHandler_Update_CouldNotCreate() {
-	suite.MockService.On("Update", suite.TestData.Rider.UserID, 2, suite.TestData.Rider.ServiceAreaID, suite.TestData.Rider.Capacity).Return(domain.Rider{}, errors.New("could not update"))
+	suite.MockService.On("Update", suite.TestData.Rider.UserID, 2, suite.TestData.Rider.ServiceAreaID, suite.TestData.Rider.Capacity, 0).Return(domain.Rider{}, errors.New("could not update"))
 
 	rr := httptest.NewRecorder()
 
@@ -316,30 +717,977 @@ func (suite *RestHandlerTestSuite) TestHandler_Update_CouldNotCreate() {
 	suite.Equal(http.StatusInternalServerError, rr.Code)
 }
 
-func (suite *RestHandlerTestSuite) TestHandler_UpdateLocation() {
-	suite.MockService.On("UpdateLocation", suite.TestData.Rider.UserID, suite.TestData.Location).Return(suite.TestData.Rider, nil)
+func (suite *RestHandlerTestSuite) TestHandler_Update_IfMatch() {
+	suite.MockService.On("Update", suite.TestData.Rider.UserID, 2, suite.TestData.Rider.ServiceAreaID, suite.TestData.Rider.Capacity, 1).Return(suite.TestData.Rider, nil)
 
 	rr := httptest.NewRecorder()
 
-	data, err := json.Marshal(dto.BodyLocation(suite.TestData.Location))
+	data, err := json.Marshal(dto.BodyCreateRider{
+		ServiceArea: suite.TestData.Rider.ServiceAreaID,
+		Capacity:    dto.CreateDimensions(suite.TestData.Rider.Capacity),
+		Status:      2,
+	})
 
 	suite.NoError(err)
 
-	request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/api/riders/%s/location", suite.TestData.Rider.UserID), strings.NewReader(string(data)))
-	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/api/riders/%s", suite.TestData.Rider.UserID), strings.NewReader(string(data)))
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+	request.Header.Set("If-Match", `"1"`)
 	suite.NoError(err)
 
 	suite.TestRouter.ServeHTTP(rr, request)
 
 	suite.Equal(http.StatusOK, rr.Code)
+	suite.Equal(`"1"`, rr.Header().Get("ETag"))
+}
 
-	var responseObject dto.RiderResponse
-	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+func (suite *RestHandlerTestSuite) TestHandler_Update_PreconditionFailed() {
+	suite.MockService.On("Update", suite.TestData.Rider.UserID, 2, suite.TestData.Rider.ServiceAreaID, suite.TestData.Rider.Capacity, 3).Return(domain.Rider{}, domain.ErrVersionConflict)
 
-	suite.NoError(err)
+	rr := httptest.NewRecorder()
+
+	data, err := json.Marshal(dto.BodyCreateRider{
+		ServiceArea: suite.TestData.Rider.ServiceAreaID,
+		Capacity:    dto.CreateDimensions(suite.TestData.Rider.Capacity),
+		Status:      2,
+	})
+
+	suite.NoError(err)
+
+	request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/api/riders/%s", suite.TestData.Rider.UserID), strings.NewReader(string(data)))
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+	request.Header.Set("If-Match", `"3"`)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusPreconditionFailed, rr.Code)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Patch() {
+	status := 0
+	suite.MockService.On("Patch", suite.TestData.Rider.UserID, domain.RiderPatch{Status: &status}, 0).Return(suite.TestData.Rider, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/api/riders/%s", suite.TestData.Rider.UserID), strings.NewReader(`{"status": 0}`))
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	request.Header.Set("Content-Type", "application/merge-patch+json")
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+	suite.MockService.AssertCalled(suite.T(), "Patch", suite.TestData.Rider.UserID, domain.RiderPatch{Status: &status}, 0)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Patch_VehicleType() {
+	vehicleType := domain.VehicleCargoBike
+	updated := suite.TestData.Rider
+	updated.VehicleType = vehicleType
+
+	suite.MockService.On("Patch", suite.TestData.Rider.UserID, domain.RiderPatch{VehicleType: &vehicleType}, 0).Return(updated, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/api/riders/%s", suite.TestData.Rider.UserID), strings.NewReader(`{"vehicleType": "cargo-bike"}`))
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	request.Header.Set("Content-Type", "application/merge-patch+json")
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+
+	var responseObject dto.RiderResponse
+	suite.NoError(json.NewDecoder(rr.Body).Decode(&responseObject))
+	suite.Equal("cargo-bike", responseObject.VehicleType)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Patch_NullMember() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/api/riders/%s", suite.TestData.Rider.UserID), strings.NewReader(`{"serviceArea": null}`))
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	request.Header.Set("Content-Type", "application/merge-patch+json")
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusBadRequest, rr.Code)
+
+	var responseObject dto.ProblemResponse
+	suite.NoError(json.NewDecoder(rr.Body).Decode(&responseObject))
+	suite.Equal([]dto.ProblemFieldError{{Field: "serviceArea", Message: "can not be removed"}}, responseObject.Errors)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Patch_Malformed() {
+	tests := []struct {
+		body   string
+		errors []dto.ProblemFieldError
+	}{
+		{`{"status": `, []dto.ProblemFieldError{{Field: "body", Message: "is not valid JSON"}}},
+		{`[]`, []dto.ProblemFieldError{{Field: "body", Message: "must be an object"}}},
+		{`{"capacity": {"width": "wide"}}`, []dto.ProblemFieldError{{Field: "capacity.width", Message: "must be an integer"}}},
+		{`{"capacity": {"depth": null}}`, []dto.ProblemFieldError{{Field: "capacity.depth", Message: "can not be removed"}}},
+		{`{"name": "rider"}`, []dto.ProblemFieldError{{Field: "name", Message: "is not a rider field"}}},
+	}
+
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+
+		request, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/api/riders/%s", suite.TestData.Rider.UserID), strings.NewReader(test.body))
+		request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+		request.Header.Set("Content-Type", "application/merge-patch+json")
+		suite.NoError(err)
+
+		suite.TestRouter.ServeHTTP(rr, request)
+
+		suite.Equal(http.StatusBadRequest, rr.Code, test.body)
+		suite.Equal("application/problem+json", rr.Header().Get("Content-Type"), test.body)
+
+		var responseObject dto.ProblemResponse
+		suite.NoError(json.NewDecoder(rr.Body).Decode(&responseObject))
+		suite.Equal(test.errors, responseObject.Errors, test.body)
+	}
+
+	suite.MockService.AssertNotCalled(suite.T(), "Patch", mock2.Anything, mock2.Anything, mock2.Anything)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Patch_UnsupportedMediaType() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/api/riders/%s", suite.TestData.Rider.UserID), strings.NewReader(`[{"op": "remove", "path": "/status"}]`))
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	request.Header.Set("Content-Type", "application/json-patch+json")
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusUnsupportedMediaType, rr.Code)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Patch_Invalid() {
+	suite.MockService.On("Patch", suite.TestData.Rider.UserID, mock2.Anything, 0).Return(domain.Rider{}, domain.NewValidationError(domain.FieldError{Field: "capacity.width", Message: "must be positive"}))
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/api/riders/%s", suite.TestData.Rider.UserID), strings.NewReader(`{"capacity": {"width": -1}}`))
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	request.Header.Set("Content-Type", "application/merge-patch+json")
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusBadRequest, rr.Code)
+
+	var responseObject dto.ProblemResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+
+	suite.Equal([]dto.ProblemFieldError{{Field: "capacity.width", Message: "must be positive"}}, responseObject.Errors)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_UpdateLocation() {
+	suite.MockService.On("UpdateLocation", suite.TestData.Rider.UserID, domain.LocationFix{Location: suite.TestData.Location}).Return(suite.TestData.Rider, nil)
+
+	rr := httptest.NewRecorder()
+
+	data, err := json.Marshal(dto.BodyLocation{Latitude: suite.TestData.Location.Latitude, Longitude: suite.TestData.Location.Longitude})
+
+	suite.NoError(err)
+
+	request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/api/riders/%s/location", suite.TestData.Rider.UserID), strings.NewReader(string(data)))
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+
+	var responseObject dto.RiderResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+
+	suite.EqualValues(suite.TestData.Rider.UserID, responseObject.ID)
+	suite.EqualValues(domain.Location{Latitude: 1.005, Longitude: 2.005}, *responseObject.Location)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_UpdateLocation_OutOfRange() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/api/riders/%s/location", suite.TestData.Rider.UserID), strings.NewReader(`{"latitude": 500, "longitude": 2}`))
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusBadRequest, rr.Code)
+
+	var responseObject dto.ProblemResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+
+	suite.Equal([]dto.ProblemFieldError{{Field: "latitude", Message: "must be at most 90"}}, responseObject.Errors)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_UpdateLocation_Telemetry() {
+	accuracy := 8.0
+	battery := 15
+
+	rider := suite.TestData.Rider
+	rider.Telemetry = domain.Telemetry{Accuracy: &accuracy, Battery: &battery}
+
+	fix := domain.LocationFix{Location: suite.TestData.Location, Telemetry: rider.Telemetry}
+	suite.MockService.On("UpdateLocation", rider.UserID, fix).Return(rider, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/api/riders/%s/location", rider.UserID), strings.NewReader(`{"latitude": 2, "longitude": 3, "accuracy": 8, "battery": 15}`))
+	request.Header.Set("X-User-Id", rider.UserID)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+
+	var responseObject dto.RiderResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+	suite.Equal(&battery, responseObject.Telemetry.Battery)
+	suite.True(responseObject.LowBattery)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_UpdateLocation_InvalidTelemetry() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/api/riders/%s/location", suite.TestData.Rider.UserID), strings.NewReader(`{"latitude": 2, "longitude": 3, "heading": 400, "battery": 101}`))
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusBadRequest, rr.Code)
+
+	var responseObject dto.ProblemResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+
+	suite.ElementsMatch([]dto.ProblemFieldError{
+		{Field: "heading", Message: "must be less than 360"},
+		{Field: "battery", Message: "must be at most 100"},
+	}, responseObject.Errors)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_UpdateLocationBatch() {
+	fixes := []domain.LocationFix{{Location: suite.TestData.Location, Sequence: 1}, {Location: suite.TestData.Rider.Location, Sequence: 2}}
+
+	suite.MockService.On("UpdateLocationBatch", suite.TestData.Rider.UserID, fixes).Return(suite.TestData.Rider, nil)
+
+	rr := httptest.NewRecorder()
+
+	body := `{"fixes": [{"latitude": 2, "longitude": 3, "sequence": 1}, {"latitude": 1, "longitude": 2, "sequence": 2}]}`
+
+	request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/riders/%s/locations:batch", suite.TestData.Rider.UserID), strings.NewReader(body))
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+
+	var responseObject dto.RiderResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
 
 	suite.EqualValues(suite.TestData.Rider.UserID, responseObject.ID)
-	suite.EqualValues(suite.TestData.Rider.Location, responseObject.Location)
+	suite.MockService.AssertCalled(suite.T(), "UpdateLocationBatch", suite.TestData.Rider.UserID, fixes)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_UpdateLocationBatch_Empty() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/riders/%s/locations:batch", suite.TestData.Rider.UserID), strings.NewReader(`{"fixes": []}`))
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusBadRequest, rr.Code)
+
+	var responseObject dto.ProblemResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+
+	suite.Equal([]dto.ProblemFieldError{{Field: "fixes", Message: "can not be empty"}}, responseObject.Errors)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_UpdateLocationBatch_UnknownAction() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/riders/%s/locations", suite.TestData.Rider.UserID), strings.NewReader(`{"fixes": []}`))
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusNotFound, rr.Code)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_UpdateLocationBatches() {
+	batches := []domain.LocationBatch{
+		{RiderID: suite.TestData.Rider.UserID, Fixes: []domain.LocationFix{{Location: suite.TestData.Location}}},
+		{RiderID: "other-id", Fixes: []domain.LocationFix{{Location: suite.TestData.Location}}},
+	}
+
+	other := suite.TestData.Rider
+	other.UserID = "other-id"
+
+	suite.MockService.On("UpdateLocationBatches", batches).Return([]domain.Rider{suite.TestData.Rider, other}, nil)
+
+	rr := httptest.NewRecorder()
+
+	body := `{"riders": [{"id": "test-id", "fixes": [{"latitude": 2, "longitude": 3}]}, {"id": "other-id", "fixes": [{"latitude": 2, "longitude": 3}]}]}`
+
+	request, err := http.NewRequest(http.MethodPost, "/api/riders/locations:batch", strings.NewReader(body))
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+
+	var responseObject []dto.RiderResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+
+	suite.Len(responseObject, 2)
+	suite.Equal("other-id", responseObject[1].ID)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_UpdateLocationBatches_OtherRider() {
+	rr := httptest.NewRecorder()
+
+	body := `{"riders": [{"id": "test-id", "fixes": [{"latitude": 2, "longitude": 3}]}, {"id": "other-id", "fixes": [{"latitude": 2, "longitude": 3}]}]}`
+
+	request, err := http.NewRequest(http.MethodPost, "/api/riders/locations:batch", strings.NewReader(body))
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusForbidden, rr.Code)
+	suite.MockService.AssertNotCalled(suite.T(), "UpdateLocationBatches", mock2.Anything)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Heartbeat() {
+	seenAt := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	seen := suite.TestData.Rider
+	seen.LastSeenAt = &seenAt
+
+	suite.MockService.On("Heartbeat", suite.TestData.Rider.UserID).Return(seen, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/riders/%s/heartbeat", suite.TestData.Rider.UserID), nil)
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+
+	var responseObject dto.RiderResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+	suite.Require().NotNil(responseObject.LastSeenAt)
+	suite.True(seenAt.Equal(*responseObject.LastSeenAt))
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_Heartbeat_OtherRider() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPost, "/api/riders/other-id/heartbeat", nil)
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusForbidden, rr.Code)
+	suite.MockService.AssertNotCalled(suite.T(), "Heartbeat", mock2.Anything)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_LoadParcel() {
+	parcel := domain.Parcel{ID: "parcel-1", Size: domain.Dimensions{Width: 50, Height: 20, Depth: 40}, Weight: 2.5}
+
+	loaded := suite.TestData.Rider
+	loaded.Parcels = domain.Parcels{{ID: "parcel-1", Size: parcel.Size, Weight: 2.5, PickedUp: true}}
+
+	suite.MockService.On("LoadParcel", suite.TestData.Rider.UserID, parcel).Return(loaded, nil)
+
+	rr := httptest.NewRecorder()
+
+	data, err := json.Marshal(dto.BodyParcel{Size: dto.CreateDimensions(parcel.Size), Weight: 2.5})
+	suite.NoError(err)
+
+	request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/api/riders/%s/parcels/parcel-1", suite.TestData.Rider.UserID), strings.NewReader(string(data)))
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+
+	var responseObject dto.RiderResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+	suite.Require().Len(responseObject.Parcels, 1)
+	suite.Equal("parcel-1", responseObject.Parcels[0].ID)
+	suite.True(responseObject.Parcels[0].PickedUp)
+	suite.EqualValues(domain.Dimensions{Width: 100, Height: 80, Depth: 100}, domain.Dimensions(responseObject.RemainingCapacity))
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_LoadParcel_InvalidSize() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/api/riders/%s/parcels/parcel-1", suite.TestData.Rider.UserID), strings.NewReader(`{"size": {"width": 0, "height": 20, "depth": 40}}`))
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusBadRequest, rr.Code)
+	suite.MockService.AssertNotCalled(suite.T(), "LoadParcel", mock2.Anything, mock2.Anything)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_LoadParcel_OtherRider() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPut, "/api/riders/other-id/parcels/parcel-1", strings.NewReader(`{"size": {"width": 50, "height": 20, "depth": 40}}`))
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusForbidden, rr.Code)
+	suite.MockService.AssertNotCalled(suite.T(), "LoadParcel", mock2.Anything, mock2.Anything)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_UnloadParcel() {
+	suite.MockService.On("UnloadParcel", suite.TestData.Rider.UserID, "parcel-1").Return(suite.TestData.Rider, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/api/riders/%s/parcels/parcel-1", suite.TestData.Rider.UserID), nil)
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+
+	var responseObject dto.RiderResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+	suite.Empty(responseObject.Parcels)
+	suite.EqualValues(suite.TestData.Rider.Capacity, domain.Dimensions(responseObject.RemainingCapacity))
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetLocationAnomalies() {
+	since := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	anomaly := domain.LocationAnomaly{
+		ID:               1,
+		RiderID:          suite.TestData.Rider.UserID,
+		Kind:             domain.AnomalyImpossibleJump,
+		Location:         suite.TestData.Location,
+		PreviousLocation: suite.TestData.Rider.Location,
+		ImpliedSpeed:     1300,
+		Rejected:         true,
+		DetectedAt:       since.Add(time.Minute),
+	}
+
+	filter := domain.LocationAnomalyFilter{RiderID: suite.TestData.Rider.UserID, Kind: domain.AnomalyImpossibleJump, Since: since, Limit: 100}
+	suite.MockService.On("GetLocationAnomalies", filter).Return([]domain.LocationAnomaly{anomaly}, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/location-anomalies?rider=test-id&kind=impossible_jump&since=2022-05-01T12:00:00Z", nil)
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+
+	var responseObject []dto.LocationAnomalyResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+	suite.Require().Len(responseObject, 1)
+	suite.Equal("impossible_jump", responseObject[0].Kind)
+	suite.EqualValues(suite.TestData.Location, responseObject[0].Location)
+	suite.True(responseObject[0].Rejected)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetLocationAnomalies_InvalidKind() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/location-anomalies?kind=teleport", nil)
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusBadRequest, rr.Code)
+
+	var responseObject dto.ProblemResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+	suite.Equal([]dto.ProblemFieldError{{Field: "kind", Message: "must be one of impossible_jump, mock_location"}}, responseObject.Errors)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetLocationAnomalies_NotAdmin() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/location-anomalies", nil)
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusForbidden, rr.Code)
+	suite.MockService.AssertNotCalled(suite.T(), "GetLocationAnomalies", mock2.Anything)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetServiceAreas() {
+	suite.MockServiceAreaService.On("GetServiceAreas").Return([]domain.ServiceArea{suite.TestData.Rider.ServiceArea}, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/service-areas", nil)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+
+	var responseObject []dto.ServiceAreaResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+	suite.Equal([]dto.ServiceAreaResponse{{ID: 1, Identifier: "test-area"}}, responseObject)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetServiceAreaRiders() {
+	deletedAt := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	rider := suite.TestData.Rider
+	rider.ServiceArea.DeletedAt = &deletedAt
+
+	suite.MockServiceAreaService.On("GetServiceArea", 1).Return(rider.ServiceArea, nil)
+	suite.MockService.On("GetAll", domain.RiderFilter{ServiceAreaID: 1}).Return([]domain.Rider{rider}, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/service-areas/1/riders", nil)
+	request.Header.Set("X-User-Claims", `{"dispatcher": true}`)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+
+	var responseObject []dto.RiderResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+	suite.Require().Len(responseObject, 1)
+	suite.Equal(rider.UserID, responseObject[0].ID)
+	suite.Equal(rider.User.Name, responseObject[0].User.Name)
+	suite.True(responseObject[0].ServiceArea.Deleted, "the rider still has to be moved out of the deleted area")
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetServiceAreaRiders_NotFound() {
+	suite.MockServiceAreaService.On("GetServiceArea", 9).Return(domain.ServiceArea{}, domain.NewNotFoundError("service area 9 not found"))
+
+	for _, path := range []string{"/api/service-areas/9/riders", "/api/service-areas/test-area/riders"} {
+		rr := httptest.NewRecorder()
+
+		request, err := http.NewRequest(http.MethodGet, path, nil)
+		request.Header.Set("X-User-Claims", `{"admin": true}`)
+		suite.NoError(err)
+
+		suite.TestRouter.ServeHTTP(rr, request)
+
+		suite.Equal(http.StatusNotFound, rr.Code, path)
+	}
+
+	suite.MockService.AssertNotCalled(suite.T(), "GetAll", mock2.Anything)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetServiceAreaRiders_NotAuthorized() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/service-areas/1/riders", nil)
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusForbidden, rr.Code)
+	suite.MockService.AssertNotCalled(suite.T(), "GetAll", mock2.Anything)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetServiceAreaStats() {
+	computedAt := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	suite.MockStatsService.On("GetServiceAreaStats", 1).Return(domain.ServiceAreaStats{
+		ServiceArea: suite.TestData.Rider.ServiceArea,
+		Riders: domain.RiderStats{
+			ByStatus:        map[int]int{domain.StatusOffline: 1, domain.StatusDelivering: 2},
+			AverageCapacity: domain.AverageDimensions{Width: 10, Height: 20, Depth: 30},
+			StaleLocations:  1,
+		},
+		ComputedAt: computedAt,
+	}, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/service-areas/1/stats", nil)
+	request.Header.Set("X-User-Claims", `{"dispatcher": true}`)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+	suite.JSONEq(`{
+		"serviceArea": {"id": 1, "identifier": "test-area"},
+		"stats": {
+			"riders": 3,
+			"online": 2,
+			"byStatus": {"offline": 1, "available": 0, "assigned": 0, "delivering": 2},
+			"averageCapacity": {"width": 10, "height": 20, "depth": 30},
+			"staleLocations": 1
+		},
+		"computedAt": "2022-05-01T12:00:00Z"
+	}`, rr.Body.String())
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetServiceAreaStats_NotFound() {
+	suite.MockStatsService.On("GetServiceAreaStats", 9).Return(domain.ServiceAreaStats{}, domain.NewNotFoundError("service area 9 not found"))
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/service-areas/9/stats", nil)
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusNotFound, rr.Code)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetServiceAreaHeatmap() {
+	computedAt := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	since := computedAt.Add(-time.Hour)
+	filter := domain.HeatmapFilter{ServiceAreaID: 1, Source: domain.HeatmapHistory, Precision: 1, Since: since}
+
+	suite.MockStatsService.On("GetServiceAreaHeatmap", filter).Return(domain.Heatmap{
+		ServiceArea: suite.TestData.Rider.ServiceArea,
+		Filter:      filter,
+		Cells:       []domain.HeatmapCell{{Geohash: "u", Count: 3, Bounds: domain.GeohashBounds("u")}},
+		ComputedAt:  computedAt,
+	}, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/service-areas/1/heatmap?source=history&precision=1&since=2022-05-01T11:00:00Z", nil)
+	request.Header.Set("X-User-Claims", `{"dispatcher": true}`)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+	suite.JSONEq(`{
+		"type": "FeatureCollection",
+		"features": [{
+			"type": "Feature",
+			"geometry": {"type": "Polygon", "coordinates": [[[0, 45], [45, 45], [45, 90], [0, 90], [0, 45]]]},
+			"properties": {"geohash": "u", "count": 3}
+		}],
+		"serviceArea": {"id": 1, "identifier": "test-area"},
+		"source": "history",
+		"precision": 1,
+		"since": "2022-05-01T11:00:00Z",
+		"computedAt": "2022-05-01T12:00:00Z"
+	}`, rr.Body.String())
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetServiceAreaHeatmap_InvalidQuery() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/service-areas/1/heatmap?source=orders", nil)
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusBadRequest, rr.Code)
+	suite.MockStatsService.AssertNotCalled(suite.T(), "GetServiceAreaHeatmap")
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetServiceAreaHeatmap_NotAuthorized() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/service-areas/1/heatmap", nil)
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusForbidden, rr.Code)
+	suite.MockStatsService.AssertNotCalled(suite.T(), "GetServiceAreaHeatmap")
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetETA() {
+	estimatedAt := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	destination := domain.Location{Latitude: 51.45, Longitude: 5.47}
+
+	suite.MockETAService.On("GetETA", suite.TestData.Rider.UserID, destination).Return(domain.RiderETA{
+		RiderID:     suite.TestData.Rider.UserID,
+		VehicleType: domain.VehicleEBike,
+		Route:       domain.Route{Distance: 1500, Duration: 90 * time.Second},
+		EstimatedAt: estimatedAt,
+	}, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/riders/test-id/eta?lat=51.45&lon=5.47", nil)
+	request.Header.Set("X-User-Claims", `{"dispatcher": true}`)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+	suite.JSONEq(`{
+		"riderId": "test-id",
+		"vehicleType": "e-bike",
+		"distance": 1500,
+		"duration": 90,
+		"estimatedAt": "2022-05-01T12:00:00Z"
+	}`, rr.Body.String())
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetETA_MissingDestination() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/riders/test-id/eta?lat=0", nil)
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusBadRequest, rr.Code)
+	suite.Contains(rr.Body.String(), `"lon"`)
+	suite.MockETAService.AssertNotCalled(suite.T(), "GetETA")
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetETA_NotAuthorized() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/riders/test-id/eta?lat=1&lon=2", nil)
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusForbidden, rr.Code)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetETAs() {
+	destination := domain.Location{Latitude: 51.45, Longitude: 5.47}
+
+	suite.MockETAService.On("GetETAs", []string{"test-id", "unknown-id"}, destination).Return([]domain.RiderETA{
+		{RiderID: "test-id", VehicleType: domain.VehicleBicycle, Route: domain.Route{Distance: 1500, Duration: 5 * time.Minute}},
+		{RiderID: "unknown-id", Err: domain.NewNotFoundError("rider unknown-id not found")},
+	}, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/riders/eta?lat=51.45&lon=5.47&rider=test-id&rider=unknown-id", nil)
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+
+	var responseObject []dto.RiderETAResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+	suite.Require().Len(responseObject, 2)
+	suite.Equal(300.0, responseObject[0].Duration)
+	suite.Empty(responseObject[0].Error)
+	suite.Equal("rider unknown-id not found", responseObject[1].Error)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetStatsSummary() {
+	area := domain.ServiceAreaStats{
+		ServiceArea: suite.TestData.Rider.ServiceArea,
+		Riders:      domain.RiderStats{ByStatus: map[int]int{domain.StatusAvailable: 2}},
+	}
+
+	suite.MockStatsService.On("GetStatsSummary").Return(domain.StatsSummary{
+		ServiceAreas: []domain.ServiceAreaStats{area},
+		Total:        area.Riders,
+	}, nil)
+
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/service-areas/stats", nil)
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+
+	var responseObject dto.StatsSummaryResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+	suite.Require().Len(responseObject.ServiceAreas, 1)
+	suite.Equal("test-area", responseObject.ServiceAreas[0].ServiceArea.Identifier)
+	suite.Equal(2, responseObject.ServiceAreas[0].Stats.Online)
+	suite.Equal(2, responseObject.Total.Riders)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_GetStatsSummary_NotAuthorized() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/service-areas/stats", nil)
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusForbidden, rr.Code)
+	suite.MockStatsService.AssertNotCalled(suite.T(), "GetStatsSummary")
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_ImportRiders_CSV() {
+	capacity := domain.Dimensions{Width: 50, Height: 40, Depth: 30}
+	rows := []domain.RiderImportRow{
+		{Line: 2, UserID: "rider-1", ServiceAreaID: 1, Capacity: capacity},
+		{Line: 3, UserID: "rider-2", ServiceAreaID: 2, Capacity: domain.Dimensions{Width: 50, Height: 40}, Invalid: []domain.FieldError{{Field: "capacity.depth", Message: "must be a whole number"}}},
+		{Line: 4, UserID: "rider-3", Invalid: []domain.FieldError{{Field: "row", Message: "must have 5 fields"}}},
+	}
+	results := []domain.RiderImportResult{
+		{Line: 2, UserID: "rider-1", Status: domain.ImportValid},
+		{Line: 3, UserID: "rider-2", Status: domain.ImportFailed, Err: domain.NewValidationError(rows[1].Invalid...)},
+		{Line: 4, UserID: "rider-3", Status: domain.ImportFailed, Err: domain.NewValidationError(rows[2].Invalid...)},
+	}
+
+	suite.MockService.On("ImportRiders", rows, false).Return(results, nil)
+
+	rr := httptest.NewRecorder()
+
+	body := "id,serviceArea,width,height,depth\nrider-1,1,50,40,30\nrider-2,2,50,40,deep\nrider-3,1\n"
+	request, err := http.NewRequest(http.MethodPost, "/api/riders/import", strings.NewReader(body))
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+
+	var responseObject dto.RiderImportResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+	suite.True(responseObject.DryRun)
+	suite.Equal(1, responseObject.Valid)
+	suite.Equal(2, responseObject.Failed)
+	suite.Require().Len(responseObject.Rows, 3)
+	suite.Equal([]dto.ProblemFieldError{{Field: "capacity.depth", Message: "must be a whole number"}}, responseObject.Rows[1].Errors)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_ImportRiders_NDJSON() {
+	rows := []domain.RiderImportRow{
+		{Line: 1, UserID: "rider-1", ServiceAreaID: 1, Capacity: domain.Dimensions{Width: 50, Height: 40, Depth: 30}},
+		{Line: 3, Invalid: []domain.FieldError{{Field: "row", Message: "is not a valid JSON object"}}},
+	}
+	results := []domain.RiderImportResult{
+		{Line: 1, UserID: "rider-1", Status: domain.ImportCreated},
+		{Line: 3, Status: domain.ImportFailed, Err: domain.NewValidationError(rows[1].Invalid...)},
+	}
+
+	suite.MockService.On("ImportRiders", rows, true).Return(results, nil)
+
+	rr := httptest.NewRecorder()
+
+	body := `{"id": "rider-1", "serviceArea": 1, "capacity": {"width": 50, "height": 40, "depth": 30}}` + "\n\n{\n"
+	request, err := http.NewRequest(http.MethodPost, "/api/riders/import?format=ndjson&commit=true", strings.NewReader(body))
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusOK, rr.Code)
+
+	var responseObject dto.RiderImportResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+	suite.False(responseObject.DryRun)
+	suite.Equal(1, responseObject.Created)
+	suite.Equal(1, responseObject.Failed)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_ImportRiders_MissingColumn() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPost, "/api/riders/import", strings.NewReader("id,serviceArea,width,height\nrider-1,1,50,40\n"))
+	request.Header.Set("X-User-Claims", `{"admin": true}`)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusBadRequest, rr.Code)
+
+	var responseObject dto.ProblemResponse
+	err = json.NewDecoder(rr.Body).Decode(&responseObject)
+
+	suite.NoError(err)
+	suite.Equal([]dto.ProblemFieldError{{Field: "header", Message: "misses the depth column"}}, responseObject.Errors)
+	suite.MockService.AssertNotCalled(suite.T(), "ImportRiders", mock2.Anything, mock2.Anything)
+}
+
+func (suite *RestHandlerTestSuite) TestHandler_ImportRiders_NotAdmin() {
+	rr := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPost, "/api/riders/import?commit=true", strings.NewReader("id,serviceArea,width,height,depth\n"))
+	request.Header.Set("X-User-Id", suite.TestData.Rider.UserID)
+	suite.NoError(err)
+
+	suite.TestRouter.ServeHTTP(rr, request)
+
+	suite.Equal(http.StatusForbidden, rr.Code)
+	suite.MockService.AssertNotCalled(suite.T(), "ImportRiders", mock2.Anything, mock2.Anything)
 }
 
 func TestIntegration_RestHandlerTestSuite(t *testing.T) {
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"reflect"
+	"rider-service/internal/core/domain"
+	"strings"
+)
+
+func init() {
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		// Report fields by their JSON name, so they match the request body.
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+
+			if name == "-" {
+				return ""
+			}
+
+			return name
+		})
+	}
+}
+
+// bindJSON binds the request body and converts binding and validation failures into a validation error.
+func bindJSON(c *gin.Context, body interface{}) error {
+	return bindingError(c.ShouldBindJSON(body), domain.FieldError{Field: "body", Message: "is not valid JSON"})
+}
+
+// bindQuery binds the query parameters like bindJSON binds the body.
+func bindQuery(c *gin.Context, query interface{}) error {
+	return bindingError(c.ShouldBindQuery(query), domain.FieldError{Field: "query", Message: "is not valid"})
+}
+
+// bindingError converts validation failures into a validation error of their fields, and any other failure into malformed.
+func bindingError(err error, malformed domain.FieldError) error {
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+
+	if !errors.As(err, &validationErrors) {
+		return domain.NewValidationError(malformed)
+	}
+
+	fields := make([]domain.FieldError, 0, len(validationErrors))
+
+	for _, fieldErr := range validationErrors {
+		fields = append(fields, domain.FieldError{
+			Field:   fieldPath(fieldErr.Namespace()),
+			Message: validationMessage(fieldErr),
+		})
+	}
+
+	return domain.NewValidationError(fields...)
+}
+
+// fieldPath strips the struct name from a validator namespace, "BodyCreateRider.capacity.width" becomes "capacity.width".
+func fieldPath(namespace string) string {
+	parts := strings.SplitN(namespace, ".", 2)
+
+	if len(parts) < 2 {
+		return namespace
+	}
+
+	return parts[1]
+}
+
+func validationMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fieldErr.Param())
+	case "gte":
+		return fmt.Sprintf("must be at least %s", fieldErr.Param())
+	case "lt":
+		return fmt.Sprintf("must be less than %s", fieldErr.Param())
+	case "lte":
+		return fmt.Sprintf("must be at most %s", fieldErr.Param())
+	case "min":
+		if fieldErr.Kind() == reflect.Slice && fieldErr.Param() == "1" {
+			return "can not be empty"
+		}
+		if fieldErr.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at least %s items", fieldErr.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fieldErr.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", strings.Join(strings.Fields(fieldErr.Param()), ", "))
+	case "max":
+		if fieldErr.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at most %s items", fieldErr.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fieldErr.Param())
+	}
+
+	return fmt.Sprintf("failed the %s check", fieldErr.Tag())
+}
@@ -0,0 +1,21 @@
+package mock
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+	"rider-service/internal/core/domain"
+)
+
+type ETAService struct {
+	mock.Mock
+}
+
+func (m *ETAService) GetETA(ctx context.Context, riderID string, destination domain.Location) (domain.RiderETA, error) {
+	args := m.Called(riderID, destination)
+	return args.Get(0).(domain.RiderETA), args.Error(1)
+}
+
+func (m *ETAService) GetETAs(ctx context.Context, riderIDs []string, destination domain.Location) ([]domain.RiderETA, error) {
+	args := m.Called(riderIDs, destination)
+	return args.Get(0).([]domain.RiderETA), args.Error(1)
+}
@@ -15,12 +15,32 @@ func (m *MessageBusPublisher) CreateRider(ctx context.Context, rider domain.Ride
 	return args.Error(0)
 }
 
-func (m *MessageBusPublisher) UpdateRider(ctx context.Context, rider domain.Rider) error {
+func (m *MessageBusPublisher) UpdateRider(ctx context.Context, rider domain.Rider, changedFields []string) error {
+	args := m.Called(rider, changedFields)
+	return args.Error(0)
+}
+
+func (m *MessageBusPublisher) UpdateRiderLocation(ctx context.Context, serviceArea domain.ServiceArea, id string, newLocation domain.Location, telemetry domain.Telemetry) error {
+	args := m.Called(serviceArea, id, newLocation, telemetry)
+	return args.Error(0)
+}
+
+func (m *MessageBusPublisher) UpdateRiderLocationBatch(ctx context.Context, serviceArea domain.ServiceArea, id string, fixes []domain.LocationFix) error {
+	args := m.Called(serviceArea, id, fixes)
+	return args.Error(0)
+}
+
+func (m *MessageBusPublisher) RiderPresenceLost(ctx context.Context, rider domain.Rider) error {
 	args := m.Called(rider)
 	return args.Error(0)
 }
 
-func (m *MessageBusPublisher) UpdateRiderLocation(ctx context.Context, serviceArea domain.ServiceArea, id string, newLocation domain.Location) error {
-	args := m.Called(serviceArea, id, newLocation)
+func (m *MessageBusPublisher) LocationAnomaly(ctx context.Context, anomaly domain.LocationAnomaly) error {
+	args := m.Called(anomaly)
+	return args.Error(0)
+}
+
+func (m *MessageBusPublisher) ServiceAreaStats(ctx context.Context, stats domain.ServiceAreaStats) error {
+	args := m.Called(stats)
 	return args.Error(0)
 }
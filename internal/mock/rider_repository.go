@@ -4,22 +4,40 @@ import (
 	"context"
 	"github.com/stretchr/testify/mock"
 	"rider-service/internal/core/domain"
+	"time"
 )
 
 type RiderRepository struct {
 	mock.Mock
 }
 
-func (m *RiderRepository) GetAll(ctx context.Context) ([]domain.Rider, error) {
-	args := m.Called()
+func (m *RiderRepository) GetAll(ctx context.Context, filter domain.RiderFilter) ([]domain.Rider, error) {
+	args := m.Called(filter)
 	return args.Get(0).([]domain.Rider), args.Error(1)
 }
 
+func (m *RiderRepository) StreamAll(ctx context.Context, filter domain.RiderFilter, fn func(domain.Rider) error) error {
+	args := m.Called(filter)
+
+	for _, rider := range args.Get(0).([]domain.Rider) {
+		if err := fn(rider); err != nil {
+			return err
+		}
+	}
+
+	return args.Error(1)
+}
+
 func (m *RiderRepository) Get(ctx context.Context, id string) (domain.Rider, error) {
 	args := m.Called(id)
 	return args.Get(0).(domain.Rider), args.Error(1)
 }
 
+func (m *RiderRepository) GetSilentRiders(ctx context.Context, seenBefore time.Time) ([]domain.Rider, error) {
+	args := m.Called(seenBefore)
+	return args.Get(0).([]domain.Rider), args.Error(1)
+}
+
 func (m *RiderRepository) Save(ctx context.Context, rider domain.Rider) (domain.Rider, error) {
 	args := m.Called(rider)
 	return args.Get(0).(domain.Rider), args.Error(1)
@@ -39,3 +57,38 @@ func (m *RiderRepository) GetUser(ctx context.Context, id string) (domain.User,
 	args := m.Called(id)
 	return args.Get(0).(domain.User), args.Error(1)
 }
+
+func (m *RiderRepository) GetServiceArea(ctx context.Context, id int) (domain.ServiceArea, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.ServiceArea), args.Error(1)
+}
+
+func (m *RiderRepository) UpdateLocations(ctx context.Context, riders []domain.Rider) error {
+	args := m.Called(riders)
+	return args.Error(0)
+}
+
+func (m *RiderRepository) SaveLocationBatches(ctx context.Context, riders []domain.Rider, history []domain.RiderLocation) error {
+	args := m.Called(riders, history)
+	return args.Error(0)
+}
+
+func (m *RiderRepository) SaveLocationAnomalies(ctx context.Context, anomalies []domain.LocationAnomaly) error {
+	args := m.Called(anomalies)
+	return args.Error(0)
+}
+
+func (m *RiderRepository) GetLocationAnomalies(ctx context.Context, filter domain.LocationAnomalyFilter) ([]domain.LocationAnomaly, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]domain.LocationAnomaly), args.Error(1)
+}
+
+func (m *RiderRepository) GetRiderStats(ctx context.Context, filter domain.RiderFilter, staleBefore time.Time) (map[int]domain.RiderStats, error) {
+	args := m.Called(filter, staleBefore)
+	return args.Get(0).(map[int]domain.RiderStats), args.Error(1)
+}
+
+func (m *RiderRepository) GetHeatmap(ctx context.Context, filter domain.HeatmapFilter) ([]domain.HeatmapCell, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]domain.HeatmapCell), args.Error(1)
+}
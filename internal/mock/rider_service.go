@@ -10,11 +10,24 @@ type RiderService struct {
 	mock.Mock
 }
 
-func (m *RiderService) GetAll(ctx context.Context) ([]domain.Rider, error) {
-	args := m.Called()
+func (m *RiderService) GetAll(ctx context.Context, filter domain.RiderFilter) ([]domain.Rider, error) {
+	args := m.Called(filter)
 	return args.Get(0).([]domain.Rider), args.Error(1)
 }
 
+// StreamAll passes the riders given to Return to fn before returning the error.
+func (m *RiderService) StreamAll(ctx context.Context, filter domain.RiderFilter, fn func(domain.Rider) error) error {
+	args := m.Called(filter)
+
+	for _, rider := range args.Get(0).([]domain.Rider) {
+		if err := fn(rider); err != nil {
+			return err
+		}
+	}
+
+	return args.Error(1)
+}
+
 func (m *RiderService) Get(ctx context.Context, id string) (domain.Rider, error) {
 	args := m.Called(id)
 	return args.Get(0).(domain.Rider), args.Error(1)
@@ -25,17 +38,67 @@ func (m *RiderService) Create(ctx context.Context, userId string, serviceArea in
 	return args.Get(0).(domain.Rider), args.Error(1)
 }
 
-func (m *RiderService) Update(ctx context.Context, id string, status int, serviceArea int, capacity domain.Dimensions) (domain.Rider, error) {
-	args := m.Called(id, status, serviceArea, capacity)
+func (m *RiderService) ImportRiders(ctx context.Context, rows []domain.RiderImportRow, commit bool) ([]domain.RiderImportResult, error) {
+	args := m.Called(rows, commit)
+	return args.Get(0).([]domain.RiderImportResult), args.Error(1)
+}
+
+func (m *RiderService) Update(ctx context.Context, id string, status int, serviceArea int, capacity domain.Dimensions, version int) (domain.Rider, error) {
+	args := m.Called(id, status, serviceArea, capacity, version)
+	return args.Get(0).(domain.Rider), args.Error(1)
+}
+
+func (m *RiderService) Patch(ctx context.Context, id string, patch domain.RiderPatch, version int) (domain.Rider, error) {
+	args := m.Called(id, patch, version)
 	return args.Get(0).(domain.Rider), args.Error(1)
 }
 
-func (m *RiderService) UpdateLocation(ctx context.Context, id string, location domain.Location) (domain.Rider, error) {
-	args := m.Called(id, location)
+func (m *RiderService) UpdateLocation(ctx context.Context, id string, fix domain.LocationFix) (domain.Rider, error) {
+	args := m.Called(id, fix)
 	return args.Get(0).(domain.Rider), args.Error(1)
 }
 
+func (m *RiderService) UpdateLocationBatch(ctx context.Context, id string, fixes []domain.LocationFix) (domain.Rider, error) {
+	args := m.Called(id, fixes)
+	return args.Get(0).(domain.Rider), args.Error(1)
+}
+
+func (m *RiderService) UpdateLocationBatches(ctx context.Context, batches []domain.LocationBatch) ([]domain.Rider, error) {
+	args := m.Called(batches)
+	return args.Get(0).([]domain.Rider), args.Error(1)
+}
+
+func (m *RiderService) Heartbeat(ctx context.Context, id string) (domain.Rider, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.Rider), args.Error(1)
+}
+
+func (m *RiderService) GetLocationAnomalies(ctx context.Context, filter domain.LocationAnomalyFilter) ([]domain.LocationAnomaly, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]domain.LocationAnomaly), args.Error(1)
+}
+
 func (m *RiderService) SaveOrUpdateUser(ctx context.Context, user domain.User) error {
 	args := m.Called(user)
 	return args.Error(0)
 }
+
+func (m *RiderService) ReassignServiceArea(ctx context.Context, from int, to int) ([]domain.Rider, error) {
+	args := m.Called(from, to)
+	return args.Get(0).([]domain.Rider), args.Error(1)
+}
+
+func (m *RiderService) HandleDeliveryEvent(ctx context.Context, event domain.DeliveryEvent) (domain.Rider, error) {
+	args := m.Called(event)
+	return args.Get(0).(domain.Rider), args.Error(1)
+}
+
+func (m *RiderService) LoadParcel(ctx context.Context, riderID string, parcel domain.Parcel) (domain.Rider, error) {
+	args := m.Called(riderID, parcel)
+	return args.Get(0).(domain.Rider), args.Error(1)
+}
+
+func (m *RiderService) UnloadParcel(ctx context.Context, riderID string, parcelID string) (domain.Rider, error) {
+	args := m.Called(riderID, parcelID)
+	return args.Get(0).(domain.Rider), args.Error(1)
+}
@@ -0,0 +1,16 @@
+package mock
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+	"rider-service/internal/core/domain"
+)
+
+type RouteEstimator struct {
+	mock.Mock
+}
+
+func (m *RouteEstimator) EstimateRoutes(ctx context.Context, origins []domain.RouteOrigin, destination domain.Location) ([]domain.Route, error) {
+	args := m.Called(origins, destination)
+	return args.Get(0).([]domain.Route), args.Error(1)
+}
@@ -1,6 +1,7 @@
 package mock
 
 import (
+	"context"
 	"github.com/stretchr/testify/mock"
 	"rider-service/internal/core/domain"
 )
@@ -13,3 +14,18 @@ func (m *ServiceAreaRepository) SaveOrUpdateServiceArea(serviceArea domain.Servi
 	args := m.Called(serviceArea)
 	return args.Error(0)
 }
+
+func (m *ServiceAreaRepository) GetServiceAreas(ctx context.Context) ([]domain.ServiceArea, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.ServiceArea), args.Error(1)
+}
+
+func (m *ServiceAreaRepository) GetServiceArea(ctx context.Context, id int) (domain.ServiceArea, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.ServiceArea), args.Error(1)
+}
+
+func (m *ServiceAreaRepository) DeleteServiceArea(ctx context.Context, id int, version int) error {
+	args := m.Called(id, version)
+	return args.Error(0)
+}
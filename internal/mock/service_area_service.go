@@ -1,6 +1,7 @@
 package mock
 
 import (
+	"context"
 	"github.com/stretchr/testify/mock"
 	"rider-service/internal/core/domain"
 )
@@ -13,3 +14,18 @@ func (m *ServiceAreaService) SaveOrUpdateServiceArea(serviceArea domain.ServiceA
 	args := m.Called(serviceArea)
 	return args.Error(0)
 }
+
+func (m *ServiceAreaService) GetServiceAreas(ctx context.Context) ([]domain.ServiceArea, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.ServiceArea), args.Error(1)
+}
+
+func (m *ServiceAreaService) GetServiceArea(ctx context.Context, id int) (domain.ServiceArea, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.ServiceArea), args.Error(1)
+}
+
+func (m *ServiceAreaService) DeleteServiceArea(ctx context.Context, id int, version int, reassignTo int) error {
+	args := m.Called(id, version, reassignTo)
+	return args.Error(0)
+}
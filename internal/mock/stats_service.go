@@ -0,0 +1,26 @@
+package mock
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+	"rider-service/internal/core/domain"
+)
+
+type StatsService struct {
+	mock.Mock
+}
+
+func (m *StatsService) GetServiceAreaStats(ctx context.Context, id int) (domain.ServiceAreaStats, error) {
+	args := m.Called(id)
+	return args.Get(0).(domain.ServiceAreaStats), args.Error(1)
+}
+
+func (m *StatsService) GetStatsSummary(ctx context.Context) (domain.StatsSummary, error) {
+	args := m.Called()
+	return args.Get(0).(domain.StatsSummary), args.Error(1)
+}
+
+func (m *StatsService) GetServiceAreaHeatmap(ctx context.Context, filter domain.HeatmapFilter) (domain.Heatmap, error) {
+	args := m.Called(filter)
+	return args.Get(0).(domain.Heatmap), args.Error(1)
+}
@@ -0,0 +1,758 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"rider-service/config"
+	"rider-service/internal/core/domain"
+	"rider-service/internal/core/interfaces"
+	"testing"
+	"time"
+)
+
+// RepositoryConformanceTestSuite runs the same behavioural checks against every repository implementation.
+// NewRepositories is called before each test and must return empty repositories.
+type RepositoryConformanceTestSuite struct {
+	suite.Suite
+	NewRepositories       func() (interfaces.RiderRepository, interfaces.ServiceAreaRepository)
+	RiderRepository       interfaces.RiderRepository
+	ServiceAreaRepository interfaces.ServiceAreaRepository
+	TestData              struct {
+		Rider    domain.Rider
+		Location domain.Location
+	}
+}
+
+func (suite *RepositoryConformanceTestSuite) SetupSuite() {
+	suite.TestData = struct {
+		Rider    domain.Rider
+		Location domain.Location
+	}{
+		Rider: domain.Rider{
+			UserID: "test-id",
+			User: domain.User{
+				ID:       "test-id",
+				Name:     "test-name",
+				LastName: "test-lastname",
+			},
+			Status:        1,
+			ServiceAreaID: 1,
+			ServiceArea: domain.ServiceArea{
+				ID:         1,
+				Identifier: "test-area",
+			},
+			Capacity: domain.Dimensions{
+				Width:  100,
+				Height: 100,
+				Depth:  100,
+			},
+			VehicleType: domain.VehicleBicycle,
+			Location: domain.Location{
+				Latitude:  1,
+				Longitude: 2,
+			},
+			Version: 1,
+		},
+		Location: domain.Location{
+			Latitude:  2,
+			Longitude: 3,
+		},
+	}
+}
+
+func (suite *RepositoryConformanceTestSuite) SetupTest() {
+	suite.RiderRepository, suite.ServiceAreaRepository = suite.NewRepositories()
+
+	suite.Require().NoError(suite.RiderRepository.SaveOrUpdateUser(context.Background(), suite.TestData.Rider.User))
+	suite.Require().NoError(suite.ServiceAreaRepository.SaveOrUpdateServiceArea(suite.TestData.Rider.ServiceArea))
+}
+
+func (suite *RepositoryConformanceTestSuite) saveTestRider() {
+	rider := suite.TestData.Rider
+	rider.User = domain.User{}
+	rider.ServiceArea = domain.ServiceArea{}
+
+	_, err := suite.RiderRepository.Save(context.Background(), rider)
+	suite.Require().NoError(err)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_Get() {
+	suite.saveTestRider()
+
+	result, err := suite.RiderRepository.Get(context.Background(), suite.TestData.Rider.UserID)
+
+	suite.NoError(err)
+	suite.EqualValues(suite.TestData.Rider, result)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_Get_NotFound() {
+	_, err := suite.RiderRepository.Get(context.Background(), "unknown")
+
+	suite.ErrorIs(err, domain.ErrNotFound)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_GetAll() {
+	suite.saveTestRider()
+
+	result, err := suite.RiderRepository.GetAll(context.Background(), domain.RiderFilter{})
+
+	suite.NoError(err)
+	suite.Require().Len(result, 1)
+	suite.Equal(suite.TestData.Rider.UserID, result[0].UserID)
+	suite.Equal(suite.TestData.Rider.User, result[0].User)
+	suite.Equal(suite.TestData.Rider.ServiceArea, result[0].ServiceArea)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_GetAll_Filter() {
+	suite.saveTestRider()
+
+	otherStatus := suite.TestData.Rider.Status + 1
+
+	result, err := suite.RiderRepository.GetAll(context.Background(), domain.RiderFilter{Status: &otherStatus})
+
+	suite.NoError(err)
+	suite.Empty(result)
+
+	result, err = suite.RiderRepository.GetAll(context.Background(), domain.RiderFilter{Status: &suite.TestData.Rider.Status, ServiceAreaID: suite.TestData.Rider.ServiceAreaID})
+
+	suite.NoError(err)
+	suite.Len(result, 1)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_GetAll_Fits() {
+	suite.saveTestRider()
+
+	loaded := suite.TestData.Rider
+	loaded.User = domain.User{}
+	loaded.ServiceArea = domain.ServiceArea{}
+	loaded.Parcels = domain.Parcels{{ID: "parcel-id", Size: domain.Dimensions{Width: 100, Height: 90, Depth: 100}, PickedUp: true}}
+
+	_, err := suite.RiderRepository.Update(context.Background(), loaded)
+	suite.Require().NoError(err)
+
+	fits := domain.Dimensions{Width: 100, Height: 10, Depth: 10}
+	result, err := suite.RiderRepository.GetAll(context.Background(), domain.RiderFilter{Fits: &fits})
+
+	suite.NoError(err)
+	suite.Len(result, 1)
+
+	tooLarge := domain.Dimensions{Width: 20, Height: 20, Depth: 20}
+	result, err = suite.RiderRepository.GetAll(context.Background(), domain.RiderFilter{Fits: &tooLarge})
+
+	suite.NoError(err)
+	suite.Empty(result, "the box is large enough, but not with the parcel in it")
+
+	err = suite.RiderRepository.StreamAll(context.Background(), domain.RiderFilter{Fits: &tooLarge}, func(rider domain.Rider) error {
+		result = append(result, rider)
+		return nil
+	})
+
+	suite.NoError(err)
+	suite.Empty(result)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_StreamAll() {
+	suite.saveTestRider()
+
+	second := domain.User{ID: "test-id-2", Name: "second", LastName: "rider"}
+	suite.Require().NoError(suite.RiderRepository.SaveOrUpdateUser(context.Background(), second))
+
+	_, err := suite.RiderRepository.Save(context.Background(), domain.NewRider(second, domain.StatusOffline, suite.TestData.Rider.ServiceAreaID, suite.TestData.Rider.Capacity))
+	suite.Require().NoError(err)
+
+	var streamed []domain.Rider
+
+	err = suite.RiderRepository.StreamAll(context.Background(), domain.RiderFilter{}, func(rider domain.Rider) error {
+		streamed = append(streamed, rider)
+		return nil
+	})
+
+	suite.NoError(err)
+	suite.Require().Len(streamed, 2)
+	suite.Equal(suite.TestData.Rider.UserID, streamed[0].UserID)
+	suite.Equal(second.ID, streamed[1].UserID)
+	suite.Equal(second.Name, streamed[1].User.Name, "riders are streamed with their user")
+	suite.Equal(suite.TestData.Rider.ServiceArea.Identifier, streamed[1].ServiceArea.Identifier, "riders are streamed with their service area")
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_StreamAll_StopsAtError() {
+	suite.saveTestRider()
+
+	stop := errors.New("stop")
+	calls := 0
+
+	err := suite.RiderRepository.StreamAll(context.Background(), domain.RiderFilter{}, func(rider domain.Rider) error {
+		calls++
+		return stop
+	})
+
+	suite.ErrorIs(err, stop)
+	suite.Equal(1, calls)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_Save_DefaultsVehicleType() {
+	rider := suite.TestData.Rider
+	rider.User = domain.User{}
+	rider.ServiceArea = domain.ServiceArea{}
+	rider.VehicleType = ""
+
+	_, err := suite.RiderRepository.Save(context.Background(), rider)
+	suite.Require().NoError(err)
+
+	result, err := suite.RiderRepository.Get(context.Background(), rider.UserID)
+
+	suite.NoError(err)
+	suite.Equal(domain.VehicleBicycle, result.VehicleType)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_Save_Duplicate() {
+	suite.saveTestRider()
+
+	_, err := suite.RiderRepository.Save(context.Background(), suite.TestData.Rider)
+
+	suite.ErrorIs(err, domain.ErrConflict)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_Save_UnknownUser() {
+	rider := suite.TestData.Rider
+	rider.UserID = "unknown"
+	rider.User = domain.User{}
+
+	_, err := suite.RiderRepository.Save(context.Background(), rider)
+
+	suite.ErrorIs(err, domain.ErrValidation)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_Update() {
+	suite.saveTestRider()
+
+	updated := suite.TestData.Rider
+	updated.User = domain.User{}
+	updated.ServiceArea = domain.ServiceArea{}
+	updated.Capacity = domain.Dimensions{Width: 1, Height: 1, Depth: 1}
+	updated.VehicleType = domain.VehicleCargoBike
+	updated.DeliveryID = "delivery-id"
+	updated.Parcels = domain.Parcels{{ID: "delivery-id", DeliveryID: "delivery-id", Size: domain.Dimensions{Width: 1, Height: 1, Depth: 1}, Weight: 0.5}}
+	updated.Location = suite.TestData.Location
+
+	_, err := suite.RiderRepository.Update(context.Background(), updated)
+	suite.NoError(err)
+
+	result, err := suite.RiderRepository.Get(context.Background(), updated.UserID)
+
+	suite.NoError(err)
+	suite.EqualValues(updated.Capacity, result.Capacity)
+	suite.Equal(domain.VehicleCargoBike, result.VehicleType)
+	suite.Equal("delivery-id", result.DeliveryID)
+	suite.Equal(updated.Parcels, result.Parcels)
+	suite.EqualValues(suite.TestData.Rider.Location, result.Location, "the location is only written by UpdateLocations")
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_Update_WritesZeroFields() {
+	suite.saveTestRider()
+
+	updated := suite.TestData.Rider
+	updated.Status = 0
+
+	_, err := suite.RiderRepository.Update(context.Background(), updated)
+	suite.NoError(err)
+
+	result, err := suite.RiderRepository.Get(context.Background(), updated.UserID)
+
+	suite.NoError(err)
+	suite.Equal(0, result.Status)
+	suite.EqualValues(suite.TestData.Rider.Capacity, result.Capacity)
+	suite.EqualValues(suite.TestData.Rider.ServiceAreaID, result.ServiceAreaID)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_Update_IncrementsVersion() {
+	suite.saveTestRider()
+
+	updated := suite.TestData.Rider
+	updated.Status = 2
+
+	result, err := suite.RiderRepository.Update(context.Background(), updated)
+
+	suite.NoError(err)
+	suite.Equal(suite.TestData.Rider.Version+1, result.Version)
+
+	stored, err := suite.RiderRepository.Get(context.Background(), updated.UserID)
+
+	suite.NoError(err)
+	suite.Equal(suite.TestData.Rider.Version+1, stored.Version)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_Update_StaleVersion() {
+	suite.saveTestRider()
+
+	stale := suite.TestData.Rider
+	stale.Status = 2
+
+	_, err := suite.RiderRepository.Update(context.Background(), stale)
+	suite.NoError(err)
+
+	_, err = suite.RiderRepository.Update(context.Background(), stale)
+	suite.ErrorIs(err, domain.ErrVersionConflict)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_UpdateLocations() {
+	suite.saveTestRider()
+
+	timestamp := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	updated := suite.TestData.Rider
+	updated.Status = 5
+	updated.Location = suite.TestData.Location
+	updated.LocationTimestamp = &timestamp
+	updated.LocationSequence = 4
+
+	speed := 4.5
+	battery := 80
+	updated.Telemetry = domain.Telemetry{Speed: &speed, Battery: &battery}
+
+	err := suite.RiderRepository.UpdateLocations(context.Background(), []domain.Rider{updated})
+	suite.NoError(err)
+
+	result, err := suite.RiderRepository.Get(context.Background(), updated.UserID)
+
+	suite.NoError(err)
+	suite.EqualValues(suite.TestData.Location, result.Location)
+	suite.True(timestamp.Equal(*result.LocationTimestamp))
+	suite.EqualValues(4, result.LocationSequence)
+	suite.Equal(updated.Telemetry, result.Telemetry)
+	suite.Equal(suite.TestData.Rider.Status, result.Status, "only the location is written")
+	suite.Equal(suite.TestData.Rider.Version, result.Version, "location updates do not change the version")
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_UpdateLocations_UnknownRider() {
+	unknown := suite.TestData.Rider
+	unknown.UserID = "unknown"
+
+	err := suite.RiderRepository.UpdateLocations(context.Background(), []domain.Rider{unknown})
+	suite.NoError(err)
+
+	_, err = suite.RiderRepository.Get(context.Background(), unknown.UserID)
+	suite.ErrorIs(err, domain.ErrNotFound)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_UpdateLocations_LastSeenOnlyMovesForward() {
+	suite.saveTestRider()
+
+	seenAt := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	earlier := seenAt.Add(-time.Minute)
+
+	seen := suite.TestData.Rider
+	seen.LastSeenAt = &seenAt
+	suite.NoError(suite.RiderRepository.UpdateLocations(context.Background(), []domain.Rider{seen}))
+
+	seen.LastSeenAt = &earlier
+	suite.NoError(suite.RiderRepository.UpdateLocations(context.Background(), []domain.Rider{seen}))
+
+	result, err := suite.RiderRepository.Get(context.Background(), seen.UserID)
+
+	suite.NoError(err)
+	suite.True(seenAt.Equal(*result.LastSeenAt))
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_GetSilentRiders() {
+	suite.saveTestRider()
+
+	seenAt := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	seen := suite.TestData.Rider
+	seen.LastSeenAt = &seenAt
+	suite.NoError(suite.RiderRepository.UpdateLocations(context.Background(), []domain.Rider{seen}))
+
+	riders, err := suite.RiderRepository.GetSilentRiders(context.Background(), seenAt)
+	suite.NoError(err)
+	suite.Empty(riders)
+
+	riders, err = suite.RiderRepository.GetSilentRiders(context.Background(), seenAt.Add(time.Second))
+	suite.NoError(err)
+	suite.Require().Len(riders, 1)
+	suite.Equal(seen.UserID, riders[0].UserID)
+	suite.Equal(suite.TestData.Rider.ServiceArea.Identifier, riders[0].ServiceArea.Identifier)
+
+	offline := suite.TestData.Rider
+	offline.Status = domain.StatusOffline
+	_, err = suite.RiderRepository.Update(context.Background(), offline)
+	suite.NoError(err)
+
+	riders, err = suite.RiderRepository.GetSilentRiders(context.Background(), seenAt.Add(time.Second))
+	suite.NoError(err)
+	suite.Empty(riders, "offline riders are not silent")
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_GetSilentRiders_NeverSeen() {
+	suite.saveTestRider()
+
+	riders, err := suite.RiderRepository.GetSilentRiders(context.Background(), time.Now())
+
+	suite.NoError(err)
+	suite.Require().Len(riders, 1, "online riders that were never seen are silent")
+	suite.Equal(suite.TestData.Rider.UserID, riders[0].UserID)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_Update_LastSeenAt() {
+	ctx := context.Background()
+	seenAt := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	earlier := seenAt.Add(-time.Minute)
+
+	suite.saveTestRider()
+
+	seen := suite.TestData.Rider
+	seen.LastSeenAt = &seenAt
+	seen, err := suite.RiderRepository.Update(ctx, seen)
+	suite.Require().NoError(err)
+
+	seen.LastSeenAt = &earlier
+	_, err = suite.RiderRepository.Update(ctx, seen)
+	suite.Require().NoError(err)
+
+	result, err := suite.RiderRepository.Get(ctx, suite.TestData.Rider.UserID)
+
+	suite.NoError(err)
+	suite.Require().NotNil(result.LastSeenAt)
+	suite.True(seenAt.Equal(*result.LastSeenAt), "the last seen time only moves forward")
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_GetRiderStats() {
+	ctx := context.Background()
+	seenAt := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	suite.saveTestRider()
+
+	seen := suite.TestData.Rider
+	seen.LastSeenAt = &seenAt
+	suite.NoError(suite.RiderRepository.UpdateLocations(ctx, []domain.Rider{seen}))
+
+	suite.Require().NoError(suite.ServiceAreaRepository.SaveOrUpdateServiceArea(domain.ServiceArea{ID: 2, Identifier: "other-area"}))
+
+	for _, rider := range []domain.Rider{
+		domain.NewRider(domain.User{ID: "delivering-id"}, domain.StatusDelivering, 1, domain.Dimensions{Width: 50, Height: 40, Depth: 30}),
+		domain.NewRider(domain.User{ID: "offline-id"}, domain.StatusOffline, 1, domain.Dimensions{Width: 10, Height: 10, Depth: 10}),
+		domain.NewRider(domain.User{ID: "other-id"}, domain.StatusAvailable, 2, domain.Dimensions{Width: 10, Height: 10, Depth: 10}),
+	} {
+		suite.Require().NoError(suite.RiderRepository.SaveOrUpdateUser(ctx, rider.User))
+
+		rider.User = domain.User{}
+		_, err := suite.RiderRepository.Save(ctx, rider)
+		suite.Require().NoError(err)
+	}
+
+	stats, err := suite.RiderRepository.GetRiderStats(ctx, domain.RiderFilter{}, seenAt)
+
+	suite.NoError(err)
+	suite.Require().Len(stats, 2)
+
+	area := stats[1]
+	suite.Equal(map[int]int{domain.StatusOffline: 1, domain.StatusAvailable: 1, domain.StatusDelivering: 1}, area.ByStatus)
+	suite.Equal(2, area.Online())
+	suite.InDelta(75, area.AverageCapacity.Width, 0.001, "offline riders do not count towards the average capacity")
+	suite.InDelta(70, area.AverageCapacity.Height, 0.001)
+	suite.InDelta(65, area.AverageCapacity.Depth, 0.001)
+	suite.Equal(1, area.StaleLocations, "the rider that was never seen has a stale location")
+
+	suite.Equal(1, stats[2].Riders())
+
+	stats, err = suite.RiderRepository.GetRiderStats(ctx, domain.RiderFilter{ServiceAreaID: 2}, seenAt.Add(time.Second))
+
+	suite.NoError(err)
+	suite.Len(stats, 1)
+	suite.Equal(1, stats[2].StaleLocations)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_GetHeatmap() {
+	ctx := context.Background()
+
+	suite.saveTestRider()
+
+	for _, rider := range []domain.Rider{
+		domain.NewRider(domain.User{ID: "nearby-id"}, domain.StatusDelivering, 1, domain.Dimensions{}),
+		domain.NewRider(domain.User{ID: "offline-id"}, domain.StatusOffline, 1, domain.Dimensions{}),
+		domain.NewRider(domain.User{ID: "unlocated-id"}, domain.StatusAvailable, 1, domain.Dimensions{}),
+	} {
+		suite.Require().NoError(suite.RiderRepository.SaveOrUpdateUser(ctx, rider.User))
+
+		rider.User = domain.User{}
+		_, err := suite.RiderRepository.Save(ctx, rider)
+		suite.Require().NoError(err)
+	}
+
+	nearby := domain.Rider{UserID: "nearby-id", Location: domain.Location{Latitude: 1.0001, Longitude: 2.0001}}
+	offline := domain.Rider{UserID: "offline-id", Location: suite.TestData.Location}
+	suite.Require().NoError(suite.RiderRepository.UpdateLocations(ctx, []domain.Rider{nearby, offline}))
+
+	heatmap, err := suite.RiderRepository.GetHeatmap(ctx, domain.HeatmapFilter{ServiceAreaID: 1, Source: domain.HeatmapRiders, Precision: 5})
+
+	suite.NoError(err)
+	suite.Require().Len(heatmap, 1, "offline riders and riders without a location are left out")
+	suite.Equal(domain.Geohash(suite.TestData.Rider.Location, 5), heatmap[0].Geohash)
+	suite.Equal(2, heatmap[0].Count)
+	suite.InDelta(domain.GeohashBounds(heatmap[0].Geohash).MinLatitude, heatmap[0].Bounds.MinLatitude, 0.000001)
+	suite.InDelta(domain.GeohashBounds(heatmap[0].Geohash).MaxLongitude, heatmap[0].Bounds.MaxLongitude, 0.000001)
+
+	since := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	fixes := []domain.LocationFix{
+		{Location: suite.TestData.Rider.Location, Sequence: 1, Timestamp: since.Add(-time.Minute)},
+		{Location: suite.TestData.Rider.Location, Sequence: 2, Timestamp: since},
+		{Location: suite.TestData.Location, Sequence: 3, Timestamp: since.Add(time.Minute)},
+		{Location: suite.TestData.Location, Sequence: 4},
+	}
+
+	var history []domain.RiderLocation
+	for _, fix := range fixes {
+		history = append(history, domain.NewRiderLocation("offline-id", fix))
+	}
+
+	suite.Require().NoError(suite.RiderRepository.SaveLocationBatches(ctx, []domain.Rider{offline}, history))
+
+	heatmap, err = suite.RiderRepository.GetHeatmap(ctx, domain.HeatmapFilter{ServiceAreaID: 1, Source: domain.HeatmapHistory, Precision: 3, Since: since})
+
+	suite.NoError(err)
+	suite.Require().Len(heatmap, 2, "fixes before since and without a timestamp are left out")
+	suite.True(heatmap[0].Geohash < heatmap[1].Geohash, "cells are ordered by geohash")
+
+	counts := map[string]int{}
+	for _, cell := range heatmap {
+		counts[cell.Geohash] = cell.Count
+	}
+
+	suite.Equal(map[string]int{
+		domain.Geohash(suite.TestData.Rider.Location, 3): 1,
+		domain.Geohash(suite.TestData.Location, 3):       1,
+	}, counts)
+
+	heatmap, err = suite.RiderRepository.GetHeatmap(ctx, domain.HeatmapFilter{ServiceAreaID: 2, Source: domain.HeatmapRiders, Precision: 5})
+
+	suite.NoError(err)
+	suite.Empty(heatmap)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_SaveLocationBatches() {
+	suite.saveTestRider()
+
+	fix := domain.LocationFix{Location: suite.TestData.Location, Sequence: 4}
+	updated := fix.Apply(suite.TestData.Rider)
+
+	err := suite.RiderRepository.SaveLocationBatches(context.Background(), []domain.Rider{updated}, []domain.RiderLocation{domain.NewRiderLocation(updated.UserID, fix)})
+	suite.NoError(err)
+
+	stored, err := suite.RiderRepository.Get(context.Background(), updated.UserID)
+
+	suite.NoError(err)
+	suite.EqualValues(suite.TestData.Location, stored.Location)
+	suite.EqualValues(4, stored.LocationSequence)
+	suite.Equal(suite.TestData.Rider.Version, stored.Version)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_GetLocationAnomalies() {
+	detectedAt := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	anomalies := []domain.LocationAnomaly{
+		{RiderID: "rider-a", Kind: domain.AnomalyImpossibleJump, Location: suite.TestData.Location, ImpliedSpeed: 120, Rejected: true, DetectedAt: detectedAt},
+		{RiderID: "rider-a", Kind: domain.AnomalyMockLocation, Location: suite.TestData.Location, DetectedAt: detectedAt.Add(time.Minute)},
+		{RiderID: "rider-b", Kind: domain.AnomalyImpossibleJump, Location: suite.TestData.Location, DetectedAt: detectedAt.Add(2 * time.Minute)},
+	}
+
+	suite.NoError(suite.RiderRepository.SaveLocationAnomalies(context.Background(), anomalies))
+
+	result, err := suite.RiderRepository.GetLocationAnomalies(context.Background(), domain.LocationAnomalyFilter{RiderID: "rider-a"})
+
+	suite.NoError(err)
+	suite.Require().Len(result, 2)
+	suite.Equal(domain.AnomalyMockLocation, result[0].Kind, "the newest anomaly comes first")
+	suite.Equal(120.0, result[1].ImpliedSpeed)
+	suite.True(result[1].Rejected)
+
+	result, err = suite.RiderRepository.GetLocationAnomalies(context.Background(), domain.LocationAnomalyFilter{Kind: domain.AnomalyImpossibleJump, Since: detectedAt.Add(time.Second), Limit: 10})
+
+	suite.NoError(err)
+	suite.Require().Len(result, 1)
+	suite.Equal("rider-b", result[0].RiderID)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_SaveOrUpdateUser() {
+	user := domain.User{ID: "test-id", Name: "new-name", LastName: "new-lastname"}
+
+	err := suite.RiderRepository.SaveOrUpdateUser(context.Background(), user)
+	suite.NoError(err)
+
+	result, err := suite.RiderRepository.GetUser(context.Background(), user.ID)
+
+	suite.NoError(err)
+	suite.EqualValues(user, result)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_SaveOrUpdateUser_IgnoresStaleVersion() {
+	newer := domain.User{ID: "test-id", Name: "newer-name", LastName: "newer-lastname", Version: 3}
+	older := domain.User{ID: "test-id", Name: "older-name", LastName: "older-lastname", Version: 2}
+
+	suite.NoError(suite.RiderRepository.SaveOrUpdateUser(context.Background(), newer))
+	suite.NoError(suite.RiderRepository.SaveOrUpdateUser(context.Background(), older))
+
+	result, err := suite.RiderRepository.GetUser(context.Background(), newer.ID)
+
+	suite.NoError(err)
+	suite.EqualValues(newer, result)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_GetUser_NotFound() {
+	_, err := suite.RiderRepository.GetUser(context.Background(), "unknown")
+
+	suite.ErrorIs(err, domain.ErrNotFound)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_SaveOrUpdateServiceArea() {
+	suite.saveTestRider()
+
+	updated := domain.ServiceArea{ID: suite.TestData.Rider.ServiceAreaID, Identifier: "new-area"}
+
+	err := suite.ServiceAreaRepository.SaveOrUpdateServiceArea(updated)
+	suite.NoError(err)
+
+	result, err := suite.RiderRepository.Get(context.Background(), suite.TestData.Rider.UserID)
+
+	suite.NoError(err)
+	suite.EqualValues(updated, result.ServiceArea)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_SaveOrUpdateServiceArea_IgnoresStaleVersion() {
+	newer := domain.ServiceArea{ID: suite.TestData.Rider.ServiceAreaID, Identifier: "newer-area", Version: 3}
+	older := domain.ServiceArea{ID: suite.TestData.Rider.ServiceAreaID, Identifier: "older-area", Version: 2}
+
+	suite.NoError(suite.ServiceAreaRepository.SaveOrUpdateServiceArea(newer))
+	suite.NoError(suite.ServiceAreaRepository.SaveOrUpdateServiceArea(older))
+
+	result, err := suite.RiderRepository.GetServiceArea(context.Background(), newer.ID)
+
+	suite.NoError(err)
+	suite.EqualValues(newer, result)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_GetServiceArea() {
+	result, err := suite.RiderRepository.GetServiceArea(context.Background(), suite.TestData.Rider.ServiceAreaID)
+
+	suite.NoError(err)
+	suite.EqualValues(suite.TestData.Rider.ServiceArea, result)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_GetServiceArea_NotFound() {
+	_, err := suite.RiderRepository.GetServiceArea(context.Background(), 999)
+
+	suite.ErrorIs(err, domain.ErrNotFound)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_GetServiceAreas() {
+	ctx := context.Background()
+	other := domain.ServiceArea{ID: 2, Identifier: "other-area"}
+	deleted := domain.ServiceArea{ID: 3, Identifier: "deleted-area"}
+
+	suite.Require().NoError(suite.ServiceAreaRepository.SaveOrUpdateServiceArea(other))
+	suite.Require().NoError(suite.ServiceAreaRepository.SaveOrUpdateServiceArea(deleted))
+	suite.Require().NoError(suite.ServiceAreaRepository.DeleteServiceArea(ctx, deleted.ID, 1))
+
+	result, err := suite.ServiceAreaRepository.GetServiceAreas(ctx)
+
+	suite.NoError(err)
+	suite.Equal([]domain.ServiceArea{suite.TestData.Rider.ServiceArea, other}, result)
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_DeleteServiceArea() {
+	ctx := context.Background()
+	suite.saveTestRider()
+
+	suite.NoError(suite.ServiceAreaRepository.DeleteServiceArea(ctx, suite.TestData.Rider.ServiceAreaID, 2))
+
+	result, err := suite.ServiceAreaRepository.GetServiceArea(ctx, suite.TestData.Rider.ServiceAreaID)
+
+	suite.NoError(err)
+	suite.True(result.IsDeleted())
+	suite.Equal(2, result.Version)
+
+	rider, err := suite.RiderRepository.Get(ctx, suite.TestData.Rider.UserID)
+
+	suite.NoError(err)
+	suite.True(rider.ServiceArea.IsDeleted(), "riders keep their deleted area")
+
+	suite.NoError(suite.ServiceAreaRepository.SaveOrUpdateServiceArea(domain.ServiceArea{ID: result.ID, Identifier: "updated-area", Version: 3}))
+
+	result, err = suite.ServiceAreaRepository.GetServiceArea(ctx, suite.TestData.Rider.ServiceAreaID)
+
+	suite.NoError(err)
+	suite.True(result.IsDeleted(), "an update does not bring back a deleted area")
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_DeleteServiceArea_IgnoresStaleVersion() {
+	ctx := context.Background()
+	area := domain.ServiceArea{ID: suite.TestData.Rider.ServiceAreaID, Identifier: "test-area", Version: 3}
+
+	suite.Require().NoError(suite.ServiceAreaRepository.SaveOrUpdateServiceArea(area))
+	suite.NoError(suite.ServiceAreaRepository.DeleteServiceArea(ctx, area.ID, 2))
+
+	result, err := suite.ServiceAreaRepository.GetServiceArea(ctx, area.ID)
+
+	suite.NoError(err)
+	suite.False(result.IsDeleted())
+}
+
+func (suite *RepositoryConformanceTestSuite) TestConformance_DeleteServiceArea_NotFound() {
+	err := suite.ServiceAreaRepository.DeleteServiceArea(context.Background(), 999, 1)
+
+	suite.ErrorIs(err, domain.ErrNotFound)
+}
+
+func TestUnit_MemoryRepositoryConformanceTestSuite(t *testing.T) {
+	conformanceSuite := new(RepositoryConformanceTestSuite)
+	conformanceSuite.NewRepositories = func() (interfaces.RiderRepository, interfaces.ServiceAreaRepository) {
+		repository := NewMemoryRepository()
+		return repository, repository
+	}
+	suite.Run(t, conformanceSuite)
+}
+
+func TestIntegration_PostgresRepositoryConformanceTestSuite(t *testing.T) {
+	cfgPath := "../../test/rider.config"
+	cfg, err := config.UseConfig(cfgPath)
+
+	if err != nil {
+		panic(errors.WithStack(err))
+	}
+
+	dsn := fmt.Sprintf("host=%s port=%d user=%s "+
+		"password=%s dbname=%s sslmode=disable",
+		cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password, cfg.Database.Database)
+	db, err := gorm.Open(postgres.Open(dsn))
+
+	if err != nil {
+		panic(errors.WithStack(err))
+	}
+
+	conformanceSuite := new(RepositoryConformanceTestSuite)
+	conformanceSuite.NewRepositories = func() (interfaces.RiderRepository, interfaces.ServiceAreaRepository) {
+		riderRepository, err := NewRiderRepository(db)
+
+		if err != nil {
+			panic(errors.WithStack(err))
+		}
+
+		serviceAreaRepository, err := NewServiceAreaRepository(db)
+
+		if err != nil {
+			panic(errors.WithStack(err))
+		}
+
+		db.Exec("DELETE FROM public.location_anomalies")
+		db.Exec("DELETE FROM public.rider_locations")
+		db.Exec("DELETE FROM public.riders")
+		db.Exec("DELETE FROM public.users")
+		db.Exec("DELETE FROM public.service_areas")
+
+		return riderRepository, serviceAreaRepository
+	}
+	suite.Run(t, conformanceSuite)
+}
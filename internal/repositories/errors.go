@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"errors"
+	"github.com/jackc/pgconn"
+	"gorm.io/gorm"
+	"rider-service/internal/core/domain"
+)
+
+const (
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+)
+
+// translateError converts database errors into domain errors, so the core does not depend on gorm or Postgres.
+func translateError(err error, entity string) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.NewNotFoundError("%s not found", entity)
+	}
+
+	var pgErr *pgconn.PgError
+
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgUniqueViolation:
+			return domain.NewConflictError("%s already exists", entity)
+		case pgForeignKeyViolation:
+			return domain.NewValidationError(foreignKeyField(pgErr.ConstraintName))
+		}
+	}
+
+	return domain.NewUnavailableError(err, "could not access %s", entity)
+}
+
+func foreignKeyField(constraint string) domain.FieldError {
+	switch constraint {
+	case "fk_riders_service_area":
+		return domain.FieldError{Field: "serviceArea", Message: "does not exist"}
+	case "fk_riders_user":
+		return domain.FieldError{Field: "id", Message: "user does not exist"}
+	}
+
+	return domain.FieldError{Field: constraint, Message: "references a missing record"}
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"rider-service/internal/core/domain"
+	"time"
+)
+
+type idempotencyRepository struct {
+	Connection *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) (*idempotencyRepository, error) {
+	err := db.AutoMigrate(&domain.IdempotencyRecord{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	database := idempotencyRepository{
+		Connection: db,
+	}
+
+	return &database, nil
+}
+
+func (repository *idempotencyRepository) GetIdempotencyRecord(ctx context.Context, key string) (domain.IdempotencyRecord, error) {
+	var record domain.IdempotencyRecord
+
+	result := repository.Connection.WithContext(ctx).First(&record, "key = ? AND expires_at > ?", key, time.Now())
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return domain.IdempotencyRecord{}, domain.NewNotFoundError("idempotency key %s not found", key)
+	}
+
+	if result.Error != nil {
+		return domain.IdempotencyRecord{}, translateError(result.Error, "idempotency key")
+	}
+
+	return record, nil
+}
+
+func (repository *idempotencyRepository) SaveIdempotencyRecord(ctx context.Context, record domain.IdempotencyRecord) error {
+	db := repository.Connection.WithContext(ctx)
+
+	if result := db.Where("expires_at <= ?", time.Now()).Delete(&domain.IdempotencyRecord{}); result.Error != nil {
+		return translateError(result.Error, "idempotency keys")
+	}
+
+	result := db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&record)
+
+	if result.Error != nil {
+		return translateError(result.Error, "idempotency key")
+	}
+
+	return nil
+}
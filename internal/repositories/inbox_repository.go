@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"rider-service/internal/core/domain"
+	"time"
+)
+
+type inboxRepository struct {
+	Connection *gorm.DB
+}
+
+func NewInboxRepository(db *gorm.DB) (*inboxRepository, error) {
+	err := db.AutoMigrate(&domain.ProcessedMessage{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	database := inboxRepository{
+		Connection: db,
+	}
+
+	return &database, nil
+}
+
+func (repository *inboxRepository) IsProcessed(ctx context.Context, id string) (bool, error) {
+	var count int64
+
+	result := repository.Connection.WithContext(ctx).Model(&domain.ProcessedMessage{}).Where("id = ? AND expires_at > ?", id, time.Now()).Count(&count)
+
+	if result.Error != nil {
+		return false, translateError(result.Error, "processed message")
+	}
+
+	return count > 0, nil
+}
+
+func (repository *inboxRepository) MarkProcessed(ctx context.Context, message domain.ProcessedMessage) error {
+	db := repository.Connection.WithContext(ctx)
+
+	if result := db.Where("expires_at <= ?", time.Now()).Delete(&domain.ProcessedMessage{}); result.Error != nil {
+		return translateError(result.Error, "processed messages")
+	}
+
+	result := db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&message)
+
+	if result.Error != nil {
+		return translateError(result.Error, "processed message")
+	}
+
+	return nil
+}
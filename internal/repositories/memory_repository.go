@@ -0,0 +1,489 @@
+package repositories
+
+import (
+	"context"
+	"rider-service/internal/core/domain"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryRepository struct {
+	mutex        sync.RWMutex
+	riders       map[string]domain.Rider
+	users        map[string]domain.User
+	serviceAreas map[int]domain.ServiceArea
+	idempotency  map[string]domain.IdempotencyRecord
+	inbox        map[string]domain.ProcessedMessage
+	history      map[string][]domain.RiderLocation
+	anomalies    []domain.LocationAnomaly
+}
+
+func NewMemoryRepository() *memoryRepository {
+	return &memoryRepository{
+		riders:       make(map[string]domain.Rider),
+		users:        make(map[string]domain.User),
+		serviceAreas: make(map[int]domain.ServiceArea),
+		idempotency:  make(map[string]domain.IdempotencyRecord),
+		inbox:        make(map[string]domain.ProcessedMessage),
+		history:      make(map[string][]domain.RiderLocation),
+	}
+}
+
+func (repository *memoryRepository) Get(ctx context.Context, id string) (domain.Rider, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	rider, exists := repository.riders[id]
+
+	if !exists {
+		return domain.Rider{}, domain.NewNotFoundError("rider %s not found", id)
+	}
+
+	return repository.preload(rider), nil
+}
+
+func (repository *memoryRepository) GetAll(ctx context.Context, filter domain.RiderFilter) ([]domain.Rider, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	riders := make([]domain.Rider, 0, len(repository.riders))
+
+	for _, rider := range repository.riders {
+		if filter.Matches(rider) {
+			riders = append(riders, repository.preload(rider))
+		}
+	}
+
+	return riders, nil
+}
+
+// StreamAll copies the matching riders first, so fn can use the repository.
+func (repository *memoryRepository) StreamAll(ctx context.Context, filter domain.RiderFilter, fn func(domain.Rider) error) error {
+	repository.mutex.RLock()
+
+	riders := make([]domain.Rider, 0, len(repository.riders))
+
+	for _, rider := range repository.riders {
+		if filter.Matches(rider) {
+			riders = append(riders, repository.preload(rider))
+		}
+	}
+
+	repository.mutex.RUnlock()
+
+	sort.Slice(riders, func(i, j int) bool {
+		return riders[i].UserID < riders[j].UserID
+	})
+
+	for _, rider := range riders {
+		if err := fn(rider); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repository *memoryRepository) GetSilentRiders(ctx context.Context, seenBefore time.Time) ([]domain.Rider, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	var riders []domain.Rider
+
+	for _, rider := range repository.riders {
+		if rider.Status != domain.StatusOffline && (rider.LastSeenAt == nil || rider.LastSeenAt.Before(seenBefore)) {
+			riders = append(riders, repository.preload(rider))
+		}
+	}
+
+	return riders, nil
+}
+
+func (repository *memoryRepository) Save(ctx context.Context, rider domain.Rider) (domain.Rider, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if _, exists := repository.riders[rider.UserID]; exists {
+		return domain.Rider{}, domain.NewConflictError("rider %s already exists", rider.UserID)
+	}
+
+	if _, exists := repository.users[rider.UserID]; !exists {
+		return domain.Rider{}, domain.NewValidationError(domain.FieldError{Field: "id", Message: "user does not exist"})
+	}
+
+	if _, exists := repository.serviceAreas[rider.ServiceAreaID]; !exists {
+		return domain.Rider{}, domain.NewValidationError(domain.FieldError{Field: "serviceArea", Message: "does not exist"})
+	}
+
+	if rider.Version == 0 {
+		rider.Version = 1
+	}
+
+	if rider.VehicleType == "" {
+		rider.VehicleType = domain.VehicleBicycle
+	}
+
+	repository.riders[rider.UserID] = stripAssociations(rider)
+
+	return rider, nil
+}
+
+func (repository *memoryRepository) Update(ctx context.Context, rider domain.Rider) (domain.Rider, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if !repository.isAtVersion(rider) {
+		return domain.Rider{}, domain.ErrVersionConflict
+	}
+
+	return repository.update(rider), nil
+}
+
+func (repository *memoryRepository) UpdateLocations(ctx context.Context, riders []domain.Rider) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.updateLocations(riders)
+
+	return nil
+}
+
+func (repository *memoryRepository) SaveLocationBatches(ctx context.Context, riders []domain.Rider, history []domain.RiderLocation) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.updateLocations(riders)
+
+	for _, location := range history {
+		repository.history[location.RiderID] = append(repository.history[location.RiderID], location)
+	}
+
+	return nil
+}
+
+func (repository *memoryRepository) SaveLocationAnomalies(ctx context.Context, anomalies []domain.LocationAnomaly) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for _, anomaly := range anomalies {
+		anomaly.ID = uint(len(repository.anomalies) + 1)
+		repository.anomalies = append(repository.anomalies, anomaly)
+	}
+
+	return nil
+}
+
+func (repository *memoryRepository) GetLocationAnomalies(ctx context.Context, filter domain.LocationAnomalyFilter) ([]domain.LocationAnomaly, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	var anomalies []domain.LocationAnomaly
+
+	for _, anomaly := range repository.anomalies {
+		if (filter.RiderID == "" || anomaly.RiderID == filter.RiderID) &&
+			(filter.Kind == "" || anomaly.Kind == filter.Kind) &&
+			!anomaly.DetectedAt.Before(filter.Since) {
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		if !anomalies[i].DetectedAt.Equal(anomalies[j].DetectedAt) {
+			return anomalies[i].DetectedAt.After(anomalies[j].DetectedAt)
+		}
+
+		return anomalies[i].ID > anomalies[j].ID
+	})
+
+	if filter.Limit > 0 && len(anomalies) > filter.Limit {
+		anomalies = anomalies[:filter.Limit]
+	}
+
+	return anomalies, nil
+}
+
+func (repository *memoryRepository) GetRiderStats(ctx context.Context, filter domain.RiderFilter, staleBefore time.Time) (map[int]domain.RiderStats, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	stats := map[int]domain.RiderStats{}
+	filter.Fits = nil
+
+	for _, rider := range repository.riders {
+		if filter.Matches(rider) {
+			stats[rider.ServiceAreaID] = stats[rider.ServiceAreaID].Add(domain.NewRiderStats(rider, staleBefore))
+		}
+	}
+
+	return stats, nil
+}
+
+func (repository *memoryRepository) GetHeatmap(ctx context.Context, filter domain.HeatmapFilter) ([]domain.HeatmapCell, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	var locations []domain.Location
+
+	for _, rider := range repository.riders {
+		if rider.ServiceAreaID != filter.ServiceAreaID {
+			continue
+		}
+
+		if filter.Source != domain.HeatmapHistory {
+			if rider.Status != domain.StatusOffline {
+				locations = append(locations, rider.Location)
+			}
+
+			continue
+		}
+
+		for _, fix := range repository.history[rider.UserID] {
+			if fix.Timestamp != nil && !fix.Timestamp.Before(filter.Since) {
+				locations = append(locations, fix.Location)
+			}
+		}
+	}
+
+	counts := map[string]int{}
+
+	for _, location := range locations {
+		// Riders start out at 0,0 until they send a location.
+		if location != (domain.Location{}) {
+			counts[domain.Geohash(location, filter.Precision)]++
+		}
+	}
+
+	heatmap := make([]domain.HeatmapCell, 0, len(counts))
+
+	for hash, count := range counts {
+		heatmap = append(heatmap, domain.HeatmapCell{Geohash: hash, Count: count, Bounds: domain.GeohashBounds(hash)})
+	}
+
+	sort.Slice(heatmap, func(i, j int) bool {
+		return heatmap[i].Geohash < heatmap[j].Geohash
+	})
+
+	return heatmap, nil
+}
+
+// updateLocations only writes the location fields of riders that exist, like an UPDATE statement. The caller holds the lock.
+func (repository *memoryRepository) updateLocations(riders []domain.Rider) {
+	for _, rider := range riders {
+		stored, exists := repository.riders[rider.UserID]
+
+		if !exists {
+			continue
+		}
+
+		stored.Location = rider.Location
+		stored.LocationTimestamp = rider.LocationTimestamp
+		stored.LocationSequence = rider.LocationSequence
+		stored.Telemetry = rider.Telemetry
+
+		if rider.LastSeenAt != nil && (stored.LastSeenAt == nil || rider.LastSeenAt.After(*stored.LastSeenAt)) {
+			stored.LastSeenAt = rider.LastSeenAt
+		}
+
+		repository.riders[rider.UserID] = stored
+	}
+}
+
+func (repository *memoryRepository) isAtVersion(rider domain.Rider) bool {
+	stored, exists := repository.riders[rider.UserID]
+	return exists && stored.Version == rider.Version
+}
+
+// update writes the fields of a rider that can change, except for its location, and increments its version.
+// The last seen time only moves forward.
+// The caller holds the lock.
+func (repository *memoryRepository) update(rider domain.Rider) domain.Rider {
+	stored := repository.riders[rider.UserID]
+
+	rider.Version++
+	stored.Version = rider.Version
+
+	stored.Status = rider.Status
+	stored.ServiceAreaID = rider.ServiceAreaID
+	stored.Capacity = rider.Capacity
+	stored.VehicleType = rider.VehicleType
+	stored.DeliveryID = rider.DeliveryID
+	stored.Parcels = rider.Parcels
+
+	if rider.LastSeenAt != nil && (stored.LastSeenAt == nil || rider.LastSeenAt.After(*stored.LastSeenAt)) {
+		stored.LastSeenAt = rider.LastSeenAt
+	}
+
+	repository.riders[rider.UserID] = stored
+
+	return rider
+}
+
+func (repository *memoryRepository) SaveOrUpdateUser(ctx context.Context, user domain.User) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if stored, exists := repository.users[user.ID]; exists && stored.Version > user.Version {
+		return nil
+	}
+
+	repository.users[user.ID] = user
+
+	return nil
+}
+
+func (repository *memoryRepository) GetUser(ctx context.Context, id string) (domain.User, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	user, exists := repository.users[id]
+
+	if !exists {
+		return domain.User{}, domain.NewNotFoundError("user %s has not been synchronised to the rider service yet", id)
+	}
+
+	return user, nil
+}
+
+func (repository *memoryRepository) GetServiceArea(ctx context.Context, id int) (domain.ServiceArea, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	serviceArea, exists := repository.serviceAreas[id]
+
+	if !exists {
+		return domain.ServiceArea{}, domain.NewNotFoundError("service area %d not found", id)
+	}
+
+	return serviceArea, nil
+}
+
+func (repository *memoryRepository) SaveOrUpdateServiceArea(serviceArea domain.ServiceArea) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	stored, exists := repository.serviceAreas[serviceArea.ID]
+
+	if exists && stored.Version > serviceArea.Version {
+		return nil
+	}
+
+	// Like the database repository, an update does not bring back a deleted area.
+	if exists {
+		serviceArea.DeletedAt = stored.DeletedAt
+	}
+
+	repository.serviceAreas[serviceArea.ID] = serviceArea
+
+	return nil
+}
+
+func (repository *memoryRepository) GetServiceAreas(ctx context.Context) ([]domain.ServiceArea, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	serviceAreas := make([]domain.ServiceArea, 0, len(repository.serviceAreas))
+
+	for _, serviceArea := range repository.serviceAreas {
+		if !serviceArea.IsDeleted() {
+			serviceAreas = append(serviceAreas, serviceArea)
+		}
+	}
+
+	sort.Slice(serviceAreas, func(i, j int) bool {
+		return serviceAreas[i].ID < serviceAreas[j].ID
+	})
+
+	return serviceAreas, nil
+}
+
+func (repository *memoryRepository) DeleteServiceArea(ctx context.Context, id int, version int) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	serviceArea, exists := repository.serviceAreas[id]
+
+	if !exists {
+		return domain.NewNotFoundError("service area %d not found", id)
+	}
+
+	if serviceArea.IsDeleted() || serviceArea.Version > version {
+		return nil
+	}
+
+	deletedAt := time.Now()
+	serviceArea.DeletedAt = &deletedAt
+	serviceArea.Version = version
+	repository.serviceAreas[id] = serviceArea
+
+	return nil
+}
+
+func (repository *memoryRepository) GetIdempotencyRecord(ctx context.Context, key string) (domain.IdempotencyRecord, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	record, exists := repository.idempotency[key]
+
+	if !exists || !record.ExpiresAt.After(time.Now()) {
+		return domain.IdempotencyRecord{}, domain.NewNotFoundError("idempotency key %s not found", key)
+	}
+
+	return record, nil
+}
+
+func (repository *memoryRepository) SaveIdempotencyRecord(ctx context.Context, record domain.IdempotencyRecord) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	now := time.Now()
+
+	for key, stored := range repository.idempotency {
+		if !stored.ExpiresAt.After(now) {
+			delete(repository.idempotency, key)
+		}
+	}
+
+	repository.idempotency[record.Key] = record
+
+	return nil
+}
+
+func (repository *memoryRepository) IsProcessed(ctx context.Context, id string) (bool, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	message, exists := repository.inbox[id]
+
+	return exists && message.ExpiresAt.After(time.Now()), nil
+}
+
+func (repository *memoryRepository) MarkProcessed(ctx context.Context, message domain.ProcessedMessage) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	now := time.Now()
+
+	for id, stored := range repository.inbox {
+		if !stored.ExpiresAt.After(now) {
+			delete(repository.inbox, id)
+		}
+	}
+
+	repository.inbox[message.ID] = message
+
+	return nil
+}
+
+// preload fills in the associations of a stored rider, like Preload(clause.Associations) does for gorm.
+func (repository *memoryRepository) preload(rider domain.Rider) domain.Rider {
+	rider.User = repository.users[rider.UserID]
+	rider.ServiceArea = repository.serviceAreas[rider.ServiceAreaID]
+	return rider
+}
+
+func stripAssociations(rider domain.Rider) domain.Rider {
+	rider.User = domain.User{}
+	rider.ServiceArea = domain.ServiceArea{}
+	return rider
+}
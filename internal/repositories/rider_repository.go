@@ -3,9 +3,12 @@ package repositories
 import (
 	"context"
 	"errors"
+	"fmt"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"rider-service/internal/core/domain"
+	"strings"
+	"time"
 )
 
 type riderRepository struct {
@@ -15,7 +18,7 @@ type riderRepository struct {
 func NewRiderRepository(db *gorm.DB) (*riderRepository, error) {
 	db.Exec("CREATE EXTENSION IF NOT EXISTS \"postgis\";")
 
-	err := db.AutoMigrate(&domain.ServiceArea{}, &domain.Rider{})
+	err := db.AutoMigrate(&domain.ServiceArea{}, &domain.Rider{}, &domain.RiderLocation{}, &domain.LocationAnomaly{})
 
 	if err != nil {
 		return nil, err
@@ -31,19 +34,114 @@ func NewRiderRepository(db *gorm.DB) (*riderRepository, error) {
 func (repository *riderRepository) Get(ctx context.Context, id string) (domain.Rider, error) {
 	var rider domain.Rider
 
-	repository.Connection.WithContext(ctx).Preload(clause.Associations).First(&rider, "user_id = ?", id)
+	result := repository.Connection.WithContext(ctx).Preload(clause.Associations).First(&rider, "user_id = ?", id)
 
-	if (rider == domain.Rider{}) {
-		return domain.Rider{}, errors.New("could not find rider")
+	if result.Error != nil {
+		return domain.Rider{}, translateError(result.Error, "rider "+id)
 	}
 
 	return rider, nil
 }
 
-func (repository *riderRepository) GetAll(ctx context.Context) ([]domain.Rider, error) {
+// streamBatchSize is the number of riders StreamAll reads at a time.
+const streamBatchSize = 500
+
+func filterRiders(filter domain.RiderFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.Status != nil {
+			db = db.Where("status = ?", *filter.Status)
+		}
+
+		if filter.ServiceAreaID != 0 {
+			db = db.Where("service_area_id = ?", filter.ServiceAreaID)
+		}
+
+		return db
+	}
+}
+
+// filterRoom leaves out the riders whose box is too small for the parcel of the filter. The parcels of the riders
+// that are left still have to be checked with the filter.
+func filterRoom(filter domain.RiderFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.Fits != nil {
+			db = db.Where("width * height * depth >= ?", filter.Fits.Width*filter.Fits.Height*filter.Fits.Depth)
+		}
+
+		return db
+	}
+}
+
+func (repository *riderRepository) GetAll(ctx context.Context, filter domain.RiderFilter) ([]domain.Rider, error) {
 	var riders []domain.Rider
 
-	repository.Connection.WithContext(ctx).Find(&riders)
+	result := repository.Connection.WithContext(ctx).
+		Preload(clause.Associations).
+		Scopes(filterRiders(filter), filterRoom(filter)).
+		Find(&riders)
+
+	if result.Error != nil {
+		return nil, translateError(result.Error, "riders")
+	}
+
+	if filter.Fits == nil {
+		return riders, nil
+	}
+
+	selected := make([]domain.Rider, 0, len(riders))
+
+	for _, rider := range riders {
+		if filter.Matches(rider) {
+			selected = append(selected, rider)
+		}
+	}
+
+	return selected, nil
+}
+
+func (repository *riderRepository) StreamAll(ctx context.Context, filter domain.RiderFilter, fn func(domain.Rider) error) error {
+	var batch []domain.Rider
+	var fnErr error
+
+	result := repository.Connection.WithContext(ctx).
+		Preload(clause.Associations).
+		Scopes(filterRiders(filter), filterRoom(filter)).
+		FindInBatches(&batch, streamBatchSize, func(tx *gorm.DB, _ int) error {
+			for _, rider := range batch {
+				if !filter.Matches(rider) {
+					continue
+				}
+
+				if fnErr = fn(rider); fnErr != nil {
+					return fnErr
+				}
+			}
+
+			return nil
+		})
+
+	if fnErr != nil {
+		return fnErr
+	}
+
+	if result.Error != nil {
+		return translateError(result.Error, "riders")
+	}
+
+	return nil
+}
+
+func (repository *riderRepository) GetSilentRiders(ctx context.Context, seenBefore time.Time) ([]domain.Rider, error) {
+	var riders []domain.Rider
+
+	result := repository.Connection.WithContext(ctx).
+		Preload("ServiceArea").
+		Where("status <> ? AND (last_seen_at IS NULL OR last_seen_at < ?)", domain.StatusOffline, seenBefore).
+		Find(&riders)
+
+	if result.Error != nil {
+		return nil, translateError(result.Error, "riders")
+	}
 
 	return riders, nil
 }
@@ -52,48 +150,303 @@ func (repository *riderRepository) Save(ctx context.Context, rider domain.Rider)
 	result := repository.Connection.WithContext(ctx).Omit("User").Create(&rider)
 
 	if result.Error != nil {
-		return domain.Rider{}, result.Error
+		return domain.Rider{}, translateError(result.Error, "rider "+rider.UserID)
 	}
 
 	return rider, nil
 }
 
 func (repository *riderRepository) Update(ctx context.Context, rider domain.Rider) (domain.Rider, error) {
-	result := repository.Connection.WithContext(ctx).Model(&rider).Updates(rider)
+	return updateRider(repository.Connection.WithContext(ctx), rider)
+}
+
+func (repository *riderRepository) UpdateLocations(ctx context.Context, riders []domain.Rider) error {
+	return updateLocations(repository.Connection.WithContext(ctx), riders)
+}
+
+func (repository *riderRepository) SaveLocationBatches(ctx context.Context, riders []domain.Rider, history []domain.RiderLocation) error {
+	return repository.Connection.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := updateLocations(tx, riders); err != nil {
+			return err
+		}
+
+		if len(history) == 0 {
+			return nil
+		}
+
+		if result := tx.Create(&history); result.Error != nil {
+			return translateError(result.Error, "location history")
+		}
+
+		return nil
+	})
+}
+
+func (repository *riderRepository) SaveLocationAnomalies(ctx context.Context, anomalies []domain.LocationAnomaly) error {
+	if len(anomalies) == 0 {
+		return nil
+	}
+
+	if result := repository.Connection.WithContext(ctx).Create(&anomalies); result.Error != nil {
+		return translateError(result.Error, "location anomalies")
+	}
+
+	return nil
+}
+
+func (repository *riderRepository) GetLocationAnomalies(ctx context.Context, filter domain.LocationAnomalyFilter) ([]domain.LocationAnomaly, error) {
+	query := repository.Connection.WithContext(ctx).Order("detected_at DESC, id DESC")
+
+	if filter.RiderID != "" {
+		query = query.Where("rider_id = ?", filter.RiderID)
+	}
+
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+
+	if !filter.Since.IsZero() {
+		query = query.Where("detected_at >= ?", filter.Since)
+	}
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var anomalies []domain.LocationAnomaly
+
+	if result := query.Find(&anomalies); result.Error != nil {
+		return nil, translateError(result.Error, "location anomalies")
+	}
+
+	return anomalies, nil
+}
+
+// riderStatsRow is the aggregate of the riders of a service area with a status.
+type riderStatsRow struct {
+	ServiceAreaID int
+	Status        int
+	Riders        int
+	Stale         int
+	Width         float64
+	Height        float64
+	Depth         float64
+}
+
+func (repository *riderRepository) GetRiderStats(ctx context.Context, filter domain.RiderFilter, staleBefore time.Time) (map[int]domain.RiderStats, error) {
+	var rows []riderStatsRow
+
+	result := repository.Connection.WithContext(ctx).
+		Model(&domain.Rider{}).
+		Scopes(filterRiders(filter)).
+		Select("service_area_id, status, count(*) AS riders, "+
+			"count(*) FILTER (WHERE last_seen_at IS NULL OR last_seen_at < ?) AS stale, "+
+			"avg(width)::float8 AS width, avg(height)::float8 AS height, avg(depth)::float8 AS depth", staleBefore).
+		Group("service_area_id, status").
+		Scan(&rows)
+
+	if result.Error != nil {
+		return nil, translateError(result.Error, "rider stats")
+	}
+
+	stats := map[int]domain.RiderStats{}
+
+	for _, row := range rows {
+		rowStats := domain.RiderStats{
+			ByStatus:        map[int]int{row.Status: row.Riders},
+			AverageCapacity: domain.AverageDimensions{Width: row.Width, Height: row.Height, Depth: row.Depth},
+		}
+
+		if row.Status != domain.StatusOffline {
+			rowStats.StaleLocations = row.Stale
+		}
+
+		stats[row.ServiceAreaID] = stats[row.ServiceAreaID].Add(rowStats)
+	}
+
+	return stats, nil
+}
+
+// heatmapRow is a geohash cell with the box PostGIS decoded from it.
+type heatmapRow struct {
+	Geohash      string
+	Count        int
+	MinLatitude  float64
+	MaxLatitude  float64
+	MinLongitude float64
+	MaxLongitude float64
+}
+
+// GetHeatmap groups the locations into cells with PostGIS, only the cells are read.
+func (repository *riderRepository) GetHeatmap(ctx context.Context, filter domain.HeatmapFilter) ([]domain.HeatmapCell, error) {
+	db := repository.Connection.WithContext(ctx)
+
+	var locations *gorm.DB
+
+	switch filter.Source {
+	case domain.HeatmapHistory:
+		locations = db.Table("rider_locations").
+			Select("rider_locations.location").
+			Joins("JOIN riders ON riders.user_id = rider_locations.rider_id").
+			Where("riders.service_area_id = ? AND rider_locations.timestamp >= ?", filter.ServiceAreaID, filter.Since)
+	default:
+		locations = db.Model(&domain.Rider{}).
+			Select("location").
+			Where("service_area_id = ? AND status <> ?", filter.ServiceAreaID, domain.StatusOffline)
+	}
+
+	cells := db.Table("(?) AS locations", locations).
+		Select("ST_GeoHash(locations.location, ?) AS geohash, count(*) AS count", filter.Precision).
+		// Riders start out at 0,0 until they send a location.
+		Where("ST_X(locations.location) <> 0 OR ST_Y(locations.location) <> 0").
+		Group("geohash")
+
+	var rows []heatmapRow
+
+	result := db.Table("(?) AS cells, ST_Box2dFromGeoHash(cells.geohash) AS box", cells).
+		Select("cells.geohash, cells.count, " +
+			"ST_YMin(box) AS min_latitude, ST_YMax(box) AS max_latitude, ST_XMin(box) AS min_longitude, ST_XMax(box) AS max_longitude").
+		Order("cells.geohash").
+		Scan(&rows)
 
 	if result.Error != nil {
-		return domain.Rider{}, result.Error
+		return nil, translateError(result.Error, "heatmap")
+	}
+
+	heatmap := make([]domain.HeatmapCell, 0, len(rows))
+
+	for _, row := range rows {
+		heatmap = append(heatmap, domain.HeatmapCell{
+			Geohash: row.Geohash,
+			Count:   row.Count,
+			Bounds: domain.Bounds{
+				MinLatitude:  row.MinLatitude,
+				MaxLatitude:  row.MaxLatitude,
+				MinLongitude: row.MinLongitude,
+				MaxLongitude: row.MaxLongitude,
+			},
+		})
+	}
+
+	return heatmap, nil
+}
+
+// locationUpdateChunkSize keeps the number of parameters of a location update well below the Postgres limit.
+const locationUpdateChunkSize = 500
+
+// updateLocations writes the locations of many riders with one UPDATE statement per chunk.
+// The last seen time only moves forward.
+func updateLocations(db *gorm.DB, riders []domain.Rider) error {
+	for start := 0; start < len(riders); start += locationUpdateChunkSize {
+		end := start + locationUpdateChunkSize
+
+		if end > len(riders) {
+			end = len(riders)
+		}
+
+		values := make([]string, 0, end-start)
+		args := make([]interface{}, 0, 10*(end-start))
+
+		for _, rider := range riders[start:end] {
+			values = append(values, "(?::text, ?::geometry, ?::timestamptz, ?::bigint, ?::timestamptz, "+
+				"?::double precision, ?::double precision, ?::double precision, ?::double precision, ?::integer)")
+			args = append(args, rider.UserID, rider.Location, rider.LocationTimestamp, rider.LocationSequence, rider.LastSeenAt,
+				rider.Telemetry.Accuracy, rider.Telemetry.Speed, rider.Telemetry.Heading, rider.Telemetry.Altitude, rider.Telemetry.Battery)
+		}
+
+		result := db.Exec(
+			"UPDATE riders SET location = v.location, location_timestamp = v.location_timestamp, location_sequence = v.location_sequence, "+
+				"last_seen_at = GREATEST(riders.last_seen_at, v.last_seen_at), "+
+				"accuracy = v.accuracy, speed = v.speed, heading = v.heading, altitude = v.altitude, battery = v.battery "+
+				"FROM (VALUES "+strings.Join(values, ", ")+") "+
+				"AS v(user_id, location, location_timestamp, location_sequence, last_seen_at, accuracy, speed, heading, altitude, battery) "+
+				"WHERE riders.user_id = v.user_id",
+			args...)
+
+		if result.Error != nil {
+			return translateError(result.Error, "rider locations")
+		}
+	}
+
+	return nil
+}
+
+// updateRider writes the fields of a rider that can change, provided it is still at the rider's version.
+// The location is left alone, it is only written by updateLocations, and the last seen time only moves forward.
+func updateRider(db *gorm.DB, rider domain.Rider) (domain.Rider, error) {
+	expectedVersion := rider.Version
+	rider.Version++
+
+	result := db.
+		Model(&domain.Rider{UserID: rider.UserID}).
+		Where("version = ?", expectedVersion).
+		Updates(map[string]interface{}{
+			"status":          rider.Status,
+			"service_area_id": rider.ServiceAreaID,
+			"width":           rider.Capacity.Width,
+			"height":          rider.Capacity.Height,
+			"depth":           rider.Capacity.Depth,
+			"vehicle_type":    rider.VehicleType,
+			"delivery_id":     rider.DeliveryID,
+			"parcels":         rider.Parcels,
+			"last_seen_at":    gorm.Expr("GREATEST(last_seen_at, ?::timestamptz)", rider.LastSeenAt),
+			"version":         rider.Version,
+		})
+
+	if result.Error != nil {
+		return domain.Rider{}, translateError(result.Error, "rider "+rider.UserID)
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.Rider{}, domain.ErrVersionConflict
 	}
 
 	return rider, nil
 }
 
 func (repository *riderRepository) SaveOrUpdateUser(ctx context.Context, user domain.User) error {
-	updateResult := repository.Connection.WithContext(ctx).Model(&user).Where("id = ?", user.ID).Updates(&user)
+	updateResult := repository.Connection.WithContext(ctx).Model(&user).Where("id = ? AND version <= ?", user.ID, user.Version).Updates(&user)
+
+	if updateResult.Error != nil {
+		return translateError(updateResult.Error, "user "+user.ID)
+	}
 
 	if updateResult.RowsAffected == 0 {
-		createResult := repository.Connection.WithContext(ctx).Create(&user)
+		// Either the user is new or a newer version is already stored, in which case the insert does nothing.
+		createResult := repository.Connection.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&user)
 
 		if createResult.Error != nil {
-			return errors.New("could not create user")
+			return translateError(createResult.Error, "user "+user.ID)
 		}
 	}
 
-	if updateResult.Error != nil {
-		return errors.New("could not update user")
-	}
-
 	return nil
 }
 
 func (repository *riderRepository) GetUser(ctx context.Context, id string) (domain.User, error) {
 	var user domain.User
 
-	repository.Connection.WithContext(ctx).Preload(clause.Associations).First(&user, "id = ?", id)
+	result := repository.Connection.WithContext(ctx).First(&user, "id = ?", id)
 
-	if (user == domain.User{}) {
-		return user, errors.New("user not found")
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return domain.User{}, domain.NewNotFoundError("user %s has not been synchronised to the rider service yet", id)
+	}
+
+	if result.Error != nil {
+		return domain.User{}, translateError(result.Error, "user "+id)
 	}
 
 	return user, nil
 }
+
+func (repository *riderRepository) GetServiceArea(ctx context.Context, id int) (domain.ServiceArea, error) {
+	var serviceArea domain.ServiceArea
+
+	result := repository.Connection.WithContext(ctx).First(&serviceArea, "id = ?", id)
+
+	if result.Error != nil {
+		return domain.ServiceArea{}, translateError(result.Error, fmt.Sprintf("service area %d", id))
+	}
+
+	return serviceArea, nil
+}
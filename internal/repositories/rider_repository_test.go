@@ -10,6 +10,7 @@ import (
 	"rider-service/config"
 	"rider-service/internal/core/domain"
 	"testing"
+	"time"
 )
 
 type RiderRepositoryTestSuite struct {
@@ -79,10 +80,12 @@ func (suite *RiderRepositoryTestSuite) SetupSuite() {
 				Height: 100,
 				Depth:  100,
 			},
+			VehicleType: domain.VehicleBicycle,
 			Location: domain.Location{
 				Latitude:  1,
 				Longitude: 2,
 			},
+			Version: 1,
 		},
 		Location: domain.Location{
 			Latitude:  2,
@@ -196,7 +199,174 @@ func (suite *RiderRepositoryTestSuite) TestRepository_GetUser() {
 	suite.EqualValues(result, user)
 }
 
+// saveRiders saves count riders with their users in the service area. Their ids start with the prefix,
+// so deleteRiders can remove them again.
+func (suite *RiderRepositoryTestSuite) saveRiders(prefix string, count int, serviceArea int, status int) []domain.Rider {
+	riders := make([]domain.Rider, 0, count)
+
+	for i := 0; i < count; i++ {
+		user := domain.User{ID: fmt.Sprintf("%s-%d", prefix, i)}
+		suite.Require().NoError(suite.TestRepo.SaveOrUpdateUser(context.Background(), user))
+
+		rider := domain.NewRider(user, status, serviceArea, domain.Dimensions{Width: 10, Height: 10, Depth: 10})
+		rider.User = domain.User{}
+
+		saved, err := suite.TestRepo.Save(context.Background(), rider)
+		suite.Require().NoError(err)
+
+		riders = append(riders, saved)
+	}
+
+	return riders
+}
+
+func (suite *RiderRepositoryTestSuite) deleteRiders(prefix string) {
+	suite.TestDb.Exec("DELETE FROM public.riders WHERE user_id LIKE ?", prefix+"-%")
+	suite.TestDb.Exec("DELETE FROM public.users WHERE id LIKE ?", prefix+"-%")
+}
+
+func (suite *RiderRepositoryTestSuite) TestRepository_UpdateLocations() {
+	defer suite.deleteRiders("located")
+
+	timestamp := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	accuracy := 5.0
+	battery := 80
+
+	riders := suite.saveRiders("located", locationUpdateChunkSize+1, 1, domain.StatusAvailable)
+
+	for i := range riders {
+		riders[i] = domain.LocationFix{
+			Location:  domain.Location{Latitude: 52, Longitude: float64(i) / 1000},
+			Timestamp: timestamp,
+			Sequence:  int64(i),
+			Telemetry: domain.Telemetry{Accuracy: &accuracy, Battery: &battery},
+		}.Apply(riders[i])
+		riders[i].LastSeenAt = &timestamp
+	}
+
+	suite.Require().NoError(suite.TestRepo.UpdateLocations(context.Background(), riders))
+
+	var written int64
+	suite.TestDb.Raw("SELECT count(*) FROM public.riders WHERE user_id LIKE 'located-%' AND ST_SRID(location) = 4326 AND "+
+		"ST_Y(location) = 52 AND location_timestamp = ? AND last_seen_at = ? AND accuracy = 5 AND battery = 80 AND speed IS NULL",
+		timestamp, timestamp).Scan(&written)
+
+	suite.EqualValues(len(riders), written, "riders in every chunk are written")
+
+	last := riders[len(riders)-1]
+	result, err := suite.TestRepo.Get(context.Background(), last.UserID)
+
+	suite.NoError(err)
+	suite.Equal(last.Location, result.Location)
+	suite.Equal(last.LocationSequence, result.LocationSequence)
+	suite.Equal(last.Version, result.Version, "location updates do not change the version")
+
+	// Telemetry that is no longer sent is cleared.
+	last.Telemetry = domain.Telemetry{}
+	suite.Require().NoError(suite.TestRepo.UpdateLocations(context.Background(), []domain.Rider{last}))
+
+	result, err = suite.TestRepo.Get(context.Background(), last.UserID)
+
+	suite.NoError(err)
+	suite.Nil(result.Telemetry.Accuracy)
+	suite.Nil(result.Telemetry.Battery)
+}
+
+func (suite *RiderRepositoryTestSuite) TestRepository_GetRiderStats() {
+	defer suite.deleteRiders("stats")
+
+	staleBefore := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	suite.TestDb.Exec("INSERT INTO public.service_areas (id, identifier) VALUES (9, 'stats-area') ON CONFLICT DO NOTHING")
+	riders := suite.saveRiders("stats", 4, 9, domain.StatusAvailable)
+
+	for i, row := range []struct {
+		status     int
+		capacity   domain.Dimensions
+		lastSeenAt *time.Time
+	}{
+		{domain.StatusAvailable, domain.Dimensions{Width: 1, Height: 2, Depth: 3}, nil},
+		{domain.StatusAvailable, domain.Dimensions{Width: 2, Height: 3, Depth: 4}, &staleBefore},
+		{domain.StatusDelivering, domain.Dimensions{Width: 4, Height: 4, Depth: 4}, timePtr(staleBefore.Add(-time.Second))},
+		{domain.StatusOffline, domain.Dimensions{Width: 100, Height: 100, Depth: 100}, nil},
+	} {
+		suite.Require().NoError(suite.TestDb.Exec("UPDATE public.riders SET status = ?, width = ?, height = ?, depth = ?, last_seen_at = ? WHERE user_id = ?",
+			row.status, row.capacity.Width, row.capacity.Height, row.capacity.Depth, row.lastSeenAt, riders[i].UserID).Error)
+	}
+
+	stats, err := suite.TestRepo.GetRiderStats(context.Background(), domain.RiderFilter{ServiceAreaID: 9}, staleBefore)
+
+	suite.NoError(err)
+	suite.Require().Len(stats, 1)
+
+	area := stats[9]
+	suite.Equal(map[int]int{domain.StatusAvailable: 2, domain.StatusDelivering: 1, domain.StatusOffline: 1}, area.ByStatus)
+	suite.Equal(2, area.StaleLocations, "riders never seen or seen before the time are stale, offline riders are not")
+	suite.InDelta(7.0/3, area.AverageCapacity.Width, 0.000001, "averages are not rounded to integers")
+	suite.InDelta(3, area.AverageCapacity.Height, 0.000001)
+	suite.InDelta(11.0/3, area.AverageCapacity.Depth, 0.000001)
+}
+
+func (suite *RiderRepositoryTestSuite) TestRepository_GetHeatmap() {
+	defer suite.TestDb.Exec("DELETE FROM public.rider_locations WHERE rider_id LIKE 'heatmap-%'")
+	defer suite.deleteRiders("heatmap")
+
+	since := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	capeTown := domain.Location{Latitude: -33.9249, Longitude: 18.4241}
+	newYork := domain.Location{Latitude: 40.7128, Longitude: -74.006}
+
+	suite.TestDb.Exec("INSERT INTO public.service_areas (id, identifier) VALUES (10, 'heatmap-area') ON CONFLICT DO NOTHING")
+	riders := suite.saveRiders("heatmap", 5, 10, domain.StatusAvailable)
+
+	riders[0].Location = capeTown
+	riders[1].Location = domain.Location{Latitude: capeTown.Latitude + 0.0001, Longitude: capeTown.Longitude + 0.0001}
+	riders[2].Location = newYork
+	riders[3].Location = newYork
+	suite.Require().NoError(suite.TestRepo.UpdateLocations(context.Background(), riders[:4]))
+	suite.Require().NoError(suite.TestDb.Exec("UPDATE public.riders SET status = ? WHERE user_id = ?", domain.StatusOffline, riders[3].UserID).Error)
+
+	heatmap, err := suite.TestRepo.GetHeatmap(context.Background(), domain.HeatmapFilter{ServiceAreaID: 10, Source: domain.HeatmapRiders, Precision: 6})
+
+	suite.NoError(err)
+	suite.Require().Len(heatmap, 2, "offline riders and riders at 0,0 are left out")
+
+	counts := map[string]int{}
+	for _, cell := range heatmap {
+		counts[cell.Geohash] = cell.Count
+		suite.InDeltaMapValues(boundsMap(domain.GeohashBounds(cell.Geohash)), boundsMap(cell.Bounds), 0.000001, "PostGIS decodes the cell like the domain does")
+	}
+
+	suite.Equal(map[string]int{domain.Geohash(capeTown, 6): 2, domain.Geohash(newYork, 6): 1}, counts, "latitude and longitude are not swapped")
+
+	history := []domain.RiderLocation{
+		domain.NewRiderLocation(riders[0].UserID, domain.LocationFix{Location: newYork, Sequence: 1, Timestamp: since.Add(-time.Minute)}),
+		domain.NewRiderLocation(riders[0].UserID, domain.LocationFix{Location: capeTown, Sequence: 2, Timestamp: since}),
+		domain.NewRiderLocation(riders[3].UserID, domain.LocationFix{Location: capeTown, Sequence: 1, Timestamp: since.Add(time.Minute)}),
+	}
+	suite.Require().NoError(suite.TestRepo.SaveLocationBatches(context.Background(), nil, history))
+
+	heatmap, err = suite.TestRepo.GetHeatmap(context.Background(), domain.HeatmapFilter{ServiceAreaID: 10, Source: domain.HeatmapHistory, Precision: 4, Since: since})
+
+	suite.NoError(err)
+	suite.Require().Len(heatmap, 1, "fixes before since are left out")
+	suite.Equal(domain.Geohash(capeTown, 4), heatmap[0].Geohash)
+	suite.Equal(2, heatmap[0].Count, "the history includes riders that are offline now")
+}
+
 func TestIntegration_RiderRepositoryTestSuite(t *testing.T) {
 	repoSuite := new(RiderRepositoryTestSuite)
 	suite.Run(t, repoSuite)
 }
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func boundsMap(bounds domain.Bounds) map[string]float64 {
+	return map[string]float64{
+		"minLatitude":  bounds.MinLatitude,
+		"maxLatitude":  bounds.MaxLatitude,
+		"minLongitude": bounds.MinLongitude,
+		"maxLongitude": bounds.MaxLongitude,
+	}
+}
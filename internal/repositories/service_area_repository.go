@@ -1,8 +1,12 @@
 package repositories
 
 import (
+	"context"
+	"fmt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"rider-service/internal/core/domain"
+	"time"
 )
 
 type serviceAreaRepository struct {
@@ -26,13 +30,57 @@ func NewServiceAreaRepository(db *gorm.DB) (*serviceAreaRepository, error) {
 }
 
 func (repository *serviceAreaRepository) SaveOrUpdateServiceArea(serviceArea domain.ServiceArea) error {
-	if repository.Connection.Model(&serviceArea).Where("id = ?", serviceArea.ID).Updates(&serviceArea).RowsAffected == 0 {
-		create := repository.Connection.Create(&serviceArea)
+	update := repository.Connection.Model(&serviceArea).Where("id = ? AND version <= ?", serviceArea.ID, serviceArea.Version).Updates(&serviceArea)
+
+	if update.Error != nil {
+		return translateError(update.Error, "service area")
+	}
+
+	if update.RowsAffected == 0 {
+		// Either the area is new or a newer version is already stored, in which case the insert does nothing.
+		create := repository.Connection.Clauses(clause.OnConflict{DoNothing: true}).Create(&serviceArea)
 
 		if create.Error != nil {
-			return create.Error
+			return translateError(create.Error, "service area")
 		}
 	}
 
 	return nil
 }
+
+func (repository *serviceAreaRepository) GetServiceAreas(ctx context.Context) ([]domain.ServiceArea, error) {
+	var serviceAreas []domain.ServiceArea
+
+	result := repository.Connection.WithContext(ctx).Where("deleted_at IS NULL").Order("id").Find(&serviceAreas)
+
+	if result.Error != nil {
+		return nil, translateError(result.Error, "service areas")
+	}
+
+	return serviceAreas, nil
+}
+
+func (repository *serviceAreaRepository) GetServiceArea(ctx context.Context, id int) (domain.ServiceArea, error) {
+	var serviceArea domain.ServiceArea
+
+	result := repository.Connection.WithContext(ctx).First(&serviceArea, "id = ?", id)
+
+	if result.Error != nil {
+		return domain.ServiceArea{}, translateError(result.Error, fmt.Sprintf("service area %d", id))
+	}
+
+	return serviceArea, nil
+}
+
+func (repository *serviceAreaRepository) DeleteServiceArea(ctx context.Context, id int, version int) error {
+	if _, err := repository.GetServiceArea(ctx, id); err != nil {
+		return err
+	}
+
+	result := repository.Connection.WithContext(ctx).
+		Model(&domain.ServiceArea{}).
+		Where("id = ? AND version <= ? AND deleted_at IS NULL", id, version).
+		Updates(map[string]interface{}{"deleted_at": time.Now(), "version": version})
+
+	return translateError(result.Error, fmt.Sprintf("service area %d", id))
+}
@@ -0,0 +1,117 @@
+// Package asyncapi describes the topics a service publishes to and consumes from as an AsyncAPI 2 document.
+package asyncapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"rider-service/pkg/events"
+)
+
+// Version is the version of the AsyncAPI specification the documents follow.
+const Version = "2.4.0"
+
+type Document struct {
+	AsyncAPI           string             `json:"asyncapi"`
+	Info               Info               `json:"info"`
+	DefaultContentType string             `json:"defaultContentType"`
+	Channels           map[string]Channel `json:"channels"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Channel is a topic. Subscribe is set for topics the service publishes to, which other services subscribe to,
+// and Publish for topics the service consumes, which other services publish to.
+type Channel struct {
+	Description string               `json:"description,omitempty"`
+	Parameters  map[string]Parameter `json:"parameters,omitempty"`
+	Subscribe   *Operation           `json:"subscribe,omitempty"`
+	Publish     *Operation           `json:"publish,omitempty"`
+}
+
+type Parameter struct {
+	Description string          `json:"description,omitempty"`
+	Schema      json.RawMessage `json:"schema"`
+}
+
+type Operation struct {
+	OperationID string  `json:"operationId"`
+	Message     Message `json:"message"`
+}
+
+type Message struct {
+	// Name is the name of the event's schema, which is sent along with the message.
+	Name    string          `json:"name"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+var channelParameter = regexp.MustCompile(`{([^}]+)}`)
+
+func New(info Info) *Document {
+	return &Document{
+		AsyncAPI:           Version,
+		Info:               info,
+		DefaultContentType: "application/json",
+		Channels:           map[string]Channel{},
+	}
+}
+
+// AddPublished documents that the service publishes the event to the channel.
+func (document *Document) AddPublished(channel string, description string, event events.Event) error {
+	operation, err := newOperation("publish", channel, event)
+
+	if err != nil {
+		return err
+	}
+
+	return document.add(channel, description, func(c *Channel) { c.Subscribe = operation })
+}
+
+// AddConsumed documents that the service consumes the event from the channel.
+func (document *Document) AddConsumed(channel string, description string, event events.Event) error {
+	operation, err := newOperation("consume", channel, event)
+
+	if err != nil {
+		return err
+	}
+
+	return document.add(channel, description, func(c *Channel) { c.Publish = operation })
+}
+
+func (document *Document) add(channel string, description string, setOperation func(c *Channel)) error {
+	if _, exists := document.Channels[channel]; exists {
+		return fmt.Errorf("channel %s is documented twice", channel)
+	}
+
+	c := Channel{Description: description}
+
+	for _, match := range channelParameter.FindAllStringSubmatch(channel, -1) {
+		if c.Parameters == nil {
+			c.Parameters = map[string]Parameter{}
+		}
+
+		c.Parameters[match[1]] = Parameter{Schema: json.RawMessage(`{"type":"string"}`)}
+	}
+
+	setOperation(&c)
+	document.Channels[channel] = c
+
+	return nil
+}
+
+func newOperation(verb string, channel string, event events.Event) (*Operation, error) {
+	payload, err := events.JSONSchema(event)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Operation{
+		OperationID: verb + " " + channel,
+		Message:     Message{Name: event.Schema(), Payload: payload},
+	}, nil
+}
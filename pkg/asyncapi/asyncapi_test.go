@@ -0,0 +1,55 @@
+package asyncapi
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/suite"
+	"rider-service/pkg/events"
+	"testing"
+)
+
+type AsyncAPITestSuite struct {
+	suite.Suite
+}
+
+func (suite *AsyncAPITestSuite) TestDocument_AddPublished() {
+	document := New(Info{Title: "test", Version: "1"})
+
+	suite.NoError(document.AddPublished("rider.{serviceArea}.update.location", "locations", events.RiderLocationUpdatedV1{}))
+
+	channel := document.Channels["rider.{serviceArea}.update.location"]
+	suite.Equal("locations", channel.Description)
+	suite.Nil(channel.Publish)
+	suite.Require().NotNil(channel.Subscribe)
+	suite.Equal("rider.update.location.v1", channel.Subscribe.Message.Name)
+	suite.Contains(channel.Parameters, "serviceArea")
+
+	var payload struct {
+		Title string `json:"title"`
+	}
+
+	suite.NoError(json.Unmarshal(channel.Subscribe.Message.Payload, &payload))
+	suite.Equal("rider.update.location.v1", payload.Title)
+}
+
+func (suite *AsyncAPITestSuite) TestDocument_AddConsumed() {
+	document := New(Info{Title: "test", Version: "1"})
+
+	suite.NoError(document.AddConsumed("user.create", "users", events.UserV1{}))
+
+	channel := document.Channels["user.create"]
+	suite.Nil(channel.Subscribe)
+	suite.Require().NotNil(channel.Publish)
+	suite.Equal("user.v1", channel.Publish.Message.Name)
+	suite.Empty(channel.Parameters)
+}
+
+func (suite *AsyncAPITestSuite) TestDocument_ChannelDocumentedTwice() {
+	document := New(Info{Title: "test", Version: "1"})
+
+	suite.NoError(document.AddConsumed("user.create", "users", events.UserV1{}))
+	suite.Error(document.AddPublished("user.create", "users", events.UserV1{}))
+}
+
+func TestUnit_AsyncAPITestSuite(t *testing.T) {
+	suite.Run(t, new(AsyncAPITestSuite))
+}
@@ -0,0 +1,44 @@
+package authorization
+
+import (
+	"context"
+	"google.golang.org/grpc/metadata"
+)
+
+type GrpcAuthorization struct {
+	identity
+}
+
+// NewGrpc reads the user from the x-user-id and x-user-claims metadata of an incoming call,
+// the gRPC counterparts of the X-User-Id and X-User-Claims headers.
+func NewGrpc(ctx context.Context) *GrpcAuthorization {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	return &GrpcAuthorization{
+		identity: newIdentity(firstValue(md, "x-user-id"), firstValue(md, "x-user-claims")),
+	}
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+type grpcAuthorizationKey struct{}
+
+// WithGrpc stores the authorization of a call in its context, so it is only read once.
+func WithGrpc(ctx context.Context, auth *GrpcAuthorization) context.Context {
+	return context.WithValue(ctx, grpcAuthorizationKey{}, auth)
+}
+
+// GrpcFromContext returns the authorization stored by WithGrpc, or reads it from the metadata when there is none.
+func GrpcFromContext(ctx context.Context) *GrpcAuthorization {
+	if auth, ok := ctx.Value(grpcAuthorizationKey{}).(*GrpcAuthorization); ok {
+		return auth
+	}
+
+	return NewGrpc(ctx)
+}
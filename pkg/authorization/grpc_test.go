@@ -0,0 +1,53 @@
+package authorization
+
+import (
+	"context"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc/metadata"
+	"testing"
+)
+
+type GrpcAuthorizationTestSuite struct {
+	suite.Suite
+}
+
+func (suite *GrpcAuthorizationTestSuite) TestGrpcAuthorization_AuthorizeAdmin() {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-user-id", "test-id", "x-user-claims", `{"admin": true}`))
+
+	sut := NewGrpc(ctx)
+
+	suite.Equal("test-id", sut.id)
+	suite.True(sut.AuthorizeAdmin())
+	suite.False(sut.AuthorizeDispatcher())
+	suite.True(sut.Authenticated())
+}
+
+func (suite *GrpcAuthorizationTestSuite) TestGrpcAuthorization_AuthorizeMatchingId() {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-user-id", "test-id"))
+
+	sut := NewGrpc(ctx)
+
+	suite.True(sut.AuthorizeMatchingId("test-id"))
+	suite.False(sut.AuthorizeMatchingId("test-2"))
+	suite.False(sut.AuthorizeAdmin())
+}
+
+func (suite *GrpcAuthorizationTestSuite) TestGrpcAuthorization_NoMetadata() {
+	sut := NewGrpc(context.Background())
+
+	suite.False(sut.Authenticated())
+	suite.False(sut.AuthorizeMatchingId("test-id"))
+}
+
+func (suite *GrpcAuthorizationTestSuite) TestGrpcAuthorization_FromContext() {
+	auth := NewGrpc(metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-user-claims", `{"dispatcher": true}`)))
+
+	sut := GrpcFromContext(WithGrpc(context.Background(), auth))
+
+	suite.Same(auth, sut)
+	suite.True(sut.AuthorizeDispatcher())
+}
+
+func TestUnit_GrpcAuthorizationTestSuite(t *testing.T) {
+	suite.Run(t, new(GrpcAuthorizationTestSuite))
+}
@@ -5,36 +5,52 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-type RestAuthorization struct {
-	context *gin.Context
-	claims  map[string]interface{}
-	id      string
+// identity is the user that made a request and its claims, as passed on by the gateway.
+type identity struct {
+	claims map[string]interface{}
+	id     string
 }
 
-func NewRest(context *gin.Context) *RestAuthorization {
-	auth := RestAuthorization{
-		context: context,
-	}
-
-	auth.id = context.GetHeader("X-User-Id")
-
-	claimHeader := context.GetHeader("X-User-Claims")
+func newIdentity(id string, claimHeader string) identity {
+	auth := identity{id: id}
 
 	if claimHeader != "" {
 		err := json.Unmarshal([]byte(claimHeader), &auth.claims)
 		if err != nil {
-			return &auth
+			return auth
 		}
 	}
 
-	return &auth
+	return auth
+}
+
+// Authenticated reports whether the request came with a user or claims at all.
+func (auth *identity) Authenticated() bool {
+	return auth.id != "" || len(auth.claims) > 0
 }
 
-func (auth *RestAuthorization) AuthorizeAdmin() bool {
+func (auth *identity) AuthorizeAdmin() bool {
 	v, exist := auth.claims["admin"]
 	return exist && v == true
 }
 
-func (auth *RestAuthorization) AuthorizeMatchingId(id string) bool {
+func (auth *identity) AuthorizeDispatcher() bool {
+	v, exist := auth.claims["dispatcher"]
+	return exist && v == true
+}
+
+func (auth *identity) AuthorizeMatchingId(id string) bool {
 	return auth.id == id
 }
+
+type RestAuthorization struct {
+	identity
+	context *gin.Context
+}
+
+func NewRest(context *gin.Context) *RestAuthorization {
+	return &RestAuthorization{
+		identity: newIdentity(context.GetHeader("X-User-Id"), context.GetHeader("X-User-Claims")),
+		context:  context,
+	}
+}
@@ -59,6 +59,27 @@ func (suite *AuthorizationTestSuite) TestAuthorization_AuthorizeAdmin_NoClaims()
 	suite.False(sut.AuthorizeAdmin())
 }
 
+func (suite *AuthorizationTestSuite) TestAuthorization_AuthorizeDispatcher() {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request, _ = http.NewRequest("GET", "/", nil)
+
+	ctx.Request.Header.Set("X-User-Claims", `{"dispatcher": true}`)
+
+	sut := NewRest(ctx)
+
+	suite.True(sut.AuthorizeDispatcher())
+	suite.False(sut.AuthorizeAdmin())
+}
+
+func (suite *AuthorizationTestSuite) TestAuthorization_AuthorizeDispatcher_NoClaims() {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request, _ = http.NewRequest("GET", "/", nil)
+
+	sut := NewRest(ctx)
+
+	suite.False(sut.AuthorizeDispatcher())
+}
+
 func (suite *AuthorizationTestSuite) TestAuthorization_AuthorizeMatchingId() {
 	userId := "test-id"
 
@@ -0,0 +1,296 @@
+// Package client is a typed Go client for the REST API of the rider service.
+//
+// The types and routes are checked against docs/swagger.yaml by the tests of this package,
+// so a change to the API that is not made here as well fails them.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	mathrand "math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// Retry is how calls are retried when the connection fails or the service responds with 429, 502, 503 or 504.
+// Only calls that are safe to repeat are retried: reads, PUTs and the POSTs that are sent with an Idempotency-Key.
+type Retry struct {
+	// MaxAttempts is how many times a call is made at most, one disables retries.
+	MaxAttempts int
+	// MinBackoff is the wait before the first retry, it doubles with every retry up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+var DefaultRetry = Retry{MaxAttempts: 3, MinBackoff: 100 * time.Millisecond, MaxBackoff: 2 * time.Second}
+
+// backoff returns the wait before the retry after the attempt, with jitter so clients do not retry in step.
+func (retry Retry) backoff(attempt int) time.Duration {
+	wait := retry.MinBackoff << (attempt - 1)
+
+	if wait > retry.MaxBackoff || wait <= 0 {
+		wait = retry.MaxBackoff
+	}
+
+	if wait <= 0 {
+		return 0
+	}
+
+	return wait/2 + time.Duration(mathrand.Int63n(int64(wait/2)+1))
+}
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	headers    http.Header
+	retry      Retry
+}
+
+type Option func(client *Client)
+
+// WithHTTPClient sets the HTTP client calls are made with, http.DefaultClient is used otherwise.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(client *Client) {
+		client.httpClient = httpClient
+	}
+}
+
+// WithUser calls as the user with the claims, like the gateway does with the X-User-Id and X-User-Claims headers.
+// Use it when calling the service directly.
+func WithUser(id string, claims map[string]interface{}) Option {
+	return func(client *Client) {
+		client.headers.Set("X-User-Id", id)
+
+		if len(claims) > 0 {
+			encoded, _ := json.Marshal(claims)
+			client.headers.Set("X-User-Claims", string(encoded))
+		}
+	}
+}
+
+// WithBearerToken sends the token in the Authorization header. Use it when calling through the gateway.
+func WithBearerToken(token string) Option {
+	return func(client *Client) {
+		client.headers.Set("Authorization", "Bearer "+token)
+	}
+}
+
+func WithRetry(retry Retry) Option {
+	return func(client *Client) {
+		client.retry = retry
+	}
+}
+
+// New creates a client for the service at baseURL, like "http://rider-service:1234".
+func New(baseURL string, options ...Option) *Client {
+	client := &Client{
+		baseURL:    baseURL,
+		httpClient: http.DefaultClient,
+		headers:    http.Header{},
+		retry:      DefaultRetry,
+	}
+
+	for _, option := range options {
+		option(client)
+	}
+
+	return client
+}
+
+// request is a call to the API. The body is kept encoded so it can be sent again for a retry.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        []byte
+	contentType string
+	header      http.Header
+	// idempotent makes the call with an Idempotency-Key, so a POST can be retried safely.
+	idempotent bool
+}
+
+func newRequest(method string, path string) *request {
+	return &request{method: method, path: path, header: http.Header{}}
+}
+
+func (r *request) withJSON(body interface{}) (*request, error) {
+	encoded, err := json.Marshal(body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.body = encoded
+	r.contentType = "application/json"
+
+	return r, nil
+}
+
+func (r *request) retryable() bool {
+	return r.method == http.MethodGet || r.method == http.MethodPut || r.idempotent
+}
+
+// send makes the call, retrying it when that is safe. Responses with an error status are returned
+// as an *Error, the caller has to close the body of a successful response.
+func (client *Client) send(ctx context.Context, r *request) (*http.Response, error) {
+	if r.idempotent {
+		r.header.Set(idempotencyKeyHeader, newIdempotencyKey())
+	}
+
+	attempts := client.retry.MaxAttempts
+
+	if attempts < 1 || !r.retryable() {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		response, err := client.do(ctx, r)
+
+		if attempt >= attempts || !shouldRetry(response, err) || ctx.Err() != nil {
+			if err != nil {
+				return nil, err
+			}
+
+			if response.StatusCode >= http.StatusBadRequest {
+				return nil, decodeError(response)
+			}
+
+			return response, nil
+		}
+
+		wait := client.retry.backoff(attempt)
+
+		if response != nil {
+			wait = retryAfter(response, wait, client.retry.MaxBackoff)
+			_, _ = io.Copy(io.Discard, response.Body)
+			_ = response.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (client *Client) do(ctx context.Context, r *request) (*http.Response, error) {
+	target := client.baseURL + r.path
+
+	if len(r.query) > 0 {
+		target += "?" + r.query.Encode()
+	}
+
+	var body io.Reader
+
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, r.method, target, body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range client.headers {
+		httpRequest.Header[name] = values
+	}
+
+	for name, values := range r.header {
+		httpRequest.Header[name] = values
+	}
+
+	if r.contentType != "" {
+		httpRequest.Header.Set("Content-Type", r.contentType)
+	}
+
+	return client.httpClient.Do(httpRequest)
+}
+
+func shouldRetry(response *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	switch response.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// retryAfter returns the wait the Retry-After header asks for, up to max, or wait when there is none.
+func retryAfter(response *http.Response, wait time.Duration, max time.Duration) time.Duration {
+	seconds, err := strconv.Atoi(response.Header.Get("Retry-After"))
+
+	if err != nil || seconds < 0 {
+		return wait
+	}
+
+	if requested := time.Duration(seconds) * time.Second; requested < max {
+		return requested
+	}
+
+	return max
+}
+
+// decodeError reads the problem details of a failed call. Responses without them, like those of a proxy,
+// get an Error with only the status.
+func decodeError(response *http.Response) error {
+	defer response.Body.Close()
+
+	problem := &Error{}
+	data, err := io.ReadAll(response.Body)
+
+	if err != nil || json.Unmarshal(data, problem) != nil {
+		problem = &Error{}
+	}
+
+	problem.Status = response.StatusCode
+
+	if problem.Title == "" {
+		problem.Title = http.StatusText(response.StatusCode)
+	}
+
+	return problem
+}
+
+// call makes the call and decodes the JSON response into result, which can be nil.
+func (client *Client) call(ctx context.Context, r *request, result interface{}) (http.Header, error) {
+	response, err := client.send(ctx, r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if result != nil {
+		if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+			return nil, err
+		}
+	}
+
+	return response.Header, nil
+}
+
+func newIdempotencyKey() string {
+	key := make([]byte, 16)
+
+	if _, err := rand.Read(key); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+
+	return hex.EncodeToString(key)
+}
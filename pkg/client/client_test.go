@@ -0,0 +1,281 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/suite"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type ClientTestSuite struct {
+	suite.Suite
+	Server   *httptest.Server
+	Handler  http.HandlerFunc
+	Requests []*http.Request
+	Bodies   []string
+	mutex    sync.Mutex
+}
+
+func (suite *ClientTestSuite) SetupTest() {
+	suite.Requests = nil
+	suite.Bodies = nil
+	suite.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		suite.mutex.Lock()
+		suite.Requests = append(suite.Requests, r)
+		suite.Bodies = append(suite.Bodies, string(body))
+		suite.mutex.Unlock()
+
+		suite.Handler(w, r)
+	}))
+}
+
+func (suite *ClientTestSuite) TearDownTest() {
+	suite.Server.Close()
+}
+
+func (suite *ClientTestSuite) newClient(options ...Option) *Client {
+	options = append([]Option{WithRetry(Retry{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond})}, options...)
+	return New(suite.Server.URL, options...)
+}
+
+// respond answers every request with the status and body, and the headers as name and value pairs.
+func respond(status int, body string, headers ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i+1 < len(headers); i += 2 {
+			w.Header().Set(headers[i], headers[i+1])
+		}
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+// failFirst answers the first attempts with the failing status and then with ok.
+func failFirst(attempts int, failing int, ok http.HandlerFunc) http.HandlerFunc {
+	calls := 0
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		if calls <= attempts {
+			w.WriteHeader(failing)
+			return
+		}
+
+		ok(w, r)
+	}
+}
+
+const riderJSON = `{"id":"test-id","user":{"id":"test-id","name":"test-name","lastName":"test-lastname"},"status":1,` +
+	`"serviceArea":{"id":1,"identifier":"test-area"},"capacity":{"width":10,"height":20,"depth":30},` +
+	`"location":{"latitude":1.005,"longitude":2.005},"locationPrecision":"coarse","telemetry":{"battery":15},"lowBattery":true}`
+
+func (suite *ClientTestSuite) TestClient_GetRider() {
+	suite.Handler = respond(http.StatusOK, riderJSON, "ETag", `"3"`)
+
+	rider, err := suite.newClient(WithUser("test-id", map[string]interface{}{"admin": true})).GetRider(context.Background(), "test-id")
+
+	suite.NoError(err)
+	suite.Equal("/api/riders/test-id", suite.Requests[0].URL.Path)
+	suite.Equal("test-id", suite.Requests[0].Header.Get("X-User-Id"))
+	suite.JSONEq(`{"admin": true}`, suite.Requests[0].Header.Get("X-User-Claims"))
+	suite.Equal("test-id", rider.ID)
+	suite.Equal("test-area", rider.ServiceArea.Identifier)
+	suite.Equal(Dimensions{Width: 10, Height: 20, Depth: 30}, rider.Capacity)
+	suite.Equal(&Location{Latitude: 1.005, Longitude: 2.005}, rider.Location)
+	suite.Equal(PrecisionCoarse, rider.LocationPrecision)
+	suite.Equal(15, *rider.Telemetry.Battery)
+	suite.True(rider.LowBattery)
+	suite.Equal(`"3"`, rider.ETag)
+}
+
+func (suite *ClientTestSuite) TestClient_BearerToken() {
+	suite.Handler = respond(http.StatusOK, riderJSON)
+
+	_, err := suite.newClient(WithBearerToken("token")).GetRider(context.Background(), "test-id")
+
+	suite.NoError(err)
+	suite.Equal("Bearer token", suite.Requests[0].Header.Get("Authorization"))
+	suite.Empty(suite.Requests[0].Header.Get("X-User-Id"))
+}
+
+func (suite *ClientTestSuite) TestClient_ListRiders_Filter() {
+	suite.Handler = respond(http.StatusOK, `[{"id":"test-id","name":"test-name","status":1,"serviceArea":1}]`)
+	status := StatusAvailable
+
+	riders, err := suite.newClient().ListRiders(context.Background(), RiderFilter{Status: &status, ServiceAreaID: 1})
+
+	suite.NoError(err)
+	suite.Equal("serviceArea=1&status=1", suite.Requests[0].URL.RawQuery)
+	suite.Equal([]RiderSummary{{ID: "test-id", Name: "test-name", Status: 1, ServiceAreaID: 1}}, riders)
+}
+
+func (suite *ClientTestSuite) TestClient_UpdateRider_IfMatch() {
+	suite.Handler = respond(http.StatusOK, riderJSON)
+
+	_, err := suite.newClient().UpdateRider(context.Background(), "test-id", UpdateRider{Status: StatusAvailable}, `"3"`)
+
+	suite.NoError(err)
+	suite.Equal(http.MethodPut, suite.Requests[0].Method)
+	suite.Equal(`"3"`, suite.Requests[0].Header.Get("If-Match"))
+	suite.JSONEq(`{"serviceArea":0,"capacity":{"width":0,"height":0,"depth":0},"status":1}`, suite.Bodies[0])
+}
+
+func (suite *ClientTestSuite) TestClient_PatchRider() {
+	suite.Handler = respond(http.StatusOK, riderJSON)
+	area := 2
+
+	_, err := suite.newClient().PatchRider(context.Background(), "test-id", PatchRider{ServiceArea: &area}, "")
+
+	suite.NoError(err)
+	suite.Equal("application/merge-patch+json", suite.Requests[0].Header.Get("Content-Type"))
+	suite.JSONEq(`{"serviceArea":2}`, suite.Bodies[0])
+	suite.Empty(suite.Requests[0].Header.Get("If-Match"))
+}
+
+func (suite *ClientTestSuite) TestClient_VersionConflict() {
+	suite.Handler = respond(http.StatusPreconditionFailed, `{"type":"about:blank","title":"Precondition Failed","status":412,"detail":"rider was modified concurrently"}`)
+
+	_, err := suite.newClient().UpdateRider(context.Background(), "test-id", UpdateRider{}, `"1"`)
+
+	suite.True(errors.Is(err, ErrVersionConflict))
+	suite.False(errors.Is(err, ErrNotFound))
+	suite.Len(suite.Requests, 1, "client errors are not retried")
+}
+
+func (suite *ClientTestSuite) TestClient_ValidationError() {
+	suite.Handler = respond(http.StatusBadRequest,
+		`{"type":"about:blank","title":"Bad Request","status":400,"traceId":"trace","errors":[{"field":"latitude","message":"must be at most 90"}]}`,
+		"Content-Type", "application/problem+json")
+
+	_, err := suite.newClient().UpdateLocation(context.Background(), "test-id", LocationFix{Latitude: 100})
+
+	suite.True(errors.Is(err, ErrValidation))
+
+	var problem *Error
+	suite.Require().True(errors.As(err, &problem))
+	suite.Equal("trace", problem.TraceID)
+	suite.Equal([]FieldError{{Field: "latitude", Message: "must be at most 90"}}, problem.Errors)
+	suite.Contains(err.Error(), "latitude must be at most 90")
+}
+
+func (suite *ClientTestSuite) TestClient_ErrorWithoutProblem() {
+	suite.Handler = respond(http.StatusNotFound, "404 page not found")
+
+	_, err := suite.newClient().GetRider(context.Background(), "test-id")
+
+	suite.True(errors.Is(err, ErrNotFound))
+
+	var problem *Error
+	suite.Require().True(errors.As(err, &problem))
+	suite.Equal("Not Found", problem.Title)
+}
+
+func (suite *ClientTestSuite) TestClient_RetriesReads() {
+	suite.Handler = failFirst(2, http.StatusServiceUnavailable, respond(http.StatusOK, riderJSON))
+
+	rider, err := suite.newClient().GetRider(context.Background(), "test-id")
+
+	suite.NoError(err)
+	suite.Equal("test-id", rider.ID)
+	suite.Len(suite.Requests, 3)
+}
+
+func (suite *ClientTestSuite) TestClient_GivesUpAfterMaxAttempts() {
+	suite.Handler = respond(http.StatusBadGateway, "")
+
+	_, err := suite.newClient().GetRider(context.Background(), "test-id")
+
+	var problem *Error
+	suite.Require().True(errors.As(err, &problem))
+	suite.Equal(http.StatusBadGateway, problem.Status)
+	suite.Len(suite.Requests, 3)
+}
+
+func (suite *ClientTestSuite) TestClient_RetriesPostWithSameIdempotencyKey() {
+	suite.Handler = failFirst(1, http.StatusServiceUnavailable, respond(http.StatusOK, riderJSON))
+
+	_, err := suite.newClient().CreateRider(context.Background(), CreateRider{ID: "test-id", ServiceArea: 1})
+
+	suite.NoError(err)
+	suite.Require().Len(suite.Requests, 2)
+	suite.NotEmpty(suite.Requests[0].Header.Get("Idempotency-Key"))
+	suite.Equal(suite.Requests[0].Header.Get("Idempotency-Key"), suite.Requests[1].Header.Get("Idempotency-Key"))
+	suite.Equal(suite.Bodies[0], suite.Bodies[1])
+}
+
+func (suite *ClientTestSuite) TestClient_DoesNotRetryPatch() {
+	suite.Handler = respond(http.StatusServiceUnavailable, "")
+
+	_, err := suite.newClient().PatchRider(context.Background(), "test-id", PatchRider{}, "")
+
+	suite.True(errors.Is(err, ErrUnavailable))
+	suite.Len(suite.Requests, 1)
+}
+
+func (suite *ClientTestSuite) TestClient_StopsRetryingWhenCancelled() {
+	suite.Handler = respond(http.StatusServiceUnavailable, "", "Retry-After", "60")
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := New(suite.Server.URL, WithRetry(Retry{MaxAttempts: 3, MinBackoff: time.Second, MaxBackoff: time.Minute})).GetRider(ctx, "test-id")
+
+	suite.True(errors.Is(err, context.DeadlineExceeded))
+	suite.Len(suite.Requests, 1)
+}
+
+func (suite *ClientTestSuite) TestClient_Riders() {
+	suite.Handler = respond(http.StatusOK, riderJSON+"\n"+`{"id":"other-id","locationPrecision":"hidden"}`+"\n")
+
+	riders, err := suite.newClient().Riders(context.Background(), RiderFilter{ServiceAreaID: 1})
+	suite.Require().NoError(err)
+	defer riders.Close()
+
+	var ids []string
+
+	for riders.Next() {
+		ids = append(ids, riders.Rider().ID)
+	}
+
+	suite.NoError(riders.Err())
+	suite.Equal([]string{"test-id", "other-id"}, ids)
+	suite.Equal("/api/riders/export", suite.Requests[0].URL.Path)
+	suite.Equal("format=ndjson&serviceArea=1", suite.Requests[0].URL.RawQuery)
+}
+
+func (suite *ClientTestSuite) TestClient_ForEachRider_StopsAtError() {
+	suite.Handler = respond(http.StatusOK, riderJSON+"\n"+riderJSON+"\n")
+	stop := errors.New("stop")
+	calls := 0
+
+	err := suite.newClient().ForEachRider(context.Background(), RiderFilter{}, func(Rider) error {
+		calls++
+		return stop
+	})
+
+	suite.Equal(stop, err)
+	suite.Equal(1, calls)
+}
+
+func (suite *ClientTestSuite) TestClient_Riders_InvalidLine() {
+	suite.Handler = respond(http.StatusOK, riderJSON+"\nnot json\n")
+
+	riders, err := suite.newClient().Riders(context.Background(), RiderFilter{})
+	suite.Require().NoError(err)
+	defer riders.Close()
+
+	suite.True(riders.Next())
+	suite.False(riders.Next())
+	suite.Error(riders.Err())
+}
+
+func TestUnit_ClientTestSuite(t *testing.T) {
+	suite.Run(t, new(ClientTestSuite))
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrUnauthorized = errors.New("not allowed")
+	ErrNotFound     = errors.New("not found")
+	ErrValidation   = errors.New("validation failed")
+	ErrConflict     = errors.New("conflict")
+	// ErrVersionConflict is returned when the rider was changed since the ETag an update was based on.
+	ErrVersionConflict = errors.New("rider was modified concurrently")
+	ErrUnavailable     = errors.New("service unavailable")
+)
+
+// statusErrors maps the status codes of the API to the errors that match an *Error with errors.Is.
+var statusErrors = map[int]error{
+	http.StatusUnauthorized:        ErrUnauthorized,
+	http.StatusForbidden:           ErrUnauthorized,
+	http.StatusNotFound:            ErrNotFound,
+	http.StatusBadRequest:          ErrValidation,
+	http.StatusUnprocessableEntity: ErrValidation,
+	http.StatusConflict:            ErrConflict,
+	http.StatusPreconditionFailed:  ErrVersionConflict,
+	http.StatusServiceUnavailable:  ErrUnavailable,
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is a failed call, decoded from the RFC 7807 problem details the API responds with.
+// Use errors.Is with the Err variables to check what kind of failure it is.
+type Error struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	TraceID  string       `json:"traceId,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+func (err *Error) Error() string {
+	message := fmt.Sprintf("rider service: %d %s", err.Status, err.Title)
+
+	if err.Detail != "" {
+		message += ": " + err.Detail
+	}
+
+	for _, field := range err.Errors {
+		message += fmt.Sprintf(", %s %s", field.Field, field.Message)
+	}
+
+	return message
+}
+
+func (err *Error) Is(target error) bool {
+	return statusErrors[err.Status] == target
+}
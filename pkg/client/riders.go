@@ -0,0 +1,413 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+func riderPath(id string) string {
+	return "/api/riders/" + url.PathEscape(id)
+}
+
+func (filter RiderFilter) query() url.Values {
+	query := url.Values{}
+
+	if filter.Status != nil {
+		query.Set("status", strconv.Itoa(*filter.Status))
+	}
+
+	if filter.ServiceAreaID != 0 {
+		query.Set("serviceArea", strconv.Itoa(filter.ServiceAreaID))
+	}
+
+	if filter.Fits != nil {
+		query.Set("fitsWidth", strconv.Itoa(filter.Fits.Width))
+		query.Set("fitsHeight", strconv.Itoa(filter.Fits.Height))
+		query.Set("fitsDepth", strconv.Itoa(filter.Fits.Depth))
+	}
+
+	return query
+}
+
+// ListRiders returns every rider that matches the filter. It needs admin claims.
+func (client *Client) ListRiders(ctx context.Context, filter RiderFilter) ([]RiderSummary, error) {
+	r := newRequest(http.MethodGet, "/api/riders")
+	r.query = filter.query()
+
+	var riders []RiderSummary
+	_, err := client.call(ctx, r, &riders)
+
+	return riders, err
+}
+
+func (client *Client) GetRider(ctx context.Context, id string) (Rider, error) {
+	return client.riderCall(ctx, newRequest(http.MethodGet, riderPath(id)))
+}
+
+func (client *Client) CreateRider(ctx context.Context, rider CreateRider) (Rider, error) {
+	r, err := newRequest(http.MethodPost, "/api/riders").withJSON(rider)
+
+	if err != nil {
+		return Rider{}, err
+	}
+
+	r.idempotent = true
+
+	return client.riderCall(ctx, r)
+}
+
+// UpdateRider replaces the rider's status. With an ETag the update fails with ErrVersionConflict
+// when the rider was changed since, pass an empty ETag to update unconditionally.
+func (client *Client) UpdateRider(ctx context.Context, id string, update UpdateRider, etag string) (Rider, error) {
+	r, err := newRequest(http.MethodPut, riderPath(id)).withJSON(update)
+
+	if err != nil {
+		return Rider{}, err
+	}
+
+	if etag != "" {
+		r.header.Set("If-Match", etag)
+	}
+
+	return client.riderCall(ctx, r)
+}
+
+// PatchRider only changes the fields of the patch that are set. The ETag works like for UpdateRider.
+// A patch is not retried, as it can not be sent with an Idempotency-Key.
+func (client *Client) PatchRider(ctx context.Context, id string, patch PatchRider, etag string) (Rider, error) {
+	r, err := newRequest(http.MethodPatch, riderPath(id)).withJSON(patch)
+
+	if err != nil {
+		return Rider{}, err
+	}
+
+	r.contentType = "application/merge-patch+json"
+
+	if etag != "" {
+		r.header.Set("If-Match", etag)
+	}
+
+	return client.riderCall(ctx, r)
+}
+
+func (client *Client) UpdateLocation(ctx context.Context, id string, fix LocationFix) (Rider, error) {
+	r, err := newRequest(http.MethodPut, riderPath(id)+"/location").withJSON(fix)
+
+	if err != nil {
+		return Rider{}, err
+	}
+
+	r.idempotent = true
+
+	return client.riderCall(ctx, r)
+}
+
+// UpdateLocationBatch sends the fixes a rider's device buffered, the rider moves to the newest one.
+func (client *Client) UpdateLocationBatch(ctx context.Context, id string, fixes []LocationFix) (Rider, error) {
+	r, err := newRequest(http.MethodPost, riderPath(id)+"/locations:batch").withJSON(locationBatch{Fixes: fixes})
+
+	if err != nil {
+		return Rider{}, err
+	}
+
+	r.idempotent = true
+
+	return client.riderCall(ctx, r)
+}
+
+// UpdateLocationBatches sends the buffered fixes of several riders at once, all or nothing.
+func (client *Client) UpdateLocationBatches(ctx context.Context, batches []RiderLocationBatch) ([]Rider, error) {
+	r, err := newRequest(http.MethodPost, "/api/riders/locations:batch").withJSON(locationBatches{Riders: batches})
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.idempotent = true
+
+	var riders []Rider
+	_, err = client.call(ctx, r, &riders)
+
+	return riders, err
+}
+
+func (client *Client) Heartbeat(ctx context.Context, id string) (Rider, error) {
+	r := newRequest(http.MethodPost, riderPath(id)+"/heartbeat")
+	r.idempotent = true
+
+	return client.riderCall(ctx, r)
+}
+
+// LoadParcel puts a parcel in the rider's box, or replaces the parcel with the same id. A parcel that does not fit
+// in the remaining capacity fails with ErrConflict.
+func (client *Client) LoadParcel(ctx context.Context, riderID string, parcelID string, parcel LoadParcel) (Rider, error) {
+	r, err := newRequest(http.MethodPut, riderPath(riderID)+"/parcels/"+url.PathEscape(parcelID)).withJSON(parcel)
+
+	if err != nil {
+		return Rider{}, err
+	}
+
+	return client.riderCall(ctx, r)
+}
+
+// UnloadParcel takes a parcel out of the rider's box. The parcels of a delivery can not be unloaded, they are
+// unloaded when the delivery is completed or cancelled.
+func (client *Client) UnloadParcel(ctx context.Context, riderID string, parcelID string) (Rider, error) {
+	return client.riderCall(ctx, newRequest(http.MethodDelete, riderPath(riderID)+"/parcels/"+url.PathEscape(parcelID)))
+}
+
+// RiderETA returns the estimated route of the rider to the destination. It needs admin or dispatcher claims.
+func (client *Client) RiderETA(ctx context.Context, id string, destination Location) (ETA, error) {
+	r := newRequest(http.MethodGet, riderPath(id)+"/eta")
+	r.query = destination.query()
+
+	var eta ETA
+	_, err := client.call(ctx, r, &eta)
+
+	return eta, err
+}
+
+// RiderETAs returns the estimated routes of the riders to the destination, fastest first. Riders without a route
+// are listed last with an Error. It needs admin or dispatcher claims.
+func (client *Client) RiderETAs(ctx context.Context, ids []string, destination Location) ([]ETA, error) {
+	r := newRequest(http.MethodGet, "/api/riders/eta")
+	r.query = destination.query()
+	r.query["rider"] = ids
+
+	var etas []ETA
+	_, err := client.call(ctx, r, &etas)
+
+	return etas, err
+}
+
+func (location Location) query() url.Values {
+	return url.Values{
+		"lat": {strconv.FormatFloat(location.Latitude, 'f', -1, 64)},
+		"lon": {strconv.FormatFloat(location.Longitude, 'f', -1, 64)},
+	}
+}
+
+// LocationAnomalies returns the suspicious fixes that match the filter, newest first. It needs admin claims.
+func (client *Client) LocationAnomalies(ctx context.Context, filter AnomalyFilter) ([]LocationAnomaly, error) {
+	r := newRequest(http.MethodGet, "/api/location-anomalies")
+	r.query = url.Values{}
+
+	if filter.RiderID != "" {
+		r.query.Set("rider", filter.RiderID)
+	}
+
+	if filter.Kind != "" {
+		r.query.Set("kind", filter.Kind)
+	}
+
+	if !filter.Since.IsZero() {
+		r.query.Set("since", filter.Since.Format(time.RFC3339))
+	}
+
+	if filter.Limit != 0 {
+		r.query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+
+	var anomalies []LocationAnomaly
+	_, err := client.call(ctx, r, &anomalies)
+
+	return anomalies, err
+}
+
+// ServiceAreas returns the service areas riders can be assigned to.
+func (client *Client) ServiceAreas(ctx context.Context) ([]ServiceArea, error) {
+	var serviceAreas []ServiceArea
+	_, err := client.call(ctx, newRequest(http.MethodGet, "/api/service-areas"), &serviceAreas)
+
+	return serviceAreas, err
+}
+
+// ServiceAreaRiders returns the riders of the service area, also of a deleted one. It needs admin or dispatcher claims.
+func (client *Client) ServiceAreaRiders(ctx context.Context, id int) ([]Rider, error) {
+	var riders []Rider
+	_, err := client.call(ctx, newRequest(http.MethodGet, "/api/service-areas/"+strconv.Itoa(id)+"/riders"), &riders)
+
+	return riders, err
+}
+
+// ServiceAreaStats returns an overview of the riders of the service area. It needs admin or dispatcher claims.
+func (client *Client) ServiceAreaStats(ctx context.Context, id int) (ServiceAreaStats, error) {
+	var stats ServiceAreaStats
+	_, err := client.call(ctx, newRequest(http.MethodGet, "/api/service-areas/"+strconv.Itoa(id)+"/stats"), &stats)
+
+	return stats, err
+}
+
+// ServiceAreaHeatmap returns the number of riders of the service area per geohash cell. It needs admin or dispatcher
+// claims.
+func (client *Client) ServiceAreaHeatmap(ctx context.Context, id int, filter HeatmapFilter) (Heatmap, error) {
+	r := newRequest(http.MethodGet, "/api/service-areas/"+strconv.Itoa(id)+"/heatmap")
+	r.query = url.Values{}
+
+	if filter.Source != "" {
+		r.query.Set("source", filter.Source)
+	}
+
+	if filter.Precision != 0 {
+		r.query.Set("precision", strconv.Itoa(filter.Precision))
+	}
+
+	if !filter.Since.IsZero() {
+		r.query.Set("since", filter.Since.Format(time.RFC3339))
+	}
+
+	var heatmap Heatmap
+	_, err := client.call(ctx, r, &heatmap)
+
+	return heatmap, err
+}
+
+// StatsSummary returns an overview of the riders of every service area. It needs admin or dispatcher claims.
+func (client *Client) StatsSummary(ctx context.Context) (StatsSummary, error) {
+	var summary StatsSummary
+	_, err := client.call(ctx, newRequest(http.MethodGet, "/api/service-areas/stats"), &summary)
+
+	return summary, err
+}
+
+// ImportRiders creates riders in bulk from a CSV or NDJSON body, see ImportFormatCSV and ImportFormatNDJSON.
+// Without commit the rows are only validated. It needs admin claims.
+func (client *Client) ImportRiders(ctx context.Context, body io.Reader, format string, commit bool) (ImportResult, error) {
+	data, err := io.ReadAll(body)
+
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	r := newRequest(http.MethodPost, "/api/riders/import")
+	r.query = url.Values{"format": {format}, "commit": {strconv.FormatBool(commit)}}
+	r.body = data
+	r.contentType = "text/csv"
+	r.idempotent = true
+
+	if format == ImportFormatNDJSON {
+		r.contentType = "application/x-ndjson"
+	}
+
+	var result ImportResult
+	_, err = client.call(ctx, r, &result)
+
+	return result, err
+}
+
+func (client *Client) riderCall(ctx context.Context, r *request) (Rider, error) {
+	var rider Rider
+	header, err := client.call(ctx, r, &rider)
+
+	if err != nil {
+		return Rider{}, err
+	}
+
+	rider.ETag = header.Get("ETag")
+
+	return rider, nil
+}
+
+// RiderIterator goes through riders one at a time, without loading them all at once.
+//
+//	riders, err := client.Riders(ctx, filter)
+//	defer riders.Close()
+//
+//	for riders.Next() {
+//		rider := riders.Rider()
+//	}
+//
+//	err = riders.Err()
+type RiderIterator struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+	rider   Rider
+	err     error
+}
+
+// Riders iterates over every rider that matches the filter. The list endpoint returns all riders in one response,
+// so the riders are streamed from the NDJSON export instead, with locations as precise as admins see them.
+// It needs admin claims.
+func (client *Client) Riders(ctx context.Context, filter RiderFilter) (*RiderIterator, error) {
+	r := newRequest(http.MethodGet, "/api/riders/export")
+	r.query = filter.query()
+	r.query.Set("format", "ndjson")
+
+	response, err := client.send(ctx, r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(response.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	return &RiderIterator{body: response.Body, scanner: scanner}, nil
+}
+
+// Next moves to the next rider, it returns false at the end or when reading failed.
+func (iterator *RiderIterator) Next() bool {
+	if iterator.err != nil {
+		return false
+	}
+
+	for iterator.scanner.Scan() {
+		line := bytes.TrimSpace(iterator.scanner.Bytes())
+
+		if len(line) == 0 {
+			continue
+		}
+
+		iterator.rider = Rider{}
+
+		if iterator.err = json.Unmarshal(line, &iterator.rider); iterator.err != nil {
+			return false
+		}
+
+		return true
+	}
+
+	iterator.err = iterator.scanner.Err()
+
+	return false
+}
+
+func (iterator *RiderIterator) Rider() Rider {
+	return iterator.rider
+}
+
+// Err returns the error that stopped the iteration, if any. An export that fails after it started
+// ends without the last riders, which is not reported.
+func (iterator *RiderIterator) Err() error {
+	return iterator.err
+}
+
+func (iterator *RiderIterator) Close() error {
+	return iterator.body.Close()
+}
+
+// ForEachRider calls fn for every rider that matches the filter, the first error of fn stops the iteration and is returned.
+func (client *Client) ForEachRider(ctx context.Context, filter RiderFilter, fn func(Rider) error) error {
+	riders, err := client.Riders(ctx, filter)
+
+	if err != nil {
+		return err
+	}
+
+	defer riders.Close()
+
+	for riders.Next() {
+		if err := fn(riders.Rider()); err != nil {
+			return err
+		}
+	}
+
+	return riders.Err()
+}
@@ -0,0 +1,282 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/yaml.v2"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+const swaggerPath = "../../docs/swagger.yaml"
+
+type swaggerSchema struct {
+	Type       string                   `yaml:"type"`
+	Ref        string                   `yaml:"$ref"`
+	Items      *swaggerSchema           `yaml:"items"`
+	Properties map[string]swaggerSchema `yaml:"properties"`
+}
+
+type swaggerParameter struct {
+	Name string `yaml:"name"`
+	In   string `yaml:"in"`
+}
+
+type swaggerOperation struct {
+	Parameters []swaggerParameter `yaml:"parameters"`
+}
+
+type swaggerSpec struct {
+	Definitions map[string]swaggerSchema               `yaml:"definitions"`
+	Paths       map[string]map[string]swaggerOperation `yaml:"paths"`
+}
+
+// recordedCall is a call the client made, as the test server received it.
+type recordedCall struct {
+	method  string
+	path    string
+	query   []string
+	hasBody bool
+}
+
+// SwaggerTestSuite checks the client against docs/swagger.yaml, so the client has to change along with the API.
+type SwaggerTestSuite struct {
+	suite.Suite
+	Spec swaggerSpec
+}
+
+func (suite *SwaggerTestSuite) SetupSuite() {
+	data, err := os.ReadFile(swaggerPath)
+	suite.Require().NoError(err)
+	suite.Require().NoError(yaml.Unmarshal(data, &suite.Spec))
+}
+
+// recordCalls calls every method of the client against a server that records the calls and answers them with null.
+func (suite *SwaggerTestSuite) recordCalls() []recordedCall {
+	var calls []recordedCall
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := make([]string, 0, len(r.URL.Query()))
+
+		for name := range r.URL.Query() {
+			query = append(query, name)
+		}
+
+		body := new(bytes.Buffer)
+		_, _ = body.ReadFrom(r.Body)
+
+		calls = append(calls, recordedCall{method: r.Method, path: r.URL.EscapedPath(), query: query, hasBody: body.Len() > 0})
+		_, _ = w.Write([]byte("null"))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	status := StatusAvailable
+	sut := New(server.URL)
+
+	_, _ = sut.ListRiders(ctx, RiderFilter{Status: &status, ServiceAreaID: 1, Fits: &Dimensions{Width: 1, Height: 1, Depth: 1}})
+	_, _ = sut.GetRider(ctx, "id")
+	_, _ = sut.CreateRider(ctx, CreateRider{ID: "id"})
+	_, _ = sut.UpdateRider(ctx, "id", UpdateRider{}, "")
+	_, _ = sut.PatchRider(ctx, "id", PatchRider{}, "")
+	_, _ = sut.UpdateLocation(ctx, "id", LocationFix{})
+	_, _ = sut.UpdateLocationBatch(ctx, "id", []LocationFix{{}})
+	_, _ = sut.UpdateLocationBatches(ctx, []RiderLocationBatch{{ID: "id"}})
+	_, _ = sut.Heartbeat(ctx, "id")
+	_, _ = sut.LoadParcel(ctx, "id", "parcel", LoadParcel{Size: Dimensions{Width: 1, Height: 1, Depth: 1}})
+	_, _ = sut.UnloadParcel(ctx, "id", "parcel")
+	_, _ = sut.RiderETA(ctx, "id", Location{Latitude: 51.44, Longitude: 5.47})
+	_, _ = sut.RiderETAs(ctx, []string{"id", "other"}, Location{Latitude: 51.44, Longitude: 5.47})
+	_, _ = sut.LocationAnomalies(ctx, AnomalyFilter{RiderID: "id", Kind: "mock_location", Since: time.Now(), Limit: 1})
+	_, _ = sut.ServiceAreas(ctx)
+	_, _ = sut.ServiceAreaRiders(ctx, 1)
+	_, _ = sut.ServiceAreaStats(ctx, 1)
+	_, _ = sut.ServiceAreaHeatmap(ctx, 1, HeatmapFilter{Source: HeatmapSourceHistory, Precision: 5, Since: time.Now()})
+	_, _ = sut.StatsSummary(ctx)
+	_, _ = sut.ImportRiders(ctx, strings.NewReader("id,serviceArea,width,height,depth"), ImportFormatCSV, false)
+	_ = sut.ForEachRider(ctx, RiderFilter{Status: &status, ServiceAreaID: 1, Fits: &Dimensions{Width: 1, Height: 1, Depth: 1}}, func(Rider) error { return nil })
+
+	return calls
+}
+
+// operationOf returns the swagger path of the call, preferring paths with the fewest parameters, like gin routes them.
+func (suite *SwaggerTestSuite) operationOf(call recordedCall) (string, bool) {
+	var matches []string
+
+	for path, operations := range suite.Spec.Paths {
+		pattern := "^" + regexp.MustCompile(`\\\{[^}]+\\\}`).ReplaceAllString(regexp.QuoteMeta(path), `[^/]+`) + "$"
+
+		if _, exists := operations[strings.ToLower(call.method)]; exists && regexp.MustCompile(pattern).MatchString(call.path) {
+			matches = append(matches, path)
+		}
+	}
+
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return strings.Count(matches[i], "{") < strings.Count(matches[j], "{")
+	})
+
+	return matches[0], true
+}
+
+func (suite *SwaggerTestSuite) TestSwagger_EveryCallIsDocumented() {
+	for _, call := range suite.recordCalls() {
+		path, documented := suite.operationOf(call)
+
+		if !suite.True(documented, "%s %s is not in swagger.yaml", call.method, call.path) {
+			continue
+		}
+
+		parameters := map[string]string{}
+
+		for _, parameter := range suite.Spec.Paths[path][strings.ToLower(call.method)].Parameters {
+			parameters[parameter.Name] = parameter.In
+		}
+
+		for _, name := range call.query {
+			suite.Equal("query", parameters[name], "%s %s sends query parameter %s that is not documented", call.method, path, name)
+		}
+
+		for name, in := range parameters {
+			if in == "body" {
+				suite.True(call.hasBody, "%s %s has body parameter %s, but the client sends no body", call.method, path, name)
+			}
+		}
+	}
+}
+
+func (suite *SwaggerTestSuite) TestSwagger_EveryOperationIsCalled() {
+	called := map[string]bool{}
+
+	for _, call := range suite.recordCalls() {
+		if path, documented := suite.operationOf(call); documented {
+			called[strings.ToLower(call.method)+" "+path] = true
+		}
+	}
+
+	for path, operations := range suite.Spec.Paths {
+		for method := range operations {
+			suite.True(called[method+" "+path], "the client has no method for %s %s", strings.ToUpper(method), path)
+		}
+	}
+}
+
+func (suite *SwaggerTestSuite) TestSwagger_TypesMatchDefinitions() {
+	definitions := map[string]reflect.Type{
+		"dto.RiderResponse":           reflect.TypeOf(Rider{}),
+		"dto.ridersResponse":          reflect.TypeOf(RiderSummary{}),
+		"dto.BodyCreateRider":         reflect.TypeOf(CreateRider{}),
+		"dto.BodyUpdateRider":         reflect.TypeOf(UpdateRider{}),
+		"dto.BodyPatchRider":          reflect.TypeOf(PatchRider{}),
+		"dto.BodyLocation":            reflect.TypeOf(LocationFix{}),
+		"dto.BodyLocationBatch":       reflect.TypeOf(locationBatch{}),
+		"dto.BodyLocationBatches":     reflect.TypeOf(locationBatches{}),
+		"dto.BodyRiderLocationBatch":  reflect.TypeOf(RiderLocationBatch{}),
+		"dto.LocationAnomalyResponse": reflect.TypeOf(LocationAnomaly{}),
+		"dto.RiderImportResponse":     reflect.TypeOf(ImportResult{}),
+		"dto.ServiceAreaResponse":     reflect.TypeOf(ServiceArea{}),
+		"dto.StatsSummaryResponse":    reflect.TypeOf(StatsSummary{}),
+		"dto.HeatmapResponse":         reflect.TypeOf(Heatmap{}),
+		"dto.RiderETAResponse":        reflect.TypeOf(ETA{}),
+		"dto.BodyParcel":              reflect.TypeOf(LoadParcel{}),
+		"dto.ParcelResponse":          reflect.TypeOf(Parcel{}),
+		"dto.ProblemResponse":         reflect.TypeOf(Error{}),
+	}
+
+	for name, typ := range definitions {
+		suite.matchSchema(swaggerSchema{Ref: "#/definitions/" + name}, typ, name)
+	}
+}
+
+// matchSchema checks that the type has the same JSON fields, of the same kind, as the schema.
+func (suite *SwaggerTestSuite) matchSchema(schema swaggerSchema, typ reflect.Type, path string) {
+	if schema.Ref != "" {
+		definition, exists := suite.Spec.Definitions[strings.TrimPrefix(schema.Ref, "#/definitions/")]
+
+		if !suite.True(exists, "%s refers to missing definition %s", path, schema.Ref) {
+			return
+		}
+
+		schema = definition
+	}
+
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ == reflect.TypeOf(time.Time{}):
+		suite.Equal("string", schema.Type, path)
+	case typ.Kind() == reflect.Struct:
+		suite.Equal("object", schema.Type, path)
+		fields := jsonFields(typ)
+
+		for name, field := range fields {
+			property, documented := schema.Properties[name]
+
+			if suite.True(documented, "%s.%s is not in swagger.yaml", path, name) {
+				suite.matchSchema(property, field, path+"."+name)
+			}
+		}
+
+		for name := range schema.Properties {
+			_, exists := fields[name]
+			suite.True(exists, "%s.%s is in swagger.yaml, but not in the client", path, name)
+		}
+	case typ.Kind() == reflect.Slice:
+		if suite.Equal("array", schema.Type, path) && suite.NotNil(schema.Items, path) {
+			suite.matchSchema(*schema.Items, typ.Elem(), path+"[]")
+		}
+	default:
+		suite.Equal(jsonKind(typ.Kind()), schema.Type, path)
+	}
+}
+
+func jsonFields(typ reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+
+		if field.PkgPath != "" || name == "-" || name == "" {
+			continue
+		}
+
+		fields[name] = field.Type
+	}
+
+	return fields
+}
+
+func jsonKind(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	}
+
+	return fmt.Sprintf("unsupported kind %s", kind)
+}
+
+func TestUnit_SwaggerTestSuite(t *testing.T) {
+	suite.Run(t, new(SwaggerTestSuite))
+}
@@ -0,0 +1,299 @@
+package client
+
+import "time"
+
+const (
+	StatusOffline    = 0
+	StatusAvailable  = 1
+	StatusAssigned   = 2
+	StatusDelivering = 3
+)
+
+const (
+	// PrecisionExact is the location as the rider's device reported it.
+	PrecisionExact = "exact"
+	// PrecisionCoarse is the centre of the grid cell the rider is in.
+	PrecisionCoarse = "coarse"
+	// PrecisionHidden means the rider has no location, it is offline.
+	PrecisionHidden = "hidden"
+)
+
+const (
+	VehicleBicycle   = "bicycle"
+	VehicleEBike     = "e-bike"
+	VehicleCargoBike = "cargo-bike"
+)
+
+type User struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	LastName string `json:"lastName"`
+}
+
+type ServiceArea struct {
+	ID         int    `json:"id"`
+	Identifier string `json:"identifier"`
+	// Deleted is set on the service area of a rider that still has to be moved out of a deleted service area.
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+type Dimensions struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	Depth  int `json:"depth"`
+}
+
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Telemetry is what the rider's device last reported, fields that were not reported are nil.
+type Telemetry struct {
+	Accuracy *float64 `json:"accuracy,omitempty"`
+	Speed    *float64 `json:"speed,omitempty"`
+	Heading  *float64 `json:"heading,omitempty"`
+	Altitude *float64 `json:"altitude,omitempty"`
+	Battery  *int     `json:"battery,omitempty"`
+}
+
+type Rider struct {
+	ID          string      `json:"id"`
+	User        User        `json:"user"`
+	Status      int         `json:"status"`
+	ServiceArea ServiceArea `json:"serviceArea"`
+	Capacity    Dimensions  `json:"capacity"`
+	VehicleType string      `json:"vehicleType"`
+	// DeliveryID is the delivery the rider is carrying, or assigned to when it carries none. It is empty while the
+	// rider has no delivery.
+	DeliveryID string `json:"deliveryId,omitempty"`
+	// RemainingCapacity is the room left in the rider's box with its parcels in it.
+	RemainingCapacity Dimensions `json:"remainingCapacity"`
+	Parcels           []Parcel   `json:"parcels"`
+	// Location is nil when the rider does not share it, LocationPrecision tells whether it is exact or coarse.
+	Location          *Location  `json:"location,omitempty"`
+	LocationPrecision string     `json:"locationPrecision"`
+	Telemetry         Telemetry  `json:"telemetry"`
+	LowBattery        bool       `json:"lowBattery"`
+	LastSeenAt        *time.Time `json:"lastSeenAt,omitempty"`
+	// ETag is the version of the rider to pass to UpdateRider and PatchRider. It is only set by the calls
+	// that return a single rider with an ETag header.
+	ETag string `json:"-"`
+}
+
+// RiderSummary is a rider as ListRiders returns it.
+type RiderSummary struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Status        int    `json:"status"`
+	ServiceAreaID int    `json:"serviceArea"`
+	// RemainingCapacity is the room left in the rider's box.
+	RemainingCapacity Dimensions `json:"remainingCapacity"`
+}
+
+// RiderFilter selects riders. Zero fields do not filter.
+type RiderFilter struct {
+	Status        *int
+	ServiceAreaID int
+	// Fits only selects riders with room left for a parcel of the size.
+	Fits *Dimensions
+}
+
+type CreateRider struct {
+	ID          string     `json:"id"`
+	ServiceArea int        `json:"serviceArea"`
+	Capacity    Dimensions `json:"capacity"`
+	Status      int        `json:"status"`
+}
+
+// UpdateRider replaces the rider's status. A service area of 0 and an empty capacity keep the current values.
+type UpdateRider struct {
+	ServiceArea int        `json:"serviceArea"`
+	Capacity    Dimensions `json:"capacity"`
+	Status      int        `json:"status"`
+}
+
+type PatchDimensions struct {
+	Width  *int `json:"width,omitempty"`
+	Height *int `json:"height,omitempty"`
+	Depth  *int `json:"depth,omitempty"`
+}
+
+// PatchRider only changes the fields that are set.
+type PatchRider struct {
+	ServiceArea *int             `json:"serviceArea,omitempty"`
+	Capacity    *PatchDimensions `json:"capacity,omitempty"`
+	Status      *int             `json:"status,omitempty"`
+	VehicleType *string          `json:"vehicleType,omitempty"`
+}
+
+// Parcel is a parcel in a rider's box. DeliveryID is empty for parcels loaded with LoadParcel, and PickedUp is not
+// set for the parcels of a delivery that was only assigned.
+type Parcel struct {
+	ID         string     `json:"id"`
+	DeliveryID string     `json:"deliveryId,omitempty"`
+	Size       Dimensions `json:"size"`
+	Weight     float64    `json:"weight"`
+	PickedUp   bool       `json:"pickedUp"`
+}
+
+// LoadParcel is the size of a parcel and its weight in kilograms.
+type LoadParcel struct {
+	Size   Dimensions `json:"size"`
+	Weight float64    `json:"weight,omitempty"`
+}
+
+// LocationFix is a location of a rider. Timestamp and sequence are optional and let the service drop fixes
+// that arrive out of order, the telemetry fields are optional as well.
+type LocationFix struct {
+	Latitude  float64    `json:"latitude"`
+	Longitude float64    `json:"longitude"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Sequence  int64      `json:"sequence,omitempty"`
+	Accuracy  *float64   `json:"accuracy,omitempty"`
+	Speed     *float64   `json:"speed,omitempty"`
+	Heading   *float64   `json:"heading,omitempty"`
+	Altitude  *float64   `json:"altitude,omitempty"`
+	Battery   *int       `json:"battery,omitempty"`
+	Mocked    bool       `json:"mocked,omitempty"`
+}
+
+// RiderLocationBatch holds the buffered fixes of one rider.
+type RiderLocationBatch struct {
+	ID    string        `json:"id"`
+	Fixes []LocationFix `json:"fixes"`
+}
+
+type locationBatch struct {
+	Fixes []LocationFix `json:"fixes"`
+}
+
+type locationBatches struct {
+	Riders []RiderLocationBatch `json:"riders"`
+}
+
+// AnomalyFilter selects location anomalies. Zero fields do not filter, without a limit the service returns 100.
+type AnomalyFilter struct {
+	RiderID string
+	Kind    string
+	Since   time.Time
+	Limit   int
+}
+
+type LocationAnomaly struct {
+	ID               uint      `json:"id"`
+	RiderID          string    `json:"riderId"`
+	Kind             string    `json:"kind"`
+	Location         Location  `json:"location"`
+	PreviousLocation Location  `json:"previousLocation"`
+	ImpliedSpeed     float64   `json:"impliedSpeed,omitempty"`
+	Rejected         bool      `json:"rejected"`
+	DetectedAt       time.Time `json:"detectedAt"`
+}
+
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+type ImportRow struct {
+	Line   int          `json:"line"`
+	ID     string       `json:"id"`
+	Status string       `json:"status"`
+	Detail string       `json:"detail,omitempty"`
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+type ImportResult struct {
+	DryRun  bool        `json:"dryRun"`
+	Created int         `json:"created"`
+	Valid   int         `json:"valid"`
+	Failed  int         `json:"failed"`
+	Rows    []ImportRow `json:"rows"`
+}
+
+type RidersByStatus struct {
+	Offline    int `json:"offline"`
+	Available  int `json:"available"`
+	Assigned   int `json:"assigned"`
+	Delivering int `json:"delivering"`
+}
+
+type AverageCapacity struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	Depth  float64 `json:"depth"`
+}
+
+type RiderStats struct {
+	Riders   int            `json:"riders"`
+	Online   int            `json:"online"`
+	ByStatus RidersByStatus `json:"byStatus"`
+	// AverageCapacity is taken over the riders that are online.
+	AverageCapacity AverageCapacity `json:"averageCapacity"`
+	StaleLocations  int             `json:"staleLocations"`
+}
+
+type ServiceAreaStats struct {
+	ServiceArea ServiceArea `json:"serviceArea"`
+	Stats       RiderStats  `json:"stats"`
+	ComputedAt  time.Time   `json:"computedAt"`
+}
+
+type StatsSummary struct {
+	ServiceAreas []ServiceAreaStats `json:"serviceAreas"`
+	Total        RiderStats         `json:"total"`
+	ComputedAt   time.Time          `json:"computedAt"`
+}
+
+const (
+	HeatmapSourceRiders  = "riders"
+	HeatmapSourceHistory = "history"
+)
+
+// HeatmapFilter picks what a heatmap counts. Zero fields are left to the service.
+type HeatmapFilter struct {
+	Source    string
+	Precision int
+	Since     time.Time
+}
+
+// Polygon is a GeoJSON Polygon, longitude first.
+type Polygon struct {
+	Type        string        `json:"type"`
+	Coordinates [][][]float64 `json:"coordinates"`
+}
+
+type HeatmapCellProperties struct {
+	Geohash string `json:"geohash"`
+	Count   int    `json:"count"`
+}
+
+type HeatmapCell struct {
+	Type       string                `json:"type"`
+	Geometry   Polygon               `json:"geometry"`
+	Properties HeatmapCellProperties `json:"properties"`
+}
+
+// Heatmap is a GeoJSON FeatureCollection of geohash cells.
+type Heatmap struct {
+	Type        string        `json:"type"`
+	Features    []HeatmapCell `json:"features"`
+	ServiceArea ServiceArea   `json:"serviceArea"`
+	Source      string        `json:"source"`
+	Precision   int           `json:"precision"`
+	Since       *time.Time    `json:"since,omitempty"`
+	ComputedAt  time.Time     `json:"computedAt"`
+}
+
+// ETA is the estimated route of a rider to a destination. Distance is in meters and Duration in seconds.
+type ETA struct {
+	RiderID     string     `json:"riderId"`
+	VehicleType string     `json:"vehicleType,omitempty"`
+	Distance    float64    `json:"distance"`
+	Duration    float64    `json:"duration"`
+	LastSeenAt  *time.Time `json:"lastSeenAt,omitempty"`
+	EstimatedAt time.Time  `json:"estimatedAt"`
+	// Error is why there is no route for the rider, it is only set by RiderETAs.
+	Error string `json:"error,omitempty"`
+}
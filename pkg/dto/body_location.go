@@ -1,6 +1,89 @@
 package dto
 
+import (
+	"rider-service/internal/core/domain"
+	"time"
+)
+
+// BodyLocation is a location fix. Timestamp and sequence are optional and let the service drop fixes
+// that arrive out of order. The telemetry fields are optional as well.
 type BodyLocation struct {
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
+	Latitude  float64    `json:"latitude" binding:"gte=-90,lte=90"`
+	Longitude float64    `json:"longitude" binding:"gte=-180,lte=180"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Sequence  int64      `json:"sequence,omitempty" binding:"gte=0"`
+	// Accuracy is the radius in meters the real position is likely within.
+	Accuracy *float64 `json:"accuracy,omitempty" binding:"omitempty,gte=0"`
+	// Speed is in meters per second.
+	Speed *float64 `json:"speed,omitempty" binding:"omitempty,gte=0"`
+	// Heading is in degrees clockwise from true north.
+	Heading *float64 `json:"heading,omitempty" binding:"omitempty,gte=0,lt=360"`
+	// Altitude is in meters above the WGS84 ellipsoid.
+	Altitude *float64 `json:"altitude,omitempty"`
+	// Battery is the charge of the device in percent.
+	Battery *int `json:"battery,omitempty" binding:"omitempty,gte=0,lte=100"`
+	// Mocked is set when the device reports that the position comes from a mock location provider.
+	Mocked bool `json:"mocked,omitempty"`
+}
+
+func (body BodyLocation) ToDomain() domain.LocationFix {
+	fix := domain.LocationFix{
+		Location: domain.Location{
+			Latitude:  body.Latitude,
+			Longitude: body.Longitude,
+		},
+		Sequence: body.Sequence,
+		Telemetry: domain.Telemetry{
+			Accuracy: body.Accuracy,
+			Speed:    body.Speed,
+			Heading:  body.Heading,
+			Altitude: body.Altitude,
+			Battery:  body.Battery,
+		},
+		Mocked: body.Mocked,
+	}
+
+	if body.Timestamp != nil {
+		fix.Timestamp = *body.Timestamp
+	}
+
+	return fix
+}
+
+// BodyLocationBatch holds the fixes a rider's device buffered, in any order.
+type BodyLocationBatch struct {
+	Fixes []BodyLocation `json:"fixes" binding:"required,min=1,max=1000,dive"`
+}
+
+func (body BodyLocationBatch) ToDomain() []domain.LocationFix {
+	fixes := make([]domain.LocationFix, 0, len(body.Fixes))
+
+	for _, fix := range body.Fixes {
+		fixes = append(fixes, fix.ToDomain())
+	}
+
+	return fixes
+}
+
+type BodyRiderLocationBatch struct {
+	ID    string         `json:"id" binding:"required"`
+	Fixes []BodyLocation `json:"fixes" binding:"required,min=1,max=1000,dive"`
+}
+
+// BodyLocationBatches holds buffered fixes of several riders, as sent by gateways.
+type BodyLocationBatches struct {
+	Riders []BodyRiderLocationBatch `json:"riders" binding:"required,min=1,max=100,dive"`
+}
+
+func (body BodyLocationBatches) ToDomain() []domain.LocationBatch {
+	batches := make([]domain.LocationBatch, 0, len(body.Riders))
+
+	for _, rider := range body.Riders {
+		batches = append(batches, domain.LocationBatch{
+			RiderID: rider.ID,
+			Fixes:   BodyLocationBatch{Fixes: rider.Fixes}.ToDomain(),
+		})
+	}
+
+	return batches
 }
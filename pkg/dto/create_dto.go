@@ -1,14 +1,14 @@
 package dto
 
 type CreateDimensions struct {
-	Width  int `json:"width"`
-	Height int `json:"height"`
-	Depth  int `json:"depth"`
+	Width  int `json:"width" binding:"gt=0"`
+	Height int `json:"height" binding:"gt=0"`
+	Depth  int `json:"depth" binding:"gt=0"`
 }
 
 type BodyCreateRider struct {
-	ID          string           `json:"id"`
-	ServiceArea int              `json:"serviceArea"`
+	ID          string           `json:"id" binding:"required"`
+	ServiceArea int              `json:"serviceArea" binding:"gt=0"`
 	Capacity    CreateDimensions `json:"capacity"`
-	Status      int              `json:"status"`
-}
\ No newline at end of file
+	Status      int              `json:"status" binding:"gte=0"`
+}
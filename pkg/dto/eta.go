@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"rider-service/internal/core/domain"
+	"time"
+)
+
+// QueryETA is the destination of an ETA.
+type QueryETA struct {
+	Lat *float64 `form:"lat" json:"lat" binding:"required,min=-90,max=90"`
+	Lon *float64 `form:"lon" json:"lon" binding:"required,min=-180,max=180"`
+}
+
+func (query QueryETA) Destination() domain.Location {
+	return domain.Location{Latitude: *query.Lat, Longitude: *query.Lon}
+}
+
+// QueryETAs is the destination of the ETAs of the riders, which are listed with one rider parameter each.
+type QueryETAs struct {
+	Lat    *float64 `form:"lat" json:"lat" binding:"required,min=-90,max=90"`
+	Lon    *float64 `form:"lon" json:"lon" binding:"required,min=-180,max=180"`
+	Riders []string `form:"rider" json:"rider" binding:"required"`
+}
+
+func (query QueryETAs) Destination() domain.Location {
+	return domain.Location{Latitude: *query.Lat, Longitude: *query.Lon}
+}
+
+type RiderETAResponse struct {
+	RiderID     string `json:"riderId"`
+	VehicleType string `json:"vehicleType,omitempty" enums:"bicycle,e-bike,cargo-bike"`
+	// Distance is in meters and Duration in seconds, both are 0 when the rider has an error.
+	Distance float64 `json:"distance"`
+	Duration float64 `json:"duration"`
+	// LastSeenAt is when the rider was last at the location the route starts from.
+	LastSeenAt  *time.Time `json:"lastSeenAt,omitempty"`
+	EstimatedAt time.Time  `json:"estimatedAt"`
+	// Error is why there is no route for the rider, it is only set in a list of ETAs.
+	Error string `json:"error,omitempty"`
+}
+
+func CreateRiderETAResponse(eta domain.RiderETA) RiderETAResponse {
+	response := RiderETAResponse{
+		RiderID:     eta.RiderID,
+		VehicleType: string(eta.VehicleType),
+		LastSeenAt:  eta.LastSeenAt,
+		EstimatedAt: eta.EstimatedAt,
+	}
+
+	if eta.Err != nil {
+		response.Error = eta.Err.Error()
+		return response
+	}
+
+	response.Distance = eta.Route.Distance
+	response.Duration = eta.Route.Duration.Seconds()
+
+	return response
+}
+
+func CreateRiderETAResponses(etas []domain.RiderETA) []RiderETAResponse {
+	response := make([]RiderETAResponse, 0, len(etas))
+
+	for _, eta := range etas {
+		response = append(response, CreateRiderETAResponse(eta))
+	}
+
+	return response
+}
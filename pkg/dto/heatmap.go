@@ -0,0 +1,94 @@
+package dto
+
+import (
+	"rider-service/internal/core/domain"
+	"time"
+)
+
+// QueryHeatmap picks what a heatmap counts. Every parameter is optional, precision defaults to heatmap.precision and
+// since to heatmap.historyWindow ago.
+type QueryHeatmap struct {
+	Source    string     `form:"source" json:"source" binding:"omitempty,oneof=riders history"`
+	Precision int        `form:"precision" json:"precision" binding:"omitempty,min=1,max=12"`
+	Since     *time.Time `form:"since" json:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+func (query QueryHeatmap) ToDomain(serviceAreaID int) domain.HeatmapFilter {
+	filter := domain.HeatmapFilter{
+		ServiceAreaID: serviceAreaID,
+		Source:        domain.HeatmapSource(query.Source),
+		Precision:     query.Precision,
+	}
+
+	if query.Since != nil {
+		filter.Since = *query.Since
+	}
+
+	return filter
+}
+
+// GeoJSONPolygon is a GeoJSON Polygon geometry. Every ring is closed, its last position is its first.
+type GeoJSONPolygon struct {
+	Type        string         `json:"type"`
+	Coordinates [][][2]float64 `json:"coordinates"`
+}
+
+type heatmapCellProperties struct {
+	Geohash string `json:"geohash"`
+	Count   int    `json:"count"`
+}
+
+// HeatmapCellFeature is a heatmap cell as a GeoJSON Feature with the box of the cell as geometry.
+type HeatmapCellFeature struct {
+	Type       string                `json:"type"`
+	Geometry   GeoJSONPolygon        `json:"geometry"`
+	Properties heatmapCellProperties `json:"properties"`
+}
+
+// HeatmapResponse is a GeoJSON FeatureCollection of the cells. What was counted is added as foreign members.
+type HeatmapResponse struct {
+	Type        string               `json:"type"`
+	Features    []HeatmapCellFeature `json:"features"`
+	ServiceArea ServiceAreaResponse  `json:"serviceArea"`
+	Source      string               `json:"source"`
+	Precision   int                  `json:"precision"`
+	Since       *time.Time           `json:"since,omitempty"`
+	ComputedAt  time.Time            `json:"computedAt"`
+}
+
+func CreateHeatmapResponse(heatmap domain.Heatmap) HeatmapResponse {
+	response := HeatmapResponse{
+		Type:        "FeatureCollection",
+		Features:    make([]HeatmapCellFeature, 0, len(heatmap.Cells)),
+		ServiceArea: CreateServiceAreaResponse(heatmap.ServiceArea),
+		Source:      string(heatmap.Filter.Source),
+		Precision:   heatmap.Filter.Precision,
+		ComputedAt:  heatmap.ComputedAt,
+	}
+
+	if heatmap.Filter.Source == domain.HeatmapHistory {
+		response.Since = &heatmap.Filter.Since
+	}
+
+	for _, cell := range heatmap.Cells {
+		bounds := cell.Bounds
+
+		response.Features = append(response.Features, HeatmapCellFeature{
+			Type: "Feature",
+			Geometry: GeoJSONPolygon{
+				Type: "Polygon",
+				// Counterclockwise, as RFC 7946 asks of exterior rings.
+				Coordinates: [][][2]float64{{
+					{bounds.MinLongitude, bounds.MinLatitude},
+					{bounds.MaxLongitude, bounds.MinLatitude},
+					{bounds.MaxLongitude, bounds.MaxLatitude},
+					{bounds.MinLongitude, bounds.MaxLatitude},
+					{bounds.MinLongitude, bounds.MinLatitude},
+				}},
+			},
+			Properties: heatmapCellProperties{Geohash: cell.Geohash, Count: cell.Count},
+		})
+	}
+
+	return response
+}
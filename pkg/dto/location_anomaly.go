@@ -0,0 +1,66 @@
+package dto
+
+import (
+	"rider-service/internal/core/domain"
+	"time"
+)
+
+// QueryLocationAnomalies filters the anomaly report. Every parameter is optional.
+type QueryLocationAnomalies struct {
+	Rider string     `form:"rider" json:"rider"`
+	Kind  string     `form:"kind" json:"kind" binding:"omitempty,oneof=impossible_jump mock_location"`
+	Since *time.Time `form:"since" json:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit int        `form:"limit" json:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+// defaultAnomalyLimit is the number of anomalies reported when the query does not set a limit.
+const defaultAnomalyLimit = 100
+
+func (query QueryLocationAnomalies) ToDomain() domain.LocationAnomalyFilter {
+	filter := domain.LocationAnomalyFilter{
+		RiderID: query.Rider,
+		Kind:    domain.AnomalyKind(query.Kind),
+		Limit:   query.Limit,
+	}
+
+	if query.Since != nil {
+		filter.Since = *query.Since
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultAnomalyLimit
+	}
+
+	return filter
+}
+
+type LocationAnomalyResponse struct {
+	ID               uint                  `json:"id"`
+	RiderID          string                `json:"riderId"`
+	Kind             string                `json:"kind"`
+	Location         riderResponseLocation `json:"location"`
+	PreviousLocation riderResponseLocation `json:"previousLocation"`
+	// ImpliedSpeed is in meters per second.
+	ImpliedSpeed float64   `json:"impliedSpeed,omitempty"`
+	Rejected     bool      `json:"rejected"`
+	DetectedAt   time.Time `json:"detectedAt"`
+}
+
+func CreateLocationAnomalyResponses(anomalies []domain.LocationAnomaly) []LocationAnomalyResponse {
+	response := make([]LocationAnomalyResponse, 0, len(anomalies))
+
+	for _, anomaly := range anomalies {
+		response = append(response, LocationAnomalyResponse{
+			ID:               anomaly.ID,
+			RiderID:          anomaly.RiderID,
+			Kind:             string(anomaly.Kind),
+			Location:         riderResponseLocation(anomaly.Location),
+			PreviousLocation: riderResponseLocation(anomaly.PreviousLocation),
+			ImpliedSpeed:     anomaly.ImpliedSpeed,
+			Rejected:         anomaly.Rejected,
+			DetectedAt:       anomaly.DetectedAt,
+		})
+	}
+
+	return response
+}
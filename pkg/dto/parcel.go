@@ -0,0 +1,44 @@
+package dto
+
+import "rider-service/internal/core/domain"
+
+// BodyParcel is a parcel loaded in a rider's box through the API, its id is part of the path.
+type BodyParcel struct {
+	Size CreateDimensions `json:"size"`
+	// Weight is in kilograms.
+	Weight float64 `json:"weight,omitempty" binding:"gte=0"`
+}
+
+func (body BodyParcel) ToDomain(id string) domain.Parcel {
+	return domain.Parcel{
+		ID:     id,
+		Size:   domain.Dimensions{Width: body.Size.Width, Height: body.Size.Height, Depth: body.Size.Depth},
+		Weight: body.Weight,
+	}
+}
+
+type ParcelResponse struct {
+	ID string `json:"id"`
+	// DeliveryID is the delivery the parcel belongs to, it is left out for parcels loaded through the API.
+	DeliveryID string                `json:"deliveryId,omitempty"`
+	Size       riderResponseCapacity `json:"size"`
+	Weight     float64               `json:"weight"`
+	// PickedUp is not set for the parcels of a delivery that was only assigned, their room is kept free.
+	PickedUp bool `json:"pickedUp"`
+}
+
+func createParcelResponses(parcels domain.Parcels) []ParcelResponse {
+	response := make([]ParcelResponse, 0, len(parcels))
+
+	for _, parcel := range parcels {
+		response = append(response, ParcelResponse{
+			ID:         parcel.ID,
+			DeliveryID: parcel.DeliveryID,
+			Size:       riderResponseCapacity(parcel.Size),
+			Weight:     parcel.Weight,
+			PickedUp:   parcel.PickedUp,
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,143 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"rider-service/internal/core/domain"
+	"sort"
+	"strings"
+)
+
+type PatchDimensions struct {
+	Width  *int `json:"width,omitempty"`
+	Height *int `json:"height,omitempty"`
+	Depth  *int `json:"depth,omitempty"`
+}
+
+// BodyPatchRider is a JSON Merge Patch (RFC 7396) document for a rider.
+// Members that are left out are not changed.
+type BodyPatchRider struct {
+	ServiceArea *int             `json:"serviceArea,omitempty"`
+	Capacity    *PatchDimensions `json:"capacity,omitempty"`
+	Status      *int             `json:"status,omitempty"`
+	VehicleType *string          `json:"vehicleType,omitempty" enums:"bicycle,e-bike,cargo-bike"`
+}
+
+// ParseRiderMergePatch decodes a merge patch document. Rider fields can not be removed,
+// so null members and members that do not exist on a rider are rejected with a validation error.
+func ParseRiderMergePatch(data []byte) (BodyPatchRider, error) {
+	var members map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &members); err != nil {
+		return BodyPatchRider{}, patchError("", err)
+	}
+
+	if err := rejectNullMembers("", members); err != nil {
+		return BodyPatchRider{}, err
+	}
+
+	if capacity, exists := members["capacity"]; exists {
+		var capacityMembers map[string]json.RawMessage
+
+		if err := json.Unmarshal(capacity, &capacityMembers); err != nil {
+			return BodyPatchRider{}, patchError("capacity", err)
+		}
+
+		if err := rejectNullMembers("capacity.", capacityMembers); err != nil {
+			return BodyPatchRider{}, err
+		}
+	}
+
+	var body BodyPatchRider
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&body); err != nil {
+		return BodyPatchRider{}, patchError("", err)
+	}
+
+	return body, nil
+}
+
+func rejectNullMembers(prefix string, members map[string]json.RawMessage) error {
+	var fields []domain.FieldError
+
+	for name, value := range members {
+		if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			fields = append(fields, domain.FieldError{Field: prefix + name, Message: "can not be removed"})
+		}
+	}
+
+	if len(fields) > 0 {
+		sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+		return domain.NewValidationError(fields...)
+	}
+
+	return nil
+}
+
+// patchError converts a decoding failure of the member at path into a validation error of that member,
+// or of the body when the document is not valid JSON.
+func patchError(path string, err error) error {
+	var typeErr *json.UnmarshalTypeError
+
+	if errors.As(err, &typeErr) {
+		field := typeErr.Field
+
+		if field == "" {
+			field = path
+		}
+
+		if field == "" {
+			return domain.NewValidationError(domain.FieldError{Field: "body", Message: "must be an object"})
+		}
+
+		return domain.NewValidationError(domain.FieldError{Field: field, Message: "must be " + jsonType(typeErr.Type.Kind())})
+	}
+
+	if message := err.Error(); strings.HasPrefix(message, "json: unknown field ") {
+		name := strings.Trim(strings.TrimPrefix(message, "json: unknown field "), `"`)
+		return domain.NewValidationError(domain.FieldError{Field: name, Message: "is not a rider field"})
+	}
+
+	return domain.NewValidationError(domain.FieldError{Field: "body", Message: "is not valid JSON"})
+}
+
+func (body BodyPatchRider) ToDomain() domain.RiderPatch {
+	patch := domain.RiderPatch{
+		Status:      body.Status,
+		ServiceArea: body.ServiceArea,
+	}
+
+	if body.VehicleType != nil {
+		vehicleType := domain.VehicleType(*body.VehicleType)
+		patch.VehicleType = &vehicleType
+	}
+
+	if body.Capacity != nil {
+		patch.Capacity = &domain.DimensionsPatch{
+			Width:  body.Capacity.Width,
+			Height: body.Capacity.Height,
+			Depth:  body.Capacity.Depth,
+		}
+	}
+
+	return patch
+}
+
+func jsonType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "an integer"
+	case reflect.String:
+		return "a string"
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	default:
+		return fmt.Sprintf("a %s", kind)
+	}
+}
@@ -0,0 +1,17 @@
+package dto
+
+// ProblemResponse is an RFC 7807 problem details document.
+type ProblemResponse struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	TraceID  string              `json:"traceId,omitempty"`
+	Errors   []ProblemFieldError `json:"errors,omitempty"`
+}
+
+type ProblemFieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,33 @@
+package dto
+
+import "rider-service/internal/core/domain"
+
+// QueryRiders filters the rider list. Every parameter is optional.
+type QueryRiders struct {
+	Status      *int `form:"status" json:"status" binding:"omitempty,min=0"`
+	ServiceArea int  `form:"serviceArea" json:"serviceArea" binding:"omitempty,min=1"`
+	// FitsWidth, FitsHeight and FitsDepth only select riders with room left for a parcel of the size. They are
+	// given together or not at all.
+	FitsWidth  int `form:"fitsWidth" json:"fitsWidth" binding:"required_with=FitsHeight FitsDepth,omitempty,min=1"`
+	FitsHeight int `form:"fitsHeight" json:"fitsHeight" binding:"required_with=FitsWidth FitsDepth,omitempty,min=1"`
+	FitsDepth  int `form:"fitsDepth" json:"fitsDepth" binding:"required_with=FitsWidth FitsHeight,omitempty,min=1"`
+}
+
+func (query QueryRiders) ToDomain() domain.RiderFilter {
+	filter := domain.RiderFilter{
+		Status:        query.Status,
+		ServiceAreaID: query.ServiceArea,
+	}
+
+	if query.FitsWidth != 0 {
+		filter.Fits = &domain.Dimensions{Width: query.FitsWidth, Height: query.FitsHeight, Depth: query.FitsDepth}
+	}
+
+	return filter
+}
+
+// QueryRiderExport filters the riders of an export like QueryRiders and picks its format, GeoJSON by default.
+type QueryRiderExport struct {
+	QueryRiders
+	Format string `form:"format" json:"format" binding:"omitempty,oneof=geojson csv ndjson"`
+}
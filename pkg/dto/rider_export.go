@@ -0,0 +1,88 @@
+package dto
+
+import (
+	"rider-service/internal/core/domain"
+	"strconv"
+	"time"
+)
+
+// GeoJSONPoint is a GeoJSON Point geometry. Coordinates are longitude first, as RFC 7946 requires.
+type GeoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+type riderFeatureProperties struct {
+	ID                string                   `json:"id"`
+	Name              string                   `json:"name"`
+	LastName          string                   `json:"lastName"`
+	Status            int                      `json:"status"`
+	ServiceAreaID     int                      `json:"serviceAreaId"`
+	ServiceArea       string                   `json:"serviceArea"`
+	LocationPrecision domain.LocationPrecision `json:"locationPrecision"`
+	LastSeenAt        *time.Time               `json:"lastSeenAt,omitempty"`
+}
+
+// RiderFeature is a rider as a GeoJSON Feature. The geometry is null when the rider does not share its location.
+type RiderFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   *GeoJSONPoint          `json:"geometry"`
+	Properties riderFeatureProperties `json:"properties"`
+}
+
+// CreateRiderFeature creates the feature of a rider whose location was already reduced to the precision.
+func CreateRiderFeature(rider domain.Rider, precision domain.LocationPrecision) RiderFeature {
+	feature := RiderFeature{
+		Type: "Feature",
+		Properties: riderFeatureProperties{
+			ID:                rider.UserID,
+			Name:              rider.User.Name,
+			LastName:          rider.User.LastName,
+			Status:            rider.Status,
+			ServiceAreaID:     rider.ServiceAreaID,
+			ServiceArea:       rider.ServiceArea.Identifier,
+			LocationPrecision: precision,
+			LastSeenAt:        rider.LastSeenAt,
+		},
+	}
+
+	if precision != domain.PrecisionHidden {
+		feature.Geometry = &GeoJSONPoint{
+			Type:        "Point",
+			Coordinates: [2]float64{rider.Location.Longitude, rider.Location.Latitude},
+		}
+	}
+
+	return feature
+}
+
+// RiderCSVHeader names the columns of CreateRiderCSVRecord.
+var RiderCSVHeader = []string{"id", "name", "lastName", "status", "serviceAreaId", "serviceArea", "latitude", "longitude", "locationPrecision", "lastSeenAt"}
+
+// CreateRiderCSVRecord creates the CSV row of a rider whose location was already reduced to the precision.
+// The coordinates are empty when the rider does not share its location.
+func CreateRiderCSVRecord(rider domain.Rider, precision domain.LocationPrecision) []string {
+	var latitude, longitude, lastSeenAt string
+
+	if precision != domain.PrecisionHidden {
+		latitude = strconv.FormatFloat(rider.Location.Latitude, 'f', -1, 64)
+		longitude = strconv.FormatFloat(rider.Location.Longitude, 'f', -1, 64)
+	}
+
+	if rider.LastSeenAt != nil {
+		lastSeenAt = rider.LastSeenAt.UTC().Format(time.RFC3339)
+	}
+
+	return []string{
+		rider.UserID,
+		rider.User.Name,
+		rider.User.LastName,
+		strconv.Itoa(rider.Status),
+		strconv.Itoa(rider.ServiceAreaID),
+		rider.ServiceArea.Identifier,
+		latitude,
+		longitude,
+		string(precision),
+		lastSeenAt,
+	}
+}
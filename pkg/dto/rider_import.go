@@ -0,0 +1,220 @@
+package dto
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"rider-service/internal/core/domain"
+	"strconv"
+	"strings"
+)
+
+// QueryRiderImport picks the format of an import, CSV by default, and whether riders are created or only validated.
+type QueryRiderImport struct {
+	Format string `form:"format" json:"format" binding:"omitempty,oneof=csv ndjson"`
+	Commit bool   `form:"commit" json:"commit"`
+}
+
+// riderImportLine is a line of an NDJSON import.
+type riderImportLine struct {
+	ID          string           `json:"id"`
+	ServiceArea int              `json:"serviceArea"`
+	Capacity    CreateDimensions `json:"capacity"`
+}
+
+// riderImportColumns are the columns a CSV import needs in its header, in any order.
+var riderImportColumns = []string{"id", "serviceArea", "width", "height", "depth"}
+
+// ParseRiderImport reads the rows of a CSV or NDJSON import. Rows that can not be read are returned with the reason
+// in Invalid, so they are reported along with the others. An error is only returned when the file as a whole can not be read.
+func ParseRiderImport(r io.Reader, format string) ([]domain.RiderImportRow, error) {
+	if format == "ndjson" {
+		return parseRiderImportNDJSON(r)
+	}
+
+	return parseRiderImportCSV(r)
+}
+
+func parseRiderImportCSV(r io.Reader) ([]domain.RiderImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, domain.NewValidationError(domain.FieldError{Field: "header", Message: "is not valid CSV"})
+	}
+
+	columns := make(map[string]int, len(header))
+
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	var missing []domain.FieldError
+
+	for _, name := range riderImportColumns {
+		if _, exists := columns[strings.ToLower(name)]; !exists {
+			missing = append(missing, domain.FieldError{Field: "header", Message: fmt.Sprintf("misses the %s column", name)})
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, domain.NewValidationError(missing...)
+	}
+
+	var rows []domain.RiderImportRow
+
+	for {
+		record, err := reader.Read()
+
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+
+		var parseErr *csv.ParseError
+
+		if errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrFieldCount) {
+			row := domain.RiderImportRow{
+				Line:    parseErr.StartLine,
+				Invalid: []domain.FieldError{{Field: "row", Message: fmt.Sprintf("must have %d fields", len(header))}},
+			}
+
+			// The id is still reported when the row has it, so the row can be found.
+			if id := columns["id"]; id < len(record) {
+				row.UserID = strings.TrimSpace(record[id])
+			}
+
+			rows = append(rows, row)
+			continue
+		}
+
+		if err != nil {
+			return nil, domain.NewValidationError(domain.FieldError{Field: "file", Message: err.Error()})
+		}
+
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			return strings.TrimSpace(record[columns[strings.ToLower(name)]])
+		}
+
+		row := domain.RiderImportRow{Line: line, UserID: field("id")}
+		row.ServiceAreaID = parseImportInt(field("serviceArea"), "serviceArea", &row)
+		row.Capacity.Width = parseImportInt(field("width"), "capacity.width", &row)
+		row.Capacity.Height = parseImportInt(field("height"), "capacity.height", &row)
+		row.Capacity.Depth = parseImportInt(field("depth"), "capacity.depth", &row)
+
+		rows = append(rows, validateImportID(row))
+	}
+}
+
+func parseImportInt(value string, field string, row *domain.RiderImportRow) int {
+	number, err := strconv.Atoi(value)
+
+	if err != nil {
+		row.Invalid = append(row.Invalid, domain.FieldError{Field: field, Message: "must be a whole number"})
+	}
+
+	return number
+}
+
+func parseRiderImportNDJSON(r io.Reader) ([]domain.RiderImportRow, error) {
+	scanner := bufio.NewScanner(r)
+
+	var rows []domain.RiderImportRow
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" {
+			continue
+		}
+
+		var line riderImportLine
+		row := domain.RiderImportRow{Line: lineNumber}
+
+		if err := json.Unmarshal([]byte(text), &line); err != nil {
+			row.Invalid = []domain.FieldError{{Field: "row", Message: "is not a valid JSON object"}}
+			rows = append(rows, row)
+			continue
+		}
+
+		row.UserID = line.ID
+		row.ServiceAreaID = line.ServiceArea
+		row.Capacity = domain.Dimensions(line.Capacity)
+
+		rows = append(rows, validateImportID(row))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, domain.NewValidationError(domain.FieldError{Field: "file", Message: err.Error()})
+	}
+
+	return rows, nil
+}
+
+func validateImportID(row domain.RiderImportRow) domain.RiderImportRow {
+	if row.UserID == "" {
+		row.Invalid = append([]domain.FieldError{{Field: "id", Message: "is required"}}, row.Invalid...)
+	}
+
+	return row
+}
+
+type riderImportRowResponse struct {
+	Line   int                      `json:"line"`
+	ID     string                   `json:"id"`
+	Status domain.RiderImportStatus `json:"status" enums:"created,valid,failed"`
+	Detail string                   `json:"detail,omitempty"`
+	Errors []ProblemFieldError      `json:"errors,omitempty"`
+}
+
+// RiderImportResponse reports the result of every row of an import and counts them.
+type RiderImportResponse struct {
+	DryRun  bool                     `json:"dryRun"`
+	Created int                      `json:"created"`
+	Valid   int                      `json:"valid"`
+	Failed  int                      `json:"failed"`
+	Rows    []riderImportRowResponse `json:"rows"`
+}
+
+func CreateRiderImportResponse(results []domain.RiderImportResult, dryRun bool) RiderImportResponse {
+	response := RiderImportResponse{DryRun: dryRun, Rows: make([]riderImportRowResponse, 0, len(results))}
+
+	for _, result := range results {
+		row := riderImportRowResponse{Line: result.Line, ID: result.UserID, Status: result.Status}
+
+		switch result.Status {
+		case domain.ImportCreated:
+			response.Created++
+		case domain.ImportValid:
+			response.Valid++
+		default:
+			response.Failed++
+		}
+
+		var domainErr *domain.Error
+
+		switch {
+		case errors.As(result.Err, &domainErr):
+			row.Detail = domainErr.Message
+
+			for _, field := range domainErr.Fields {
+				row.Errors = append(row.Errors, ProblemFieldError(field))
+			}
+		case result.Err != nil:
+			row.Detail = "the rider could not be imported"
+		}
+
+		response.Rows = append(response.Rows, row)
+	}
+
+	return response
+}
@@ -1,6 +1,9 @@
 package dto
 
-import "rider-service/internal/core/domain"
+import (
+	"rider-service/internal/core/domain"
+	"time"
+)
 
 type riderResponseUser struct {
 	ID       string `json:"id"`
@@ -8,11 +11,6 @@ type riderResponseUser struct {
 	LastName string `json:"lastName"`
 }
 
-type riderResponseArea struct {
-	ID         int    `json:"id"`
-	Identifier string `json:"identifier"`
-}
-
 type riderResponseCapacity struct {
 	Width  int `json:"width"`
 	Height int `json:"height"`
@@ -24,22 +22,62 @@ type riderResponseLocation struct {
 	Longitude float64 `json:"longitude"`
 }
 
+type riderResponseTelemetry struct {
+	Accuracy *float64 `json:"accuracy,omitempty"`
+	Speed    *float64 `json:"speed,omitempty"`
+	Heading  *float64 `json:"heading,omitempty"`
+	Altitude *float64 `json:"altitude,omitempty"`
+	Battery  *int     `json:"battery,omitempty"`
+}
+
 type RiderResponse struct {
 	ID          string                `json:"id"`
 	User        riderResponseUser     `json:"user"`
 	Status      int                   `json:"status"`
-	ServiceArea riderResponseArea     `json:"serviceArea"`
+	ServiceArea ServiceAreaResponse   `json:"serviceArea"`
 	Capacity    riderResponseCapacity `json:"capacity"`
-	Location    riderResponseLocation `json:"location"`
+	VehicleType string                `json:"vehicleType" enums:"bicycle,e-bike,cargo-bike"`
+	// DeliveryID is the delivery the rider is carrying, or assigned to when it carries none. It is left out while the
+	// rider has no delivery.
+	DeliveryID string `json:"deliveryId,omitempty"`
+	// RemainingCapacity is the room left in the box with the parcels in it, each parcel is stacked as a layer of its own.
+	RemainingCapacity riderResponseCapacity `json:"remainingCapacity"`
+	Parcels           []ParcelResponse      `json:"parcels"`
+	// Location is left out when the rider does not share it, LocationPrecision tells whether it is exact or coarse.
+	Location          *riderResponseLocation   `json:"location,omitempty"`
+	LocationPrecision domain.LocationPrecision `json:"locationPrecision" enums:"exact,coarse,hidden"`
+	Telemetry         riderResponseTelemetry   `json:"telemetry"`
+	// LowBattery is set when the rider's device last reported a battery level at or below domain.LowBatteryLevel.
+	LowBattery bool       `json:"lowBattery"`
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
 }
 
-func CreateRiderResponse(rider domain.Rider) RiderResponse {
-	return RiderResponse{
-		ID:          rider.UserID,
-		User:        riderResponseUser(rider.User),
-		Status:      rider.Status,
-		ServiceArea: riderResponseArea(rider.ServiceArea),
-		Capacity:    riderResponseCapacity(rider.Capacity),
-		Location:    riderResponseLocation(rider.Location),
+// CreateRiderResponse creates the response of a rider whose location was already reduced to the precision.
+func CreateRiderResponse(rider domain.Rider, precision domain.LocationPrecision) RiderResponse {
+	response := RiderResponse{
+		ID: rider.UserID,
+		User: riderResponseUser{
+			ID:       rider.User.ID,
+			Name:     rider.User.Name,
+			LastName: rider.User.LastName,
+		},
+		Status:            rider.Status,
+		ServiceArea:       CreateServiceAreaResponse(rider.ServiceArea),
+		Capacity:          riderResponseCapacity(rider.Capacity),
+		VehicleType:       string(rider.VehicleType),
+		DeliveryID:        rider.DeliveryID,
+		RemainingCapacity: riderResponseCapacity(rider.RemainingCapacity()),
+		Parcels:           createParcelResponses(rider.Parcels),
+		LocationPrecision: precision,
+		Telemetry:         riderResponseTelemetry(rider.Telemetry),
+		LowBattery:        rider.Telemetry.HasLowBattery(),
+		LastSeenAt:        rider.LastSeenAt,
 	}
+
+	if precision != domain.PrecisionHidden {
+		location := riderResponseLocation(rider.Location)
+		response.Location = &location
+	}
+
+	return response
 }
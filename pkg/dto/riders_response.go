@@ -7,14 +7,17 @@ type ridersResponse struct {
 	Name          string `json:"name"`
 	Status        int    `json:"status"`
 	ServiceAreaID int    `json:"serviceArea"`
+	// RemainingCapacity is the room left in the rider's box.
+	RemainingCapacity riderResponseCapacity `json:"remainingCapacity"`
 }
 
 func createRidersResponse(rider domain.Rider) ridersResponse {
 	return ridersResponse{
-		ID:            rider.UserID,
-		Name:          rider.User.Name,
-		Status:        rider.Status,
-		ServiceAreaID: rider.ServiceArea.ID,
+		ID:                rider.UserID,
+		Name:              rider.User.Name,
+		Status:            rider.Status,
+		ServiceAreaID:     rider.ServiceArea.ID,
+		RemainingCapacity: riderResponseCapacity(rider.RemainingCapacity()),
 	}
 }
 
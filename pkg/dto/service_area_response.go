@@ -0,0 +1,28 @@
+package dto
+
+import "rider-service/internal/core/domain"
+
+type ServiceAreaResponse struct {
+	ID         int    `json:"id"`
+	Identifier string `json:"identifier"`
+	// Deleted is set on the service area of a rider that still has to be moved out of a deleted service area.
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+func CreateServiceAreaResponse(serviceArea domain.ServiceArea) ServiceAreaResponse {
+	return ServiceAreaResponse{
+		ID:         serviceArea.ID,
+		Identifier: serviceArea.Identifier,
+		Deleted:    serviceArea.IsDeleted(),
+	}
+}
+
+func CreateServiceAreaResponses(serviceAreas []domain.ServiceArea) []ServiceAreaResponse {
+	response := make([]ServiceAreaResponse, 0, len(serviceAreas))
+
+	for _, serviceArea := range serviceAreas {
+		response = append(response, CreateServiceAreaResponse(serviceArea))
+	}
+
+	return response
+}
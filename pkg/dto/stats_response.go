@@ -0,0 +1,78 @@
+package dto
+
+import (
+	"rider-service/internal/core/domain"
+	"time"
+)
+
+type ridersByStatus struct {
+	Offline    int `json:"offline"`
+	Available  int `json:"available"`
+	Assigned   int `json:"assigned"`
+	Delivering int `json:"delivering"`
+}
+
+type averageCapacity struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	Depth  float64 `json:"depth"`
+}
+
+type RiderStatsResponse struct {
+	Riders   int            `json:"riders"`
+	Online   int            `json:"online"`
+	ByStatus ridersByStatus `json:"byStatus"`
+	// AverageCapacity is taken over the riders that are online.
+	AverageCapacity averageCapacity `json:"averageCapacity"`
+	// StaleLocations counts the riders that are online but were not heard from for stats.staleAfter.
+	StaleLocations int `json:"staleLocations"`
+}
+
+type ServiceAreaStatsResponse struct {
+	ServiceArea ServiceAreaResponse `json:"serviceArea"`
+	Stats       RiderStatsResponse  `json:"stats"`
+	ComputedAt  time.Time           `json:"computedAt"`
+}
+
+type StatsSummaryResponse struct {
+	ServiceAreas []ServiceAreaStatsResponse `json:"serviceAreas"`
+	Total        RiderStatsResponse         `json:"total"`
+	ComputedAt   time.Time                  `json:"computedAt"`
+}
+
+func createRiderStatsResponse(stats domain.RiderStats) RiderStatsResponse {
+	return RiderStatsResponse{
+		Riders: stats.Riders(),
+		Online: stats.Online(),
+		ByStatus: ridersByStatus{
+			Offline:    stats.ByStatus[domain.StatusOffline],
+			Available:  stats.ByStatus[domain.StatusAvailable],
+			Assigned:   stats.ByStatus[domain.StatusAssigned],
+			Delivering: stats.ByStatus[domain.StatusDelivering],
+		},
+		AverageCapacity: averageCapacity(stats.AverageCapacity),
+		StaleLocations:  stats.StaleLocations,
+	}
+}
+
+func CreateServiceAreaStatsResponse(stats domain.ServiceAreaStats) ServiceAreaStatsResponse {
+	return ServiceAreaStatsResponse{
+		ServiceArea: CreateServiceAreaResponse(stats.ServiceArea),
+		Stats:       createRiderStatsResponse(stats.Riders),
+		ComputedAt:  stats.ComputedAt,
+	}
+}
+
+func CreateStatsSummaryResponse(summary domain.StatsSummary) StatsSummaryResponse {
+	response := StatsSummaryResponse{
+		ServiceAreas: make([]ServiceAreaStatsResponse, 0, len(summary.ServiceAreas)),
+		Total:        createRiderStatsResponse(summary.Total),
+		ComputedAt:   summary.ComputedAt,
+	}
+
+	for _, stats := range summary.ServiceAreas {
+		response.ServiceAreas = append(response.ServiceAreas, CreateServiceAreaStatsResponse(stats))
+	}
+
+	return response
+}
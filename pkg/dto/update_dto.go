@@ -0,0 +1,14 @@
+package dto
+
+type UpdateDimensions struct {
+	Width  int `json:"width" binding:"gte=0"`
+	Height int `json:"height" binding:"gte=0"`
+	Depth  int `json:"depth" binding:"gte=0"`
+}
+
+// BodyUpdateRider replaces the rider's status. A service area of 0 and an empty capacity keep the current values.
+type BodyUpdateRider struct {
+	ServiceArea int              `json:"serviceArea" binding:"gte=0"`
+	Capacity    UpdateDimensions `json:"capacity"`
+	Status      int              `json:"status" binding:"gte=0"`
+}
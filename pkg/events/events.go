@@ -0,0 +1,188 @@
+// Package events holds the messages the rider service publishes, so consumers in other services can decode them
+// with the same types.
+//
+// Every event type is versioned. Fields may be added to a version, consumers should ignore fields they do not know.
+// Renaming or removing a field, or changing its type, is a new version with a new type, like RiderCreatedV2.
+// The JSON schemas of the events are generated from the types into docs/events with go generate.
+package events
+
+//go:generate go run gen.go
+
+import "time"
+
+// Event is a message the service publishes or consumes.
+type Event interface {
+	// Schema is the name of the event's JSON schema. It is sent along with the event, in the type of a RabbitMQ
+	// message and the "type" property of an Azure Service Bus message, so consumers can tell versions apart.
+	Schema() string
+}
+
+// All is an event of every schema the service publishes, it is used to generate the schemas in docs/events.
+var All = []Event{
+	RiderCreatedV1{},
+	RiderUpdatedV1{},
+	RiderLocationUpdatedV1{},
+	RiderPresenceLostV1{},
+	LocationAnomalyV1{},
+	ServiceAreaStatsV1{},
+}
+
+type User struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	LastName string `json:"lastName"`
+}
+
+type ServiceArea struct {
+	ID         int    `json:"id"`
+	Identifier string `json:"identifier"`
+}
+
+type Dimensions struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	Depth  int `json:"depth"`
+}
+
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Telemetry is what the rider's device reported with a fix, fields it did not report are left out.
+type Telemetry struct {
+	// Accuracy is in meters.
+	Accuracy *float64 `json:"accuracy,omitempty"`
+	// Speed is in meters per second.
+	Speed *float64 `json:"speed,omitempty"`
+	// Heading is in degrees clockwise from north.
+	Heading *float64 `json:"heading,omitempty"`
+	// Altitude is in meters.
+	Altitude *float64 `json:"altitude,omitempty"`
+	// Battery is in percent.
+	Battery *int `json:"battery,omitempty"`
+	// LowBattery is set when the last reported battery level is 20 percent or less.
+	LowBattery bool `json:"lowBattery,omitempty"`
+}
+
+// Rider is a rider as it is published when it is created or updated.
+type Rider struct {
+	UserID        string      `json:"userid"`
+	User          User        `json:"user"`
+	Status        int         `json:"status" enum:"0,1,2,3"`
+	ServiceAreaID int         `json:"serviceAreaId"`
+	ServiceArea   ServiceArea `json:"serviceArea"`
+	Capacity      Dimensions  `json:"capacity"`
+	VehicleType   string      `json:"vehicleType" enum:"bicycle,e-bike,cargo-bike"`
+	// DeliveryID is the delivery the rider is carrying, or assigned to when it carries none. It is left out while the
+	// rider has no delivery.
+	DeliveryID string `json:"deliveryId,omitempty"`
+	// RemainingCapacity is the room left in the rider's box for another parcel.
+	RemainingCapacity Dimensions `json:"remainingCapacity"`
+	// Location is left out while the rider is offline, it is coarse unless the rider is on a delivery.
+	Location   *Location  `json:"location,omitempty"`
+	Telemetry  Telemetry  `json:"telemetry"`
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+	// Version is the version of the rider, it is increased by every change.
+	Version int `json:"version"`
+}
+
+// RiderCreatedV1 is published to rider.create.
+type RiderCreatedV1 struct {
+	Rider
+}
+
+func (RiderCreatedV1) Schema() string {
+	return "rider.create.v1"
+}
+
+// RiderUpdatedV1 is published to rider.update.
+type RiderUpdatedV1 struct {
+	Rider
+	ChangedFields []string `json:"changedFields"`
+}
+
+func (RiderUpdatedV1) Schema() string {
+	return "rider.update.v1"
+}
+
+// LocationFix is a fix of a batch of buffered fixes.
+type LocationFix struct {
+	Latitude  float64    `json:"latitude"`
+	Longitude float64    `json:"longitude"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Sequence  int64      `json:"sequence,omitempty"`
+	Telemetry
+}
+
+// RiderLocationUpdatedV1 is published to rider.{service-area}.update.location. The location and telemetry are
+// those of the newest fix, Fixes is only set for a batch of buffered fixes and lists all of them, oldest first.
+type RiderLocationUpdatedV1 struct {
+	ID       string   `json:"id"`
+	Location Location `json:"location"`
+	Telemetry
+	Fixes []LocationFix `json:"fixes,omitempty"`
+}
+
+func (RiderLocationUpdatedV1) Schema() string {
+	return "rider.update.location.v1"
+}
+
+// RiderPresenceLostV1 is published to rider.presence.lost.
+type RiderPresenceLostV1 struct {
+	ID          string     `json:"id"`
+	ServiceArea string     `json:"serviceArea"`
+	LastSeenAt  *time.Time `json:"lastSeenAt,omitempty"`
+}
+
+func (RiderPresenceLostV1) Schema() string {
+	return "rider.presence.lost.v1"
+}
+
+// LocationAnomalyV1 is published to rider.location.anomaly.
+type LocationAnomalyV1 struct {
+	ID               string   `json:"id"`
+	Kind             string   `json:"kind" enum:"impossible_jump,mock_location"`
+	Location         Location `json:"location"`
+	PreviousLocation Location `json:"previousLocation"`
+	// ImpliedSpeed is in meters per second and left out when it is unknown.
+	ImpliedSpeed float64   `json:"impliedSpeed,omitempty"`
+	Rejected     bool      `json:"rejected"`
+	DetectedAt   time.Time `json:"detectedAt"`
+}
+
+func (LocationAnomalyV1) Schema() string {
+	return "rider.location.anomaly.v1"
+}
+
+// AverageDimensions is the average capacity of a number of riders.
+type AverageDimensions struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	Depth  float64 `json:"depth"`
+}
+
+// RidersByStatus counts riders per status.
+type RidersByStatus struct {
+	Offline    int `json:"offline"`
+	Available  int `json:"available"`
+	Assigned   int `json:"assigned"`
+	Delivering int `json:"delivering"`
+}
+
+// ServiceAreaStatsV1 is published to rider.stats.{serviceArea}.
+type ServiceAreaStatsV1 struct {
+	ServiceArea ServiceArea    `json:"serviceArea"`
+	Riders      int            `json:"riders"`
+	Online      int            `json:"online"`
+	ByStatus    RidersByStatus `json:"byStatus"`
+	// AverageCapacity is taken over the riders that are online.
+	AverageCapacity AverageDimensions `json:"averageCapacity"`
+	// StaleLocations counts the riders that are online but were not heard from for a while.
+	StaleLocations int       `json:"staleLocations"`
+	ComputedAt     time.Time `json:"computedAt"`
+}
+
+func (ServiceAreaStatsV1) Schema() string {
+	return "rider.stats.v1"
+}
@@ -0,0 +1,31 @@
+//go:build ignore
+
+// gen writes the JSON schema of every event to docs/events.
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"rider-service/pkg/events"
+)
+
+const schemaDir = "../../docs/events"
+
+func main() {
+	if err := os.MkdirAll(schemaDir, 0755); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, event := range events.All {
+		js, err := events.JSONSchema(event)
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err := os.WriteFile(filepath.Join(schemaDir, event.Schema()+".json"), js, 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
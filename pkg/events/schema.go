@@ -0,0 +1,154 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schema is the part of draft-07 JSON schema the events need.
+type schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+}
+
+// JSONSchema returns the JSON schema of the event. Fields without omitempty are required. Other properties are
+// allowed, so consumers that validate against the schema keep working when fields are added to the version.
+func JSONSchema(event Event) ([]byte, error) {
+	root, err := schemaOf(reflect.TypeOf(event), "")
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", event.Schema(), err)
+	}
+
+	root.Schema = "http://json-schema.org/draft-07/schema#"
+	root.Title = event.Schema()
+
+	js, err := json.MarshalIndent(root, "", "  ")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append(js, '\n'), nil
+}
+
+func schemaOf(typ reflect.Type, enum string) (*schema, error) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ == reflect.TypeOf(time.Time{}) {
+		return &schema{Type: "string", Format: "date-time"}, nil
+	}
+
+	var s *schema
+
+	switch typ.Kind() {
+	case reflect.String:
+		s = &schema{Type: "string"}
+	case reflect.Bool:
+		s = &schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = &schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		s = &schema{Type: "number"}
+	case reflect.Slice:
+		items, err := schemaOf(typ.Elem(), "")
+
+		if err != nil {
+			return nil, err
+		}
+
+		s = &schema{Type: "array", Items: items}
+	case reflect.Struct:
+		additional := true
+		s = &schema{Type: "object", Properties: map[string]*schema{}, AdditionalProperties: &additional}
+
+		if err := addFields(s, typ); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type %s", typ)
+	}
+
+	if enum != "" {
+		for _, value := range strings.Split(enum, ",") {
+			if s.Type != "integer" {
+				s.Enum = append(s.Enum, value)
+				continue
+			}
+
+			number, err := strconv.Atoi(value)
+
+			if err != nil {
+				return nil, fmt.Errorf("enum value %q is not an integer", value)
+			}
+
+			s.Enum = append(s.Enum, number)
+		}
+	}
+
+	return s, nil
+}
+
+// addFields adds the fields of the struct to the object schema, embedded structs without a name are inlined
+// like encoding/json does.
+func addFields(object *schema, typ reflect.Type) error {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		name := tag[0]
+
+		if name == "-" || (field.PkgPath != "" && !field.Anonymous) {
+			continue
+		}
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			if err := addFields(object, field.Type); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property, err := schemaOf(field.Type, field.Tag.Get("enum"))
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		object.Properties[name] = property
+
+		if !hasOption(tag[1:], "omitempty") {
+			object.Required = append(object.Required, name)
+		}
+	}
+
+	return nil
+}
+
+func hasOption(options []string, option string) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,69 @@
+package events
+
+import (
+	"github.com/stretchr/testify/suite"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const schemaDir = "../../docs/events"
+
+type SchemaTestSuite struct {
+	suite.Suite
+}
+
+func (suite *SchemaTestSuite) TestSchema_GeneratedSchemasAreUpToDate() {
+	for _, event := range All {
+		generated, err := JSONSchema(event)
+		suite.Require().NoError(err)
+
+		written, err := os.ReadFile(filepath.Join(schemaDir, event.Schema()+".json"))
+
+		if suite.NoError(err, "run go generate ./pkg/events") {
+			suite.Equal(string(generated), string(written), "%s is out of date, run go generate ./pkg/events", event.Schema())
+		}
+	}
+}
+
+func (suite *SchemaTestSuite) TestSchema_NoSchemaWithoutEvent() {
+	schemas := map[string]bool{}
+
+	for _, event := range All {
+		schemas[event.Schema()] = true
+	}
+
+	files, err := filepath.Glob(filepath.Join(schemaDir, "*.json"))
+	suite.Require().NoError(err)
+
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+		suite.True(schemas[name], "%s has no event in All", file)
+	}
+}
+
+func (suite *SchemaTestSuite) TestSchema_RequiredAndOptionalFields() {
+	js := mustSchema(suite, RiderUpdatedV1{})
+
+	suite.Contains(string(js), `"userid"`, "fields of embedded structs are inlined")
+	suite.Contains(string(js), `"changedFields"`)
+	suite.Contains(string(js), `"additionalProperties": true`, "fields can be added to a version")
+	suite.NotContains(string(js), `"Rider"`)
+
+	suite.JSONEq(`{"type": "object", "properties": {"id": {"type": "string"}, "serviceArea": {"type": "string"},
+		"lastSeenAt": {"type": "string", "format": "date-time"}}, "required": ["id", "serviceArea"],
+		"additionalProperties": true, "$schema": "http://json-schema.org/draft-07/schema#", "title": "rider.presence.lost.v1"}`,
+		string(mustSchema(suite, RiderPresenceLostV1{})))
+}
+
+func mustSchema(suite *SchemaTestSuite, event Event) []byte {
+	js, err := JSONSchema(event)
+	suite.Require().NoError(err)
+
+	return js
+}
+
+func TestUnit_SchemaTestSuite(t *testing.T) {
+	suite.Run(t, new(SchemaTestSuite))
+}
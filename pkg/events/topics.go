@@ -0,0 +1,127 @@
+package events
+
+import "strings"
+
+// The topics the service publishes to. The names leave out the prefix of the broker, "rider." on RabbitMQ
+// and "customer." on Azure Service Bus.
+const (
+	TopicRiderCreated = "create"
+	TopicRiderUpdated = "update"
+	// TopicRiderLocationUpdated is a topic per service area, use LocationTopic for the topic of an area.
+	TopicRiderLocationUpdated = "{serviceArea}.update.location"
+	TopicRiderPresenceLost    = "presence.lost"
+	TopicLocationAnomaly      = "location.anomaly"
+	// TopicServiceAreaStats is a topic per service area, use StatsTopic for the topic of an area.
+	TopicServiceAreaStats = "stats.{serviceArea}"
+)
+
+// LocationTopic returns the topic the locations of the riders in the service area are published to.
+func LocationTopic(serviceArea string) string {
+	return strings.Replace(TopicRiderLocationUpdated, "{serviceArea}", serviceArea, 1)
+}
+
+// StatsTopic returns the topic the stats of the riders in the service area are published to.
+func StatsTopic(serviceArea string) string {
+	return strings.Replace(TopicServiceAreaStats, "{serviceArea}", serviceArea, 1)
+}
+
+// Topic is a topic the service publishes to, with the event it publishes there.
+type Topic struct {
+	Name        string
+	Description string
+	Event       Event
+}
+
+// Topics are the topics the service publishes to, they are documented in the AsyncAPI document of the service.
+// A publisher that publishes to a topic that is not here fails the contract tests.
+var Topics = []Topic{
+	{
+		Name:        TopicRiderCreated,
+		Description: "Published when a rider is created.",
+		Event:       RiderCreatedV1{},
+	},
+	{
+		Name:        TopicRiderUpdated,
+		Description: "Published when a rider is updated, together with the fields that were changed.",
+		Event:       RiderUpdatedV1{},
+	},
+	{
+		Name: TopicRiderLocationUpdated,
+		Description: "Published when a rider sends a location, or a batch of buffered fixes. The location is exact while the rider " +
+			"is on a delivery and coarse otherwise, nothing is published while the rider is offline.",
+		Event: RiderLocationUpdatedV1{},
+	},
+	{
+		Name:        TopicRiderPresenceLost,
+		Description: "Published when a rider is set offline because it stopped sending locations and heartbeats.",
+		Event:       RiderPresenceLostV1{},
+	},
+	{
+		Name:        TopicLocationAnomaly,
+		Description: "Published when a location fix looks implausible.",
+		Event:       LocationAnomalyV1{},
+	},
+	{
+		Name: TopicServiceAreaStats,
+		Description: "Published periodically with an overview of the riders of a service area, for dashboards. Areas without " +
+			"riders are published too.",
+		Event: ServiceAreaStatsV1{},
+	},
+}
+
+// UserV1 is consumed from the topics of the user service. A user is only overwritten by a user with the same
+// or a higher version.
+type UserV1 struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	LastName string `json:"lastName"`
+	Version  int    `json:"version,omitempty"`
+}
+
+func (UserV1) Schema() string {
+	return "user.v1"
+}
+
+// ServiceAreaV1 is consumed from the topics of the service area service. A service area is only overwritten
+// by a service area with the same or a higher version.
+type ServiceAreaV1 struct {
+	ID         int    `json:"id"`
+	Identifier string `json:"identifier"`
+	Version    int    `json:"version,omitempty"`
+}
+
+func (ServiceAreaV1) Schema() string {
+	return "service_area.v1"
+}
+
+// ServiceAreaDeletedV1 is consumed from the topics of the service area service. The riders of the deleted
+// service area are moved to the service area ReassignTo, or set offline when it is left out.
+type ServiceAreaDeletedV1 struct {
+	ID         int `json:"id"`
+	Version    int `json:"version,omitempty"`
+	ReassignTo int `json:"reassignTo,omitempty"`
+}
+
+func (ServiceAreaDeletedV1) Schema() string {
+	return "service_area.delete.v1"
+}
+
+// Parcel is the size of a parcel with its weight in kilograms.
+type Parcel struct {
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	Depth  int     `json:"depth"`
+	Weight float64 `json:"weight,omitempty"`
+}
+
+// DeliveryV1 is consumed from the topics of the delivery service when a delivery is assigned to a rider, picked up,
+// completed or cancelled. The parcel is optional.
+type DeliveryV1 struct {
+	ID      string  `json:"id"`
+	RiderID string  `json:"riderId"`
+	Parcel  *Parcel `json:"parcel,omitempty"`
+}
+
+func (DeliveryV1) Schema() string {
+	return "delivery.v1"
+}
@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"encoding/json"
+	"rider-service/config"
+	"strings"
+)
+
+// redacted replaces the values of location keys when locations are redacted.
+const redacted = "[redacted]"
+
+// locationKeys are the lower case keys whose values are locations or coordinates.
+var locationKeys = map[string]bool{
+	"location":         true,
+	"previouslocation": true,
+	"latitude":         true,
+	"longitude":        true,
+}
+
+func isLocationKey(key string) bool {
+	return locationKeys[strings.ToLower(key)]
+}
+
+// redactKeysAndValues replaces the values of location keys when the config asks for locations to be redacted.
+func redactKeysAndValues(cfg *config.Config, keysAndValues []interface{}) []interface{} {
+	if cfg == nil || !cfg.Privacy.RedactLocations {
+		return keysAndValues
+	}
+
+	result := make([]interface{}, len(keysAndValues))
+	copy(result, keysAndValues)
+
+	for i := 0; i+1 < len(result); i += 2 {
+		if key, ok := result[i].(string); ok && isLocationKey(key) {
+			result[i+1] = redacted
+		}
+	}
+
+	return result
+}
+
+// RedactJSON returns the JSON document with the values of location keys replaced at any depth,
+// for recording message bodies in traces. Documents that are not valid JSON are redacted as a whole.
+func RedactJSON(js []byte) string {
+	var document interface{}
+
+	if err := json.Unmarshal(js, &document); err != nil {
+		return redacted
+	}
+
+	redactedJs, err := json.Marshal(redactValue(document))
+
+	if err != nil {
+		return redacted
+	}
+
+	return string(redactedJs)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isLocationKey(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,27 @@
+package logging
+
+import (
+	"github.com/stretchr/testify/assert"
+	"rider-service/config"
+	"testing"
+)
+
+func TestUnit_RedactJSON(t *testing.T) {
+	js := []byte(`{"Id":"test-id","Location":{"Latitude":1,"Longitude":2},"Fixes":[{"latitude":1,"longitude":2,"sequence":1}]}`)
+
+	assert.JSONEq(t,
+		`{"Id":"test-id","Location":"[redacted]","Fixes":[{"latitude":"[redacted]","longitude":"[redacted]","sequence":1}]}`,
+		RedactJSON(js))
+}
+
+func TestUnit_RedactKeysAndValues(t *testing.T) {
+	cfg := &config.Config{}
+	keysAndValues := []interface{}{"rider", "test-id", "location", "1,2"}
+
+	assert.Equal(t, keysAndValues, redactKeysAndValues(cfg, keysAndValues))
+
+	cfg.Privacy.RedactLocations = true
+
+	assert.Equal(t, []interface{}{"rider", "test-id", "location", "[redacted]"}, redactKeysAndValues(cfg, keysAndValues))
+	assert.Equal(t, "1,2", keysAndValues[3], "the caller's values are left alone")
+}
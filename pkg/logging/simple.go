@@ -27,17 +27,21 @@ func (l *SimpleLogger) Fatal(ctx context.Context, args ...interface{}) {
 }
 
 func (l *SimpleLogger) Info(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	keysAndValues = redactKeysAndValues(l.Config, keysAndValues)
 	fmt.Printf("INFO: "+msg+"\n", keysAndValues)
 }
 
 func (l *SimpleLogger) Debug(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	keysAndValues = redactKeysAndValues(l.Config, keysAndValues)
 	fmt.Printf("DEBUG: "+msg+"\n", keysAndValues)
 }
 
 func (l *SimpleLogger) Warning(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	keysAndValues = redactKeysAndValues(l.Config, keysAndValues)
 	fmt.Printf("WARNING: "+msg+"\n", keysAndValues)
 }
 
 func (l *SimpleLogger) Error(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	keysAndValues = redactKeysAndValues(l.Config, keysAndValues)
 	fmt.Printf("ERROR: "+msg+"\n", keysAndValues)
 }
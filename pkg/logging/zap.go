@@ -44,17 +44,21 @@ func (l *OtelzapSugaredLogger) Fatal(ctx context.Context, args ...interface{}) {
 }
 
 func (l *OtelzapSugaredLogger) Info(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	keysAndValues = redactKeysAndValues(l.Config, keysAndValues)
 	l.Logger.InfowContext(ctx, msg, keysAndValues...)
 }
 
 func (l *OtelzapSugaredLogger) Debug(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	keysAndValues = redactKeysAndValues(l.Config, keysAndValues)
 	l.Logger.Ctx(ctx).Debugw(msg, keysAndValues...)
 }
 
 func (l *OtelzapSugaredLogger) Warning(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	keysAndValues = redactKeysAndValues(l.Config, keysAndValues)
 	l.Logger.WarnwContext(ctx, msg, keysAndValues...)
 }
 
 func (l *OtelzapSugaredLogger) Error(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	keysAndValues = redactKeysAndValues(l.Config, keysAndValues)
 	l.Logger.ErrorwContext(ctx, msg, keysAndValues...)
 }
@@ -0,0 +1,327 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.5.1-go
+// source: rider.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// RiderServiceClient is the client API for RiderService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RiderServiceClient interface {
+	// GetRider is allowed for admins, dispatchers and the rider itself.
+	GetRider(ctx context.Context, in *GetRiderRequest, opts ...grpc.CallOption) (*Rider, error)
+	// ListRiders is allowed for admins.
+	ListRiders(ctx context.Context, in *ListRidersRequest, opts ...grpc.CallOption) (*ListRidersResponse, error)
+	// CreateRider is allowed for admins and the user that becomes the rider.
+	CreateRider(ctx context.Context, in *CreateRiderRequest, opts ...grpc.CallOption) (*Rider, error)
+	// UpdateRider is allowed for admins and the rider itself.
+	UpdateRider(ctx context.Context, in *UpdateRiderRequest, opts ...grpc.CallOption) (*Rider, error)
+	// UpdateRiderLocation is allowed for admins and the rider itself.
+	UpdateRiderLocation(ctx context.Context, in *UpdateRiderLocationRequest, opts ...grpc.CallOption) (*Rider, error)
+	// WatchRiders sends the riders that match the filter and then every change to them, until the call is cancelled.
+	// It is allowed for admins and dispatchers.
+	WatchRiders(ctx context.Context, in *WatchRidersRequest, opts ...grpc.CallOption) (RiderService_WatchRidersClient, error)
+}
+
+type riderServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRiderServiceClient(cc grpc.ClientConnInterface) RiderServiceClient {
+	return &riderServiceClient{cc}
+}
+
+func (c *riderServiceClient) GetRider(ctx context.Context, in *GetRiderRequest, opts ...grpc.CallOption) (*Rider, error) {
+	out := new(Rider)
+	err := c.cc.Invoke(ctx, "/rider.v1.RiderService/GetRider", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *riderServiceClient) ListRiders(ctx context.Context, in *ListRidersRequest, opts ...grpc.CallOption) (*ListRidersResponse, error) {
+	out := new(ListRidersResponse)
+	err := c.cc.Invoke(ctx, "/rider.v1.RiderService/ListRiders", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *riderServiceClient) CreateRider(ctx context.Context, in *CreateRiderRequest, opts ...grpc.CallOption) (*Rider, error) {
+	out := new(Rider)
+	err := c.cc.Invoke(ctx, "/rider.v1.RiderService/CreateRider", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *riderServiceClient) UpdateRider(ctx context.Context, in *UpdateRiderRequest, opts ...grpc.CallOption) (*Rider, error) {
+	out := new(Rider)
+	err := c.cc.Invoke(ctx, "/rider.v1.RiderService/UpdateRider", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *riderServiceClient) UpdateRiderLocation(ctx context.Context, in *UpdateRiderLocationRequest, opts ...grpc.CallOption) (*Rider, error) {
+	out := new(Rider)
+	err := c.cc.Invoke(ctx, "/rider.v1.RiderService/UpdateRiderLocation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *riderServiceClient) WatchRiders(ctx context.Context, in *WatchRidersRequest, opts ...grpc.CallOption) (RiderService_WatchRidersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &RiderService_ServiceDesc.Streams[0], "/rider.v1.RiderService/WatchRiders", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &riderServiceWatchRidersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RiderService_WatchRidersClient interface {
+	Recv() (*WatchRidersResponse, error)
+	grpc.ClientStream
+}
+
+type riderServiceWatchRidersClient struct {
+	grpc.ClientStream
+}
+
+func (x *riderServiceWatchRidersClient) Recv() (*WatchRidersResponse, error) {
+	m := new(WatchRidersResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RiderServiceServer is the server API for RiderService service.
+// All implementations must embed UnimplementedRiderServiceServer
+// for forward compatibility
+type RiderServiceServer interface {
+	// GetRider is allowed for admins, dispatchers and the rider itself.
+	GetRider(context.Context, *GetRiderRequest) (*Rider, error)
+	// ListRiders is allowed for admins.
+	ListRiders(context.Context, *ListRidersRequest) (*ListRidersResponse, error)
+	// CreateRider is allowed for admins and the user that becomes the rider.
+	CreateRider(context.Context, *CreateRiderRequest) (*Rider, error)
+	// UpdateRider is allowed for admins and the rider itself.
+	UpdateRider(context.Context, *UpdateRiderRequest) (*Rider, error)
+	// UpdateRiderLocation is allowed for admins and the rider itself.
+	UpdateRiderLocation(context.Context, *UpdateRiderLocationRequest) (*Rider, error)
+	// WatchRiders sends the riders that match the filter and then every change to them, until the call is cancelled.
+	// It is allowed for admins and dispatchers.
+	WatchRiders(*WatchRidersRequest, RiderService_WatchRidersServer) error
+	mustEmbedUnimplementedRiderServiceServer()
+}
+
+// UnimplementedRiderServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedRiderServiceServer struct {
+}
+
+func (UnimplementedRiderServiceServer) GetRider(context.Context, *GetRiderRequest) (*Rider, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRider not implemented")
+}
+func (UnimplementedRiderServiceServer) ListRiders(context.Context, *ListRidersRequest) (*ListRidersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRiders not implemented")
+}
+func (UnimplementedRiderServiceServer) CreateRider(context.Context, *CreateRiderRequest) (*Rider, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateRider not implemented")
+}
+func (UnimplementedRiderServiceServer) UpdateRider(context.Context, *UpdateRiderRequest) (*Rider, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateRider not implemented")
+}
+func (UnimplementedRiderServiceServer) UpdateRiderLocation(context.Context, *UpdateRiderLocationRequest) (*Rider, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateRiderLocation not implemented")
+}
+func (UnimplementedRiderServiceServer) WatchRiders(*WatchRidersRequest, RiderService_WatchRidersServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchRiders not implemented")
+}
+func (UnimplementedRiderServiceServer) mustEmbedUnimplementedRiderServiceServer() {}
+
+// UnsafeRiderServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RiderServiceServer will
+// result in compilation errors.
+type UnsafeRiderServiceServer interface {
+	mustEmbedUnimplementedRiderServiceServer()
+}
+
+func RegisterRiderServiceServer(s grpc.ServiceRegistrar, srv RiderServiceServer) {
+	s.RegisterService(&RiderService_ServiceDesc, srv)
+}
+
+func _RiderService_GetRider_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRiderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RiderServiceServer).GetRider(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rider.v1.RiderService/GetRider",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RiderServiceServer).GetRider(ctx, req.(*GetRiderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RiderService_ListRiders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRidersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RiderServiceServer).ListRiders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rider.v1.RiderService/ListRiders",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RiderServiceServer).ListRiders(ctx, req.(*ListRidersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RiderService_CreateRider_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRiderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RiderServiceServer).CreateRider(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rider.v1.RiderService/CreateRider",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RiderServiceServer).CreateRider(ctx, req.(*CreateRiderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RiderService_UpdateRider_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRiderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RiderServiceServer).UpdateRider(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rider.v1.RiderService/UpdateRider",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RiderServiceServer).UpdateRider(ctx, req.(*UpdateRiderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RiderService_UpdateRiderLocation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRiderLocationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RiderServiceServer).UpdateRiderLocation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rider.v1.RiderService/UpdateRiderLocation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RiderServiceServer).UpdateRiderLocation(ctx, req.(*UpdateRiderLocationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RiderService_WatchRiders_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRidersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RiderServiceServer).WatchRiders(m, &riderServiceWatchRidersServer{stream})
+}
+
+type RiderService_WatchRidersServer interface {
+	Send(*WatchRidersResponse) error
+	grpc.ServerStream
+}
+
+type riderServiceWatchRidersServer struct {
+	grpc.ServerStream
+}
+
+func (x *riderServiceWatchRidersServer) Send(m *WatchRidersResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// RiderService_ServiceDesc is the grpc.ServiceDesc for RiderService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RiderService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "rider.v1.RiderService",
+	HandlerType: (*RiderServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetRider",
+			Handler:    _RiderService_GetRider_Handler,
+		},
+		{
+			MethodName: "ListRiders",
+			Handler:    _RiderService_ListRiders_Handler,
+		},
+		{
+			MethodName: "CreateRider",
+			Handler:    _RiderService_CreateRider_Handler,
+		},
+		{
+			MethodName: "UpdateRider",
+			Handler:    _RiderService_UpdateRider_Handler,
+		},
+		{
+			MethodName: "UpdateRiderLocation",
+			Handler:    _RiderService_UpdateRiderLocation_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchRiders",
+			Handler:       _RiderService_WatchRiders_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "rider.proto",
+}